@@ -0,0 +1,192 @@
+// Command eval runs the golden transcripts through an AI provider and scores the outputs, so a
+// model or prompt change can be compared with the previous run before it ships.
+//
+//	go run ./cmd/eval -provider openai:gpt-4o-mini -prompt-version v2 -json v2.json -baseline v1.json
+//
+// API keys and the local endpoint are read from the same environment variables (or .env file)
+// as the server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+	"github.com/yourusername/yt-transcript-downloader/internal/eval"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	var (
+		providerSpec  = flag.String("provider", "mock", `provider to evaluate, as "provider[:model]"`)
+		judgeSpec     = flag.String("judge", "", `optional provider grading every output, as "provider[:model]"`)
+		fixturesDir   = flag.String("fixtures", "", "directory of *.json fixtures (default: the built-in golden dataset)")
+		promptsDir    = flag.String("prompts-dir", os.Getenv("AI_PROMPTS_DIR"), "directory of additional prompt versions")
+		promptVersion = flag.String("prompt-version", envOr("AI_PROMPT_VERSION", services.DefaultPromptVersion), "prompt version to evaluate")
+		jsonPath      = flag.String("json", "", "write the JSON report to this file")
+		markdownPath  = flag.String("markdown", "", "write the Markdown report to this file (default: stdout unless -json is set)")
+		baselinePath  = flag.String("baseline", "", "JSON report of a previous run to compare with")
+		failUnder     = flag.Float64("fail-under", 0, "exit with status 1 when the overall score is below this value")
+		timeout       = flag.Duration("timeout", eval.DefaultTimeout, "timeout of each provider call")
+		maxTokens     = flag.Int("max-tokens", 4000, "maximum tokens per response")
+		temperature   = flag.Float64("temperature", 0, "sampling temperature; keep 0 for comparable runs")
+	)
+	flag.Parse()
+
+	if err := run(options{
+		providerSpec:  *providerSpec,
+		judgeSpec:     *judgeSpec,
+		fixturesDir:   *fixturesDir,
+		promptsDir:    *promptsDir,
+		promptVersion: *promptVersion,
+		jsonPath:      *jsonPath,
+		markdownPath:  *markdownPath,
+		baselinePath:  *baselinePath,
+		failUnder:     *failUnder,
+		timeout:       *timeout,
+		maxTokens:     *maxTokens,
+		temperature:   *temperature,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "eval: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type options struct {
+	providerSpec  string
+	judgeSpec     string
+	fixturesDir   string
+	promptsDir    string
+	promptVersion string
+	jsonPath      string
+	markdownPath  string
+	baselinePath  string
+	failUnder     float64
+	timeout       time.Duration
+	maxTokens     int
+	temperature   float64
+}
+
+func run(opts options) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fixtures, err := loadFixtures(opts.fixturesDir)
+	if err != nil {
+		return fmt.Errorf("load fixtures: %w", err)
+	}
+
+	registry, err := services.LoadPromptRegistry(opts.promptsDir)
+	if err != nil {
+		return err
+	}
+	prompts, ok := registry.Get(opts.promptVersion)
+	if !ok {
+		return fmt.Errorf("%w: %s", services.ErrUnknownPromptVersion, opts.promptVersion)
+	}
+
+	provider, err := newProvider(opts.providerSpec, opts.maxTokens, opts.temperature)
+	if err != nil {
+		return err
+	}
+	runner := &eval.Runner{Provider: provider.Provider, Timeout: opts.timeout}
+	if opts.judgeSpec != "" {
+		judge, err := newProvider(opts.judgeSpec, opts.maxTokens, 0)
+		if err != nil {
+			return fmt.Errorf("judge: %w", err)
+		}
+		grader, ok := judge.Provider.(services.Grader)
+		if !ok {
+			return fmt.Errorf("judge: %s cannot grade", opts.judgeSpec)
+		}
+		runner.Judge = eval.NewJudge(grader)
+	}
+
+	var baseline *eval.Report
+	if opts.baselinePath != "" {
+		if baseline, err = eval.ReadReport(opts.baselinePath); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Evaluating %s with prompts %s on %d fixtures...\n", provider, prompts.Version, len(fixtures))
+	report := eval.NewReport(runner.Run(services.WithPromptSet(ctx, prompts), fixtures))
+	report.Provider = provider.Name
+	report.Model = provider.Model
+	report.PromptVersion = prompts.Version
+	report.Judge = opts.judgeSpec
+
+	if opts.jsonPath != "" {
+		if err := writeFile(opts.jsonPath, report.WriteJSON); err != nil {
+			return err
+		}
+	}
+	switch {
+	case opts.markdownPath != "":
+		err = writeFile(opts.markdownPath, func(w io.Writer) error { return report.WriteMarkdown(w, baseline) })
+	case opts.jsonPath == "":
+		err = report.WriteMarkdown(os.Stdout, baseline)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Score: %.3f\n", report.Score)
+	if report.Score < opts.failUnder {
+		return fmt.Errorf("score %.3f is below %.3f", report.Score, opts.failUnder)
+	}
+	return nil
+}
+
+func loadFixtures(dir string) ([]eval.Fixture, error) {
+	if dir == "" {
+		return eval.DefaultFixtures()
+	}
+	return eval.LoadFixtures(dir)
+}
+
+// newProvider builds a provider from a "provider[:model]" spec with the server's credentials.
+func newProvider(spec string, maxTokens int, temperature float64) (services.NamedProvider, error) {
+	name, model := config.ParseProviderSpec(spec)
+	cfg := &config.Config{
+		OpenAIAPIKey:    os.Getenv("OPENAI_API_KEY"),
+		AnthropicAPIKey: os.Getenv("ANTHROPIC_API_KEY"),
+		GoogleAPIKey:    os.Getenv("GOOGLE_API_KEY"),
+		LocalAIAPIKey:   os.Getenv("LOCAL_AI_API_KEY"),
+		LocalAIBaseURL:  os.Getenv("LOCAL_AI_BASE_URL"),
+	}
+	provider, err := services.NewNamedProvider(name, cfg.APIKeyFor(name), cfg.BaseURLFor(name), model, maxTokens, temperature)
+	if err != nil {
+		return services.NamedProvider{}, fmt.Errorf("%s: %w", spec, err)
+	}
+	return provider, nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -2,15 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/yourusername/yt-transcript-downloader/internal/api"
 	"github.com/yourusername/yt-transcript-downloader/internal/config"
 	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/logging"
 	"github.com/yourusername/yt-transcript-downloader/internal/services"
+	"github.com/yourusername/yt-transcript-downloader/internal/tracing"
 )
 
 func main() {
@@ -18,24 +24,74 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// `server migrate ...` manages the schema and exits without starting the API. It needs only
+	// the database settings, so it also works before the rest of the configuration is in place.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrateMain(ctx, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Load configuration
-	fmt.Println("📝 Loading configuration...")
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Println("✅ Configuration loaded successfully")
+
+	// Everything below logs through slog, including the standard log package.
+	logger, err := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to configure logging: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+	slog.Info("configuration loaded", "log_level", cfg.LogLevel)
+
+	if cfg.TracingEndpoint != "" {
+		shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+			Endpoint:    cfg.TracingEndpoint,
+			SampleRatio: cfg.TracingSampleRatio,
+			ServiceName: cfg.TracingServiceName,
+		})
+		if err != nil {
+			slog.Error("failed to configure tracing", "error", err)
+			os.Exit(1)
+		}
+		defer func() {
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(flushCtx); err != nil {
+				slog.Error("failed to flush traces", "error", err)
+			}
+		}()
+		slog.Info("tracing enabled", "endpoint", cfg.TracingEndpoint, "sample_ratio", cfg.TracingSampleRatio)
+	}
 
 	// Connect to database
-	fmt.Println("🔌 Connecting to database...")
+	slog.Info("connecting to database", "database", logging.Redact(cfg.ConnectionString()))
 	database, err := db.Connect(ctx, cfg.ConnectionString())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		slog.Error("failed to connect to database", "error", err)
 		os.Exit(1)
 	}
 	defer database.Close()
-	fmt.Println("✅ Database connected successfully")
+
+	migrator := db.NewMigrator(database)
+	if cfg.DBAutoMigrate {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			slog.Error("failed to apply database migrations", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("database migrations applied", "count", len(applied))
+	} else if _, _, err := migrator.SchemaVersion(ctx); errors.Is(err, db.ErrNotBaselined) {
+		// /readyz only reports this as degraded, so say it where operators look first.
+		slog.Warn("database schema has no recorded migrations; run `server migrate baseline <version>` "+
+			"with the last migration applied by hand", "error", err)
+	}
 
 	// Initialize dependencies
 	youtubeService := services.NewYouTubeService()
@@ -43,45 +99,55 @@ func main() {
 	transcriptRepo := db.NewTranscriptRepository(database)
 	summaryRepo := db.NewAISummaryRepository(database)
 	extractionRepo := db.NewAIExtractionRepository(database)
+	cleanViewRepo := db.NewTranscriptCleanViewRepository(database)
+	usageRepo := db.NewAIUsageRepository(database)
 
-	var aiProvider services.AIProvider
-	switch cfg.AIProvider {
-	case "openai":
-		openAIProvider, providerErr := services.NewOpenAIProvider(cfg.OpenAIAPIKey, cfg.AIModel, cfg.AIMaxTokens, cfg.AITemperature)
-		if providerErr != nil {
-			fmt.Fprintf(os.Stderr, "Failed to configure OpenAI provider: %v\n", providerErr)
-			os.Exit(1)
-		}
-		aiProvider = openAIProvider
-	case "anthropic":
-		anthropicProvider, providerErr := services.NewAnthropicProvider(cfg.AnthropicAPIKey, cfg.AIModel, cfg.AIMaxTokens, cfg.AITemperature)
-		if providerErr != nil {
-			fmt.Fprintf(os.Stderr, "Failed to configure Anthropic provider: %v\n", providerErr)
-			os.Exit(1)
-		}
-		aiProvider = anthropicProvider
-	case "google":
-		geminiProvider, providerErr := services.NewGeminiProvider(cfg.GoogleAPIKey, cfg.AIModel, cfg.AIMaxTokens, cfg.AITemperature)
-		if providerErr != nil {
-			fmt.Fprintf(os.Stderr, "Failed to configure Google Gemini provider: %v\n", providerErr)
-			os.Exit(1)
-		}
-		aiProvider = geminiProvider
-	default:
-		fmt.Fprintf(os.Stderr, "Unsupported AI provider: %s\n", cfg.AIProvider)
+	prices := aiPriceTable(cfg)
+	aiSvc, err := newAIService(cfg, newUsageMeter(usageRepo, prices), newBudgetEnforcer(cfg, usageRepo, prices))
+	if err != nil {
+		slog.Error("failed to configure AI providers", "error", err)
 		os.Exit(1)
 	}
 
-	aiSvc := services.NewAIService(aiProvider, cfg.AIModel)
-
 	// Create API server
-	fmt.Println("🏗️  Creating API server...")
-	server, err := api.NewServer(cfg, database, youtubeService, videoRepo, transcriptRepo, aiSvc, summaryRepo, extractionRepo)
+	serverOpts := []api.ServerOption{
+		api.WithTranscriptCleanRepository(cleanViewRepo),
+		api.WithLibraryRepositories(videoRepo, transcriptRepo),
+		api.WithUsageRepository(usageRepo),
+		api.WithAPIKeyRepository(db.NewAPIKeyRepository(database)),
+		api.WithUserRepositories(db.NewUserRepository(database), db.NewLibraryRepository(database)),
+		api.WithSchemaVersionReader(migrator),
+	}
+	if cfg.AIDistributedLocks {
+		serverOpts = append(serverOpts, api.WithGenerationLocker(db.NewAdvisoryLocker(database)))
+	}
+	if cfg.RateLimitStore == "postgres" {
+		serverOpts = append(serverOpts, api.WithRateLimitStore(db.NewRateLimitRepository(database)))
+	}
+	if cfg.OIDCIssuerURL != "" {
+		provider, err := services.NewOIDCProvider(ctx, services.OIDCConfig{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+			GroupsClaim:  cfg.OIDCGroupsClaim,
+		})
+		if err != nil {
+			slog.Error("failed to configure single sign-on", "error", err)
+			os.Exit(1)
+		}
+		serverOpts = append(serverOpts, api.WithOIDCProvider(provider))
+		slog.Info("single sign-on enabled", "issuer", provider.Issuer())
+	}
+	server, err := api.NewServer(cfg, database, youtubeService, videoRepo, transcriptRepo, aiSvc, summaryRepo, extractionRepo, serverOpts...)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create API server: %v\n", err)
+		slog.Error("failed to create API server", "error", err)
 		os.Exit(1)
 	}
-	fmt.Println("✅ API server created successfully")
+	if !cfg.AuthRequired {
+		slog.Warn("AUTH_REQUIRED is off: API routes accept requests without an API key")
+	}
 
 	// Setup signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -96,16 +162,153 @@ func main() {
 	// Wait for shutdown signal or server error
 	select {
 	case sig := <-sigChan:
-		fmt.Printf("\n📡 Received signal: %v\n", sig)
+		slog.Info("received signal, shutting down", "signal", sig.String())
 		cancel() // Cancel context to trigger graceful shutdown
 		// Wait for server to finish shutting down
 		<-serverErrChan
 	case err := <-serverErrChan:
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+			slog.Error("server error", "error", err)
 			os.Exit(1)
 		}
 	}
 
-	fmt.Println("👋 Server stopped")
+	slog.Info("server stopped")
+}
+
+// aiPriceTable merges AI_PRICES into the built-in per-model prices.
+func aiPriceTable(cfg *config.Config) services.PriceTable {
+	prices := make(services.PriceTable, len(cfg.AIPrices))
+	for model, price := range cfg.AIPrices {
+		prices[model] = services.ModelPrice(price)
+	}
+	return services.DefaultPriceTable.Merge(prices)
+}
+
+// newUsageMeter records every AI call in the usage ledger.
+func newUsageMeter(repo *db.AIUsageRepository, prices services.PriceTable) *services.UsageMeter {
+	recorder := services.UsageRecorderFunc(func(ctx context.Context, record services.UsageRecord) error {
+		return repo.CreateAIUsage(ctx, &db.AIUsage{
+			TranscriptID:     record.TranscriptID,
+			ClientKey:        record.ClientKey,
+			Provider:         record.Provider,
+			Model:            record.Model,
+			Operation:        record.Operation,
+			PromptVersion:    record.PromptVersion,
+			PromptTokens:     record.PromptTokens,
+			CompletionTokens: record.CompletionTokens,
+			LatencyMs:        record.Latency.Milliseconds(),
+			CostUSD:          record.CostUSD,
+			Success:          record.Success,
+			Error:            record.Error,
+		})
+	})
+	return services.NewUsageMeter(recorder, prices)
+}
+
+// newBudgetEnforcer checks AI_BUDGET and AI_KEY_BUDGET against the spend in the usage ledger.
+func newBudgetEnforcer(cfg *config.Config, repo *db.AIUsageRepository, prices services.PriceTable) *services.BudgetEnforcer {
+	spend := services.SpendReaderFunc(func(ctx context.Context, clientKey string, dayStart, monthStart time.Time) (services.Spend, error) {
+		spend, err := repo.GetAISpend(ctx, clientKey, dayStart, monthStart)
+		if err != nil {
+			return services.Spend{}, err
+		}
+		return services.Spend{
+			DailyTokens:   spend.DailyTokens,
+			DailyUSD:      spend.DailyCostUSD,
+			MonthlyTokens: spend.MonthlyTokens,
+			MonthlyUSD:    spend.MonthlyCostUSD,
+		}, nil
+	})
+	return services.NewBudgetEnforcer(services.Budget(cfg.AIBudget), services.Budget(cfg.AIKeyBudget), spend, prices)
+}
+
+// newMockProvider builds the offline "mock" provider with the configured latency and failures.
+func newMockProvider(cfg *config.Config, model string) (services.NamedProvider, error) {
+	mock, err := services.NewMockProvider(services.MockOptions{
+		Model:     model,
+		Latency:   time.Duration(cfg.MockAILatencyMs) * time.Millisecond,
+		Failure:   cfg.MockAIFailure,
+		FailEvery: cfg.MockAIFailureEvery,
+	})
+	if err != nil {
+		return services.NamedProvider{}, err
+	}
+	return services.NamedProvider{Name: "mock", Model: mock.Model(), Provider: mock}, nil
+}
+
+// newAIService builds the provider chain (AI_PROVIDER followed by AI_FALLBACK_PROVIDERS)
+// and the per-operation routes from AI_ROUTES. Each provider is metered, sits behind the
+// client-side rate limiter and is checked against the spending budgets first.
+func newAIService(cfg *config.Config, meter *services.UsageMeter, budgets *services.BudgetEnforcer) (*services.AIService, error) {
+	overrides := make(map[string]services.RateLimit, len(cfg.AIRateLimits))
+	for key, limit := range cfg.AIRateLimits {
+		overrides[key] = services.RateLimit(limit)
+	}
+	limiter := services.NewRateLimiter(services.RateLimit{
+		RequestsPerMinute: cfg.AIRequestsPerMinute,
+		TokensPerMinute:   cfg.AITokensPerMinute,
+		MaxConcurrency:    cfg.AIMaxConcurrency,
+	}, overrides, time.Duration(cfg.AIRateLimitMaxWaitSeconds)*time.Second)
+
+	build := func(spec string) (services.NamedProvider, error) {
+		name, model := config.ParseProviderSpec(spec)
+		var (
+			provider services.NamedProvider
+			err      error
+		)
+		if name == "mock" {
+			provider, err = newMockProvider(cfg, model)
+		} else {
+			provider, err = services.NewNamedProvider(name, cfg.APIKeyFor(name), cfg.BaseURLFor(name), model, cfg.AIMaxTokens, cfg.AITemperature)
+		}
+		if err != nil {
+			return services.NamedProvider{}, fmt.Errorf("%s: %w", spec, err)
+		}
+		return budgets.Guard(limiter.Limit(meter.Meter(provider))), nil
+	}
+
+	primary, err := build(cfg.AIProvider + ":" + cfg.AIModel)
+	if err != nil {
+		return nil, err
+	}
+	chain := []services.NamedProvider{primary}
+	for _, spec := range cfg.AIFallbackProviders {
+		provider, err := build(spec)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, provider)
+	}
+
+	prompts, err := newPromptSelector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts := []services.AIServiceOption{services.WithPromptSelector(prompts)}
+	for operation, spec := range cfg.AIRoutes {
+		provider, err := build(spec)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, services.WithRoute(operation, provider))
+	}
+
+	names := make([]string, 0, len(chain))
+	for _, provider := range chain {
+		names = append(names, provider.String())
+	}
+	slog.Info("AI providers configured", "chain", strings.Join(names, " -> "), "prompts", prompts.String())
+
+	return services.NewAIServiceWithChain(chain, opts...), nil
+}
+
+// newPromptSelector loads the embedded prompts plus AI_PROMPTS_DIR and sends
+// AI_PROMPT_CANDIDATE_PERCENT of the transcripts to AI_PROMPT_CANDIDATE.
+func newPromptSelector(cfg *config.Config) (*services.PromptSelector, error) {
+	registry, err := services.LoadPromptRegistry(cfg.AIPromptsDir)
+	if err != nil {
+		return nil, err
+	}
+	return services.NewPromptSelector(registry, cfg.AIPromptVersion, cfg.AIPromptCandidate, float64(cfg.AIPromptCandidatePercent)/100)
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/logging"
+)
+
+const migrateUsage = "usage: server migrate up | down [steps] | status | baseline <version>"
+
+// schemaMigrator is the part of db.Migrator that the migrate subcommand drives.
+type schemaMigrator interface {
+	Up(ctx context.Context) ([]int, error)
+	Down(ctx context.Context, steps int) ([]int, error)
+	Status(ctx context.Context) ([]db.MigrationStatus, error)
+	Baseline(ctx context.Context, version int) error
+}
+
+// migrateMain connects with the database settings alone and runs the migrate subcommand.
+func migrateMain(ctx context.Context, args []string) error {
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		return err
+	}
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	database, err := db.Connect(ctx, cfg.ConnectionString())
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	defer database.Close()
+	return runMigrate(ctx, db.NewMigrator(database), args, os.Stdout)
+}
+
+// runMigrate handles `server migrate up|down [steps]|status|baseline <version>`.
+func runMigrate(ctx context.Context, migrator schemaMigrator, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "Database is up to date.")
+			return nil
+		}
+		fmt.Fprintf(out, "Applied %d migration(s): %v\n", len(applied), applied)
+		return nil
+
+	case "down":
+		steps := 1
+		switch len(args) {
+		case 1:
+		case 2:
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid steps %q: must be a positive number", args[1])
+			}
+			steps = n
+		default:
+			return errors.New(migrateUsage)
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(out, "No migrations to roll back.")
+			return nil
+		}
+		fmt.Fprintf(out, "Rolled back %d migration(s): %v\n", len(reverted), reverted)
+		return nil
+
+	case "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return writeMigrationStatus(out, statuses)
+
+	case "baseline":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 1 {
+			return fmt.Errorf("invalid version %q: must be a positive number", args[1])
+		}
+		if err := migrator.Baseline(ctx, version); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Recorded migrations 1-%d as applied.\n", version)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate command %q; %s", args[0], migrateUsage)
+	}
+}
+
+func writeMigrationStatus(out io.Writer, statuses []db.MigrationStatus) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, status := range statuses {
+		appliedAt, note := "pending", ""
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		switch {
+		case status.Modified:
+			note = "modified since applied"
+		case status.Baselined:
+			note = "baselined"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", status.Version, status.Name, appliedAt, note)
+	}
+	return w.Flush()
+}
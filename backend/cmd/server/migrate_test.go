@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+)
+
+type fakeMigrator struct {
+	applied   []int
+	reverted  []int
+	statuses  []db.MigrationStatus
+	err       error
+	steps     int
+	baselined int
+}
+
+func (m *fakeMigrator) Up(context.Context) ([]int, error) {
+	return m.applied, m.err
+}
+
+func (m *fakeMigrator) Down(_ context.Context, steps int) ([]int, error) {
+	m.steps = steps
+	return m.reverted, m.err
+}
+
+func (m *fakeMigrator) Status(context.Context) ([]db.MigrationStatus, error) {
+	return m.statuses, m.err
+}
+
+func (m *fakeMigrator) Baseline(_ context.Context, version int) error {
+	m.baselined = version
+	return m.err
+}
+
+func TestRunMigrate_RejectsBadArguments(t *testing.T) {
+	for name, args := range map[string][]string{
+		"no command":          nil,
+		"unknown command":     {"sideways"},
+		"up with args":        {"up", "2"},
+		"status with args":    {"status", "all"},
+		"down with two args":  {"down", "1", "2"},
+		"down zero":           {"down", "0"},
+		"down not a number":   {"down", "all"},
+		"baseline no version": {"baseline"},
+		"baseline negative":   {"baseline", "-3"},
+		"baseline not number": {"baseline", "latest"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			migrator := &fakeMigrator{}
+			var out strings.Builder
+			assert.Error(t, runMigrate(context.Background(), migrator, args, &out))
+			assert.Empty(t, out.String())
+			assert.Zero(t, migrator.steps, "nothing is rolled back")
+			assert.Zero(t, migrator.baselined, "nothing is baselined")
+		})
+	}
+}
+
+func TestRunMigrate_ReportsResults(t *testing.T) {
+	ctx := context.Background()
+	run := func(migrator *fakeMigrator, args ...string) string {
+		t.Helper()
+		var out strings.Builder
+		require.NoError(t, runMigrate(ctx, migrator, args, &out))
+		return out.String()
+	}
+
+	assert.Equal(t, "Applied 2 migration(s): [10 11]\n", run(&fakeMigrator{applied: []int{10, 11}}, "up"))
+	assert.Equal(t, "Database is up to date.\n", run(&fakeMigrator{}, "up"))
+
+	migrator := &fakeMigrator{reverted: []int{11}}
+	assert.Equal(t, "Rolled back 1 migration(s): [11]\n", run(migrator, "down"))
+	assert.Equal(t, 1, migrator.steps, "down rolls back one migration by default")
+	run(migrator, "down", "3")
+	assert.Equal(t, 3, migrator.steps)
+	assert.Equal(t, "No migrations to roll back.\n", run(&fakeMigrator{}, "down"))
+
+	migrator = &fakeMigrator{}
+	assert.Equal(t, "Recorded migrations 1-10 as applied.\n", run(migrator, "baseline", "10"))
+	assert.Equal(t, 10, migrator.baselined)
+}
+
+func TestRunMigrate_ReturnsMigratorErrors(t *testing.T) {
+	failing := &fakeMigrator{err: db.ErrNotBaselined}
+	for _, args := range [][]string{{"up"}, {"down"}, {"status"}, {"baseline", "5"}} {
+		var out strings.Builder
+		err := runMigrate(context.Background(), failing, args, &out)
+		assert.True(t, errors.Is(err, db.ErrNotBaselined), "%v", args)
+		assert.Empty(t, out.String(), "%v", args)
+	}
+}
+
+func TestWriteMigrationStatus(t *testing.T) {
+	appliedAt := time.Date(2026, 10, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	statuses := []db.MigrationStatus{
+		{Migration: db.Migration{Version: 1, Name: "init"}, AppliedAt: &appliedAt, Baselined: true},
+		{Migration: db.Migration{Version: 2, Name: "indexes"}, AppliedAt: &appliedAt, Baselined: true, Modified: true},
+		{Migration: db.Migration{Version: 3, Name: "ai_extractions"}},
+	}
+
+	var out strings.Builder
+	require.NoError(t, writeMigrationStatus(&out, statuses))
+	lines := strings.Split(out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	assert.Equal(t, `VERSION  NAME            APPLIED AT            NOTE
+001      init            2026-10-01T10:30:00Z  baselined
+002      indexes         2026-10-01T10:30:00Z  modified since applied
+003      ai_extractions  pending
+`, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+)
+
+const (
+	apiKeyPrefix        = "ytk_"
+	apiKeyDisplayLength = 12
+	maxAPIKeyNameLength = 100
+)
+
+type createAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type apiKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RotatedAt  *time.Time `json:"rotated_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// apiKeySecretResponse carries the raw key, which is only ever shown on creation and rotation.
+type apiKeySecretResponse struct {
+	apiKeyResponse
+	Key string `json:"key"`
+}
+
+type apiKeyListResponse struct {
+	Keys []apiKeyResponse `json:"keys"`
+}
+
+// handleCreateAPIKey serves POST /api/v1/admin/keys.
+func (s *Server) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if s.apiKeys == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "API keys are not configured on this server")
+		return
+	}
+
+	var req createAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeStructuredError(w, http.StatusBadRequest, err, "Invalid JSON request body")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		writeStructuredError(w, http.StatusBadRequest, nil, fmt.Sprintf("name is required (at most %d characters)", maxAPIKeyNameLength))
+		return
+	}
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		writeStructuredError(w, http.StatusBadRequest, err, err.Error())
+		return
+	}
+
+	raw, err := generateAPIKey()
+	if err != nil {
+		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to generate API key")
+		return
+	}
+	key := &db.APIKey{Name: name, KeyPrefix: raw[:apiKeyDisplayLength], KeyHash: hashAPIKey(raw), Scopes: scopes}
+	if err := s.apiKeys.CreateAPIKey(r.Context(), key); err != nil {
+		writeRepositoryError(w, err, "Failed to create API key")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, apiKeySecretResponse{apiKeyResponse: buildAPIKeyResponse(key), Key: raw})
+}
+
+// handleListAPIKeys serves GET /api/v1/admin/keys, including revoked keys.
+func (s *Server) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if s.apiKeys == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "API keys are not configured on this server")
+		return
+	}
+
+	keys, err := s.apiKeys.ListAPIKeys(r.Context())
+	if err != nil {
+		writeRepositoryError(w, err, "Failed to list API keys")
+		return
+	}
+
+	response := apiKeyListResponse{Keys: make([]apiKeyResponse, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, buildAPIKeyResponse(key))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleRevokeAPIKey serves DELETE /api/v1/admin/keys/{keyID}.
+func (s *Server) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if s.apiKeys == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "API keys are not configured on this server")
+		return
+	}
+
+	id := chi.URLParam(r, "keyID")
+	if _, err := uuid.Parse(id); err != nil {
+		writeStructuredError(w, http.StatusNotFound, nil, "API key not found")
+		return
+	}
+	key, err := s.apiKeys.RevokeAPIKey(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeStructuredError(w, http.StatusNotFound, err, "API key not found")
+			return
+		}
+		writeRepositoryError(w, err, "Failed to revoke API key")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, buildAPIKeyResponse(key))
+}
+
+// handleRotateAPIKey serves POST /api/v1/admin/keys/{keyID}/rotate: the key gets a new secret
+// and keeps its ID, name and scopes.
+func (s *Server) handleRotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if s.apiKeys == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "API keys are not configured on this server")
+		return
+	}
+
+	id := chi.URLParam(r, "keyID")
+	if _, err := uuid.Parse(id); err != nil {
+		writeStructuredError(w, http.StatusNotFound, nil, "API key not found")
+		return
+	}
+	raw, err := generateAPIKey()
+	if err != nil {
+		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to generate API key")
+		return
+	}
+	key, err := s.apiKeys.RotateAPIKey(r.Context(), id, raw[:apiKeyDisplayLength], hashAPIKey(raw))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeStructuredError(w, http.StatusNotFound, err, "API key not found or revoked")
+			return
+		}
+		writeRepositoryError(w, err, "Failed to rotate API key")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiKeySecretResponse{apiKeyResponse: buildAPIKeyResponse(key), Key: raw})
+}
+
+// normalizeScopes lowercases and de-duplicates scopes, rejecting unknown ones.
+func normalizeScopes(requested []string) ([]string, error) {
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !slices.Contains(apiKeyScopes, scope) {
+			return nil, fmt.Errorf("invalid scope %q (use any of %s)", scope, strings.Join(apiKeyScopes, ", "))
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	return scopes, nil
+}
+
+// generateAPIKey returns a new random key with a recognizable prefix.
+func generateAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func buildAPIKeyResponse(key *db.APIKey) apiKeyResponse {
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return apiKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.KeyPrefix,
+		Scopes:     scopes,
+		CreatedAt:  key.CreatedAt,
+		RotatedAt:  key.RotatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+// API key and session scopes. The admin scope grants every other scope as well.
+const (
+	scopeTranscriptsRead  = "transcripts:read"
+	scopeTranscriptsWrite = "transcripts:write"
+	scopeTranscriptsAll   = "transcripts:all" // every user's transcripts, not only a library's
+	scopeAIWrite          = "ai:write"
+	scopeUsageRead        = "usage:read"
+	scopeAdmin            = "admin"
+)
+
+var apiKeyScopes = []string{scopeTranscriptsRead, scopeTranscriptsWrite, scopeTranscriptsAll, scopeAIWrite, scopeUsageRead, scopeAdmin}
+
+// apiKeyTouchInterval limits how often last_used_at is written for a busy key.
+const apiKeyTouchInterval = time.Minute
+
+type apiKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *db.APIKey) error
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (*db.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*db.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) (*db.APIKey, error)
+	RotateAPIKey(ctx context.Context, id, keyPrefix, keyHash string) (*db.APIKey, error)
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// WithAPIKeyRepository enables API keys stored in the database and the admin key endpoints.
+func WithAPIKeyRepository(repo apiKeyRepository) ServerOption {
+	return func(s *Server) {
+		s.apiKeys = repo
+	}
+}
+
+// apiPrincipal is the authenticated caller: a stored API key (KeyID), a signed-in user (UserID
+// and SessionID, plus Role after single sign-on) or the configured admin key (neither).
+type apiPrincipal struct {
+	KeyID     string
+	UserID    string
+	SessionID string
+	Name      string
+	Role      string
+	Scopes    []string
+
+	user *db.User
+}
+
+// clientKey attributes the caller's AI usage and budgets.
+func (p *apiPrincipal) clientKey() string {
+	if p.UserID != "" {
+		return p.UserID
+	}
+	return p.KeyID
+}
+
+func (p *apiPrincipal) hasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, scopeAdmin)
+}
+
+type principalContextKey struct{}
+
+func withPrincipal(ctx context.Context, principal *apiPrincipal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// principalFromContext returns the caller authenticated by requireScope, or nil.
+func principalFromContext(ctx context.Context) *apiPrincipal {
+	principal, _ := ctx.Value(principalContextKey{}).(*apiPrincipal)
+	return principal
+}
+
+var (
+	errInvalidAPIKey  = errors.New("invalid or revoked API key")
+	errInvalidSession = errors.New("invalid or expired session")
+)
+
+func isInvalidCredentials(err error) bool {
+	return errors.Is(err, errInvalidAPIKey) || errors.Is(err, errInvalidSession)
+}
+
+// requireScope only lets callers whose API key or session holds scope through. When
+// AUTH_REQUIRED is off, requests without valid credentials pass as before, except to admin
+// routes; valid credentials are always held to their scopes. Authenticated requests are
+// attributed to the key or user ID for budgets.
+func (s *Server) requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			enforced := s.config.AuthRequired || scope == scopeAdmin
+
+			raw := apiKeyFromRequest(r)
+			if raw == "" {
+				if enforced {
+					writeUnauthorized(w, "API key or session token required")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := s.authenticate(r.Context(), raw)
+			if err != nil {
+				if !enforced {
+					if !isInvalidCredentials(err) {
+						slog.WarnContext(r.Context(), "credential lookup failed", "error", err)
+					}
+					next.ServeHTTP(w, r)
+					return
+				}
+				writeAuthenticationError(w, r, err)
+				return
+			}
+
+			if !principal.hasScope(scope) {
+				writeStructuredErrorWithDetails(w, http.StatusForbidden, nil,
+					fmt.Sprintf("These credentials lack the %s scope", scope), map[string]string{"required_scope": scope})
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withAuthenticatedContext(r.Context(), principal)))
+		})
+	}
+}
+
+// requireSession only lets signed-in users through, for routes that act on the user's account.
+func (s *Server) requireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := apiKeyFromRequest(r)
+		if !strings.HasPrefix(raw, sessionTokenPrefix) {
+			writeUnauthorized(w, "Sign in required")
+			return
+		}
+		principal, err := s.authenticate(r.Context(), raw)
+		if err != nil {
+			writeAuthenticationError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withAuthenticatedContext(r.Context(), principal)))
+	})
+}
+
+// requireSessionScope also holds signed-in users to scope, for routes whose effects every user
+// shares. API keys only need the route's own scope.
+func requireSessionScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if principal := principalFromContext(r.Context()); principal != nil && principal.SessionID != "" && !principal.hasScope(scope) {
+				writeStructuredErrorWithDetails(w, http.StatusForbidden, nil,
+					fmt.Sprintf("Signed-in users need the %s scope for this action", scope), map[string]string{"required_scope": scope})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func withAuthenticatedContext(ctx context.Context, principal *apiPrincipal) context.Context {
+	ctx = withPrincipal(ctx, principal)
+	if key := principal.clientKey(); key != "" {
+		ctx = services.WithClientKey(ctx, key)
+	}
+	return ctx
+}
+
+func writeAuthenticationError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errInvalidAPIKey):
+		writeUnauthorized(w, "Invalid or revoked API key")
+	case errors.Is(err, errInvalidSession):
+		writeUnauthorized(w, "Session expired. Please sign in again.")
+	default:
+		slog.ErrorContext(r.Context(), "credential lookup failed", "error", err)
+		writeStructuredError(w, http.StatusServiceUnavailable, err, "Could not verify the credentials. Please try again later.")
+	}
+}
+
+// authenticate resolves a raw bearer token to its principal: a session token, the configured
+// admin key or an active stored key. Last use is recorded at most once per apiKeyTouchInterval.
+func (s *Server) authenticate(ctx context.Context, raw string) (*apiPrincipal, error) {
+	if strings.HasPrefix(raw, sessionTokenPrefix) {
+		return s.authenticateSession(ctx, raw)
+	}
+	if admin := s.config.AuthAdminKey; admin != "" && subtle.ConstantTimeCompare([]byte(raw), []byte(admin)) == 1 {
+		return &apiPrincipal{Name: "admin", Scopes: []string{scopeAdmin}}, nil
+	}
+	if s.apiKeys == nil {
+		return nil, errInvalidAPIKey
+	}
+
+	key, err := s.apiKeys.GetActiveAPIKeyByHash(ctx, hashAPIKey(raw))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, errInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.apiKeys.TouchAPIKey(ctx, key.ID, now); err != nil {
+			slog.WarnContext(ctx, "failed to record use of API key", "key_id", key.ID, "error", err)
+		}
+	}
+	return &apiPrincipal{KeyID: key.ID, Name: key.Name, Scopes: key.Scopes}, nil
+}
+
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	writeStructuredError(w, http.StatusUnauthorized, nil, message)
+}
+
+// hashAPIKey is the digest stored for a key. Keys are random, so a plain SHA-256 suffices.
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+const testAdminKey = "admin-key-0123456789abcdef0123456789"
+
+// stubAPIKeyRepo keeps keys in memory by hash.
+type stubAPIKeyRepo struct {
+	keys    []*db.APIKey
+	touched []string
+	err     error
+}
+
+func (r *stubAPIKeyRepo) CreateAPIKey(_ context.Context, key *db.APIKey) error {
+	if r.err != nil {
+		return r.err
+	}
+	key.ID = uuid.NewString()
+	key.CreatedAt = time.Now()
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *stubAPIKeyRepo) GetActiveAPIKeyByHash(_ context.Context, keyHash string) (*db.APIKey, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash && key.RevokedAt == nil {
+			return key, nil
+		}
+	}
+	return nil, db.ErrNotFound
+}
+
+func (r *stubAPIKeyRepo) ListAPIKeys(context.Context) ([]*db.APIKey, error) {
+	return r.keys, r.err
+}
+
+func (r *stubAPIKeyRepo) find(id string) *db.APIKey {
+	for _, key := range r.keys {
+		if key.ID == id {
+			return key
+		}
+	}
+	return nil
+}
+
+func (r *stubAPIKeyRepo) RevokeAPIKey(_ context.Context, id string) (*db.APIKey, error) {
+	key := r.find(id)
+	if key == nil {
+		return nil, db.ErrNotFound
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return key, nil
+}
+
+func (r *stubAPIKeyRepo) RotateAPIKey(_ context.Context, id, keyPrefix, keyHash string) (*db.APIKey, error) {
+	key := r.find(id)
+	if key == nil || key.RevokedAt != nil {
+		return nil, db.ErrNotFound
+	}
+	now := time.Now()
+	key.KeyPrefix, key.KeyHash, key.RotatedAt = keyPrefix, keyHash, &now
+	return key, nil
+}
+
+func (r *stubAPIKeyRepo) TouchAPIKey(_ context.Context, id string, usedAt time.Time) error {
+	r.touched = append(r.touched, id)
+	if key := r.find(id); key != nil {
+		key.LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+func newAuthTestServer(t *testing.T, required bool, repo *stubAPIKeyRepo) *Server {
+	t.Helper()
+	cfg := &config.Config{APIPort: 8080, AuthRequired: required, AuthAdminKey: testAdminKey}
+	server, err := NewServer(cfg, &mockDB{}, noopYouTubeService{}, &recordingVideoRepo{}, &recordingTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{},
+		WithUsageRepository(&stubUsageRepo{}), WithAPIKeyRepository(repo))
+	require.NoError(t, err)
+	return server
+}
+
+func serveWithKey(server *Server, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func createTestKey(t *testing.T, server *Server, scopes ...string) apiKeySecretResponse {
+	t.Helper()
+	body, err := json.Marshal(createAPIKeyRequest{Name: "test", Scopes: scopes})
+	require.NoError(t, err)
+	rec := serveWithKey(server, http.MethodPost, "/api/v1/admin/keys", testAdminKey, string(body))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created apiKeySecretResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	return created
+}
+
+func TestRequireScope_AuthRequired(t *testing.T) {
+	repo := &stubAPIKeyRepo{}
+	server := newAuthTestServer(t, true, repo)
+
+	rec := serveWithKey(server, http.MethodGet, "/api/v1/usage", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `Bearer realm="api"`, rec.Header().Get("WWW-Authenticate"))
+
+	rec = serveWithKey(server, http.MethodGet, "/api/v1/usage", "ytk_unknown", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Invalid or revoked API key")
+
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, "/api/v1/health", "", "").Code, "health stays open")
+
+	created := createTestKey(t, server, "usage:read")
+	assert.True(t, strings.HasPrefix(created.Key, apiKeyPrefix))
+	assert.Equal(t, created.Key[:apiKeyDisplayLength], created.Prefix)
+	assert.Equal(t, hashAPIKey(created.Key), repo.keys[0].KeyHash, "only the digest is stored")
+
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, "/api/v1/usage", created.Key, "").Code)
+	assert.Equal(t, []string{created.ID}, repo.touched)
+	serveWithKey(server, http.MethodGet, "/api/v1/usage", created.Key, "")
+	assert.Len(t, repo.touched, 1, "last use is recorded at most once a minute")
+
+	rec = serveWithKey(server, http.MethodPost, "/api/v1/transcripts/abc/summarize", created.Key, `{}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"required_scope":"ai:write"`)
+
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(server, http.MethodGet, "/api/v1/admin/keys", created.Key+"x", "").Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(server, http.MethodGet, "/api/v1/admin/keys", created.Key, "").Code)
+}
+
+func TestRequireScope_AuthOptional(t *testing.T) {
+	repo := &stubAPIKeyRepo{}
+	server := newAuthTestServer(t, false, repo)
+
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, "/api/v1/usage", "", "").Code)
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, "/api/v1/usage", "not-a-stored-key", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(server, http.MethodGet, "/api/v1/admin/keys", "", "").Code, "admin routes always need a key")
+
+	created := createTestKey(t, server, "transcripts:read")
+	assert.Equal(t, http.StatusForbidden, serveWithKey(server, http.MethodGet, "/api/v1/usage", created.Key, "").Code, "a valid key is held to its scopes")
+}
+
+func TestRequireScope_AttributesClientKeyAndReportsLookupFailures(t *testing.T) {
+	repo := &stubAPIKeyRepo{}
+	server := newAuthTestServer(t, true, repo)
+	created := createTestKey(t, server, "ai:write")
+
+	var clientKey string
+	handler := server.requireScope(scopeAIWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientKey = services.ClientKeyFromContext(r.Context())
+		assert.Equal(t, "test", principalFromContext(r.Context()).Name)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-API-Key", created.Key)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, created.ID, clientKey, "stored keys are attributed to their ID")
+
+	repo.err = errors.New("database connection failed: connection refused")
+	rec := serveWithKey(server, http.MethodGet, "/api/v1/usage", created.Key, "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestAPIKeyAdminEndpoints(t *testing.T) {
+	repo := &stubAPIKeyRepo{}
+	server := newAuthTestServer(t, true, repo)
+
+	rec := serveWithKey(server, http.MethodPost, "/api/v1/admin/keys", testAdminKey, `{"name": "ci", "scopes": ["root"]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `invalid scope \"root\"`)
+	rec = serveWithKey(server, http.MethodPost, "/api/v1/admin/keys", testAdminKey, `{"name": "ci", "scopes": []}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serveWithKey(server, http.MethodPost, "/api/v1/admin/keys", testAdminKey, `{"name": " ", "scopes": ["admin"]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	created := createTestKey(t, server, "Transcripts:Read", "transcripts:read", "ai:write")
+	assert.Equal(t, []string{"transcripts:read", "ai:write"}, created.Scopes)
+
+	rec = serveWithKey(server, http.MethodGet, "/api/v1/admin/keys", testAdminKey, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), created.Key, "listings never include the key")
+	assert.NotContains(t, rec.Body.String(), repo.keys[0].KeyHash)
+	var list apiKeyListResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Keys, 1)
+	assert.Equal(t, created.ID, list.Keys[0].ID)
+
+	rec = serveWithKey(server, http.MethodPost, "/api/v1/admin/keys/"+created.ID+"/rotate", testAdminKey, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var rotated apiKeySecretResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rotated))
+	assert.Equal(t, created.ID, rotated.ID)
+	assert.NotEqual(t, created.Key, rotated.Key)
+	assert.NotNil(t, rotated.RotatedAt)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(server, http.MethodGet, "/api/v1/transcripts/x/clean", created.Key, "").Code, "the old secret stops working")
+
+	rec = serveWithKey(server, http.MethodDelete, "/api/v1/admin/keys/"+created.ID, testAdminKey, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"revoked_at"`)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(server, http.MethodGet, "/api/v1/transcripts/x/clean", rotated.Key, "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(server, http.MethodPost, "/api/v1/admin/keys/"+created.ID+"/rotate", testAdminKey, "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(server, http.MethodDelete, "/api/v1/admin/keys/not-a-uuid", testAdminKey, "").Code)
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// apiKeyFromRequest returns the key sent in X-API-Key or as an Authorization bearer token. It is
+// only a claim: callers are identified for budgets and the usage ledger once requireScope has
+// authenticated it, and anonymous traffic shares the global budget.
+func apiKeyFromRequest(r *http.Request) string {
+	key := strings.TrimSpace(r.Header.Get("X-API-Key"))
+	if key == "" {
+		auth := strings.TrimSpace(r.Header.Get("Authorization"))
+		if len(auth) > len("Bearer ") && strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+			key = strings.TrimSpace(auth[len("Bearer "):])
+		}
+	}
+	return key
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+func TestAPIKeyFromRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Empty(t, apiKeyFromRequest(req))
+
+	req.Header.Set("Authorization", "Bearer secret-key")
+	assert.Equal(t, "secret-key", apiKeyFromRequest(req))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", " secret-key ")
+	assert.Equal(t, "secret-key", apiKeyFromRequest(req))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	assert.Empty(t, apiKeyFromRequest(req))
+}
+
+func TestRequireScope_UnauthenticatedCallersHaveNoClientKey(t *testing.T) {
+	repo := &stubAPIKeyRepo{}
+	server := newAuthTestServer(t, false, repo)
+
+	clientKey := "unset"
+	handler := server.requireScope(scopeAIWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientKey = services.ClientKeyFromContext(r.Context())
+	}))
+	for _, header := range []string{"", "someone-elses-key", "rotated-every-request"} {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if header != "" {
+			req.Header.Set("X-API-Key", header)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Empty(t, clientKey, "an unverified %q header must not pick the budget bucket", header)
+	}
+}
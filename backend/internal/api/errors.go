@@ -1,8 +1,17 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
 )
 
 type ErrorResponse struct {
@@ -10,11 +19,18 @@ type ErrorResponse struct {
 	Message    string            `json:"message,omitempty"`
 	StatusCode int               `json:"status_code"`
 	Details    map[string]string `json:"details,omitempty"`
+	TraceID    string            `json:"trace_id,omitempty"`
 }
 
 func writeStructuredError(w http.ResponseWriter, statusCode int, err error, userMessage string) {
+	writeStructuredErrorWithDetails(w, statusCode, err, userMessage, nil)
+}
+
+func writeStructuredErrorWithDetails(w http.ResponseWriter, statusCode int, err error, userMessage string, details map[string]string) {
 	response := ErrorResponse{
 		StatusCode: statusCode,
+		Details:    details,
+		TraceID:    w.Header().Get(traceIDHeader),
 	}
 
 	if userMessage != "" {
@@ -29,3 +45,81 @@ func writeStructuredError(w http.ResponseWriter, statusCode int, err error, user
 	w.WriteHeader(statusCode)
 	_ = json.NewEncoder(w).Encode(response)
 }
+
+// writeRepositoryError answers 503 when the database is unreachable and 500 with message otherwise.
+func writeRepositoryError(w http.ResponseWriter, err error, message string) {
+	if isDatabaseUnavailableError(err) {
+		writeStructuredError(w, http.StatusServiceUnavailable, err, "Database unavailable. Please try again later.")
+		return
+	}
+	writeStructuredError(w, http.StatusInternalServerError, err, message)
+}
+
+// setRetryAfterHeader tells a client rejected by the local AI rate limiter when to try again.
+func setRetryAfterHeader(w http.ResponseWriter, err error) {
+	var limitErr *services.RateLimitError
+	if errors.As(err, &limitErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(limitErr.RetryAfter.Seconds())))))
+	}
+}
+
+// writeAIError maps an AI service error to a status code and a user message. action completes
+// "Failed to ..." for unexpected errors and timeoutHint follows the timeout message.
+func writeAIError(w http.ResponseWriter, err error, action, timeoutHint string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidChapters):
+		writeStructuredError(w, http.StatusBadGateway, err, "AI returned chapters that do not match the transcript timeline. Please try again.")
+	case errors.Is(err, services.ErrAIBudgetExceeded):
+		writeBudgetError(w, err)
+	case errors.Is(err, services.ErrAIRequestLimitExceeded):
+		setRetryAfterHeader(w, err)
+		writeStructuredError(w, http.StatusTooManyRequests, err, "Too many AI requests are in progress. Please wait a moment and try again.")
+	case errors.Is(err, services.ErrAIRateLimited):
+		writeStructuredError(w, http.StatusTooManyRequests, err, "AI rate limit reached. Please wait a moment and try again.")
+	case errors.Is(err, services.ErrAIQuotaExceeded):
+		writeStructuredError(w, http.StatusPaymentRequired, err, "AI quota exceeded. Please contact the administrator.")
+	case errors.Is(err, services.ErrAIServiceUnavailable):
+		writeStructuredError(w, http.StatusServiceUnavailable, err, "AI service is temporarily unavailable. Please try again in a few moments.")
+	case errors.Is(err, services.ErrAIProviderNotConfigured):
+		writeStructuredError(w, http.StatusServiceUnavailable, err, "AI service is not configured. Please contact the administrator.")
+	case errors.Is(err, context.DeadlineExceeded):
+		writeStructuredError(w, http.StatusGatewayTimeout, err, "AI request timed out. "+timeoutHint)
+	case errors.Is(err, context.Canceled):
+		writeStructuredError(w, http.StatusRequestTimeout, err, "Request was canceled.")
+	case isAIFormatError(err):
+		writeStructuredError(w, http.StatusInternalServerError, err, "AI response format error. The AI returned an invalid response. Please try again.")
+	default:
+		writeStructuredError(w, http.StatusInternalServerError, err, fmt.Sprintf("Failed to %s: %v", action, err))
+	}
+}
+
+// isAIFormatError reports errors from parsing the model output.
+func isAIFormatError(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "parse") || strings.Contains(message, "unmarshal") || strings.Contains(message, "json")
+}
+
+// writeBudgetError answers 402 with the exhausted budget, what is left of it and when it resets.
+func writeBudgetError(w http.ResponseWriter, err error) {
+	var budgetErr *services.BudgetError
+	if !errors.As(err, &budgetErr) {
+		writeStructuredError(w, http.StatusPaymentRequired, err, "AI budget exhausted. Please contact the administrator.")
+		return
+	}
+
+	if wait := time.Until(budgetErr.ResetsAt); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	}
+	message := fmt.Sprintf("The %s AI budget is exhausted. It resets at %s.", budgetErr.Period, budgetErr.ResetsAt.Format(time.RFC3339))
+	if budgetErr.Scope == services.BudgetScopeKey {
+		message = fmt.Sprintf("The %s AI budget for this API key is exhausted. It resets at %s.", budgetErr.Period, budgetErr.ResetsAt.Format(time.RFC3339))
+	}
+	writeStructuredErrorWithDetails(w, http.StatusPaymentRequired, err, message, map[string]string{
+		"scope":     budgetErr.Scope,
+		"period":    budgetErr.Period,
+		"unit":      budgetErr.Unit,
+		"limit":     strconv.FormatFloat(budgetErr.Limit, 'f', -1, 64),
+		"remaining": strconv.FormatFloat(budgetErr.Remaining, 'f', -1, 64),
+		"resets_at": budgetErr.ResetsAt.Format(time.RFC3339),
+	})
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -15,12 +15,18 @@ import (
 )
 
 const (
-	exportFormatJSON = "json"
-	exportFormatText = "text"
+	exportFormatJSON     = "json"
+	exportFormatText     = "text"
+	exportFormatSRT      = "srt"
+	exportFormatMarkdown = "markdown"
+	exportFormatHTML     = "html"
+	exportFormatChapters = "chapters"
 )
 
 // handleExportTranscript supports GET /api/v1/transcripts/{id}/export requests.
-// Supported formats: json (default) and text (plain text).
+// Supported formats: json (default), text (plain text), srt (SubRip subtitles), markdown and html
+// (transcript bundled with every cached summary and extraction) and chapters (YouTube description chapters).
+// The text format accepts view=clean to render the stored sentence/paragraph view instead of raw segments.
 func (s *Server) handleExportTranscript(w http.ResponseWriter, r *http.Request) {
 	transcriptID := strings.TrimSpace(chi.URLParam(r, "id"))
 	if transcriptID == "" {
@@ -33,14 +39,14 @@ func (s *Server) handleExportTranscript(w http.ResponseWriter, r *http.Request)
 		format = exportFormatJSON
 	}
 	if !isSupportedExportFormat(format) {
-		writeStructuredError(w, http.StatusBadRequest, nil, "Unsupported export format. Use json or text.")
+		writeStructuredError(w, http.StatusBadRequest, nil, "Unsupported export format. Use json, text, srt, markdown, html, or chapters.")
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	transcript, video, apiErr := s.lookupTranscriptBundle(ctx, r.Method, r.URL.Path, transcriptID)
+	transcript, video, apiErr := s.lookupTranscriptBundle(ctx, transcriptID)
 	if apiErr != nil {
 		writeStructuredError(w, apiErr.status, apiErr.err, apiErr.message)
 		return
@@ -54,11 +60,80 @@ func (s *Server) handleExportTranscript(w http.ResponseWriter, r *http.Request)
 		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
 		writeJSON(w, http.StatusOK, payload)
 	case exportFormatText:
+		body := ""
+		switch view := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("view"))); view {
+		case "", "raw":
+			body = buildPlainTextExport(payload)
+		case "clean":
+			cleanView, apiErr := s.lookupCleanView(ctx, transcriptID)
+			if apiErr != nil {
+				writeStructuredError(w, apiErr.status, apiErr.err, apiErr.message)
+				return
+			}
+			body = buildCleanTextExport(payload, cleanView)
+		default:
+			writeStructuredError(w, http.StatusBadRequest, nil, "Unsupported view. Use raw or clean.")
+			return
+		}
+
 		filename := fmt.Sprintf("transcript-%s.txt", transcriptID)
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(buildPlainTextExport(payload)))
+		_, _ = w.Write([]byte(body))
+	case exportFormatSRT:
+		filename := fmt.Sprintf("transcript-%s.srt", transcriptID)
+		w.Header().Set("Content-Type", "application/x-subrip; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(buildSRTExport(payload)))
+	case exportFormatMarkdown, exportFormatHTML:
+		doc, apiErr := s.loadExportDocument(ctx, transcript, video)
+		if apiErr != nil {
+			writeStructuredError(w, apiErr.status, apiErr.err, apiErr.message)
+			return
+		}
+
+		body := buildMarkdownExport(doc)
+		contentType := "text/markdown; charset=utf-8"
+		filename := fmt.Sprintf("transcript-%s.md", transcriptID)
+		if format == exportFormatHTML {
+			rendered, err := buildHTMLExport(doc)
+			if err != nil {
+				logAPILookupError(ctx, "render html export", err)
+				writeStructuredError(w, http.StatusInternalServerError, err, "Failed to render export")
+				return
+			}
+			body = rendered
+			contentType = "text/html; charset=utf-8"
+			filename = fmt.Sprintf("transcript-%s.html", transcriptID)
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(body))
+	case exportFormatChapters:
+		summary, err := s.aiSummaryRepo.GetAISummary(ctx, transcriptID, summaryTypeChapters)
+		if err != nil {
+			if errorsIsNotFound(err) {
+				writeStructuredError(w, http.StatusNotFound, err, "Chapters have not been generated for this transcript yet")
+				return
+			}
+			logAPILookupError(ctx, "get chapters summary", err)
+			writeStructuredError(w, http.StatusInternalServerError, err, "Failed to load chapters")
+			return
+		}
+		if len(summary.Content.Chapters) == 0 {
+			writeStructuredError(w, http.StatusNotFound, nil, "Chapters have not been generated for this transcript yet")
+			return
+		}
+
+		filename := fmt.Sprintf("chapters-%s.txt", transcriptID)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(buildYouTubeChaptersExport(summary.Content.Chapters)))
 	}
 }
 
@@ -68,7 +143,18 @@ type apiError struct {
 	message string
 }
 
-func (s *Server) lookupTranscriptBundle(ctx context.Context, method, path, transcriptID string) (*db.Transcript, *db.Video, *apiError) {
+func (e *apiError) Error() string {
+	if e.err != nil {
+		return e.message + ": " + e.err.Error()
+	}
+	return e.message
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+func (s *Server) lookupTranscriptBundle(ctx context.Context, transcriptID string) (*db.Transcript, *db.Video, *apiError) {
 	transcript, err := s.transcriptRepo.GetTranscriptByID(ctx, transcriptID)
 	if err != nil {
 		if errorsIsNotFound(err) {
@@ -85,7 +171,7 @@ func (s *Server) lookupTranscriptBundle(ctx context.Context, method, path, trans
 				message: "Database unavailable. Please try again later.",
 			}
 		}
-		logAPILookupError(method, path, "get transcript", err)
+		logAPILookupError(ctx, "get transcript", err)
 		return nil, nil, &apiError{
 			status:  http.StatusInternalServerError,
 			err:     err,
@@ -109,7 +195,7 @@ func (s *Server) lookupTranscriptBundle(ctx context.Context, method, path, trans
 				message: "Database unavailable. Please try again later.",
 			}
 		}
-		logAPILookupError(method, path, "get video", err)
+		logAPILookupError(ctx, "get video", err)
 		return nil, nil, &apiError{
 			status:  http.StatusInternalServerError,
 			err:     err,
@@ -127,18 +213,106 @@ func normalizeExportFormat(format string) string {
 		return exportFormatJSON
 	case "text", "txt", "plain", "plaintext":
 		return exportFormatText
+	case "markdown", "md":
+		return exportFormatMarkdown
+	case "html", "htm":
+		return exportFormatHTML
+	case "srt", "subrip":
+		return exportFormatSRT
+	case "chapters", "youtube_chapters":
+		return exportFormatChapters
 	default:
 		return format
 	}
 }
 
 func isSupportedExportFormat(format string) bool {
-	return format == exportFormatJSON || format == exportFormatText
+	switch format {
+	case exportFormatJSON, exportFormatText, exportFormatSRT, exportFormatMarkdown, exportFormatHTML, exportFormatChapters:
+		return true
+	default:
+		return false
+	}
 }
 
 func buildPlainTextExport(resp TranscriptResponse) string {
 	var b strings.Builder
 
+	writePlainTextHeader(&b, resp)
+
+	for _, line := range resp.Transcript {
+		text := strings.TrimSpace(line.Text)
+		if text == "" {
+			continue
+		}
+		b.WriteString("[")
+		b.WriteString(formatTimestamp(line.Start))
+		b.WriteString("] ")
+		if line.Speaker != "" {
+			b.WriteString(line.Speaker)
+			b.WriteString(": ")
+		}
+		b.WriteString(text)
+		b.WriteRune('\n')
+	}
+
+	return b.String()
+}
+
+// buildSRTExport renders transcript lines as SubRip cues. The speaker label is prefixed to a cue
+// whenever the speaker changes so subtitles stay readable during long turns.
+func buildSRTExport(resp TranscriptResponse) string {
+	var b strings.Builder
+
+	index := 0
+	previousSpeaker := ""
+	for _, line := range resp.Transcript {
+		text := strings.TrimSpace(line.Text)
+		if text == "" {
+			continue
+		}
+		index++
+		if index > 1 {
+			b.WriteRune('\n')
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n", index, formatSRTTimestamp(line.Start), formatSRTTimestamp(line.Start+line.Duration))
+		if line.Speaker != "" && line.Speaker != previousSpeaker {
+			b.WriteString(line.Speaker)
+			b.WriteString(": ")
+		}
+		previousSpeaker = line.Speaker
+		b.WriteString(text)
+		b.WriteRune('\n')
+	}
+
+	return b.String()
+}
+
+// buildCleanTextExport renders the clean view as timestamped paragraphs separated by blank lines.
+func buildCleanTextExport(resp TranscriptResponse, view *db.TranscriptCleanView) string {
+	var b strings.Builder
+
+	writePlainTextHeader(&b, resp)
+
+	for i, paragraph := range view.Paragraphs {
+		text := strings.TrimSpace(cleanParagraphText(paragraph))
+		if text == "" {
+			continue
+		}
+		if i > 0 {
+			b.WriteRune('\n')
+		}
+		b.WriteString("[")
+		b.WriteString(formatTimestamp(paragraph.StartMs))
+		b.WriteString("] ")
+		b.WriteString(text)
+		b.WriteRune('\n')
+	}
+
+	return b.String()
+}
+
+func writePlainTextHeader(b *strings.Builder, resp TranscriptResponse) {
 	if resp.Title != "" {
 		b.WriteString(resp.Title)
 		b.WriteRune('\n')
@@ -157,22 +331,53 @@ func buildPlainTextExport(resp TranscriptResponse) string {
 	}
 
 	b.WriteString("\nTranscript\n----------\n")
+}
 
-	for _, line := range resp.Transcript {
-		text := strings.TrimSpace(line.Text)
-		if text == "" {
-			continue
+// buildYouTubeChaptersExport renders chapters in the format YouTube parses from video
+// descriptions, e.g. "00:00 Intro". Hours are only included when the video needs them.
+func buildYouTubeChaptersExport(chapters []db.Chapter) string {
+	withHours := false
+	for _, chapter := range chapters {
+		if chapter.StartMs >= int64(time.Hour/time.Millisecond) {
+			withHours = true
+			break
 		}
-		b.WriteString("[")
-		b.WriteString(formatTimestamp(line.Start))
-		b.WriteString("] ")
-		b.WriteString(text)
-		b.WriteRune('\n')
 	}
 
+	var b strings.Builder
+	for _, chapter := range chapters {
+		b.WriteString(formatChapterTimestamp(chapter.StartMs, withHours))
+		b.WriteRune(' ')
+		b.WriteString(strings.TrimSpace(chapter.Title))
+		b.WriteRune('\n')
+	}
 	return b.String()
 }
 
+func formatChapterTimestamp(milliseconds int64, withHours bool) string {
+	if milliseconds < 0 {
+		milliseconds = 0
+	}
+	totalSeconds := milliseconds / 1000
+	hours := totalSeconds / 3600
+	minutes := (totalSeconds % 3600) / 60
+	seconds := totalSeconds % 60
+	if withHours {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%02d:%02d", minutes, seconds)
+}
+
+func formatSRTTimestamp(milliseconds int64) string {
+	if milliseconds < 0 {
+		milliseconds = 0
+	}
+	hours := milliseconds / 3600000
+	minutes := (milliseconds % 3600000) / 60000
+	seconds := (milliseconds % 60000) / 1000
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", hours, minutes, seconds, milliseconds%1000)
+}
+
 func formatTimestamp(milliseconds int64) string {
 	if milliseconds < 0 {
 		milliseconds = 0
@@ -188,6 +393,6 @@ func errorsIsNotFound(err error) bool {
 	return err != nil && errors.Is(err, db.ErrNotFound)
 }
 
-func logAPILookupError(method, path, context string, err error) {
-	log.Printf("ERROR [%s %s] %s: %v", method, path, context, err)
+func logAPILookupError(ctx context.Context, operation string, err error) {
+	slog.ErrorContext(ctx, operation+" failed", "error", err)
 }
@@ -0,0 +1,336 @@
+package api
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+)
+
+const (
+	// archiveWriteTimeout replaces the server-wide WriteTimeout for archive downloads, which
+	// stream for as long as the library takes to serialize.
+	archiveWriteTimeout = 30 * time.Minute
+	defaultArchiveMaxMB = 512
+)
+
+var archivePathUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type archiveManifest struct {
+	GeneratedAt   time.Time              `json:"generated_at"`
+	Filters       archiveFilters         `json:"filters"`
+	MaxBytes      int64                  `json:"max_bytes"`
+	TotalBytes    int64                  `json:"total_bytes"`
+	Truncated     bool                   `json:"truncated"`
+	Videos        []archiveManifestVideo `json:"videos"`
+	SkippedVideos []string               `json:"skipped_videos,omitempty"`
+	Errors        []string               `json:"errors,omitempty"`
+}
+
+type archiveFilters struct {
+	Channel string     `json:"channel,omitempty"`
+	From    *time.Time `json:"from,omitempty"`
+	To      *time.Time `json:"to,omitempty"`
+}
+
+type archiveManifestVideo struct {
+	YouTubeID   string                      `json:"youtube_id"`
+	Title       string                      `json:"title"`
+	Channel     string                      `json:"channel"`
+	Duration    int                         `json:"duration"`
+	CreatedAt   time.Time                   `json:"created_at"`
+	Transcripts []archiveManifestTranscript `json:"transcripts"`
+}
+
+type archiveManifestTranscript struct {
+	ID       string   `json:"id"`
+	Language string   `json:"language"`
+	Files    []string `json:"files"`
+}
+
+type archiveFile struct {
+	name string
+	data []byte
+}
+
+// handleExportArchive streams GET /api/v1/export/archive as a ZIP containing, per video, every
+// transcript in JSON/SRT/TXT with its cached summaries and extractions, plus a manifest.json.
+// Supported query parameters: channel, from and to (RFC 3339 or YYYY-MM-DD, to is inclusive)
+// and max_mb, which can only lower the configured size cap. Each video is serialized on its own
+// so memory stays bounded by the largest video rather than the library; once adding the next
+// video would exceed the cap the archive is closed and the manifest lists what was skipped.
+func (s *Server) handleExportArchive(w http.ResponseWriter, r *http.Request) {
+	if s.libraryVideos == nil || s.libraryTranscripts == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "Library export is not configured on this server")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := db.VideoFilter{Channel: strings.TrimSpace(query.Get("channel"))}
+	manifest := archiveManifest{
+		GeneratedAt: time.Now().UTC(),
+		Filters:     archiveFilters{Channel: filter.Channel},
+	}
+
+	if raw := strings.TrimSpace(query.Get("from")); raw != "" {
+		from, _, err := parseArchiveDate(raw)
+		if err != nil {
+			writeStructuredError(w, http.StatusBadRequest, err, "Invalid 'from' date. Use RFC 3339 or YYYY-MM-DD.")
+			return
+		}
+		filter.CreatedAfter = from
+		manifest.Filters.From = &from
+	}
+	if raw := strings.TrimSpace(query.Get("to")); raw != "" {
+		to, dateOnly, err := parseArchiveDate(raw)
+		if err != nil {
+			writeStructuredError(w, http.StatusBadRequest, err, "Invalid 'to' date. Use RFC 3339 or YYYY-MM-DD.")
+			return
+		}
+		manifest.Filters.To = &to
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.CreatedBefore = to
+	}
+
+	maxMB := s.config.ExportArchiveMaxMB
+	if maxMB <= 0 {
+		maxMB = defaultArchiveMaxMB
+	}
+	if raw := strings.TrimSpace(query.Get("max_mb")); raw != "" {
+		requested, err := strconv.Atoi(raw)
+		if err != nil || requested <= 0 || requested > maxMB {
+			writeStructuredError(w, http.StatusBadRequest, err, fmt.Sprintf("max_mb must be between 1 and %d", maxMB))
+			return
+		}
+		maxMB = requested
+	}
+	manifest.MaxBytes = int64(maxMB) << 20
+
+	ctx := r.Context()
+	// Signed-in users export their own library; admins and keys export everything.
+	var inLibrary map[string]bool
+	if userID := libraryOwnerFromContext(ctx); userID != "" && s.library != nil {
+		filter.UserID = userID
+		entries, err := s.library.ListLibrary(ctx, userID)
+		if err != nil {
+			logAPILookupError(ctx, "list library", err)
+			writeRepositoryError(w, err, "Failed to list library")
+			return
+		}
+		inLibrary = make(map[string]bool, len(entries))
+		for _, entry := range entries {
+			inLibrary[entry.TranscriptID] = true
+		}
+	}
+
+	videos, err := s.libraryVideos.ListVideos(ctx, filter)
+	if err != nil {
+		if isDatabaseUnavailableError(err) {
+			writeStructuredError(w, http.StatusServiceUnavailable, err, "Database unavailable. Please try again later.")
+			return
+		}
+		logAPILookupError(ctx, "list videos", err)
+		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to list videos")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(archiveWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(ctx, "extend write deadline failed", "error", err)
+	}
+
+	filename := fmt.Sprintf("library-%s.zip", manifest.GeneratedAt.Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	zw := zip.NewWriter(w)
+	manifest.Videos = make([]archiveManifestVideo, 0, len(videos))
+
+	for i, video := range videos {
+		files, entry, err := s.buildArchiveVideo(ctx, video, inLibrary)
+		if err != nil {
+			if ctx.Err() != nil {
+				slog.ErrorContext(ctx, "archive aborted", "error", ctx.Err())
+				return
+			}
+			logAPILookupError(ctx, "archive video "+video.YouTubeID, err)
+			manifest.Errors = append(manifest.Errors, fmt.Sprintf("%s: %v", video.YouTubeID, err))
+			continue
+		}
+
+		var size int64
+		for _, file := range files {
+			size += int64(len(file.data))
+		}
+		if manifest.TotalBytes+size > manifest.MaxBytes {
+			manifest.Truncated = true
+			for _, skipped := range videos[i:] {
+				manifest.SkippedVideos = append(manifest.SkippedVideos, skipped.YouTubeID)
+			}
+			break
+		}
+
+		for _, file := range files {
+			if err := writeArchiveFile(zw, file, manifest.GeneratedAt); err != nil {
+				slog.ErrorContext(ctx, "write archive entry failed", "entry", file.name, "error", err)
+				return
+			}
+		}
+		manifest.TotalBytes += size
+		manifest.Videos = append(manifest.Videos, entry)
+
+		if err := zw.Flush(); err == nil {
+			_ = rc.Flush()
+		}
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		err = writeArchiveFile(zw, archiveFile{name: "manifest.json", data: manifestJSON}, manifest.GeneratedAt)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "finish archive failed", "error", err)
+	}
+}
+
+// buildArchiveVideo renders every file for a single video in memory. When inLibrary is not nil,
+// only the transcripts it contains are included.
+func (s *Server) buildArchiveVideo(ctx context.Context, video *db.Video, inLibrary map[string]bool) ([]archiveFile, archiveManifestVideo, error) {
+	entry := archiveManifestVideo{
+		YouTubeID:   video.YouTubeID,
+		Title:       video.Title,
+		Channel:     video.Channel,
+		Duration:    video.Duration,
+		CreatedAt:   video.CreatedAt,
+		Transcripts: make([]archiveManifestTranscript, 0),
+	}
+
+	transcripts, err := s.libraryTranscripts.GetTranscriptsByVideoID(ctx, video.ID)
+	if err != nil {
+		return nil, entry, fmt.Errorf("list transcripts: %w", err)
+	}
+
+	var files []archiveFile
+	videoDir := path.Join("videos", archivePathSegment(video.YouTubeID, video.ID))
+	usedDirs := make(map[string]int)
+
+	for _, transcript := range transcripts {
+		if inLibrary != nil && !inLibrary[transcript.ID] {
+			continue
+		}
+		dirName := archivePathSegment(transcript.Language, "unknown")
+		if n := usedDirs[dirName]; n > 0 {
+			usedDirs[dirName] = n + 1
+			dirName = fmt.Sprintf("%s-%d", dirName, n+1)
+		} else {
+			usedDirs[dirName] = 1
+		}
+		dir := path.Join(videoDir, dirName)
+
+		payload := buildTranscriptResponse(video, transcript)
+		transcriptJSON, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			return nil, entry, fmt.Errorf("encode transcript %s: %w", transcript.ID, err)
+		}
+
+		transcriptFiles := []archiveFile{
+			{name: path.Join(dir, "transcript.json"), data: transcriptJSON},
+			{name: path.Join(dir, "transcript.srt"), data: []byte(buildSRTExport(payload))},
+			{name: path.Join(dir, "transcript.txt"), data: []byte(buildPlainTextExport(payload))},
+		}
+
+		summaries, err := s.aiSummaryRepo.ListAISummaries(ctx, transcript.ID)
+		if err != nil {
+			return nil, entry, fmt.Errorf("list summaries for transcript %s: %w", transcript.ID, err)
+		}
+		if len(summaries) > 0 {
+			responses := make([]summaryResponse, 0, len(summaries))
+			for _, summary := range summaries {
+				responses = append(responses, buildSummaryResponse(summary))
+			}
+			data, err := json.MarshalIndent(responses, "", "  ")
+			if err != nil {
+				return nil, entry, fmt.Errorf("encode summaries for transcript %s: %w", transcript.ID, err)
+			}
+			transcriptFiles = append(transcriptFiles, archiveFile{name: path.Join(dir, "summaries.json"), data: data})
+		}
+
+		extractions, err := s.aiExtractionRepo.ListAIExtractions(ctx, transcript.ID)
+		if err != nil {
+			return nil, entry, fmt.Errorf("list extractions for transcript %s: %w", transcript.ID, err)
+		}
+		if len(extractions) > 0 {
+			responses := make([]extractionResponse, 0, len(extractions))
+			for _, extraction := range extractions {
+				responses = append(responses, buildExtractionResponse(extraction))
+			}
+			data, err := json.MarshalIndent(responses, "", "  ")
+			if err != nil {
+				return nil, entry, fmt.Errorf("encode extractions for transcript %s: %w", transcript.ID, err)
+			}
+			transcriptFiles = append(transcriptFiles, archiveFile{name: path.Join(dir, "extractions.json"), data: data})
+		}
+
+		names := make([]string, 0, len(transcriptFiles))
+		for _, file := range transcriptFiles {
+			names = append(names, file.name)
+		}
+		entry.Transcripts = append(entry.Transcripts, archiveManifestTranscript{
+			ID:       transcript.ID,
+			Language: transcript.Language,
+			Files:    names,
+		})
+		files = append(files, transcriptFiles...)
+	}
+
+	return files, entry, nil
+}
+
+func writeArchiveFile(zw *zip.Writer, file archiveFile, modified time.Time) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     file.name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(file.data)
+	return err
+}
+
+// parseArchiveDate accepts RFC 3339 timestamps or plain dates and reports whether the value was a date.
+func parseArchiveDate(raw string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), false, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
+
+func archivePathSegment(value, fallback string) string {
+	cleaned := strings.Trim(archivePathUnsafe.ReplaceAllString(value, "_"), "._")
+	if cleaned == "" {
+		return fallback
+	}
+	return cleaned
+}
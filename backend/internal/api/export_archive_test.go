@@ -0,0 +1,185 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+)
+
+type inMemoryLibrary struct {
+	videos      []*db.Video
+	transcripts []*db.Transcript
+	filters     []db.VideoFilter
+}
+
+func (l *inMemoryLibrary) ListVideos(_ context.Context, filter db.VideoFilter) ([]*db.Video, error) {
+	l.filters = append(l.filters, filter)
+	videos := make([]*db.Video, 0)
+	for _, video := range l.videos {
+		if filter.Channel != "" && video.Channel != filter.Channel {
+			continue
+		}
+		if !filter.CreatedAfter.IsZero() && video.CreatedAt.Before(filter.CreatedAfter) {
+			continue
+		}
+		if !filter.CreatedBefore.IsZero() && !video.CreatedAt.Before(filter.CreatedBefore) {
+			continue
+		}
+		videos = append(videos, video)
+	}
+	return videos, nil
+}
+
+func (l *inMemoryLibrary) GetTranscriptsByVideoID(_ context.Context, videoID string) ([]*db.Transcript, error) {
+	transcripts := make([]*db.Transcript, 0)
+	for _, transcript := range l.transcripts {
+		if transcript.VideoID == videoID {
+			transcripts = append(transcripts, transcript)
+		}
+	}
+	return transcripts, nil
+}
+
+func newArchiveTestServer(t *testing.T, maxMB int) (*Server, *inMemoryLibrary) {
+	t.Helper()
+
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	library := &inMemoryLibrary{
+		videos: []*db.Video{
+			{ID: "video-1", YouTubeID: "aaaaaaaaaaa", Title: "First", Channel: "Gophers", CreatedAt: created},
+			{ID: "video-2", YouTubeID: "bbbbbbbbbbb", Title: "Second", Channel: "Gophers", CreatedAt: created.AddDate(0, 0, 2)},
+		},
+		transcripts: []*db.Transcript{
+			{ID: "transcript-1", VideoID: "video-1", Language: "en", Content: db.TranscriptSegments{{StartMs: 0, DurationMs: 1000, Text: "Hello"}}},
+			{ID: "transcript-2", VideoID: "video-2", Language: "de", Content: db.TranscriptSegments{{StartMs: 0, DurationMs: 1000, Text: strings.Repeat("Hallo ", 100000)}}},
+		},
+	}
+
+	summaryRepo := newInMemoryAISummaryRepo()
+	require.NoError(t, summaryRepo.CreateAISummary(context.Background(), &db.AISummary{
+		TranscriptID: "transcript-1",
+		SummaryType:  "brief",
+		Content:      db.SummaryContent{Text: "A greeting."},
+	}))
+
+	cfg := &config.Config{APIPort: 8080, ExportArchiveMaxMB: maxMB}
+	server, err := NewServer(cfg, &mockDB{}, noopYouTubeService{}, &recordingVideoRepo{}, &recordingTranscriptRepo{}, noopAIService{}, summaryRepo, newInMemoryAIExtractionRepo(),
+		WithLibraryRepositories(library, library))
+	require.NoError(t, err)
+	return server, library
+}
+
+func readArchive(t *testing.T, body []byte) (map[string]string, archiveManifest) {
+	t.Helper()
+
+	reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[file.Name] = string(data)
+	}
+
+	var manifest archiveManifest
+	require.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest))
+	return files, manifest
+}
+
+func TestHandleExportArchive_StreamsLibrary(t *testing.T) {
+	server, _ := newArchiveTestServer(t, 0)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/export/archive", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+
+	files, manifest := readArchive(t, rec.Body.Bytes())
+	assert.Contains(t, files, "videos/aaaaaaaaaaa/en/transcript.json")
+	assert.Contains(t, files["videos/aaaaaaaaaaa/en/transcript.srt"], "00:00:00,000 --> 00:00:01,000\nHello")
+	assert.Contains(t, files["videos/aaaaaaaaaaa/en/transcript.txt"], "[00:00:00] Hello")
+	assert.Contains(t, files["videos/aaaaaaaaaaa/en/summaries.json"], "A greeting.")
+	assert.NotContains(t, files, "videos/aaaaaaaaaaa/en/extractions.json")
+	assert.Contains(t, files, "videos/bbbbbbbbbbb/de/transcript.json")
+
+	assert.False(t, manifest.Truncated)
+	assert.Equal(t, int64(defaultArchiveMaxMB)<<20, manifest.MaxBytes)
+	require.Len(t, manifest.Videos, 2)
+	assert.Equal(t, "aaaaaaaaaaa", manifest.Videos[0].YouTubeID)
+	assert.Contains(t, manifest.Videos[0].Transcripts[0].Files, "videos/aaaaaaaaaaa/en/summaries.json")
+}
+
+func TestHandleExportArchive_AppliesFilters(t *testing.T) {
+	server, library := newArchiveTestServer(t, 0)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/export/archive?channel=Gophers&from=2025-03-01&to=2025-03-02", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, library.filters, 1)
+	assert.Equal(t, "Gophers", library.filters[0].Channel)
+	assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), library.filters[0].CreatedBefore, "date-only 'to' is inclusive")
+
+	_, manifest := readArchive(t, rec.Body.Bytes())
+	require.Len(t, manifest.Videos, 1)
+	assert.Equal(t, "aaaaaaaaaaa", manifest.Videos[0].YouTubeID)
+}
+
+func TestHandleExportArchive_StopsAtSizeCap(t *testing.T) {
+	server, _ := newArchiveTestServer(t, 1)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/export/archive", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	files, manifest := readArchive(t, rec.Body.Bytes())
+	assert.True(t, manifest.Truncated)
+	assert.Equal(t, []string{"bbbbbbbbbbb"}, manifest.SkippedVideos)
+	require.Len(t, manifest.Videos, 1)
+	assert.NotContains(t, files, "videos/bbbbbbbbbbb/de/transcript.json")
+	assert.LessOrEqual(t, manifest.TotalBytes, manifest.MaxBytes)
+}
+
+func TestHandleExportArchive_InvalidParameters(t *testing.T) {
+	server, _ := newArchiveTestServer(t, 10)
+
+	for _, query := range []string{"from=yesterday", "to=03/02/2025", "max_mb=0", "max_mb=11"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/export/archive?"+query, nil)
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestHandleExportArchive_NotConfigured(t *testing.T) {
+	server, err := NewServer(&config.Config{APIPort: 8080}, &mockDB{}, noopYouTubeService{}, &recordingVideoRepo{}, &recordingTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/export/archive", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
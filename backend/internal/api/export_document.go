@@ -0,0 +1,379 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+// summaryExportOrder and extractionExportOrder keep documents stable regardless of the order
+// in which artifacts were generated. Unknown types are appended alphabetically.
+var (
+	summaryExportOrder    = []string{"brief", "key_points", "detailed", summaryTypeChapters}
+	extractionExportOrder = []string{"quotes", "action_items", "code"}
+)
+
+var artifactTitles = map[string]string{
+	"brief":             "Brief Summary",
+	"detailed":          "Detailed Summary",
+	"key_points":        "Key Points",
+	summaryTypeChapters: "Chapters",
+	"quotes":            "Quotes",
+	"action_items":      "Action Items",
+	"code":              "Code Snippets",
+}
+
+// exportDocument is the format-independent model rendered by the markdown and html exports.
+type exportDocument struct {
+	Title       string
+	VideoURL    string
+	Channel     string
+	Duration    string
+	Language    string
+	Summaries   []exportSummary
+	Extractions []exportExtraction
+	Transcript  []exportLine
+}
+
+type exportSummary struct {
+	Title     string
+	Text      string
+	KeyPoints []string
+	Sections  []db.Section
+	Chapters  []exportLine
+}
+
+type exportExtraction struct {
+	Title string
+	Type  string
+	Items []services.ExtractionItem
+}
+
+// exportLine is a timestamped entry linking back into the video.
+type exportLine struct {
+	Timestamp string
+	URL       string
+	Speaker   string
+	Text      string
+}
+
+type extractionContent struct {
+	Items []services.ExtractionItem `json:"items"`
+}
+
+// loadExportDocument gathers the video, transcript and every cached AI artifact for a transcript.
+func (s *Server) loadExportDocument(ctx context.Context, transcript *db.Transcript, video *db.Video) (*exportDocument, *apiError) {
+	summaries, err := s.aiSummaryRepo.ListAISummaries(ctx, transcript.ID)
+	if err != nil {
+		logAPILookupError(ctx, "list summaries", err)
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			err:     err,
+			message: "Failed to load summaries",
+		}
+	}
+
+	extractions, err := s.aiExtractionRepo.ListAIExtractions(ctx, transcript.ID)
+	if err != nil {
+		logAPILookupError(ctx, "list extractions", err)
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			err:     err,
+			message: "Failed to load extractions",
+		}
+	}
+
+	return buildExportDocument(video, transcript, summaries, extractions), nil
+}
+
+func buildExportDocument(video *db.Video, transcript *db.Transcript, summaries []*db.AISummary, extractions []*db.AIExtraction) *exportDocument {
+	doc := &exportDocument{
+		Title:    video.Title,
+		Channel:  video.Channel,
+		Language: strings.ToUpper(transcript.Language),
+	}
+	if doc.Title == "" {
+		doc.Title = "Transcript " + transcript.ID
+	}
+	if video.YouTubeID != "" {
+		doc.VideoURL = "https://youtube.com/watch?v=" + url.QueryEscape(video.YouTubeID)
+	}
+	if video.Duration > 0 {
+		doc.Duration = formatTimestamp(int64(video.Duration) * 1000)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return artifactLess(summaryExportOrder, summaries[i].SummaryType, summaries[j].SummaryType)
+	})
+	for _, summary := range summaries {
+		entry := exportSummary{
+			Title:     artifactTitle(summary.SummaryType),
+			Text:      strings.TrimSpace(summary.Content.Text),
+			KeyPoints: summary.Content.KeyPoints,
+			Sections:  summary.Content.Sections,
+		}
+		for _, chapter := range summary.Content.Chapters {
+			entry.Chapters = append(entry.Chapters, exportLine{
+				Timestamp: formatTimestamp(chapter.StartMs),
+				URL:       videoTimestampURL(doc.VideoURL, chapter.StartMs),
+				Text:      strings.TrimSpace(chapter.Title),
+			})
+		}
+		doc.Summaries = append(doc.Summaries, entry)
+	}
+
+	sort.SliceStable(extractions, func(i, j int) bool {
+		return artifactLess(extractionExportOrder, extractions[i].ExtractionType, extractions[j].ExtractionType)
+	})
+	for _, extraction := range extractions {
+		var content extractionContent
+		if err := json.Unmarshal(extraction.Content, &content); err != nil || len(content.Items) == 0 {
+			continue
+		}
+		doc.Extractions = append(doc.Extractions, exportExtraction{
+			Title: artifactTitle(extraction.ExtractionType),
+			Type:  extraction.ExtractionType,
+			Items: content.Items,
+		})
+	}
+
+	for _, segment := range transcript.Content {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+		doc.Transcript = append(doc.Transcript, exportLine{
+			Timestamp: formatTimestamp(segment.StartMs),
+			URL:       videoTimestampURL(doc.VideoURL, segment.StartMs),
+			Speaker:   segment.Speaker,
+			Text:      text,
+		})
+	}
+
+	return doc
+}
+
+// buildMarkdownExport renders the document as CommonMark suitable for Obsidian or Notion imports.
+func buildMarkdownExport(doc *exportDocument) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", doc.Title)
+	if doc.VideoURL != "" {
+		fmt.Fprintf(&b, "- **Video:** %s\n", doc.VideoURL)
+	}
+	if doc.Channel != "" {
+		fmt.Fprintf(&b, "- **Channel:** %s\n", doc.Channel)
+	}
+	if doc.Duration != "" {
+		fmt.Fprintf(&b, "- **Duration:** %s\n", doc.Duration)
+	}
+	if doc.Language != "" {
+		fmt.Fprintf(&b, "- **Language:** %s\n", doc.Language)
+	}
+
+	for _, summary := range doc.Summaries {
+		fmt.Fprintf(&b, "\n## %s\n\n", summary.Title)
+		if summary.Text != "" && len(summary.Chapters) == 0 {
+			b.WriteString(summary.Text)
+			b.WriteString("\n\n")
+		}
+		for _, point := range summary.KeyPoints {
+			fmt.Fprintf(&b, "- %s\n", point)
+		}
+		for _, section := range summary.Sections {
+			fmt.Fprintf(&b, "### %s\n\n%s\n\n", section.Title, section.Content)
+		}
+		for _, chapter := range summary.Chapters {
+			fmt.Fprintf(&b, "- %s %s\n", markdownTimestampLink(chapter), chapter.Text)
+		}
+	}
+
+	for _, extraction := range doc.Extractions {
+		fmt.Fprintf(&b, "\n## %s\n\n", extraction.Title)
+		for _, item := range extraction.Items {
+			writeMarkdownExtractionItem(&b, extraction.Type, item)
+		}
+	}
+
+	b.WriteString("\n## Transcript\n\n")
+	for _, line := range doc.Transcript {
+		b.WriteString(markdownTimestampLink(line))
+		b.WriteRune(' ')
+		if line.Speaker != "" {
+			fmt.Fprintf(&b, "**%s:** ", line.Speaker)
+		}
+		b.WriteString(line.Text)
+		b.WriteString("  \n")
+	}
+
+	return b.String()
+}
+
+func writeMarkdownExtractionItem(b *strings.Builder, extractionType string, item services.ExtractionItem) {
+	switch extractionType {
+	case "quotes":
+		fmt.Fprintf(b, "> %s\n", item.Quote)
+		if item.Speaker != "" {
+			fmt.Fprintf(b, ">\n> — %s\n", item.Speaker)
+		}
+		if item.Context != "" {
+			fmt.Fprintf(b, "\n%s\n", item.Context)
+		}
+		b.WriteRune('\n')
+	case "action_items":
+		fmt.Fprintf(b, "- [ ] %s", item.Action)
+		if meta := joinNonEmpty(", ", item.Priority, item.Category); meta != "" {
+			fmt.Fprintf(b, " _(%s)_", meta)
+		}
+		if item.Context != "" {
+			fmt.Fprintf(b, " — %s", item.Context)
+		}
+		b.WriteRune('\n')
+	case "code":
+		if item.Context != "" {
+			fmt.Fprintf(b, "%s\n\n", item.Context)
+		}
+		fmt.Fprintf(b, "```%s\n%s\n```\n\n", item.Language, strings.TrimRight(item.Code, "\n"))
+	default:
+		if item.Content != "" {
+			fmt.Fprintf(b, "- %s\n", item.Content)
+		}
+	}
+}
+
+func markdownTimestampLink(line exportLine) string {
+	if line.URL == "" {
+		return "[" + line.Timestamp + "]"
+	}
+	return "[" + line.Timestamp + "](" + line.URL + ")"
+}
+
+var htmlExportTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"join": joinNonEmpty,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 860px; margin: 2rem auto; padding: 0 1rem; line-height: 1.6; color: #1f2933; }
+a { color: #2563eb; }
+blockquote { border-left: 4px solid #cbd2d9; margin: 1rem 0; padding: 0 1rem; color: #3e4c59; }
+pre { background: #f5f7fa; padding: 0.75rem; overflow-x: auto; }
+.transcript p { margin: 0.25rem 0; }
+.timestamp { font-family: monospace; text-decoration: none; margin-right: 0.5rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<ul class="meta">
+{{- if .VideoURL}}<li><strong>Video:</strong> <a href="{{.VideoURL}}">{{.VideoURL}}</a></li>{{end}}
+{{- if .Channel}}<li><strong>Channel:</strong> {{.Channel}}</li>{{end}}
+{{- if .Duration}}<li><strong>Duration:</strong> {{.Duration}}</li>{{end}}
+{{- if .Language}}<li><strong>Language:</strong> {{.Language}}</li>{{end}}
+</ul>
+{{- range .Summaries}}
+<section>
+<h2>{{.Title}}</h2>
+{{- if and .Text (not .Chapters)}}
+<p>{{.Text}}</p>
+{{- end}}
+{{- if .KeyPoints}}
+<ul>{{range .KeyPoints}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+{{- range .Sections}}
+<h3>{{.Title}}</h3>
+<p>{{.Content}}</p>
+{{- end}}
+{{- if .Chapters}}
+<ul>{{range .Chapters}}<li>{{template "timestamp" .}}{{.Text}}</li>{{end}}</ul>
+{{- end}}
+</section>
+{{- end}}
+{{- range .Extractions}}
+<section>
+<h2>{{.Title}}</h2>
+{{- $type := .Type}}
+{{- range .Items}}
+{{- if eq $type "quotes"}}
+<blockquote><p>{{.Quote}}</p>{{if .Speaker}}<footer>— {{.Speaker}}</footer>{{end}}</blockquote>
+{{- if .Context}}<p>{{.Context}}</p>{{end}}
+{{- else if eq $type "action_items"}}
+<p>☐ {{.Action}}{{with join ", " .Priority .Category}} <em>({{.}})</em>{{end}}{{if .Context}} — {{.Context}}{{end}}</p>
+{{- else if eq $type "code"}}
+{{- if .Context}}<p>{{.Context}}</p>{{end}}
+<pre><code{{if .Language}} class="language-{{.Language}}"{{end}}>{{.Code}}</code></pre>
+{{- else if .Content}}
+<p>{{.Content}}</p>
+{{- end}}
+{{- end}}
+</section>
+{{- end}}
+<section class="transcript">
+<h2>Transcript</h2>
+{{- range .Transcript}}
+<p>{{template "timestamp" .}}{{if .Speaker}}<strong>{{.Speaker}}:</strong> {{end}}{{.Text}}</p>
+{{- end}}
+</section>
+</body>
+</html>
+{{define "timestamp"}}{{if .URL}}<a class="timestamp" href="{{.URL}}">[{{.Timestamp}}]</a>{{else}}<span class="timestamp">[{{.Timestamp}}]</span>{{end}}{{end}}`))
+
+// buildHTMLExport renders the document as a standalone HTML page with all user content escaped.
+func buildHTMLExport(doc *exportDocument) (string, error) {
+	var b strings.Builder
+	if err := htmlExportTemplate.Execute(&b, doc); err != nil {
+		return "", fmt.Errorf("render html export: %w", err)
+	}
+	return b.String(), nil
+}
+
+func videoTimestampURL(videoURL string, startMs int64) string {
+	if videoURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s&t=%ds", videoURL, max(startMs, 0)/int64(time.Second/time.Millisecond))
+}
+
+func artifactLess(order []string, a, b string) bool {
+	rankA, rankB := artifactRank(order, a), artifactRank(order, b)
+	if rankA != rankB {
+		return rankA < rankB
+	}
+	return a < b
+}
+
+func artifactRank(order []string, artifactType string) int {
+	for i, candidate := range order {
+		if candidate == artifactType {
+			return i
+		}
+	}
+	return len(order)
+}
+
+func artifactTitle(artifactType string) string {
+	if title, ok := artifactTitles[artifactType]; ok {
+		return title
+	}
+	return strings.ReplaceAll(artifactType, "_", " ")
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+)
+
+func newDocumentExportServer(t *testing.T) *Server {
+	t.Helper()
+
+	videoRepo := &recordingVideoRepo{}
+	videoRepo.saved = append(videoRepo.saved, &db.Video{
+		ID:        "video-uuid",
+		YouTubeID: "dQw4w9WgXcQ",
+		Title:     "Go <Concurrency> Patterns",
+		Channel:   "GopherCon",
+		Duration:  3725,
+	})
+
+	transcriptRepo := &recordingTranscriptRepo{}
+	transcriptRepo.saved = append(transcriptRepo.saved, &db.Transcript{
+		ID:       "transcript-uuid",
+		VideoID:  "video-uuid",
+		Language: "en",
+		Content: db.TranscriptSegments{
+			{StartMs: 0, DurationMs: 2000, Text: "Welcome everyone.", Speaker: "Host"},
+			{StartMs: 95500, DurationMs: 2000, Text: "Channels are <typed> pipes."},
+		},
+	})
+
+	ctx := context.Background()
+	summaryRepo := newInMemoryAISummaryRepo()
+	require.NoError(t, summaryRepo.CreateAISummary(ctx, &db.AISummary{
+		TranscriptID: "transcript-uuid",
+		SummaryType:  "chapters",
+		Content:      db.SummaryContent{Chapters: []db.Chapter{{Title: "Intro", StartMs: 0}, {Title: "Channels", StartMs: 95000}}},
+	}))
+	require.NoError(t, summaryRepo.CreateAISummary(ctx, &db.AISummary{
+		TranscriptID: "transcript-uuid",
+		SummaryType:  "brief",
+		Content:      db.SummaryContent{Text: "A tour of Go concurrency."},
+	}))
+
+	extractionRepo := newInMemoryAIExtractionRepo()
+	require.NoError(t, extractionRepo.CreateAIExtraction(ctx, &db.AIExtraction{
+		TranscriptID:   "transcript-uuid",
+		ExtractionType: "quotes",
+		Content:        []byte(`{"items":[{"quote":"Don't communicate by sharing memory","speaker":"Rob Pike","context":"Go proverb"}]}`),
+	}))
+	require.NoError(t, extractionRepo.CreateAIExtraction(ctx, &db.AIExtraction{
+		TranscriptID:   "transcript-uuid",
+		ExtractionType: "code",
+		Content:        []byte(`{"items":[{"language":"go","code":"ch := make(chan int)","context":"Creating a channel"}]}`),
+	}))
+
+	server, err := NewServer(&config.Config{APIPort: 8080}, &mockDB{}, noopYouTubeService{}, videoRepo, transcriptRepo, noopAIService{}, summaryRepo, extractionRepo)
+	require.NoError(t, err)
+	return server
+}
+
+func TestHandleExportTranscript_Markdown(t *testing.T) {
+	server := newDocumentExportServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/transcript-uuid/export?format=md", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/markdown; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "transcript-transcript-uuid.md")
+
+	body := rec.Body.String()
+	assert.Contains(t, body, "# Go <Concurrency> Patterns\n")
+	assert.Contains(t, body, "- **Channel:** GopherCon\n")
+	assert.Contains(t, body, "- **Duration:** 01:02:05\n")
+	assert.Contains(t, body, "## Brief Summary\n\nA tour of Go concurrency.")
+	assert.Contains(t, body, "- [00:01:35](https://youtube.com/watch?v=dQw4w9WgXcQ&t=95s) Channels\n")
+	assert.Contains(t, body, "> Don't communicate by sharing memory\n>\n> — Rob Pike\n")
+	assert.Contains(t, body, "```go\nch := make(chan int)\n```")
+	assert.Contains(t, body, "[00:00:00](https://youtube.com/watch?v=dQw4w9WgXcQ&t=0s) **Host:** Welcome everyone.")
+
+	assert.Less(t, strings.Index(body, "## Brief Summary"), strings.Index(body, "## Chapters"), "summaries follow a stable order")
+	assert.Less(t, strings.Index(body, "## Quotes"), strings.Index(body, "## Code Snippets"))
+}
+
+func TestHandleExportTranscript_HTMLEscapesContent(t *testing.T) {
+	server := newDocumentExportServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/transcript-uuid/export?format=html", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, "<h1>Go &lt;Concurrency&gt; Patterns</h1>")
+	assert.Contains(t, body, "Channels are &lt;typed&gt; pipes.")
+	assert.Contains(t, body, `href="https://youtube.com/watch?v=dQw4w9WgXcQ&amp;t=95s"`)
+	assert.Contains(t, body, `<code class="language-go">ch := make(chan int)</code>`)
+	assert.Contains(t, body, "<footer>— Rob Pike</footer>")
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -115,7 +116,7 @@ func TestHandleExportTranscript_InvalidFormat(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	var errResp ErrorResponse
 	require.NoError(t, json.NewDecoder(rec.Body).Decode(&errResp))
-	assert.Equal(t, "Unsupported export format. Use json or text.", errResp.Error)
+	assert.Equal(t, "Unsupported export format. Use json, text, srt, markdown, html, or chapters.", errResp.Error)
 	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
 }
 
@@ -139,3 +140,95 @@ func TestHandleExportTranscript_NotFound(t *testing.T) {
 	require.NoError(t, json.NewDecoder(rec.Body).Decode(&errResp))
 	assert.Equal(t, "Transcript not found", errResp.Error)
 }
+
+func TestHandleExportTranscript_Chapters(t *testing.T) {
+	cfg := &config.Config{APIPort: 8080}
+
+	videoRepo := &recordingVideoRepo{}
+	videoRepo.saved = append(videoRepo.saved, &db.Video{ID: "video-uuid", YouTubeID: "dQw4w9WgXcQ", Title: "Sample Title"})
+
+	transcriptRepo := &recordingTranscriptRepo{}
+	transcriptRepo.saved = append(transcriptRepo.saved, &db.Transcript{ID: "transcript-uuid", VideoID: "video-uuid", Language: "en"})
+
+	summaryRepo := newInMemoryAISummaryRepo()
+	require.NoError(t, summaryRepo.CreateAISummary(context.Background(), &db.AISummary{
+		TranscriptID: "transcript-uuid",
+		SummaryType:  "chapters",
+		Content: db.SummaryContent{Chapters: []db.Chapter{
+			{Title: "Intro", StartMs: 0},
+			{Title: "Deep dive", StartMs: 95000},
+		}},
+	}))
+
+	server, err := NewServer(cfg, &mockDB{}, noopYouTubeService{}, videoRepo, transcriptRepo, noopAIService{}, summaryRepo, noopAIExtractionRepo{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/transcript-uuid/export?format=chapters", nil)
+	rec := httptest.NewRecorder()
+
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "chapters-transcript-uuid.txt")
+	assert.Equal(t, "00:00 Intro\n01:35 Deep dive\n", rec.Body.String())
+}
+
+func TestHandleExportTranscript_ChaptersNotGenerated(t *testing.T) {
+	videoRepo := &recordingVideoRepo{}
+	videoRepo.saved = append(videoRepo.saved, &db.Video{ID: "video-uuid", YouTubeID: "dQw4w9WgXcQ"})
+	transcriptRepo := &recordingTranscriptRepo{}
+	transcriptRepo.saved = append(transcriptRepo.saved, &db.Transcript{ID: "transcript-uuid", VideoID: "video-uuid"})
+
+	server, err := NewServer(&config.Config{APIPort: 8080}, &mockDB{}, noopYouTubeService{}, videoRepo, transcriptRepo, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/transcript-uuid/export?format=chapters", nil)
+	rec := httptest.NewRecorder()
+
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestBuildYouTubeChaptersExport_LongVideoUsesHours(t *testing.T) {
+	out := buildYouTubeChaptersExport([]db.Chapter{
+		{Title: "Intro", StartMs: 0},
+		{Title: "Q&A", StartMs: 3725000},
+	})
+	assert.Equal(t, "0:00:00 Intro\n1:02:05 Q&A\n", out)
+}
+
+func TestHandleExportTranscript_SRTWithSpeakers(t *testing.T) {
+	videoRepo := &recordingVideoRepo{}
+	videoRepo.saved = append(videoRepo.saved, &db.Video{ID: "video-uuid", YouTubeID: "dQw4w9WgXcQ"})
+	transcriptRepo := &recordingTranscriptRepo{}
+	transcriptRepo.saved = append(transcriptRepo.saved, &db.Transcript{
+		ID:      "transcript-uuid",
+		VideoID: "video-uuid",
+		Content: db.TranscriptSegments{
+			{StartMs: 0, DurationMs: 1500, Text: "Hi there", Speaker: "Host"},
+			{StartMs: 1500, DurationMs: 2000, Text: "and welcome", Speaker: "Host"},
+			{StartMs: 3661250, DurationMs: 1000, Text: "Thanks", Speaker: "Jane"},
+		},
+	})
+
+	server, err := NewServer(&config.Config{APIPort: 8080}, &mockDB{}, noopYouTubeService{}, videoRepo, transcriptRepo, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/transcript-uuid/export?format=srt", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "transcript-transcript-uuid.srt")
+	assert.Equal(t,
+		"1\n00:00:00,000 --> 00:00:01,500\nHost: Hi there\n\n"+
+			"2\n00:00:01,500 --> 00:00:03,500\nand welcome\n\n"+
+			"3\n01:01:01,250 --> 01:01:02,250\nJane: Thanks\n",
+		rec.Body.String())
+
+	textReq := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/transcript-uuid/export?format=text", nil)
+	textRec := httptest.NewRecorder()
+	server.router.ServeHTTP(textRec, textReq)
+	assert.Contains(t, textRec.Body.String(), "[01:01:01] Jane: Thanks")
+}
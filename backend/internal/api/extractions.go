@@ -4,8 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -35,6 +34,7 @@ type extractionResponse struct {
 	Items          []map[string]interface{} `json:"items"`
 	Model          string                   `json:"model"`
 	TokensUsed     int                      `json:"tokens_used"`
+	PromptVersion  string                   `json:"prompt_version,omitempty"`
 	CreatedAt      time.Time                `json:"created_at"`
 }
 
@@ -62,57 +62,78 @@ func (s *Server) handleExtractFromTranscript(w http.ResponseWriter, r *http.Requ
 
 	// Check for cached extraction
 	if cached, err := s.aiExtractionRepo.GetAIExtraction(ctx, transcriptID, extractionType); err == nil {
+		observeCacheLookup("extraction", true)
 		writeJSON(w, http.StatusOK, buildExtractionResponse(cached))
 		return
 	} else if !errors.Is(err, db.ErrNotFound) {
 		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to lookup cached extraction")
 		return
 	}
+	observeCacheLookup("extraction", false)
+
+	key := "extraction:" + transcriptID + ":" + extractionType
+	extraction, shared, err := coalesce(ctx, s.generations, key, extractTimeout, func(ctx context.Context) (*db.AIExtraction, error) {
+		return s.generateExtraction(ctx, transcriptID, extractionType)
+	})
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			writeStructuredError(w, apiErr.status, apiErr.err, apiErr.message)
+			return
+		}
+		slog.ErrorContext(r.Context(), "AI extraction failed",
+			"type", extractionType, "transcript_id", transcriptID, "error", err)
+		writeAIError(w, err, "extract content", "Try with a shorter transcript or try again later.")
+		return
+	}
+	if shared {
+		slog.InfoContext(r.Context(), "shared in-flight extraction generation",
+			"type", extractionType, "transcript_id", transcriptID)
+	}
+
+	writeJSON(w, http.StatusOK, buildExtractionResponse(extraction))
+}
+
+// generateExtraction calls the AI provider and stores the result, re-checking the cache first.
+// Lookup and storage failures are returned as *apiError; provider errors are returned as is.
+func (s *Server) generateExtraction(ctx context.Context, transcriptID, extractionType string) (*db.AIExtraction, error) {
+	if cached, err := s.aiExtractionRepo.GetAIExtraction(ctx, transcriptID, extractionType); err == nil {
+		return cached, nil
+	} else if !errors.Is(err, db.ErrNotFound) {
+		return nil, &apiError{status: http.StatusInternalServerError, err: err, message: "Failed to lookup cached extraction"}
+	}
 
-	// Fetch transcript
 	transcript, err := s.transcriptRepo.GetTranscriptByID(ctx, transcriptID)
 	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
-			writeStructuredError(w, http.StatusNotFound, err, "Transcript not found")
-			return
+			return nil, &apiError{status: http.StatusNotFound, err: err, message: "Transcript not found"}
 		}
 		if isDatabaseUnavailableError(err) {
-			writeStructuredError(w, http.StatusServiceUnavailable, err, "Database unavailable. Please try again later.")
-			return
+			return nil, &apiError{status: http.StatusServiceUnavailable, err: err, message: "Database unavailable. Please try again later."}
 		}
-		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to load transcript")
-		return
+		return nil, &apiError{status: http.StatusInternalServerError, err: err, message: "Failed to load transcript"}
 	}
 
 	transcriptText := buildTranscriptText(transcript.Content)
 	if transcriptText == "" {
-		writeStructuredError(w, http.StatusNotFound, nil, "Transcript is empty or unavailable")
-		return
+		return nil, &apiError{status: http.StatusNotFound, message: "Transcript is empty or unavailable"}
 	}
 
-	// Call AI service to extract content
-	aiExtraction, err := s.aiService.Extract(ctx, transcriptText, extractionType)
+	aiExtraction, err := s.aiService.Extract(services.WithTranscriptID(ctx, transcriptID), transcriptText, extractionType)
 	if err != nil {
-		log.Printf("ERROR [%s %s] AI extraction failed (type=%s, transcript=%s): %v",
-			r.Method, r.URL.Path, extractionType, transcriptID, err)
-		handleAIExtractionError(w, err)
-		return
+		return nil, err
 	}
 
-	// Convert to database format
 	dbExtraction, err := convertToDatabaseExtraction(transcriptID, extractionType, aiExtraction)
 	if err != nil {
-		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to process extraction result")
-		return
+		return nil, &apiError{status: http.StatusInternalServerError, err: err, message: "Failed to process extraction result"}
 	}
+	dbExtraction.CreatedBy = userIDFromContext(ctx)
 
-	// Store in database
 	if err := s.aiExtractionRepo.CreateAIExtraction(ctx, dbExtraction); err != nil {
-		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to store AI extraction")
-		return
+		return nil, &apiError{status: http.StatusInternalServerError, err: err, message: "Failed to store AI extraction"}
 	}
-
-	writeJSON(w, http.StatusOK, buildExtractionResponse(dbExtraction))
+	return dbExtraction, nil
 }
 
 func normalizeExtractionType(raw string) string {
@@ -124,31 +145,6 @@ func isExtractionTypeAllowed(extractionType string) bool {
 	return ok
 }
 
-func handleAIExtractionError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, services.ErrAIRateLimited):
-		writeStructuredError(w, http.StatusTooManyRequests, err, "AI rate limit reached. Please wait a moment and try again.")
-	case errors.Is(err, services.ErrAIQuotaExceeded):
-		writeStructuredError(w, http.StatusPaymentRequired, err, "AI quota exceeded. Please contact the administrator.")
-	case errors.Is(err, services.ErrAIServiceUnavailable):
-		writeStructuredError(w, http.StatusServiceUnavailable, err, "AI service is temporarily unavailable. Please try again in a few moments.")
-	case errors.Is(err, services.ErrAIProviderNotConfigured):
-		writeStructuredError(w, http.StatusServiceUnavailable, err, "AI service is not configured. Please contact the administrator.")
-	case errors.Is(err, context.DeadlineExceeded):
-		writeStructuredError(w, http.StatusGatewayTimeout, err, "AI request timed out (>60s). Try with a shorter transcript or try again later.")
-	case errors.Is(err, context.Canceled):
-		writeStructuredError(w, http.StatusRequestTimeout, err, "Request was canceled.")
-	default:
-		// Check if it's a JSON parsing error
-		if strings.Contains(err.Error(), "parse") || strings.Contains(err.Error(), "unmarshal") || strings.Contains(err.Error(), "json") {
-			writeStructuredError(w, http.StatusInternalServerError, err, "AI response format error. The AI returned an invalid response. Please try again.")
-		} else {
-			// Generic error with hint about the actual error
-			writeStructuredError(w, http.StatusInternalServerError, err, fmt.Sprintf("Failed to extract content: %v", err))
-		}
-	}
-}
-
 func convertToDatabaseExtraction(transcriptID, extractionType string, extraction *services.AIExtraction) (*db.AIExtraction, error) {
 	// Convert ExtractionItem to JSON
 	itemsJSON, err := json.Marshal(map[string]interface{}{
@@ -164,6 +160,7 @@ func convertToDatabaseExtraction(transcriptID, extractionType string, extraction
 		Content:        itemsJSON,
 		Model:          extraction.Model,
 		TokensUsed:     extraction.TokensUsed,
+		PromptVersion:  extraction.PromptVersion,
 	}, nil
 }
 
@@ -179,6 +176,7 @@ func buildExtractionResponse(extraction *db.AIExtraction) extractionResponse {
 			Items:          []map[string]interface{}{},
 			Model:          extraction.Model,
 			TokensUsed:     extraction.TokensUsed,
+			PromptVersion:  extraction.PromptVersion,
 			CreatedAt:      extraction.CreatedAt,
 		}
 	}
@@ -202,6 +200,7 @@ func buildExtractionResponse(extraction *db.AIExtraction) extractionResponse {
 		Items:          items,
 		Model:          extraction.Model,
 		TokensUsed:     extraction.TokensUsed,
+		PromptVersion:  extraction.PromptVersion,
 		CreatedAt:      extraction.CreatedAt,
 	}
 }
@@ -43,6 +43,14 @@ func (s *stubExtractionAIService) Answer(ctx context.Context, text string, quest
 	return &services.AIAnswer{}, nil
 }
 
+func (s *stubExtractionAIService) Punctuate(ctx context.Context, lines []string) (*services.AIPunctuation, error) {
+	return &services.AIPunctuation{Lines: lines}, nil
+}
+
+func (s *stubExtractionAIService) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*services.AISpeakerTurns, error) {
+	return &services.AISpeakerTurns{Turns: []services.SpeakerTurn{{Line: 1, Speaker: "Speaker 1"}}}, nil
+}
+
 type inMemoryAIExtractionRepo struct {
 	store map[string]*db.AIExtraction
 }
@@ -441,6 +449,77 @@ func TestHandleExtractFromTranscript_AIServiceError(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "rate limit")
 }
 
+func TestHandleExtractFromTranscript_LocalRateLimit(t *testing.T) {
+	cfg := &config.Config{APIPort: 8080}
+
+	aiService := &stubExtractionAIService{
+		err: &services.RateLimitError{Provider: "openai:gpt-4", RetryAfter: 2500 * time.Millisecond},
+	}
+
+	transcriptRepo := newInMemoryTranscriptRepo()
+	transcriptRepo.transcripts["test-transcript"] = &db.Transcript{
+		ID:      "test-transcript",
+		VideoID: "video-123",
+		Content: db.TranscriptSegments{{StartMs: 0, DurationMs: 1000, Text: "test"}},
+	}
+
+	server, err := NewServer(cfg, &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, transcriptRepo, aiService, newInMemoryAISummaryRepo(), newInMemoryAIExtractionRepo())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/test-transcript/extract", bytes.NewBufferString(`{"extraction_type": "code"}`))
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), "Too many AI requests")
+}
+
+func TestHandleExtractFromTranscript_BudgetExceeded(t *testing.T) {
+	cfg := &config.Config{APIPort: 8080}
+
+	resetsAt := time.Now().Add(90 * time.Minute).UTC().Truncate(time.Second)
+	aiService := &stubExtractionAIService{
+		err: &services.BudgetError{
+			Scope:     services.BudgetScopeKey,
+			Period:    services.BudgetPeriodDaily,
+			Unit:      services.BudgetUnitUSD,
+			Limit:     5,
+			Remaining: 0.25,
+			ResetsAt:  resetsAt,
+		},
+	}
+
+	transcriptRepo := newInMemoryTranscriptRepo()
+	transcriptRepo.transcripts["test-transcript"] = &db.Transcript{
+		ID:      "test-transcript",
+		VideoID: "video-123",
+		Content: db.TranscriptSegments{{StartMs: 0, DurationMs: 1000, Text: "test"}},
+	}
+
+	server, err := NewServer(cfg, &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, transcriptRepo, aiService, newInMemoryAISummaryRepo(), newInMemoryAIExtractionRepo())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/test-transcript/extract", bytes.NewBufferString(`{"extraction_type": "code"}`))
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Contains(t, response.Error, "for this API key")
+	assert.Equal(t, map[string]string{
+		"scope":     "key",
+		"period":    "daily",
+		"unit":      "usd",
+		"limit":     "5",
+		"remaining": "0.25",
+		"resets_at": resetsAt.Format(time.RFC3339),
+	}, response.Details)
+}
+
 func TestHandleExtractFromTranscript_InvalidJSON(t *testing.T) {
 	cfg := &config.Config{APIPort: 8080}
 
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// generationLocker serializes generations of the same artifact across server instances.
+type generationLocker interface {
+	Lock(ctx context.Context, key string) (func(), error)
+}
+
+// generationGroup coalesces concurrent requests for the same cached AI artifact so the provider
+// is called once and every waiter receives the stored result.
+type generationGroup struct {
+	flight singleflight.Group
+	locker generationLocker
+}
+
+// WithGenerationLocker makes AI generations also coordinate across instances, e.g. through
+// Postgres advisory locks.
+func WithGenerationLocker(locker generationLocker) ServerOption {
+	return func(s *Server) {
+		s.generations.locker = locker
+	}
+}
+
+// coalesce runs generate once per key among concurrent callers and reports whether the result
+// was shared. generate runs on a context detached from the first caller's cancellation, bounded
+// by timeout, so one client disconnecting does not fail the others; each caller stops waiting
+// when its own ctx ends. generate should re-check the cache because another instance may have
+// produced the artifact while this one waited for the lock.
+func coalesce[T any](ctx context.Context, g *generationGroup, key string, timeout time.Duration, generate func(context.Context) (T, error)) (T, bool, error) {
+	ch := g.flight.DoChan(key, func() (any, error) {
+		runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+
+		if g.locker != nil {
+			unlock, err := g.locker.Lock(runCtx, key)
+			if err != nil {
+				if runCtx.Err() != nil {
+					return nil, fmt.Errorf("wait for generation lock: %w", runCtx.Err())
+				}
+				slog.WarnContext(ctx, "generation lock unavailable, continuing without it", "lock", key, "error", err)
+			} else {
+				defer unlock()
+			}
+		}
+		return generate(runCtx)
+	})
+
+	var zero T
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return zero, result.Shared, result.Err
+		}
+		return result.Val.(T), result.Shared, nil
+	case <-ctx.Done():
+		return zero, false, ctx.Err()
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+type recordingLocker struct {
+	mu       sync.Mutex
+	locked   []string
+	unlocked int
+	err      error
+}
+
+func (l *recordingLocker) Lock(_ context.Context, key string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return nil, l.err
+	}
+	l.locked = append(l.locked, key)
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.unlocked++
+	}, nil
+}
+
+// gatedGenerate blocks until gate is closed and counts how often it ran.
+func gatedGenerate(calls *atomic.Int32, gate <-chan struct{}) func(context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		select {
+		case <-gate:
+			return "generated", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+func TestCoalesce_SharesOneGeneration(t *testing.T) {
+	group := &generationGroup{}
+	gate := make(chan struct{})
+	var calls atomic.Int32
+
+	results := make(chan string, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, _, err := coalesce(context.Background(), group, "summary:t1:brief", time.Second, gatedGenerate(&calls, gate))
+			assert.NoError(t, err)
+			results <- value
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(gate)
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, int32(1), calls.Load())
+	for value := range results {
+		assert.Equal(t, "generated", value)
+	}
+}
+
+func TestCoalesce_FirstCallerCancellationDoesNotFailOthers(t *testing.T) {
+	group := &generationGroup{}
+	gate := make(chan struct{})
+	var calls atomic.Int32
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstDone := make(chan error, 1)
+	go func() {
+		_, _, err := coalesce(firstCtx, group, "key", time.Second, gatedGenerate(&calls, gate))
+		firstDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	secondDone := make(chan string, 1)
+	go func() {
+		value, shared, err := coalesce(context.Background(), group, "key", time.Second, gatedGenerate(&calls, gate))
+		assert.NoError(t, err)
+		assert.True(t, shared)
+		secondDone <- value
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancelFirst()
+	assert.ErrorIs(t, <-firstDone, context.Canceled)
+
+	close(gate)
+	assert.Equal(t, "generated", <-secondDone)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestCoalesce_UsesLocker(t *testing.T) {
+	locker := &recordingLocker{}
+	group := &generationGroup{locker: locker}
+
+	value, shared, err := coalesce(context.Background(), group, "extraction:t1:code", time.Second, func(context.Context) (string, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", value)
+	assert.False(t, shared)
+	assert.Equal(t, []string{"extraction:t1:code"}, locker.locked)
+	assert.Equal(t, 1, locker.unlocked)
+
+	// A locker failure degrades to in-process coalescing only.
+	locker.err = errors.New("database connection failed")
+	value, _, err = coalesce(context.Background(), group, "extraction:t1:code", time.Second, func(context.Context) (string, error) {
+		return "still ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "still ok", value)
+}
+
+// lockedSummaryRepo makes the in-memory summary repository safe for concurrent handlers.
+type lockedSummaryRepo struct {
+	mu    sync.Mutex
+	inner *inMemoryAISummaryRepo
+}
+
+func (r *lockedSummaryRepo) CreateAISummary(ctx context.Context, summary *db.AISummary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.CreateAISummary(ctx, summary)
+}
+
+func (r *lockedSummaryRepo) GetAISummary(ctx context.Context, transcriptID, summaryType string) (*db.AISummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.GetAISummary(ctx, transcriptID, summaryType)
+}
+
+func (r *lockedSummaryRepo) ListAISummaries(ctx context.Context, transcriptID string) ([]*db.AISummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.ListAISummaries(ctx, transcriptID)
+}
+
+// gatedSummaryAIService blocks Summarize until gate is closed.
+type gatedSummaryAIService struct {
+	noopAIService
+	gate  chan struct{}
+	calls atomic.Int32
+}
+
+func (s *gatedSummaryAIService) Summarize(ctx context.Context, text, summaryType string) (*services.AISummary, error) {
+	s.calls.Add(1)
+	<-s.gate
+	return &services.AISummary{Content: services.SummaryContent{Text: "shared"}, Model: "gpt-4", Type: summaryType}, nil
+}
+
+func TestHandleSummarizeTranscript_CoalescesConcurrentRequests(t *testing.T) {
+	transcriptRepo := newInMemoryTranscriptRepo()
+	transcriptRepo.transcripts["t1"] = &db.Transcript{
+		ID:      "t1",
+		VideoID: "v1",
+		Content: db.TranscriptSegments{{StartMs: 0, DurationMs: 1000, Text: "hello"}},
+	}
+	aiSvc := &gatedSummaryAIService{gate: make(chan struct{})}
+	summaryRepo := &lockedSummaryRepo{inner: newInMemoryAISummaryRepo()}
+
+	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, transcriptRepo, aiSvc, summaryRepo, newInMemoryAIExtractionRepo())
+	require.NoError(t, err)
+
+	recorders := make([]*httptest.ResponseRecorder, 3)
+	var wg sync.WaitGroup
+	for i := range recorders {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(rec *httptest.ResponseRecorder) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/t1/summarize", bytes.NewBufferString(`{"summary_type":"brief"}`))
+			server.router.ServeHTTP(rec, req)
+		}(recorders[i])
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(aiSvc.gate)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), aiSvc.calls.Load())
+	for _, rec := range recorders {
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp summaryResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, "summary-brief", resp.ID)
+		assert.Equal(t, "shared", resp.Content.Text)
+	}
+}
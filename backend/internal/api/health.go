@@ -3,8 +3,15 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
 )
 
 type HealthResponse struct {
@@ -51,3 +58,272 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(statusCode)
 	_ = json.NewEncoder(w).Encode(response)
 }
+
+// Readiness check states. A failing critical check is down and makes the server not ready; a
+// failing optional check is degraded, which is reported but keeps the server in rotation.
+const (
+	checkOK       = "ok"
+	checkDegraded = "degraded"
+	checkDown     = "down"
+)
+
+const (
+	// readinessCheckTimeout bounds each check so one slow dependency cannot stall the probe.
+	readinessCheckTimeout = 2 * time.Second
+	// externalCheckInterval is how long results of checks against third parties are reused, so
+	// frequent probes neither add latency nor hammer the providers.
+	externalCheckInterval = time.Minute
+	// poolSaturationThreshold is the share of connections in use above which the pool is
+	// reported as saturated.
+	poolSaturationThreshold = 0.9
+)
+
+// schemaVersionReader reports the applied and expected database schema versions.
+type schemaVersionReader interface {
+	SchemaVersion(ctx context.Context) (current, latest int, err error)
+}
+
+// WithSchemaVersionReader enables the migration check of /readyz.
+func WithSchemaVersionReader(reader schemaVersionReader) ServerOption {
+	return func(s *Server) {
+		s.schema = reader
+	}
+}
+
+// aiPinger is implemented by AI services that can check their provider is reachable.
+type aiPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// youtubePinger is implemented by YouTube services that can check YouTube is reachable.
+type youtubePinger interface {
+	Ping(ctx context.Context) error
+	LastSuccess() time.Time
+}
+
+// LivenessResponse is the body of /livez.
+type LivenessResponse struct {
+	Status        string `json:"status"`
+	Timestamp     string `json:"timestamp"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+}
+
+// CheckResult is the outcome of one readiness check.
+type CheckResult struct {
+	Status      string     `json:"status"`
+	LatencyMs   int64      `json:"latency_ms"`
+	Detail      string     `json:"detail,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CheckedAt   time.Time  `json:"checked_at"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+}
+
+// ReadinessResponse is the body of /readyz. Status is ready, degraded or not_ready.
+type ReadinessResponse struct {
+	Status    string                 `json:"status"`
+	Timestamp string                 `json:"timestamp"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// degradedError marks a failure of a critical check that should only degrade readiness.
+type degradedError struct{ err error }
+
+func (e degradedError) Error() string { return e.err.Error() }
+func (e degradedError) Unwrap() error { return e.err }
+
+// readinessCheck is one dependency checked by /readyz.
+type readinessCheck struct {
+	name string
+	// critical checks make the server not ready when they fail.
+	critical bool
+	// cacheFor reuses the last result for this long; zero runs the check on every probe.
+	cacheFor time.Duration
+	run      func(ctx context.Context) (detail string, err error)
+	// lastSuccess, if set, reports successes seen outside the check itself.
+	lastSuccess func() time.Time
+}
+
+// readinessState remembers the last result of every check, for caching and last_success, and
+// runs each check once among concurrent probes.
+type readinessState struct {
+	mu      sync.Mutex
+	results map[string]CheckResult
+	flight  singleflight.Group
+}
+
+func newReadinessState() *readinessState {
+	return &readinessState{results: make(map[string]CheckResult)}
+}
+
+// handleLive reports that the process is up and serving. It checks no dependencies, so an
+// outage elsewhere never gets the server restarted.
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	writeJSON(w, http.StatusOK, LivenessResponse{
+		Status:        "alive",
+		Timestamp:     now.UTC().Format(time.RFC3339),
+		UptimeSeconds: int64(now.Sub(s.startedAt).Seconds()),
+	})
+}
+
+// handleReady checks every dependency concurrently. It answers 503 when a critical check is
+// down and 200 otherwise, with status degraded when an optional check failed.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	checks := s.readinessChecks()
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.readiness.run(r.Context(), check)
+		}()
+	}
+	wg.Wait()
+
+	response := ReadinessResponse{
+		Status:    "ready",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Checks:    make(map[string]CheckResult, len(checks)),
+	}
+	statusCode := http.StatusOK
+	for i, check := range checks {
+		response.Checks[check.name] = results[i]
+		switch results[i].Status {
+		case checkDown:
+			response.Status = "not_ready"
+			statusCode = http.StatusServiceUnavailable
+		case checkDegraded:
+			if response.Status == "ready" {
+				response.Status = "degraded"
+			}
+		}
+	}
+	writeJSON(w, statusCode, response)
+}
+
+// readinessChecks lists the checks for the dependencies this server was configured with.
+func (s *Server) readinessChecks() []readinessCheck {
+	checks := []readinessCheck{{
+		name:     "database",
+		critical: true,
+		run: func(ctx context.Context) (string, error) {
+			return "", s.db.Ping(ctx)
+		},
+	}}
+	if s.schema != nil {
+		checks = append(checks, readinessCheck{name: "migrations", critical: true, run: s.checkSchemaVersion})
+	}
+	if s.db.Stat() != nil {
+		checks = append(checks, readinessCheck{name: "db_pool", run: s.checkPoolSaturation})
+	}
+	if pinger, ok := s.aiService.(aiPinger); ok {
+		checks = append(checks, readinessCheck{
+			name:     "ai_provider",
+			cacheFor: externalCheckInterval,
+			run: func(ctx context.Context) (string, error) {
+				return "", pinger.Ping(ctx)
+			},
+		})
+	}
+	if pinger, ok := s.youtube.(youtubePinger); ok {
+		checks = append(checks, readinessCheck{
+			name:     "youtube",
+			cacheFor: externalCheckInterval,
+			run: func(ctx context.Context) (string, error) {
+				return "", pinger.Ping(ctx)
+			},
+			lastSuccess: pinger.LastSuccess,
+		})
+	}
+	return checks
+}
+
+// checkSchemaVersion fails when migrations are pending. A database migrated by hand, with tables
+// but no recorded migrations, is only degraded: its schema is most likely current and just
+// needs `server migrate baseline`.
+func (s *Server) checkSchemaVersion(ctx context.Context) (string, error) {
+	current, latest, err := s.schema.SchemaVersion(ctx)
+	if errors.Is(err, db.ErrNotBaselined) {
+		return fmt.Sprintf("no recorded migrations, this build expects %d", latest), degradedError{err}
+	}
+	if err != nil {
+		return "", err
+	}
+	detail := fmt.Sprintf("schema version %d, this build expects %d", current, latest)
+	if current < latest {
+		return detail, errors.New("database schema is behind; apply the pending migrations")
+	}
+	return detail, nil
+}
+
+func (s *Server) checkPoolSaturation(context.Context) (string, error) {
+	stat := s.db.Stat()
+	detail := fmt.Sprintf("%d of %d connections in use, %d idle", stat.AcquiredConns(), stat.MaxConns(), stat.IdleConns())
+	if stat.MaxConns() > 0 && float64(stat.AcquiredConns()) >= poolSaturationThreshold*float64(stat.MaxConns()) {
+		return detail, errors.New("connection pool is saturated")
+	}
+	return detail, nil
+}
+
+// run returns the cached result of check while it is fresh, otherwise runs it. Probes arriving
+// while the check runs share its result instead of calling the dependency again; the check runs
+// detached from the first probe's cancellation, bounded by readinessCheckTimeout.
+func (rs *readinessState) run(ctx context.Context, check readinessCheck) CheckResult {
+	if result, fresh := rs.cached(check); fresh {
+		return result
+	}
+	result, _, _ := rs.flight.Do(check.name, func() (any, error) {
+		if result, fresh := rs.cached(check); fresh {
+			return result, nil
+		}
+		return rs.check(context.WithoutCancel(ctx), check), nil
+	})
+	return result.(CheckResult)
+}
+
+// cached returns the last result of check and whether it can still be reused.
+func (rs *readinessState) cached(check readinessCheck) (CheckResult, bool) {
+	rs.mu.Lock()
+	previous, seen := rs.results[check.name]
+	rs.mu.Unlock()
+	return previous, seen && check.cacheFor > 0 && time.Since(previous.CheckedAt) < check.cacheFor
+}
+
+// check runs check and records its result.
+func (rs *readinessState) check(ctx context.Context, check readinessCheck) CheckResult {
+	previous, _ := rs.cached(check)
+
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+	start := time.Now()
+	detail, err := check.run(ctx)
+	result := CheckResult{
+		Status:    checkOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Detail:    detail,
+		CheckedAt: start.UTC(),
+	}
+	if err != nil {
+		result.Status = checkDegraded
+		if check.critical && !errors.As(err, new(degradedError)) {
+			result.Status = checkDown
+		}
+		result.Error = err.Error()
+		result.LastSuccess = previous.LastSuccess
+	} else {
+		result.LastSuccess = &result.CheckedAt
+	}
+	if check.lastSuccess != nil {
+		if seenElsewhere := check.lastSuccess(); !seenElsewhere.IsZero() &&
+			(result.LastSuccess == nil || seenElsewhere.After(*result.LastSuccess)) {
+			seenElsewhere = seenElsewhere.UTC()
+			result.LastSuccess = &seenElsewhere
+		}
+	}
+
+	rs.mu.Lock()
+	rs.results[check.name] = result
+	rs.mu.Unlock()
+	return result
+}
@@ -1,15 +1,22 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
 )
 
 func TestHealthEndpoint(t *testing.T) {
@@ -67,3 +74,172 @@ func TestHealthEndpoint(t *testing.T) {
 		assert.Contains(t, response.Checks, "youtube_service")
 	})
 }
+
+type stubSchemaVersion struct {
+	current, latest int
+	err             error
+}
+
+func (s stubSchemaVersion) SchemaVersion(context.Context) (int, int, error) {
+	return s.current, s.latest, s.err
+}
+
+type pingingAIService struct {
+	noopAIService
+	err   error
+	pings int
+}
+
+func (s *pingingAIService) Ping(context.Context) error {
+	s.pings++
+	return s.err
+}
+
+// slowPingAIService holds every ping until release is closed.
+type slowPingAIService struct {
+	noopAIService
+	release chan struct{}
+	pings   atomic.Int32
+}
+
+func (s *slowPingAIService) Ping(context.Context) error {
+	s.pings.Add(1)
+	<-s.release
+	return nil
+}
+
+type pingingYouTubeService struct {
+	noopYouTubeService
+	err         error
+	lastSuccess time.Time
+}
+
+func (s pingingYouTubeService) Ping(context.Context) error {
+	return s.err
+}
+
+func (s pingingYouTubeService) LastSuccess() time.Time {
+	return s.lastSuccess
+}
+
+func getReadiness(t *testing.T, server *Server) (int, ReadinessResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var response ReadinessResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	return rec.Code, response
+}
+
+func TestLivez_IgnoresDependencies(t *testing.T) {
+	server, err := NewServer(mockConfig(), &mockDB{pingError: errors.New("boom")}, noopYouTubeService{}, noopVideoRepo{}, noopTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response LivenessResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "alive", response.Status)
+}
+
+func TestReadyz_AllChecksPass(t *testing.T) {
+	seen := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	server, err := NewServer(mockConfig(), &mockDB{}, pingingYouTubeService{lastSuccess: seen}, noopVideoRepo{}, noopTranscriptRepo{}, &pingingAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{},
+		WithSchemaVersionReader(stubSchemaVersion{current: 11, latest: 11}))
+	require.NoError(t, err)
+
+	code, response := getReadiness(t, server)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", response.Status)
+	assert.ElementsMatch(t, []string{"database", "migrations", "ai_provider", "youtube"}, slices.Collect(maps.Keys(response.Checks)))
+	for name, check := range response.Checks {
+		assert.Equal(t, checkOK, check.Status, name)
+		require.NotNil(t, check.LastSuccess, name)
+	}
+	assert.Equal(t, "schema version 11, this build expects 11", response.Checks["migrations"].Detail)
+	assert.True(t, response.Checks["youtube"].LastSuccess.After(seen), "a fresh ping is newer than the last fetch")
+}
+
+func TestReadyz_CriticalFailuresAreNotReady(t *testing.T) {
+	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, noopTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{},
+		WithSchemaVersionReader(stubSchemaVersion{current: 10, latest: 11}))
+	require.NoError(t, err)
+
+	code, response := getReadiness(t, server)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", response.Status)
+	assert.Equal(t, checkOK, response.Checks["database"].Status)
+	migrations := response.Checks["migrations"]
+	assert.Equal(t, checkDown, migrations.Status)
+	assert.Contains(t, migrations.Error, "apply the pending migrations")
+	assert.Nil(t, migrations.LastSuccess)
+
+	server, err = NewServer(mockConfig(), &mockDB{pingError: errors.New("connection refused")}, noopYouTubeService{}, noopVideoRepo{}, noopTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+	code, response = getReadiness(t, server)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, checkDown, response.Checks["database"].Status)
+	assert.Equal(t, "connection refused", response.Checks["database"].Error)
+}
+
+func TestReadyz_UnrecordedSchemaIsDegraded(t *testing.T) {
+	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, noopTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{},
+		WithSchemaVersionReader(stubSchemaVersion{latest: 11, err: db.ErrNotBaselined}))
+	require.NoError(t, err)
+
+	code, response := getReadiness(t, server)
+	assert.Equal(t, http.StatusOK, code, "a database migrated by hand stays in rotation")
+	assert.Equal(t, "degraded", response.Status)
+	migrations := response.Checks["migrations"]
+	assert.Equal(t, checkDegraded, migrations.Status)
+	assert.Equal(t, "no recorded migrations, this build expects 11", migrations.Detail)
+	assert.Contains(t, migrations.Error, "migrate baseline")
+}
+
+func TestReadyz_OptionalFailuresDegradeAndAreCached(t *testing.T) {
+	ai := &pingingAIService{err: errors.New("anthropic: status 401")}
+	seen := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	youtube := pingingYouTubeService{err: errors.New("youtube: status 503"), lastSuccess: seen}
+	server, err := NewServer(mockConfig(), &mockDB{}, youtube, noopVideoRepo{}, noopTranscriptRepo{}, ai, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+
+	for range 2 {
+		code, response := getReadiness(t, server)
+		assert.Equal(t, http.StatusOK, code, "degraded dependencies keep the server in rotation")
+		assert.Equal(t, "degraded", response.Status)
+		assert.Equal(t, checkDegraded, response.Checks["ai_provider"].Status)
+		assert.Equal(t, "anthropic: status 401", response.Checks["ai_provider"].Error)
+		assert.Equal(t, checkDegraded, response.Checks["youtube"].Status)
+		require.NotNil(t, response.Checks["youtube"].LastSuccess)
+		assert.True(t, seen.Equal(*response.Checks["youtube"].LastSuccess), "last successful fetch is reported")
+	}
+	assert.Equal(t, 1, ai.pings, "external checks are cached between probes")
+}
+
+func TestReadyz_ConcurrentProbesShareExternalChecks(t *testing.T) {
+	ai := &slowPingAIService{release: make(chan struct{})}
+	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, noopTranscriptRepo{}, ai, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 5)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			server.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			codes[i] = rec.Code
+		}()
+	}
+	require.Eventually(t, func() bool { return ai.pings.Load() > 0 }, time.Second, time.Millisecond)
+	close(ai.release)
+	wg.Wait()
+
+	for _, code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+	assert.Equal(t, int32(1), ai.pings.Load(), "probes waiting on a running check share its result")
+}
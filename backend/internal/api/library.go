@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+)
+
+type libraryEntryResponse struct {
+	TranscriptID string    `json:"transcript_id"`
+	Language     string    `json:"language"`
+	VideoID      string    `json:"video_id"`
+	Title        string    `json:"title"`
+	Channel      string    `json:"channel"`
+	Duration     int       `json:"duration"`
+	AddedAt      time.Time `json:"added_at"`
+}
+
+type libraryResponse struct {
+	Transcripts []libraryEntryResponse `json:"transcripts"`
+}
+
+// handleListLibrary serves GET /api/v1/library with the signed-in user's transcripts.
+func (s *Server) handleListLibrary(w http.ResponseWriter, r *http.Request) {
+	if s.library == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "User accounts are not configured on this server")
+		return
+	}
+
+	entries, err := s.library.ListLibrary(r.Context(), userIDFromContext(r.Context()))
+	if err != nil {
+		writeRepositoryError(w, err, "Failed to list library")
+		return
+	}
+
+	response := libraryResponse{Transcripts: make([]libraryEntryResponse, 0, len(entries))}
+	for _, entry := range entries {
+		response.Transcripts = append(response.Transcripts, libraryEntryResponse{
+			TranscriptID: entry.TranscriptID,
+			Language:     entry.Language,
+			VideoID:      entry.Video.YouTubeID,
+			Title:        entry.Video.Title,
+			Channel:      entry.Video.Channel,
+			Duration:     entry.Video.Duration,
+			AddedAt:      entry.AddedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleRemoveFromLibrary serves DELETE /api/v1/library/{id}. The shared transcript is kept.
+func (s *Server) handleRemoveFromLibrary(w http.ResponseWriter, r *http.Request) {
+	if s.library == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "User accounts are not configured on this server")
+		return
+	}
+
+	id := strings.TrimSpace(chi.URLParam(r, "id"))
+	if _, err := uuid.Parse(id); err != nil {
+		writeStructuredError(w, http.StatusNotFound, nil, "Transcript not found")
+		return
+	}
+	if err := s.library.RemoveFromLibrary(r.Context(), userIDFromContext(r.Context()), id); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeStructuredError(w, http.StatusNotFound, err, "Transcript not found")
+			return
+		}
+		writeRepositoryError(w, err, "Failed to remove transcript from library")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireTranscriptAccess limits callers to the transcripts they may see. Signed-in users see
+// their library, and other transcripts look missing to them. Admins and keys with the
+// transcripts:all scope see every transcript; other keys see none. On servers without user
+// accounts there are no libraries to keep apart, so anonymous callers and every key see every
+// transcript.
+func (s *Server) requireTranscriptAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := principalFromContext(r.Context())
+		userID := libraryOwnerFromContext(r.Context())
+		id := strings.TrimSpace(chi.URLParam(r, "id"))
+		switch {
+		case principal == nil:
+			if s.accountsEnabled() {
+				writeUnauthorized(w, "API key or session token required")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		case !s.accountsEnabled() && userID == "":
+			next.ServeHTTP(w, r)
+			return
+		case principal.hasScope(scopeTranscriptsAll):
+			next.ServeHTTP(w, r)
+			return
+		case userID == "":
+			writeStructuredErrorWithDetails(w, http.StatusForbidden, nil,
+				fmt.Sprintf("These credentials lack the %s scope", scopeTranscriptsAll), map[string]string{"required_scope": scopeTranscriptsAll})
+			return
+		case id == "":
+			// Routes without a transcript ID, such as the archive export, filter by library.
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.library == nil {
+			writeStructuredError(w, http.StatusServiceUnavailable, nil, "User accounts are not configured on this server")
+			return
+		}
+		if _, err := uuid.Parse(id); err != nil {
+			writeStructuredError(w, http.StatusNotFound, nil, "Transcript not found")
+			return
+		}
+
+		ok, err := s.library.InLibrary(r.Context(), userID, id)
+		if err != nil {
+			writeRepositoryError(w, err, "Failed to load transcript")
+			return
+		}
+		if !ok {
+			writeStructuredError(w, http.StatusNotFound, nil, "Transcript not found")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// accountsEnabled reports whether users can sign up or sign in, so transcripts belong to
+// libraries that anonymous callers must not see.
+func (s *Server) accountsEnabled() bool {
+	return s.users != nil && (s.config.AuthSignupEnabled || s.oidc != nil)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/logging"
+)
+
+// captureLogs sends the default logger to a buffer for the rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "debug", "json")
+	require.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line), scanner.Text())
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestLogRequests_CorrelatesLinesByRequestID(t *testing.T) {
+	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, noopTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+	logs := captureLogs(t)
+
+	rec := serveWithKey(server, http.MethodPost, "/api/v1/transcripts/fetch", "", `{"video_url": ""}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	lines := logLines(t, logs)
+	require.Len(t, lines, 2)
+	handlerLine, accessLine := lines[0], lines[1]
+	assert.Equal(t, "video URL validation failed", handlerLine["msg"])
+	assert.Equal(t, "request", accessLine["msg"])
+	assert.Equal(t, "POST", accessLine["method"])
+	assert.Equal(t, "/api/v1/transcripts/fetch", accessLine["route"])
+	assert.Equal(t, float64(http.StatusBadRequest), accessLine["status"])
+
+	assert.NotEmpty(t, accessLine["request_id"])
+	assert.Equal(t, accessLine["request_id"], handlerLine["request_id"])
+}
+
+func TestLogRequests_LogsServerErrorsAtErrorLevel(t *testing.T) {
+	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, noopTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+	server.router.Get("/boom", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	logs := captureLogs(t)
+
+	serveWithKey(server, http.MethodGet, "/boom", "", "")
+
+	lines := logLines(t, logs)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.Equal(t, float64(http.StatusBadGateway), lines[0]["status"])
+}
@@ -4,7 +4,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"runtime"
+	"strconv"
 	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/metrics"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
 )
 
 type MetricsResponse struct {
@@ -12,10 +21,13 @@ type MetricsResponse struct {
 	MemoryUsage  uint64 `json:"memory_usage_bytes"`
 	NumGoroutine int    `json:"num_goroutines"`
 	NumCPU       int    `json:"num_cpu"`
+
+	AIRetries map[string]services.RetryStats `json:"ai_retries"`
 }
 
 var startTime = time.Now()
 
+// handleMetrics serves GET /api/metrics: a JSON snapshot of the process for quick checks.
 func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
@@ -25,8 +37,48 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 		MemoryUsage:  m.Alloc,
 		NumGoroutine: runtime.NumGoroutine(),
 		NumCPU:       runtime.NumCPU(),
+		AIRetries:    services.AIRetryStats(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(response)
 }
+
+// prometheusHandler serves GET /metrics in the Prometheus text format: the application
+// metrics and Go runtime and process collectors from the default registry, plus the
+// connection pool stats read at scrape time.
+func (s *Server) prometheusHandler() http.Handler {
+	pool := prometheus.NewRegistry()
+	pool.MustRegister(metrics.NewPoolCollector(s.db.Stat))
+	return promhttp.HandlerFor(prometheus.Gatherers{prometheus.DefaultGatherer, pool}, promhttp.HandlerOpts{})
+}
+
+// requestMetrics counts requests and their latency by route pattern rather than path, so IDs
+// do not create new series. Requests that match no route share the "unmatched" route.
+func requestMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		metrics.HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// observeCacheLookup counts whether a stored AI result was found before generating one.
+func observeCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	metrics.AICacheLookups.WithLabelValues(cache, result).Inc()
+}
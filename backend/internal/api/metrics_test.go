@@ -14,27 +14,38 @@ func TestMetricsEndpoint(t *testing.T) {
 	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, noopTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
 	require.NoError(t, err)
 
-	t.Run("returns metrics at root path", func(t *testing.T) {
+	t.Run("returns prometheus metrics at root path", func(t *testing.T) {
+		server.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/abc/export", nil))
+
 		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
 		rec := httptest.NewRecorder()
 
 		server.router.ServeHTTP(rec, req)
 
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
-
-		var response MetricsResponse
-		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
-		assert.NotEmpty(t, response.Uptime)
-		assert.Greater(t, response.NumCPU, 0)
-		assert.GreaterOrEqual(t, response.NumGoroutine, 1)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+		body := rec.Body.String()
+		assert.Contains(t, body, "# TYPE yt_http_requests_total counter")
+		assert.Contains(t, body, `yt_http_request_duration_seconds_count{method="GET",route="/api/v1/transcripts/{id}/export"}`,
+			"requests are labelled by route pattern, not path")
+		assert.Contains(t, body, "# TYPE yt_http_request_duration_seconds histogram")
+		assert.Contains(t, body, "go_goroutines ")
+		assert.NotContains(t, body, "yt_db_pool_", "pool stats are skipped without a pool")
 	})
 
-	t.Run("returns metrics under api path", func(t *testing.T) {
+	t.Run("returns json metrics under api path", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/api/metrics", nil)
 		rec := httptest.NewRecorder()
 
 		server.router.ServeHTTP(rec, req)
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var response MetricsResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.NotEmpty(t, response.Uptime)
+		assert.Greater(t, response.NumCPU, 0)
+		assert.GreaterOrEqual(t, response.NumGoroutine, 1)
+		assert.NotNil(t, response.AIRetries)
 	})
 }
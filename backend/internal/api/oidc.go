@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+// Roles of signed-in users. Single sign-on maps them from the identity provider's groups.
+const (
+	roleViewer = "viewer"
+	roleEditor = "editor"
+	roleAdmin  = "admin"
+)
+
+// roleScopes are the scopes each role holds; requireScope enforces them like API key scopes.
+var roleScopes = map[string][]string{
+	roleViewer: {scopeTranscriptsRead},
+	roleEditor: {scopeTranscriptsRead, scopeTranscriptsWrite, scopeAIWrite},
+	roleAdmin:  {scopeAdmin},
+}
+
+const (
+	oidcStateCookie = "yts_oidc_state"
+	oidcCookiePath  = "/api/v1/auth/oidc"
+	oidcStateTTL    = 10 * time.Minute
+)
+
+type oidcProvider interface {
+	Issuer() string
+	AuthCodeURL(state, nonce, codeChallenge string) string
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*services.OIDCIdentity, error)
+}
+
+// WithOIDCProvider enables single sign-on. It needs WithUserRepositories as well.
+func WithOIDCProvider(provider oidcProvider) ServerOption {
+	return func(s *Server) {
+		s.oidc = provider
+	}
+}
+
+var (
+	errOIDCEmailRequired   = errors.New("identity provider did not supply a verified email")
+	errOIDCPasswordAccount = errors.New("account with this email signs in with a password")
+)
+
+// handleOIDCLogin serves GET /api/v1/auth/oidc/login by sending the browser to the identity
+// provider. State, nonce and PKCE verifier travel in a short-lived cookie to the callback.
+func (s *Server) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil || s.users == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "Single sign-on is not configured on this server")
+		return
+	}
+
+	state, errState := services.NewOIDCNonce()
+	nonce, errNonce := services.NewOIDCNonce()
+	verifier, errVerifier := services.NewPKCEVerifier()
+	if err := errors.Join(errState, errNonce, errVerifier); err != nil {
+		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to start sign-in")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    strings.Join([]string{state, nonce, verifier}, "."),
+		Path:     oidcCookiePath,
+		MaxAge:   int(oidcStateTTL / time.Second),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(s.config.OIDCRedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, s.oidc.AuthCodeURL(state, nonce, services.PKCEChallenge(verifier)), http.StatusFound)
+}
+
+// handleOIDCCallback serves GET /api/v1/auth/oidc/callback: it redeems the code, maps the
+// user's groups to a role and starts a session. With OIDC_POST_LOGIN_URL the browser is sent
+// there with the token in the URL fragment; otherwise the session is returned as JSON.
+func (s *Server) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil || s.users == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "Single sign-on is not configured on this server")
+		return
+	}
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		writeStructuredErrorWithDetails(w, http.StatusUnauthorized, nil, "Sign-in was cancelled or denied by the identity provider",
+			map[string]string{"error": providerErr, "error_description": query.Get("error_description")})
+		return
+	}
+
+	cookie, err := r.Cookie(oidcStateCookie)
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: oidcCookiePath, MaxAge: -1, HttpOnly: true})
+	var state, nonce, verifier string
+	if err == nil {
+		parts := strings.Split(cookie.Value, ".")
+		if len(parts) == 3 {
+			state, nonce, verifier = parts[0], parts[1], parts[2]
+		}
+	}
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(query.Get("state"))) != 1 || query.Get("code") == "" {
+		writeStructuredError(w, http.StatusBadRequest, err, "Sign-in request expired or invalid. Please start again.")
+		return
+	}
+
+	identity, err := s.oidc.Exchange(r.Context(), query.Get("code"), verifier, nonce)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "oidc exchange failed", "error", err)
+		if errors.Is(err, services.ErrOIDCExchange) || errors.Is(err, services.ErrOIDCInvalidToken) {
+			writeUnauthorized(w, "Single sign-on failed. Please start again.")
+			return
+		}
+		writeStructuredError(w, http.StatusBadGateway, err, "Could not reach the identity provider")
+		return
+	}
+
+	role := s.oidcRole(identity.Groups)
+	if role == "" {
+		writeStructuredError(w, http.StatusForbidden, nil, "Your account is not in a group with access to this server")
+		return
+	}
+
+	user, err := s.resolveOIDCUser(r.Context(), identity)
+	if err != nil {
+		if errors.Is(err, errOIDCEmailRequired) {
+			writeStructuredError(w, http.StatusForbidden, err, "The identity provider did not supply a verified email")
+			return
+		}
+		if errors.Is(err, errOIDCPasswordAccount) {
+			writeStructuredError(w, http.StatusConflict, err, "An account with this email already exists. Sign in with its password instead.")
+			return
+		}
+		writeRepositoryError(w, err, "Failed to sign in")
+		return
+	}
+
+	session, err := s.startSession(r.Context(), user, role)
+	if err != nil {
+		writeRepositoryError(w, err, "Failed to start session")
+		return
+	}
+
+	if target := s.config.OIDCPostLoginURL; target != "" {
+		fragment := url.Values{"token": {session.Token}, "expires_at": {session.ExpiresAt.UTC().Format(time.RFC3339)}}
+		http.Redirect(w, r, strings.SplitN(target, "#", 2)[0]+"#"+fragment.Encode(), http.StatusSeeOther)
+		return
+	}
+	writeJSON(w, http.StatusOK, session)
+}
+
+// oidcRole returns the highest role any of groups maps to, or the configured default.
+func (s *Server) oidcRole(groups []string) string {
+	for _, mapping := range []struct {
+		role   string
+		groups []string
+	}{
+		{roleAdmin, s.config.OIDCAdminGroups},
+		{roleEditor, s.config.OIDCEditorGroups},
+		{roleViewer, s.config.OIDCViewerGroups},
+	} {
+		for _, group := range groups {
+			if slices.Contains(mapping.groups, group) {
+				return mapping.role
+			}
+		}
+	}
+	return s.config.OIDCDefaultRole
+}
+
+// resolveOIDCUser finds the user linked to identity. On first sign-in the identity is linked
+// to the account without a password that has the same verified email, or to a new one. Password
+// accounts are never linked: any configured issuer could otherwise take them over.
+func (s *Server) resolveOIDCUser(ctx context.Context, identity *services.OIDCIdentity) (*db.User, error) {
+	user, err := s.users.GetUserByIdentity(ctx, identity.Issuer, identity.Subject)
+	if err == nil || !errors.Is(err, db.ErrNotFound) {
+		return user, err
+	}
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errOIDCEmailRequired
+	}
+
+	user, err = s.users.GetUserByEmail(ctx, identity.Email)
+	if errors.Is(err, db.ErrNotFound) {
+		user = &db.User{Email: identity.Email, DisplayName: truncateRunes(strings.TrimSpace(identity.Name), maxDisplayNameLength)}
+		err = s.users.CreateUser(ctx, user)
+		if errors.Is(err, db.ErrAlreadyExists) {
+			// A concurrent first sign-in created the account.
+			user, err = s.users.GetUserByEmail(ctx, identity.Email)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.PasswordHash != "" {
+		return nil, errOIDCPasswordAccount
+	}
+	if err := s.users.LinkIdentity(ctx, user.ID, identity.Issuer, identity.Subject); err != nil {
+		if errors.Is(err, db.ErrAlreadyExists) {
+			return s.users.GetUserByIdentity(ctx, identity.Issuer, identity.Subject)
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+	"github.com/yourusername/yt-transcript-downloader/internal/services/oidctest"
+)
+
+const testOIDCRedirectURL = "http://localhost:8080/api/v1/auth/oidc/callback"
+
+func newOIDCTestServer(t *testing.T, configure func(*config.Config)) (*Server, *oidctest.Issuer, *stubUserRepo) {
+	t.Helper()
+	issuer := oidctest.NewIssuer(t, "yt-app", "secret")
+	provider, err := services.NewOIDCProvider(context.Background(), services.OIDCConfig{
+		IssuerURL:    issuer.URL(),
+		ClientID:     issuer.ClientID,
+		ClientSecret: issuer.ClientSecret,
+		RedirectURL:  testOIDCRedirectURL,
+		GroupsClaim:  "groups",
+	})
+	require.NoError(t, err)
+
+	cfg := &config.Config{
+		APIPort:          8080,
+		AuthRequired:     true,
+		OIDCRedirectURL:  testOIDCRedirectURL,
+		OIDCAdminGroups:  []string{"platform"},
+		OIDCEditorGroups: []string{"research"},
+		OIDCViewerGroups: []string{"everyone"},
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+	users := &stubUserRepo{}
+	server, err := NewServer(cfg, &mockDB{}, noopYouTubeService{}, &recordingVideoRepo{}, &recordingTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{},
+		WithUserRepositories(users, &stubLibraryRepo{}), WithOIDCProvider(provider))
+	require.NoError(t, err)
+	return server, issuer, users
+}
+
+// oidcSignIn runs the browser side of the code flow for a user with the given claims and
+// returns the callback response.
+func oidcSignIn(t *testing.T, server *Server, issuer *oidctest.Issuer, claims map[string]any) *httptest.ResponseRecorder {
+	t.Helper()
+	issuer.SetUser(claims)
+
+	rec := serveWithKey(server, http.MethodGet, "/api/v1/auth/oidc/login", "", "")
+	require.Equal(t, http.StatusFound, rec.Code)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.True(t, cookies[0].HttpOnly)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOIDCSignIn_MapsGroupsToRoles(t *testing.T) {
+	server, issuer, users := newOIDCTestServer(t, nil)
+
+	rec := oidcSignIn(t, server, issuer, map[string]any{
+		"sub": "ada", "email": "ada@example.com", "email_verified": true, "name": "Ada", "groups": []string{"everyone", "research"},
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var editor sessionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &editor))
+	assert.Equal(t, roleEditor, editor.User.Role, "the highest matching role wins")
+	require.Len(t, users.users, 1)
+	assert.Empty(t, users.users[0].PasswordHash)
+
+	me := serveWithKey(server, http.MethodGet, "/api/v1/auth/me", editor.Token, "")
+	require.Equal(t, http.StatusOK, me.Code)
+	assert.Contains(t, me.Body.String(), `"role":"editor"`)
+	assert.NotEqual(t, http.StatusForbidden, serveWithKey(server, http.MethodPost, "/api/v1/transcripts/abc/summarize", editor.Token, `{}`).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(server, http.MethodGet, "/api/v1/usage", editor.Token, "").Code)
+
+	rec = oidcSignIn(t, server, issuer, map[string]any{
+		"sub": "bob", "email": "bob@example.com", "email_verified": true, "groups": []string{"everyone"},
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var viewer sessionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &viewer))
+	assert.Equal(t, roleViewer, viewer.User.Role)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(server, http.MethodPost, "/api/v1/transcripts/abc/summarize", viewer.Token, `{}`).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(server, http.MethodPost, "/api/v1/transcripts/fetch", viewer.Token, `{}`).Code)
+
+	rec = oidcSignIn(t, server, issuer, map[string]any{
+		"sub": "cy", "email": "cy@example.com", "email_verified": true, "groups": "platform",
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var admin sessionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &admin))
+	assert.Equal(t, http.StatusServiceUnavailable, serveWithKey(server, http.MethodGet, "/api/v1/admin/keys", admin.Token, "").Code, "admins pass the scope check")
+
+	rec = oidcSignIn(t, server, issuer, map[string]any{
+		"sub": "dee", "email": "dee@example.com", "email_verified": true, "groups": []string{"contractors"},
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code, "users without a mapped group are turned away")
+}
+
+func TestOIDCSignIn_LinksAccounts(t *testing.T) {
+	server, issuer, users := newOIDCTestServer(t, func(cfg *config.Config) { cfg.OIDCDefaultRole = roleViewer })
+	require.NoError(t, users.CreateUser(context.Background(), &db.User{Email: "ada@example.com"}))
+	require.NoError(t, users.CreateUser(context.Background(), &db.User{Email: "bob@example.com", PasswordHash: "hash"}))
+
+	claims := map[string]any{"sub": "ada", "email": "ada@example.com", "email_verified": true}
+	require.Equal(t, http.StatusOK, oidcSignIn(t, server, issuer, claims).Code)
+	claims["email"] = "ada@new-domain.example.com"
+	require.Equal(t, http.StatusOK, oidcSignIn(t, server, issuer, claims).Code)
+	assert.Len(t, users.users, 2, "a verified email links the existing account, later sign-ins match the subject")
+
+	rec := oidcSignIn(t, server, issuer, map[string]any{"sub": "bob", "email": "bob@example.com", "email_verified": true})
+	assert.Equal(t, http.StatusConflict, rec.Code, "password accounts are not taken over by an issuer")
+	assert.Len(t, users.identities, 1, "only ada's identity is linked")
+	assert.Len(t, users.users, 2)
+
+	rec = oidcSignIn(t, server, issuer, map[string]any{"sub": "eve", "email": "eve@example.com", "email_verified": false})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Len(t, users.users, 2)
+}
+
+// racingUserRepo misses an account on the first lookup by email, as when another first sign-in
+// creates it between the lookup and CreateUser.
+type racingUserRepo struct {
+	*stubUserRepo
+	missed bool
+}
+
+func (r *racingUserRepo) GetUserByEmail(ctx context.Context, email string) (*db.User, error) {
+	if !r.missed {
+		r.missed = true
+		return nil, db.ErrNotFound
+	}
+	return r.stubUserRepo.GetUserByEmail(ctx, email)
+}
+
+func TestResolveOIDCUser_ConcurrentFirstSignIn(t *testing.T) {
+	server, _, users := newOIDCTestServer(t, nil)
+	existing := &db.User{Email: "ada@example.com"}
+	require.NoError(t, users.CreateUser(context.Background(), existing))
+	server.users = &racingUserRepo{stubUserRepo: users}
+
+	user, err := server.resolveOIDCUser(context.Background(), &services.OIDCIdentity{
+		Issuer: "https://issuer.example.com", Subject: "ada", Email: "ada@example.com", EmailVerified: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, user.ID, "the account the other sign-in created is linked")
+	assert.Len(t, users.users, 1)
+}
+
+func TestOIDCCallback_RejectsForgedOrExpiredState(t *testing.T) {
+	server, _, _ := newOIDCTestServer(t, nil)
+
+	rec := serveWithKey(server, http.MethodGet, "/api/v1/auth/oidc/callback?code=abc&state=xyz", "", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?code=abc&state=xyz", nil)
+	req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: "other.nonce.verifier"})
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serveWithKey(server, http.MethodGet, "/api/v1/auth/oidc/callback?error=access_denied", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "access_denied")
+}
+
+func TestOIDCCallback_RedirectsToPostLoginURL(t *testing.T) {
+	server, issuer, _ := newOIDCTestServer(t, func(cfg *config.Config) { cfg.OIDCPostLoginURL = "http://localhost:3000/auth/done" })
+
+	rec := oidcSignIn(t, server, issuer, map[string]any{"sub": "ada", "email": "ada@example.com", "email_verified": true, "groups": []string{"research"}})
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/auth/done", location.Path)
+	fragment, err := url.ParseQuery(location.Fragment)
+	require.NoError(t, err)
+	token := fragment.Get("token")
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, "/api/v1/auth/me", token, "").Code)
+}
+
+func TestOIDCLogin_NotConfigured(t *testing.T) {
+	server, _, _ := newUserTestServer(t, &config.Config{APIPort: 8080}, noopYouTubeService{}, &recordingTranscriptRepo{})
+	assert.Equal(t, http.StatusServiceUnavailable, serveWithKey(server, http.MethodGet, "/api/v1/auth/oidc/login", "", "").Code)
+}
@@ -4,8 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -28,16 +27,17 @@ type qaRequest struct {
 }
 
 type qaResponse struct {
-	ID           string    `json:"id"`
-	TranscriptID string    `json:"transcript_id"`
-	Question     string    `json:"question"`
-	Answer       string    `json:"answer"`
-	Confidence   string    `json:"confidence"`
-	Sources      []string  `json:"sources"`
-	NotFound     bool      `json:"not_found"`
-	Model        string    `json:"model"`
-	TokensUsed   int       `json:"tokens_used"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID            string    `json:"id"`
+	TranscriptID  string    `json:"transcript_id"`
+	Question      string    `json:"question"`
+	Answer        string    `json:"answer"`
+	Confidence    string    `json:"confidence"`
+	Sources       []string  `json:"sources"`
+	NotFound      bool      `json:"not_found"`
+	Model         string    `json:"model"`
+	TokensUsed    int       `json:"tokens_used"`
+	PromptVersion string    `json:"prompt_version,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 func (s *Server) handleTranscriptQA(w http.ResponseWriter, r *http.Request) {
@@ -96,11 +96,11 @@ func (s *Server) handleTranscriptQA(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call AI service for answer
-	aiAnswer, err := s.aiService.Answer(ctx, transcriptText, question)
+	aiAnswer, err := s.aiService.Answer(services.WithTranscriptID(ctx, transcriptID), transcriptText, question)
 	if err != nil {
-		log.Printf("ERROR [%s %s] AI Q&A failed (question=%s, transcript=%s): %v",
-			r.Method, r.URL.Path, question, transcriptID, err)
-		handleAIAnswerError(w, err)
+		slog.ErrorContext(r.Context(), "AI Q&A failed",
+			"question", question, "transcript_id", transcriptID, "error", err)
+		writeAIError(w, err, "answer question", "Try with a shorter question or try again later.")
 		return
 	}
 
@@ -110,42 +110,18 @@ func (s *Server) handleTranscriptQA(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, response)
 }
 
-func handleAIAnswerError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, services.ErrAIRateLimited):
-		writeStructuredError(w, http.StatusTooManyRequests, err, "AI rate limit reached. Please wait a moment and try again.")
-	case errors.Is(err, services.ErrAIQuotaExceeded):
-		writeStructuredError(w, http.StatusPaymentRequired, err, "AI quota exceeded. Please contact the administrator.")
-	case errors.Is(err, services.ErrAIServiceUnavailable):
-		writeStructuredError(w, http.StatusServiceUnavailable, err, "AI service is temporarily unavailable. Please try again in a few moments.")
-	case errors.Is(err, services.ErrAIProviderNotConfigured):
-		writeStructuredError(w, http.StatusServiceUnavailable, err, "AI service is not configured. Please contact the administrator.")
-	case errors.Is(err, context.DeadlineExceeded):
-		writeStructuredError(w, http.StatusGatewayTimeout, err, "AI request timed out (>60s). Try with a shorter question or try again later.")
-	case errors.Is(err, context.Canceled):
-		writeStructuredError(w, http.StatusRequestTimeout, err, "Request was canceled.")
-	default:
-		// Check if it's a JSON parsing error
-		if strings.Contains(err.Error(), "parse") || strings.Contains(err.Error(), "unmarshal") || strings.Contains(err.Error(), "json") {
-			writeStructuredError(w, http.StatusInternalServerError, err, "AI response format error. The AI returned an invalid response. Please try again.")
-		} else {
-			// Generic error with hint about the actual error
-			writeStructuredError(w, http.StatusInternalServerError, err, fmt.Sprintf("Failed to answer question: %v", err))
-		}
-	}
-}
-
 func buildQAResponse(transcriptID, question string, answer *services.AIAnswer) qaResponse {
 	return qaResponse{
-		ID:           uuid.New().String(),
-		TranscriptID: transcriptID,
-		Question:     question,
-		Answer:       answer.Answer,
-		Confidence:   answer.Confidence,
-		Sources:      answer.Sources,
-		NotFound:     answer.NotFound,
-		Model:        answer.Model,
-		TokensUsed:   answer.TokensUsed,
-		CreatedAt:    time.Now().UTC(),
+		ID:            uuid.New().String(),
+		TranscriptID:  transcriptID,
+		Question:      question,
+		Answer:        answer.Answer,
+		Confidence:    answer.Confidence,
+		Sources:       answer.Sources,
+		NotFound:      answer.NotFound,
+		Model:         answer.Model,
+		TokensUsed:    answer.TokensUsed,
+		PromptVersion: answer.PromptVersion,
+		CreatedAt:     time.Now().UTC(),
 	}
 }
@@ -39,6 +39,14 @@ func (s *stubQAAIService) Answer(ctx context.Context, text string, question stri
 	return s.answer, nil
 }
 
+func (s *stubQAAIService) Punctuate(ctx context.Context, lines []string) (*services.AIPunctuation, error) {
+	return &services.AIPunctuation{Lines: lines}, nil
+}
+
+func (s *stubQAAIService) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*services.AISpeakerTurns, error) {
+	return &services.AISpeakerTurns{Turns: []services.SpeakerTurn{{Line: 1, Speaker: "Speaker 1"}}}, nil
+}
+
 func TestHandleTranscriptQA_Success(t *testing.T) {
 	cfg := mockConfig()
 	cfg.AIProvider = "openai"
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Route classes with their own inbound rate limits.
+const (
+	rateLimitDefault = "default"
+	rateLimitFetch   = "fetch"
+	rateLimitAI      = "ai"
+	rateLimitAuth    = "auth"
+)
+
+// rateLimitStore keeps one token bucket per key. Buckets hold limit tokens and refill at limit
+// tokens per minute.
+type rateLimitStore interface {
+	TakeRateLimitToken(ctx context.Context, key string, limit int, now time.Time) (remaining float64, allowed bool, err error)
+}
+
+// WithRateLimitStore replaces the in-memory rate limit buckets, e.g. with a store shared by all
+// instances.
+func WithRateLimitStore(store rateLimitStore) ServerOption {
+	return func(s *Server) {
+		s.rateLimits = store
+	}
+}
+
+// rateLimit allows each client perMinute requests per minute on the routes it wraps, with
+// bursts up to perMinute. Clients are identified by their API key or user once requireScope
+// has run, otherwise by IP. Every response carries RateLimit-* headers; rejected requests get
+// 429 with Retry-After. When the store fails, requests are let through.
+func (s *Server) rateLimit(class string, perMinute int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if perMinute <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			remaining, allowed, err := s.rateLimits.TakeRateLimitToken(r.Context(), class+":"+rateLimitClient(r), perMinute, time.Now())
+			if err != nil {
+				slog.WarnContext(r.Context(), "rate limit store failed", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			perSecond := float64(perMinute) / 60
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(perMinute))
+			header.Set("RateLimit-Remaining", strconv.Itoa(int(math.Max(0, math.Floor(remaining)))))
+			header.Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil((float64(perMinute)-remaining)/perSecond))))
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=60", perMinute))
+
+			if !allowed {
+				header.Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil((1-remaining)/perSecond)))))
+				writeStructuredErrorWithDetails(w, http.StatusTooManyRequests, nil, "Too many requests. Please slow down.",
+					map[string]string{"limit": class})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitClient identifies the caller for rate limiting. Unauthenticated credentials are
+// ignored, so random keys cannot dodge the per-IP limit, and the IP is only taken from
+// forwarding headers set by a trusted proxy (see realIP).
+func rateLimitClient(r *http.Request) string {
+	if principal := principalFromContext(r.Context()); principal != nil {
+		if principal.UserID != "" {
+			return "user:" + principal.UserID
+		}
+		if principal.KeyID != "" {
+			return "key:" + principal.KeyID
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// realIP replaces RemoteAddr with the client address forwarded by a trusted proxy. Forwarding
+// headers from any other peer are ignored, since clients could otherwise pick the address they
+// are rate limited and logged under. X-Forwarded-For is read from the right: the first hop
+// that is not a trusted proxy is the client.
+func (s *Server) realIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.trustedProxy(remoteAddr(r.RemoteAddr)) {
+			if client, ok := s.forwardedClient(r.Header); ok {
+				r.RemoteAddr = client.String()
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) forwardedClient(header http.Header) (netip.Addr, bool) {
+	var hops []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		if !s.trustedProxy(hop) || i == 0 {
+			return hop.Unmap(), true
+		}
+	}
+	if hop, err := netip.ParseAddr(strings.TrimSpace(header.Get("X-Real-IP"))); err == nil {
+		return hop.Unmap(), true
+	}
+	return netip.Addr{}, false
+}
+
+func (s *Server) trustedProxy(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	for _, prefix := range s.config.TrustedProxies {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteAddr parses a RemoteAddr with or without its port.
+func remoteAddr(raw string) netip.Addr {
+	host, _, err := net.SplitHostPort(raw)
+	if err != nil {
+		host = raw
+	}
+	addr, _ := netip.ParseAddr(host)
+	return addr
+}
+
+// memoryRateLimitStore keeps buckets in process; limits then apply per instance.
+type memoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*rateLimitBucket
+	pruned  time.Time
+}
+
+type rateLimitBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newMemoryRateLimitStore() *memoryRateLimitStore {
+	return &memoryRateLimitStore{buckets: make(map[string]*rateLimitBucket)}
+}
+
+func (m *memoryRateLimitStore) TakeRateLimitToken(_ context.Context, key string, limit int, now time.Time) (float64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// A bucket idle for a minute is full again, so it can be dropped.
+	if now.Sub(m.pruned) >= time.Minute {
+		for bucketKey, bucket := range m.buckets {
+			if now.Sub(bucket.last) >= time.Minute {
+				delete(m.buckets, bucketKey)
+			}
+		}
+		m.pruned = now
+	}
+
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{tokens: float64(limit), last: now}
+		m.buckets[key] = bucket
+	}
+	if elapsed := now.Sub(bucket.last).Seconds(); elapsed > 0 {
+		bucket.tokens = math.Min(float64(limit), bucket.tokens+elapsed*float64(limit)/60)
+		bucket.last = now
+	}
+	if bucket.tokens < 1 {
+		return bucket.tokens, false, nil
+	}
+	bucket.tokens--
+	return bucket.tokens, true, nil
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+)
+
+func newRateLimitTestServer(t *testing.T, cfg *config.Config, opts ...ServerOption) *Server {
+	t.Helper()
+	cfg.APIPort = 8080
+	cfg.AuthAdminKey = testAdminKey
+	opts = append([]ServerOption{WithUsageRepository(&stubUsageRepo{}), WithAPIKeyRepository(&stubAPIKeyRepo{})}, opts...)
+	server, err := NewServer(cfg, &mockDB{}, noopYouTubeService{}, &recordingVideoRepo{}, &recordingTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{}, opts...)
+	require.NoError(t, err)
+	return server
+}
+
+func serveFromIP(server *Server, path, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = ip + ":12345"
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimit_PerAPIKey(t *testing.T) {
+	server := newRateLimitTestServer(t, &config.Config{AuthRequired: true, RateLimitPerMinute: 3})
+	first := createTestKey(t, server, scopeTranscriptsRead)
+	second := createTestKey(t, server, scopeTranscriptsRead)
+
+	rec := serveWithKey(server, http.MethodGet, "/api/v1/transcripts/abc", first.Key, "")
+	assert.NotEqual(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "2", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "20", rec.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "3;w=60", rec.Header().Get("RateLimit-Policy"))
+
+	serveWithKey(server, http.MethodGet, "/api/v1/transcripts/abc", first.Key, "")
+	serveWithKey(server, http.MethodGet, "/api/v1/transcripts/abc", first.Key, "")
+	rec = serveWithKey(server, http.MethodGet, "/api/v1/transcripts/abc", first.Key, "")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "20", rec.Header().Get("Retry-After"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+
+	rec = serveWithKey(server, http.MethodGet, "/api/v1/transcripts/abc", second.Key, "")
+	assert.NotEqual(t, http.StatusTooManyRequests, rec.Code, "each key has its own bucket")
+
+	rec = serveWithKey(server, http.MethodGet, "/api/v1/transcripts/abc", "ytk_unknown", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, rec.Header().Get("RateLimit-Limit"), "invalid credentials are rejected before counting")
+}
+
+func TestRateLimit_PerIPForAnonymousCallers(t *testing.T) {
+	server := newRateLimitTestServer(t, &config.Config{RateLimitPerMinute: 1})
+
+	assert.NotEqual(t, http.StatusTooManyRequests, serveFromIP(server, "/api/v1/transcripts/abc", "203.0.113.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serveFromIP(server, "/api/v1/transcripts/abc", "203.0.113.1").Code)
+	assert.NotEqual(t, http.StatusTooManyRequests, serveFromIP(server, "/api/v1/transcripts/abc", "203.0.113.2").Code)
+
+	rec := serveFromIP(server, "/health", "203.0.113.1")
+	assert.Equal(t, http.StatusOK, rec.Code, "health checks are never limited")
+	assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimit_ForwardedAddressesOnlyFromTrustedProxies(t *testing.T) {
+	server := newRateLimitTestServer(t, &config.Config{
+		RateLimitPerMinute: 1,
+		TrustedProxies:     []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	})
+	serve := func(peer, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/abc", nil)
+		req.RemoteAddr = peer + ":12345"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// A client talking to the server directly cannot pick a fresh bucket with a header.
+	assert.NotEqual(t, http.StatusTooManyRequests, serve("203.0.113.1", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("203.0.113.1", "198.51.100.2"))
+
+	// Behind the proxy, the client is the last hop the proxy did not add.
+	assert.NotEqual(t, http.StatusTooManyRequests, serve("10.0.0.5", "198.51.100.3"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("10.0.0.5", "198.51.100.3"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("10.0.0.6", "192.0.2.99, 198.51.100.3, 10.0.0.7"), "spoofed leading hops are ignored")
+	assert.NotEqual(t, http.StatusTooManyRequests, serve("10.0.0.5", "198.51.100.4"))
+}
+
+func TestRateLimit_FetchHasItsOwnLimit(t *testing.T) {
+	server := newRateLimitTestServer(t, &config.Config{RateLimitPerMinute: 100, RateLimitFetchPerMinute: 1})
+
+	assert.NotEqual(t, http.StatusTooManyRequests, serveWithKey(server, http.MethodPost, "/api/v1/transcripts/fetch", "", `{}`).Code)
+	rec := serveWithKey(server, http.MethodPost, "/api/v1/transcripts/fetch", "", `{}`)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), `"limit":"fetch"`)
+
+	assert.NotEqual(t, http.StatusTooManyRequests, serveWithKey(server, http.MethodGet, "/api/v1/transcripts/abc", "", "").Code,
+		"reads keep their own budget")
+}
+
+func TestRateLimit_SignInHasItsOwnLimit(t *testing.T) {
+	server := newRateLimitTestServer(t, &config.Config{RateLimitPerMinute: 100, RateLimitAuthPerMinute: 1})
+
+	assert.NotEqual(t, http.StatusTooManyRequests, serveWithKey(server, http.MethodPost, "/api/v1/auth/login", "", `{}`).Code)
+	rec := serveWithKey(server, http.MethodPost, "/api/v1/auth/login", "", `{}`)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"limit":"auth"`)
+	assert.Equal(t, http.StatusTooManyRequests, serveWithKey(server, http.MethodPost, "/api/v1/auth/register", "", `{}`).Code,
+		"register and login share one budget")
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) TakeRateLimitToken(context.Context, string, int, time.Time) (float64, bool, error) {
+	return 0, false, errors.New("database connection failed")
+}
+
+func TestRateLimit_FailsOpenWhenStoreFails(t *testing.T) {
+	server := newRateLimitTestServer(t, &config.Config{RateLimitPerMinute: 1}, WithRateLimitStore(failingRateLimitStore{}))
+
+	for range 3 {
+		rec := serveWithKey(server, http.MethodGet, "/api/v1/transcripts/abc", "", "")
+		assert.NotEqual(t, http.StatusTooManyRequests, rec.Code)
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestMemoryRateLimitStore_Refills(t *testing.T) {
+	store := newMemoryRateLimitStore()
+	now := time.Now()
+
+	for range 2 {
+		_, allowed, err := store.TakeRateLimitToken(context.Background(), "k", 2, now)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	remaining, allowed, _ := store.TakeRateLimitToken(context.Background(), "k", 2, now)
+	assert.False(t, allowed)
+	assert.Zero(t, remaining)
+
+	_, allowed, _ = store.TakeRateLimitToken(context.Background(), "k", 2, now.Add(30*time.Second))
+	assert.True(t, allowed, "one token refills every 30 seconds at 2 per minute")
+
+	remaining, allowed, _ = store.TakeRateLimitToken(context.Background(), "k", 2, now.Add(time.Hour))
+	assert.True(t, allowed)
+	assert.Equal(t, 1.0, remaining, "buckets never hold more than the limit")
+}
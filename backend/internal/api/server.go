@@ -5,7 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -19,8 +19,8 @@ import (
 )
 
 type youtubeService interface {
-	GetVideoMetadata(videoID string) (*services.VideoMetadata, error)
-	GetTranscript(videoID, language string) ([]services.TranscriptLine, error)
+	GetVideoMetadata(ctx context.Context, videoID string) (*services.VideoMetadata, error)
+	GetTranscript(ctx context.Context, videoID, language string) ([]services.TranscriptLine, error)
 }
 
 type videoRepository interface {
@@ -37,6 +37,8 @@ type aiService interface {
 	Summarize(ctx context.Context, text string, summaryType string) (*services.AISummary, error)
 	Extract(ctx context.Context, text string, extractionType string) (*services.AIExtraction, error)
 	Answer(ctx context.Context, text string, question string) (*services.AIAnswer, error)
+	Punctuate(ctx context.Context, lines []string) (*services.AIPunctuation, error)
+	LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*services.AISpeakerTurns, error)
 }
 
 type aiSummaryRepository interface {
@@ -51,6 +53,37 @@ type aiExtractionRepository interface {
 	ListAIExtractions(ctx context.Context, transcriptID string) ([]*db.AIExtraction, error)
 }
 
+type transcriptCleanRepository interface {
+	SaveTranscriptCleanView(ctx context.Context, view *db.TranscriptCleanView) error
+	GetTranscriptCleanView(ctx context.Context, transcriptID string) (*db.TranscriptCleanView, error)
+}
+
+type libraryVideoRepository interface {
+	ListVideos(ctx context.Context, filter db.VideoFilter) ([]*db.Video, error)
+}
+
+type libraryTranscriptRepository interface {
+	GetTranscriptsByVideoID(ctx context.Context, videoID string) ([]*db.Transcript, error)
+}
+
+// ServerOption configures optional server dependencies.
+type ServerOption func(*Server)
+
+// WithTranscriptCleanRepository enables the clean transcript view endpoints.
+func WithTranscriptCleanRepository(repo transcriptCleanRepository) ServerOption {
+	return func(s *Server) {
+		s.transcriptCleanRepo = repo
+	}
+}
+
+// WithLibraryRepositories enables library-wide endpoints such as the archive export.
+func WithLibraryRepositories(videos libraryVideoRepository, transcripts libraryTranscriptRepository) ServerOption {
+	return func(s *Server) {
+		s.libraryVideos = videos
+		s.libraryTranscripts = transcripts
+	}
+}
+
 // Server represents the HTTP API server
 type Server struct {
 	db               db.DB
@@ -63,10 +96,28 @@ type Server struct {
 	aiService        aiService
 	aiSummaryRepo    aiSummaryRepository
 	aiExtractionRepo aiExtractionRepository
+
+	transcriptCleanRepo transcriptCleanRepository
+	libraryVideos       libraryVideoRepository
+	libraryTranscripts  libraryTranscriptRepository
+
+	usageRepo usageRepository
+	apiKeys   apiKeyRepository
+	users     userRepository
+	library   libraryRepository
+	oidc      oidcProvider
+
+	rateLimits rateLimitStore
+
+	schema    schemaVersionReader
+	readiness *readinessState
+	startedAt time.Time
+
+	generations *generationGroup
 }
 
 // NewServer creates a new API server with the given configuration and database connection
-func NewServer(cfg *config.Config, database db.DB, ytSvc youtubeService, videoRepo videoRepository, transcriptRepo transcriptRepository, aiSvc aiService, summaryRepo aiSummaryRepository, extractionRepo aiExtractionRepository) (*Server, error) {
+func NewServer(cfg *config.Config, database db.DB, ytSvc youtubeService, videoRepo videoRepository, transcriptRepo transcriptRepository, aiSvc aiService, summaryRepo aiSummaryRepository, extractionRepo aiExtractionRepository, opts ...ServerOption) (*Server, error) {
 	if cfg == nil {
 		return nil, errors.New("config cannot be nil")
 	}
@@ -102,6 +153,14 @@ func NewServer(cfg *config.Config, database db.DB, ytSvc youtubeService, videoRe
 		aiService:        aiSvc,
 		aiSummaryRepo:    summaryRepo,
 		aiExtractionRepo: extractionRepo,
+		generations:      &generationGroup{},
+		rateLimits:       newMemoryRateLimitStore(),
+		readiness:        newReadinessState(),
+		startedAt:        time.Now(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
 
 	// Setup routes and middleware
@@ -123,10 +182,12 @@ func NewServer(cfg *config.Config, database db.DB, ytSvc youtubeService, videoRe
 func (s *Server) setupRoutes() {
 	// Core middleware stack
 	s.router.Use(middleware.RequestID)
-	s.router.Use(middleware.RealIP)
-	s.router.Use(middleware.Logger)
+	s.router.Use(s.realIP)
+	s.router.Use(traceRequests)
+	s.router.Use(logRequests)
 	s.router.Use(middleware.Recoverer)
 	s.router.Use(requestTimer)
+	s.router.Use(requestMetrics)
 
 	// CORS configuration
 	allowedOrigins := s.config.CORSAllowedOrigins
@@ -137,15 +198,17 @@ func (s *Server) setupRoutes() {
 	s.router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key", "X-CSRF-Token"},
+		ExposedHeaders:   []string{"Link", traceIDHeader, "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
 	// Health and metrics routes
 	s.router.Get("/health", s.handleHealth)
-	s.router.Get("/metrics", s.handleMetrics)
+	s.router.Get("/livez", s.handleLive)
+	s.router.Get("/readyz", s.handleReady)
+	s.router.Method(http.MethodGet, "/metrics", s.prometheusHandler())
 
 	// API routes
 	s.router.Route("/api", func(r chi.Router) {
@@ -154,18 +217,69 @@ func (s *Server) setupRoutes() {
 
 		r.Route("/v1", func(r chi.Router) {
 			r.Get("/health", s.handleHealth)
-			r.Get("/transcripts/{id}", s.handleGetTranscript)
-			r.Post("/transcripts/fetch", s.handleFetchTranscript)
-			r.Route("/transcripts/{id}/summarize", func(r chi.Router) {
-				r.Post("/", s.handleSummarizeTranscript)
+
+			defaultLimit := s.rateLimit(rateLimitDefault, s.config.RateLimitPerMinute)
+
+			r.Group(func(r chi.Router) {
+				r.Use(s.requireScope(scopeTranscriptsRead))
+				r.Use(defaultLimit)
+				r.Use(s.requireTranscriptAccess)
+				r.Get("/transcripts/{id}", s.handleGetTranscript)
+				r.Get("/transcripts/{id}/export", s.handleExportTranscript)
+				r.Get("/transcripts/{id}/clean", s.handleGetCleanTranscript)
+				r.Get("/export/archive", s.handleExportArchive)
+			})
+			r.With(s.requireScope(scopeTranscriptsWrite), s.rateLimit(rateLimitFetch, s.config.RateLimitFetchPerMinute)).
+				Post("/transcripts/fetch", s.handleFetchTranscript)
+
+			r.Group(func(r chi.Router) {
+				r.Use(s.requireScope(scopeAIWrite))
+				r.Use(s.rateLimit(rateLimitAI, s.config.RateLimitAIPerMinute))
+				r.Use(s.requireTranscriptAccess)
+				r.Route("/transcripts/{id}/summarize", func(r chi.Router) {
+					r.Post("/", s.handleSummarizeTranscript)
+				})
+				r.Route("/transcripts/{id}/extract", func(r chi.Router) {
+					r.Post("/", s.handleExtractFromTranscript)
+				})
+				r.Route("/transcripts/{id}/qa", func(r chi.Router) {
+					r.Post("/", s.handleTranscriptQA)
+				})
+				r.Post("/transcripts/{id}/clean", s.handleCleanTranscript)
 			})
-			r.Route("/transcripts/{id}/extract", func(r chi.Router) {
-				r.Post("/", s.handleExtractFromTranscript)
+			// Speaker labels are stored on the transcript every user shares, so of signed-in users
+			// only admins set them.
+			r.With(s.requireScope(scopeAIWrite), requireSessionScope(scopeAdmin), s.rateLimit(rateLimitAI, s.config.RateLimitAIPerMinute), s.requireTranscriptAccess).
+				Post("/transcripts/{id}/speakers", s.handleLabelSpeakers)
+
+			r.With(s.requireScope(scopeUsageRead), defaultLimit).Get("/usage", s.handleGetUsage)
+
+			r.Route("/auth", func(r chi.Router) {
+				// Sign-in routes have their own, stricter limit per IP to slow down password guessing.
+				authLimit := s.rateLimit(rateLimitAuth, s.config.RateLimitAuthPerMinute)
+				r.With(authLimit).Post("/register", s.handleRegister)
+				r.With(authLimit).Post("/login", s.handleLogin)
+				r.With(s.requireSession, defaultLimit).Post("/logout", s.handleLogout)
+				r.With(s.requireSession, defaultLimit).Get("/me", s.handleGetCurrentUser)
+				r.With(defaultLimit).Get("/oidc/login", s.handleOIDCLogin)
+				r.With(defaultLimit).Get("/oidc/callback", s.handleOIDCCallback)
 			})
-			r.Route("/transcripts/{id}/qa", func(r chi.Router) {
-				r.Post("/", s.handleTranscriptQA)
+
+			r.Route("/library", func(r chi.Router) {
+				r.Use(s.requireSession)
+				r.Use(defaultLimit)
+				r.Get("/", s.handleListLibrary)
+				r.Delete("/{id}", s.handleRemoveFromLibrary)
+			})
+
+			r.Route("/admin/keys", func(r chi.Router) {
+				r.Use(s.requireScope(scopeAdmin))
+				r.Use(defaultLimit)
+				r.Get("/", s.handleListAPIKeys)
+				r.Post("/", s.handleCreateAPIKey)
+				r.Delete("/{keyID}", s.handleRevokeAPIKey)
+				r.Post("/{keyID}/rotate", s.handleRotateAPIKey)
 			})
-			r.Get("/transcripts/{id}/export", s.handleExportTranscript)
 		})
 	})
 }
@@ -182,7 +296,7 @@ func (s *Server) Start(ctx context.Context) error {
 
 	// Start server in goroutine
 	go func() {
-		fmt.Printf("🚀 Server starting on http://localhost:%d\n", s.config.APIPort)
+		slog.Info("server starting", "addr", s.srv.Addr)
 		if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errChan <- err
 		}
@@ -191,7 +305,7 @@ func (s *Server) Start(ctx context.Context) error {
 	// Wait for context cancellation or server error
 	select {
 	case <-ctx.Done():
-		fmt.Println("\n🛑 Shutting down server...")
+		slog.Info("shutting down server")
 		// Create shutdown context with timeout
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
@@ -200,7 +314,7 @@ func (s *Server) Start(ctx context.Context) error {
 		if err := s.srv.Shutdown(shutdownCtx); err != nil {
 			return fmt.Errorf("server shutdown failed: %w", err)
 		}
-		fmt.Println("✅ Server shutdown complete")
+		slog.Info("server shutdown complete")
 		return nil
 
 	case err := <-errChan:
@@ -214,12 +328,12 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		return errors.New("server not initialized")
 	}
 
-	fmt.Println("🛑 Initiating server shutdown...")
+	slog.Info("shutting down server")
 	if err := s.srv.Shutdown(ctx); err != nil {
 		return fmt.Errorf("server shutdown failed: %w", err)
 	}
 
-	fmt.Println("✅ Server shutdown complete")
+	slog.Info("server shutdown complete")
 	return nil
 }
 
@@ -230,7 +344,7 @@ func requestTimer(next http.Handler) http.Handler {
 		duration := time.Since(start)
 
 		if duration > time.Second {
-			log.Printf("SLOW REQUEST: %s %s took %v", r.Method, r.URL.Path, duration)
+			slog.WarnContext(r.Context(), "slow request", "method", r.Method, "path", r.URL.Path, "duration_ms", duration.Milliseconds())
 		}
 	})
 }
@@ -61,11 +61,11 @@ func (m *mockDB) Stat() *pgxpool.Stat {
 
 type noopYouTubeService struct{}
 
-func (noopYouTubeService) GetVideoMetadata(string) (*services.VideoMetadata, error) {
+func (noopYouTubeService) GetVideoMetadata(context.Context, string) (*services.VideoMetadata, error) {
 	return &services.VideoMetadata{}, nil
 }
 
-func (noopYouTubeService) GetTranscript(string, string) ([]services.TranscriptLine, error) {
+func (noopYouTubeService) GetTranscript(context.Context, string, string) ([]services.TranscriptLine, error) {
 	return nil, errors.New("not implemented")
 }
 
@@ -109,6 +109,14 @@ func (noopAIService) Answer(context.Context, string, string) (*services.AIAnswer
 	return &services.AIAnswer{}, nil
 }
 
+func (noopAIService) Punctuate(_ context.Context, lines []string) (*services.AIPunctuation, error) {
+	return &services.AIPunctuation{Lines: lines}, nil
+}
+
+func (noopAIService) LabelSpeakers(context.Context, []string, []string) (*services.AISpeakerTurns, error) {
+	return &services.AISpeakerTurns{Turns: []services.SpeakerTurn{{Line: 1, Speaker: "Speaker 1"}}}, nil
+}
+
 type noopAISummaryRepo struct{}
 
 func (noopAISummaryRepo) CreateAISummary(context.Context, *db.AISummary) error {
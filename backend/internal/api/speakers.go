@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+const speakersTimeout = 90 * time.Second
+
+type speakerLabelResponse struct {
+	TranscriptID  string           `json:"transcript_id"`
+	Speakers      []string         `json:"speakers"`
+	Model         string           `json:"model"`
+	TokensUsed    int              `json:"tokens_used"`
+	PromptVersion string           `json:"prompt_version,omitempty"`
+	Transcript    []TranscriptLine `json:"transcript"`
+}
+
+// handleLabelSpeakers handles POST /api/v1/transcripts/{id}/speakers by labeling every segment
+// with a speaker and storing the labels on the transcript. Transcripts are shared by every user
+// who added them, so the route needs the admin scope.
+func (s *Server) handleLabelSpeakers(w http.ResponseWriter, r *http.Request) {
+	transcriptID := strings.TrimSpace(chi.URLParam(r, "id"))
+	if transcriptID == "" {
+		writeStructuredError(w, http.StatusBadRequest, nil, "transcript id is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), speakersTimeout)
+	defer cancel()
+
+	transcript, err := s.transcriptRepo.GetTranscriptByID(ctx, transcriptID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeStructuredError(w, http.StatusNotFound, err, "Transcript not found")
+			return
+		}
+		if isDatabaseUnavailableError(err) {
+			writeStructuredError(w, http.StatusServiceUnavailable, err, "Database unavailable. Please try again later.")
+			return
+		}
+		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to load transcript")
+		return
+	}
+
+	// Only segments with text are sent to the provider; empty ones keep no speaker.
+	indexes := make([]int, 0, len(transcript.Content))
+	lines := make([]string, 0, len(transcript.Content))
+	for i, segment := range transcript.Content {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+		indexes = append(indexes, i)
+		lines = append(lines, text)
+	}
+	if len(lines) == 0 {
+		writeStructuredError(w, http.StatusNotFound, nil, "Transcript is empty or unavailable")
+		return
+	}
+
+	labels, usage, err := services.AssignSpeakers(services.WithTranscriptID(ctx, transcriptID), s.aiService, lines)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "AI speaker labeling failed",
+			"transcript_id", transcriptID, "error", err)
+		writeAIError(w, err, "label speakers", "Please try again later.")
+		return
+	}
+
+	segments := make(db.TranscriptSegments, len(transcript.Content))
+	copy(segments, transcript.Content)
+	for i := range segments {
+		segments[i].Speaker = ""
+	}
+	for i, index := range indexes {
+		segments[index].Speaker = labels[i]
+	}
+	transcript.Content = segments
+
+	if err := s.transcriptRepo.SaveTranscript(ctx, transcript); err != nil {
+		if isDatabaseUnavailableError(err) {
+			writeStructuredError(w, http.StatusServiceUnavailable, err, "Database unavailable. Please try again later.")
+			return
+		}
+		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to store speaker labels")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, speakerLabelResponse{
+		TranscriptID:  transcript.ID,
+		Speakers:      distinctSpeakers(transcript.Content),
+		Model:         usage.Model,
+		TokensUsed:    usage.TokensUsed,
+		PromptVersion: usage.PromptVersion,
+		Transcript:    convertSegmentsToLines(transcript.Content),
+	})
+}
+
+// distinctSpeakers returns the speaker labels in order of first appearance.
+func distinctSpeakers(segments db.TranscriptSegments) []string {
+	speakers := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, segment := range segments {
+		if segment.Speaker == "" || seen[segment.Speaker] {
+			continue
+		}
+		seen[segment.Speaker] = true
+		speakers = append(speakers, segment.Speaker)
+	}
+	return speakers
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+type stubSpeakerAIService struct {
+	noopAIService
+	turns []services.SpeakerTurn
+	lines []string
+}
+
+func (s *stubSpeakerAIService) LabelSpeakers(_ context.Context, lines []string, _ []string) (*services.AISpeakerTurns, error) {
+	s.lines = lines
+	return &services.AISpeakerTurns{Turns: s.turns, Model: "stub-model", TokensUsed: 33}, nil
+}
+
+func newSpeakerTestServer(t *testing.T, ai aiService, cfg *config.Config, opts ...ServerOption) (*Server, *recordingTranscriptRepo) {
+	t.Helper()
+
+	videoRepo := &recordingVideoRepo{}
+	videoRepo.saved = append(videoRepo.saved, &db.Video{ID: "video-uuid", YouTubeID: "dQw4w9WgXcQ", Title: "Interview"})
+	transcriptRepo := &recordingTranscriptRepo{}
+	transcriptRepo.saved = append(transcriptRepo.saved, &db.Transcript{
+		ID:       "transcript-uuid",
+		VideoID:  "video-uuid",
+		Language: "en",
+		Content: db.TranscriptSegments{
+			{StartMs: 0, DurationMs: 2000, Text: "Welcome back, today my guest is Jane."},
+			{StartMs: 2000, DurationMs: 500, Text: " "},
+			{StartMs: 2500, DurationMs: 1500, Text: "Thanks for having me."},
+			{StartMs: 4000, DurationMs: 1000, Text: "Let's start."},
+		},
+	})
+
+	cfg.APIPort = 8080
+	server, err := NewServer(cfg, &mockDB{}, noopYouTubeService{}, videoRepo, transcriptRepo, ai, noopAISummaryRepo{}, noopAIExtractionRepo{}, opts...)
+	require.NoError(t, err)
+	return server, transcriptRepo
+}
+
+func TestHandleLabelSpeakers_StoresLabelsOnSegments(t *testing.T) {
+	ai := &stubSpeakerAIService{turns: []services.SpeakerTurn{
+		{Line: 1, Speaker: "Host"},
+		{Line: 2, Speaker: "Jane"},
+		{Line: 3, Speaker: "Host"},
+	}}
+	server, transcriptRepo := newSpeakerTestServer(t, ai, &config.Config{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/transcript-uuid/speakers", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Len(t, ai.lines, 3, "empty segments are not sent to the provider")
+
+	var resp speakerLabelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, []string{"Host", "Jane"}, resp.Speakers)
+	assert.Equal(t, 33, resp.TokensUsed)
+	require.Len(t, resp.Transcript, 4)
+	assert.Equal(t, "Host", resp.Transcript[0].Speaker)
+	assert.Empty(t, resp.Transcript[1].Speaker)
+	assert.Equal(t, "Jane", resp.Transcript[2].Speaker)
+
+	stored, err := transcriptRepo.GetTranscriptByID(context.Background(), "transcript-uuid")
+	require.NoError(t, err)
+	assert.Equal(t, "Host", stored.Content[3].Speaker)
+	assert.Equal(t,
+		"Host: Welcome back, today my guest is Jane.\nJane: Thanks for having me.\nHost: Let's start.",
+		buildTranscriptText(stored.Content))
+}
+
+func TestHandleLabelSpeakers_SignedInUsersNeedAdmin(t *testing.T) {
+	ai := &stubSpeakerAIService{turns: []services.SpeakerTurn{{Line: 1, Speaker: "Host"}}}
+	server, transcriptRepo := newSpeakerTestServer(t, ai, &config.Config{AuthAdminKey: testAdminKey, AuthSignupEnabled: true},
+		WithAPIKeyRepository(&stubAPIKeyRepo{}), WithUserRepositories(&stubUserRepo{}, &stubLibraryRepo{}))
+	path := "/api/v1/transcripts/transcript-uuid/speakers"
+
+	editor := registerTestUser(t, server, "ada@example.com")
+	rec := serveWithKey(server, http.MethodPost, path, editor.Token, "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), scopeAdmin)
+	assert.Nil(t, ai.lines, "the provider is not called")
+	stored, err := transcriptRepo.GetTranscriptByID(context.Background(), "transcript-uuid")
+	require.NoError(t, err)
+	assert.Empty(t, stored.Content[0].Speaker, "labels other users see are unchanged")
+
+	key := createTestKey(t, server, scopeAIWrite, scopeTranscriptsAll)
+	rec = serveWithKey(server, http.MethodPost, path, key.Key, "")
+	assert.Equal(t, http.StatusOK, rec.Code, "API keys only need ai:write")
+}
+
+func TestHandleLabelSpeakers_InvalidTurns(t *testing.T) {
+	ai := &stubSpeakerAIService{turns: []services.SpeakerTurn{{Line: 9, Speaker: "Host"}}}
+	server, _ := newSpeakerTestServer(t, ai, &config.Config{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/transcript-uuid/speakers", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	var errResp ErrorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&errResp))
+	assert.Contains(t, errResp.Error, "AI response format error")
+}
+
+func TestBuildTranscriptText_WithoutSpeakers(t *testing.T) {
+	text := buildTranscriptText(db.TranscriptSegments{{Text: "Hello"}, {Text: "world"}})
+	assert.Equal(t, "Hello world", text)
+}
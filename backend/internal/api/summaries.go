@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -16,12 +16,16 @@ import (
 	"github.com/yourusername/yt-transcript-downloader/internal/services"
 )
 
-const summarizeTimeout = 60 * time.Second
+const (
+	summarizeTimeout    = 60 * time.Second
+	summaryTypeChapters = "chapters"
+)
 
 var allowedSummaryTypes = map[string]struct{}{
-	"brief":      {},
-	"detailed":   {},
-	"key_points": {},
+	"brief":             {},
+	"detailed":          {},
+	"key_points":        {},
+	summaryTypeChapters: {},
 }
 
 type summarizeRequest struct {
@@ -29,19 +33,21 @@ type summarizeRequest struct {
 }
 
 type summaryResponse struct {
-	ID           string                 `json:"id"`
-	TranscriptID string                 `json:"transcript_id"`
-	SummaryType  string                 `json:"summary_type"`
-	Content      summaryContentResponse `json:"content"`
-	Model        string                 `json:"model"`
-	TokensUsed   int                    `json:"tokens_used"`
-	CreatedAt    time.Time              `json:"created_at"`
+	ID            string                 `json:"id"`
+	TranscriptID  string                 `json:"transcript_id"`
+	SummaryType   string                 `json:"summary_type"`
+	Content       summaryContentResponse `json:"content"`
+	Model         string                 `json:"model"`
+	TokensUsed    int                    `json:"tokens_used"`
+	PromptVersion string                 `json:"prompt_version,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
 }
 
 type summaryContentResponse struct {
 	Text      string                  `json:"text"`
 	KeyPoints []string                `json:"key_points,omitempty"`
 	Sections  []summarySectionMessage `json:"sections,omitempty"`
+	Chapters  []summaryChapterMessage `json:"chapters,omitempty"`
 }
 
 type summarySectionMessage struct {
@@ -49,6 +55,12 @@ type summarySectionMessage struct {
 	Content string `json:"content"`
 }
 
+type summaryChapterMessage struct {
+	Title   string `json:"title"`
+	StartMs int64  `json:"start_ms"`
+	EndMs   int64  `json:"end_ms"`
+}
+
 func (s *Server) handleSummarizeTranscript(w http.ResponseWriter, r *http.Request) {
 	transcriptID := chi.URLParam(r, "id")
 	if strings.TrimSpace(transcriptID) == "" {
@@ -72,49 +84,81 @@ func (s *Server) handleSummarizeTranscript(w http.ResponseWriter, r *http.Reques
 	defer cancel()
 
 	if cached, err := s.aiSummaryRepo.GetAISummary(ctx, transcriptID, summaryType); err == nil {
+		observeCacheLookup("summary", true)
 		writeJSON(w, http.StatusOK, buildSummaryResponse(cached))
 		return
 	} else if !errors.Is(err, db.ErrNotFound) {
 		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to lookup cached summary")
 		return
 	}
+	observeCacheLookup("summary", false)
+
+	key := "summary:" + transcriptID + ":" + summaryType
+	summary, shared, err := coalesce(ctx, s.generations, key, summarizeTimeout, func(ctx context.Context) (*db.AISummary, error) {
+		return s.generateSummary(ctx, transcriptID, summaryType)
+	})
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			writeStructuredError(w, apiErr.status, apiErr.err, apiErr.message)
+			return
+		}
+		slog.ErrorContext(r.Context(), "AI summarize failed",
+			"type", summaryType, "transcript_id", transcriptID, "error", err)
+		writeAIError(w, err, "generate AI summary", "Try with a shorter transcript or try again later.")
+		return
+	}
+	if shared {
+		slog.InfoContext(r.Context(), "shared in-flight summary generation",
+			"type", summaryType, "transcript_id", transcriptID)
+	}
+
+	writeJSON(w, http.StatusOK, buildSummaryResponse(summary))
+}
+
+// generateSummary calls the AI provider and stores the result. The cache is checked again because
+// a generation on another instance may have finished while this one waited for the lock. Lookup
+// and storage failures are returned as *apiError; provider errors are returned as is.
+func (s *Server) generateSummary(ctx context.Context, transcriptID, summaryType string) (*db.AISummary, error) {
+	if cached, err := s.aiSummaryRepo.GetAISummary(ctx, transcriptID, summaryType); err == nil {
+		return cached, nil
+	} else if !errors.Is(err, db.ErrNotFound) {
+		return nil, &apiError{status: http.StatusInternalServerError, err: err, message: "Failed to lookup cached summary"}
+	}
 
 	transcript, err := s.transcriptRepo.GetTranscriptByID(ctx, transcriptID)
 	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
-			writeStructuredError(w, http.StatusNotFound, err, "Transcript not found")
-			return
+			return nil, &apiError{status: http.StatusNotFound, err: err, message: "Transcript not found"}
 		}
 		if isDatabaseUnavailableError(err) {
-			writeStructuredError(w, http.StatusServiceUnavailable, err, "Database unavailable. Please try again later.")
-			return
+			return nil, &apiError{status: http.StatusServiceUnavailable, err: err, message: "Database unavailable. Please try again later."}
 		}
-		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to load transcript")
-		return
+		return nil, &apiError{status: http.StatusInternalServerError, err: err, message: "Failed to load transcript"}
 	}
 
 	transcriptText := buildTranscriptText(transcript.Content)
 	if transcriptText == "" {
-		writeStructuredError(w, http.StatusNotFound, nil, "Transcript is empty or unavailable")
-		return
+		return nil, &apiError{status: http.StatusNotFound, message: "Transcript is empty or unavailable"}
+	}
+	if summaryType == summaryTypeChapters {
+		transcriptText = buildTimestampedTranscriptText(transcript.Content)
 	}
 
-	aiSummary, err := s.aiService.Summarize(ctx, transcriptText, summaryType)
+	aiSummary, err := s.aiService.Summarize(services.WithTranscriptID(ctx, transcriptID), transcriptText, summaryType)
+	if err == nil && summaryType == summaryTypeChapters {
+		err = s.anchorSummaryChapters(ctx, transcript, aiSummary)
+	}
 	if err != nil {
-		log.Printf("ERROR [%s %s] AI summarize failed (type=%s, transcript=%s): %v",
-			r.Method, r.URL.Path, summaryType, transcriptID, err)
-		handleAISummarizeError(w, err)
-		return
+		return nil, err
 	}
 
 	dbSummary := convertToDatabaseSummary(transcriptID, summaryType, aiSummary)
-
+	dbSummary.CreatedBy = userIDFromContext(ctx)
 	if err := s.aiSummaryRepo.CreateAISummary(ctx, dbSummary); err != nil {
-		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to store AI summary")
-		return
+		return nil, &apiError{status: http.StatusInternalServerError, err: err, message: "Failed to store AI summary"}
 	}
-
-	writeJSON(w, http.StatusOK, buildSummaryResponse(dbSummary))
+	return dbSummary, nil
 }
 
 func normalizeSummaryType(raw string) string {
@@ -126,43 +170,78 @@ func isSummaryTypeAllowed(summaryType string) bool {
 	return ok
 }
 
+// buildTranscriptText joins segment texts into a single prompt. When the transcript has speaker
+// labels, every speaker turn starts on its own line prefixed with "Speaker: " so providers can
+// attribute quotes and answers.
 func buildTranscriptText(segments db.TranscriptSegments) string {
 	var builder strings.Builder
+	currentSpeaker := ""
 	for _, segment := range segments {
-		if segment.Text == "" {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
 			continue
 		}
-		if builder.Len() > 0 {
+		if segment.Speaker != "" && segment.Speaker != currentSpeaker {
+			if builder.Len() > 0 {
+				builder.WriteString("\n")
+			}
+			builder.WriteString(segment.Speaker)
+			builder.WriteString(": ")
+			currentSpeaker = segment.Speaker
+		} else if builder.Len() > 0 {
 			builder.WriteString(" ")
 		}
-		builder.WriteString(strings.TrimSpace(segment.Text))
+		builder.WriteString(text)
 	}
 	return builder.String()
 }
 
-func handleAISummarizeError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, services.ErrAIRateLimited):
-		writeStructuredError(w, http.StatusTooManyRequests, err, "AI rate limit reached. Please wait a moment and try again.")
-	case errors.Is(err, services.ErrAIQuotaExceeded):
-		writeStructuredError(w, http.StatusPaymentRequired, err, "AI quota exceeded. Please contact the administrator.")
-	case errors.Is(err, services.ErrAIServiceUnavailable):
-		writeStructuredError(w, http.StatusServiceUnavailable, err, "AI service is temporarily unavailable. Please try again in a few moments.")
-	case errors.Is(err, services.ErrAIProviderNotConfigured):
-		writeStructuredError(w, http.StatusServiceUnavailable, err, "AI service is not configured. Please contact the administrator.")
-	case errors.Is(err, context.DeadlineExceeded):
-		writeStructuredError(w, http.StatusGatewayTimeout, err, "AI request timed out (>60s). Try with a shorter transcript or try again later.")
-	case errors.Is(err, context.Canceled):
-		writeStructuredError(w, http.StatusRequestTimeout, err, "Request was canceled.")
-	default:
-		// Check if it's a JSON parsing error
-		if strings.Contains(err.Error(), "parse") || strings.Contains(err.Error(), "unmarshal") || strings.Contains(err.Error(), "json") {
-			writeStructuredError(w, http.StatusInternalServerError, err, "AI response format error. The AI returned an invalid response. Please try again.")
-		} else {
-			// Generic error with hint about the actual error
-			writeStructuredError(w, http.StatusInternalServerError, err, fmt.Sprintf("Failed to generate AI summary: %v", err))
+// buildTimestampedTranscriptText prefixes every segment with its start offset in milliseconds
+// so providers can anchor chapters to real segment boundaries.
+func buildTimestampedTranscriptText(segments db.TranscriptSegments) string {
+	var builder strings.Builder
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+		if segment.Speaker != "" {
+			fmt.Fprintf(&builder, "[%d] %s: %s\n", segment.StartMs, segment.Speaker, text)
+			continue
+		}
+		fmt.Fprintf(&builder, "[%d] %s\n", segment.StartMs, text)
+	}
+	return strings.TrimSpace(builder.String())
+}
+
+// anchorSummaryChapters replaces the provider chapters with ones snapped to the transcript's
+// segment starts and bounded by the video duration.
+func (s *Server) anchorSummaryChapters(ctx context.Context, transcript *db.Transcript, summary *services.AISummary) error {
+	boundaries := make([]int64, 0, len(transcript.Content))
+	var lastEndMs int64
+	for _, segment := range transcript.Content {
+		if strings.TrimSpace(segment.Text) == "" {
+			continue
 		}
+		boundaries = append(boundaries, segment.StartMs)
+		if end := segment.StartMs + segment.DurationMs; end > lastEndMs {
+			lastEndMs = end
+		}
+	}
+
+	duration := time.Duration(lastEndMs) * time.Millisecond
+	if video, err := s.videoRepository.GetVideoByID(ctx, transcript.VideoID); err == nil && video.Duration > 0 {
+		duration = time.Duration(video.Duration) * time.Second
+	} else if err != nil {
+		slog.WarnContext(ctx, "chapters: video lookup failed, using transcript length", "transcript_id", transcript.ID, "error", err)
+	}
+
+	chapters, err := services.AnchorChapters(summary.Content.Chapters, boundaries, duration)
+	if err != nil {
+		return err
 	}
+	summary.Content.Chapters = chapters
+	return nil
 }
 
 func convertToDatabaseSummary(transcriptID, summaryType string, summary *services.AISummary) *db.AISummary {
@@ -174,6 +253,15 @@ func convertToDatabaseSummary(transcriptID, summaryType string, summary *service
 		})
 	}
 
+	var chapters []db.Chapter
+	for _, chapter := range summary.Content.Chapters {
+		chapters = append(chapters, db.Chapter{
+			Title:   chapter.Title,
+			StartMs: chapter.StartMs,
+			EndMs:   chapter.EndMs,
+		})
+	}
+
 	return &db.AISummary{
 		TranscriptID: transcriptID,
 		SummaryType:  summaryType,
@@ -181,9 +269,11 @@ func convertToDatabaseSummary(transcriptID, summaryType string, summary *service
 			Text:      summary.Content.Text,
 			KeyPoints: summary.Content.KeyPoints,
 			Sections:  sections,
+			Chapters:  chapters,
 		},
-		Model:      summary.Model,
-		TokensUsed: summary.TokensUsed,
+		Model:         summary.Model,
+		TokensUsed:    summary.TokensUsed,
+		PromptVersion: summary.PromptVersion,
 	}
 }
 
@@ -199,6 +289,15 @@ func buildSummaryResponse(summary *db.AISummary) summaryResponse {
 		})
 	}
 
+	chapters := make([]summaryChapterMessage, 0, len(summary.Content.Chapters))
+	for _, chapter := range summary.Content.Chapters {
+		chapters = append(chapters, summaryChapterMessage{
+			Title:   chapter.Title,
+			StartMs: chapter.StartMs,
+			EndMs:   chapter.EndMs,
+		})
+	}
+
 	return summaryResponse{
 		ID:           summary.ID,
 		TranscriptID: summary.TranscriptID,
@@ -207,9 +306,11 @@ func buildSummaryResponse(summary *db.AISummary) summaryResponse {
 			Text:      summary.Content.Text,
 			KeyPoints: summary.Content.KeyPoints,
 			Sections:  sections,
+			Chapters:  chapters,
 		},
-		Model:      summary.Model,
-		TokensUsed: summary.TokensUsed,
-		CreatedAt:  summary.CreatedAt,
+		Model:         summary.Model,
+		TokensUsed:    summary.TokensUsed,
+		PromptVersion: summary.PromptVersion,
+		CreatedAt:     summary.CreatedAt,
 	}
 }
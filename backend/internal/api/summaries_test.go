@@ -10,10 +10,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/metrics"
 	"github.com/yourusername/yt-transcript-downloader/internal/services"
 )
 
@@ -41,6 +43,14 @@ func (s *stubAIService) Answer(ctx context.Context, text string, question string
 	return &services.AIAnswer{}, nil
 }
 
+func (s *stubAIService) Punctuate(ctx context.Context, lines []string) (*services.AIPunctuation, error) {
+	return &services.AIPunctuation{Lines: lines}, nil
+}
+
+func (s *stubAIService) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*services.AISpeakerTurns, error) {
+	return &services.AISpeakerTurns{Turns: []services.SpeakerTurn{{Line: 1, Speaker: "Speaker 1"}}}, nil
+}
+
 type inMemoryAISummaryRepo struct {
 	store map[string]*db.AISummary
 }
@@ -177,6 +187,9 @@ func TestHandleSummarizeTranscript_Cached(t *testing.T) {
 	server, err := NewServer(cfg, database, noopYouTubeService{}, noopVideoRepo{}, transcriptRepo, aiSvc, summaryRepo, newInMemoryAIExtractionRepo())
 	require.NoError(t, err)
 
+	hits := metrics.AICacheLookups.WithLabelValues("summary", "hit")
+	hitsBefore := testutil.ToFloat64(hits)
+
 	body := []byte(`{"summary_type":"brief"}`)
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/"+transcriptID+"/summarize", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
@@ -186,6 +199,7 @@ func TestHandleSummarizeTranscript_Cached(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 	assert.Equal(t, 0, aiSvc.calls)
+	assert.Equal(t, hitsBefore+1, testutil.ToFloat64(hits))
 
 	var resp summaryResponse
 	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
@@ -222,3 +236,81 @@ func TestHandleSummarizeTranscript_TranscriptNotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, rec.Code)
 }
+
+func TestHandleSummarizeTranscript_Chapters(t *testing.T) {
+	transcriptRepo := newInMemoryTranscriptRepo()
+	summaryRepo := newInMemoryAISummaryRepo()
+	aiSvc := &stubAIService{
+		summary: &services.AISummary{
+			Content: services.SummaryContent{
+				Text: "A tour of the release process.",
+				Chapters: []services.SummaryChapter{
+					{Title: "Intro", StartMs: 500},
+					{Title: "Release checklist", StartMs: 61000},
+				},
+			},
+			Model: "gpt-4",
+		},
+	}
+
+	transcriptID := "transcript-chapters"
+	transcriptRepo.transcripts[transcriptID] = &db.Transcript{
+		ID:      transcriptID,
+		VideoID: "video-123",
+		Content: db.TranscriptSegments{
+			{StartMs: 0, DurationMs: 30000, Text: "Welcome back"},
+			{StartMs: 30000, DurationMs: 30000, Text: "Today we ship"},
+			{StartMs: 60000, DurationMs: 30000, Text: "First the checklist"},
+		},
+	}
+
+	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, transcriptRepo, aiSvc, summaryRepo, newInMemoryAIExtractionRepo())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/"+transcriptID+"/summarize", bytes.NewReader([]byte(`{"summary_type":"chapters"}`)))
+	rec := httptest.NewRecorder()
+
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp summaryResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Content.Chapters, 2)
+	assert.Equal(t, summaryChapterMessage{Title: "Intro", StartMs: 0, EndMs: 60000}, resp.Content.Chapters[0])
+	// noopVideoRepo reports a 300 second video.
+	assert.Equal(t, summaryChapterMessage{Title: "Release checklist", StartMs: 60000, EndMs: 300000}, resp.Content.Chapters[1])
+}
+
+func TestHandleSummarizeTranscript_ChaptersOutsideVideo(t *testing.T) {
+	transcriptRepo := newInMemoryTranscriptRepo()
+	aiSvc := &stubAIService{
+		summary: &services.AISummary{
+			Content: services.SummaryContent{
+				Chapters: []services.SummaryChapter{
+					{Title: "Intro", StartMs: 0},
+					{Title: "Hallucinated", StartMs: 900000},
+				},
+			},
+		},
+	}
+
+	transcriptID := "transcript-bad-chapters"
+	transcriptRepo.transcripts[transcriptID] = &db.Transcript{
+		ID:      transcriptID,
+		VideoID: "video-123",
+		Content: db.TranscriptSegments{{StartMs: 0, DurationMs: 1000, Text: "Hello"}},
+	}
+
+	summaryRepo := newInMemoryAISummaryRepo()
+	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, transcriptRepo, aiSvc, summaryRepo, newInMemoryAIExtractionRepo())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/"+transcriptID+"/summarize", bytes.NewReader([]byte(`{"summary_type":"chapters"}`)))
+	rec := httptest.NewRecorder()
+
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Empty(t, summaryRepo.store)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/tracing/tracingtest"
+)
+
+func TestTraceRequests_ContinuesTraceAndReportsTraceID(t *testing.T) {
+	tracingtest.Install()
+	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, &recordingTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/missing", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, traceID, rec.Header().Get(traceIDHeader))
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, traceID, response.TraceID, "error bodies carry the trace ID")
+
+	id, err := trace.TraceIDFromHex(traceID)
+	require.NoError(t, err)
+	spans := tracingtest.Spans(id)
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /api/v1/transcripts/{id}", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Contains(t, span.Attributes, semconv.HTTPRoute("/api/v1/transcripts/{id}"))
+	assert.Contains(t, span.Attributes, semconv.HTTPResponseStatusCode(http.StatusNotFound))
+}
+
+func TestTraceRequests_StartsTraceWithoutParent(t *testing.T) {
+	tracingtest.Install()
+	server, err := NewServer(mockConfig(), &mockDB{}, noopYouTubeService{}, noopVideoRepo{}, noopTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+
+	rec := serveWithKey(server, http.MethodGet, "/health", "", "")
+	id, err := trace.TraceIDFromHex(rec.Header().Get(traceIDHeader))
+	require.NoError(t, err)
+	require.Len(t, tracingtest.Spans(id), 1)
+	assert.Equal(t, "GET /health", tracingtest.Spans(id)[0].Name)
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -31,7 +31,7 @@ func (s *Server) handleFetchTranscript(w http.ResponseWriter, r *http.Request) {
 
 	videoID, err := ValidateVideoURL(req.VideoURL)
 	if err != nil {
-		log.Printf("ERROR [%s %s] validation failed: %v", r.Method, r.URL.Path, err)
+		slog.WarnContext(r.Context(), "video URL validation failed", "error", err)
 		switch {
 		case errors.Is(err, ErrVideoURLRequired):
 			writeStructuredError(w, http.StatusBadRequest, err, "video_url is required")
@@ -52,7 +52,7 @@ func (s *Server) handleFetchTranscript(w http.ResponseWriter, r *http.Request) {
 
 	lang := strings.TrimSpace(req.Language)
 	if err := ValidateLanguage(lang); err != nil {
-		log.Printf("ERROR [%s %s] language validation failed: %v", r.Method, r.URL.Path, err)
+		slog.WarnContext(r.Context(), "language validation failed", "error", err)
 		writeStructuredError(w, http.StatusBadRequest, err, fmt.Sprintf("Invalid language: %v", err))
 		return
 	}
@@ -64,7 +64,7 @@ func (s *Server) handleFetchTranscript(w http.ResponseWriter, r *http.Request) {
 
 	metadata, err := fetchMetadataWithContext(ctx, s.youtube, videoID)
 	if err != nil {
-		log.Printf("ERROR [%s %s] metadata fetch: %v", r.Method, r.URL.Path, err)
+		slog.ErrorContext(r.Context(), "metadata fetch failed", "error", err)
 		switch {
 		case errors.Is(err, context.DeadlineExceeded):
 			writeStructuredError(w, http.StatusGatewayTimeout, err, "Request timed out while fetching video metadata")
@@ -89,7 +89,7 @@ func (s *Server) handleFetchTranscript(w http.ResponseWriter, r *http.Request) {
 
 	transcriptLines, err := fetchTranscriptWithContext(ctx, s.youtube, metadata.ID, lang)
 	if err != nil {
-		log.Printf("ERROR [%s %s] transcript fetch: %v", r.Method, r.URL.Path, err)
+		slog.ErrorContext(r.Context(), "transcript fetch failed", "error", err)
 		switch {
 		case errors.Is(err, context.DeadlineExceeded):
 			writeStructuredError(w, http.StatusGatewayTimeout, err, "Request timed out while fetching transcript")
@@ -115,10 +115,11 @@ func (s *Server) handleFetchTranscript(w http.ResponseWriter, r *http.Request) {
 		Title:     metadata.Title,
 		Channel:   metadata.Author,
 		Duration:  int(metadata.Duration / time.Second),
+		CreatedBy: userIDFromContext(ctx),
 	}
 
 	if err := s.videoRepository.SaveVideo(ctx, videoModel); err != nil {
-		log.Printf("ERROR [%s %s] save video: %v", r.Method, r.URL.Path, err)
+		slog.ErrorContext(r.Context(), "save video failed", "error", err)
 		if isDatabaseUnavailableError(err) {
 			writeStructuredError(w, http.StatusServiceUnavailable, err, "Database unavailable. Please try again later.")
 			return
@@ -127,15 +128,16 @@ func (s *Server) handleFetchTranscript(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dbSegments, apiLines := convertTranscriptLines(transcriptLines)
+	dbSegments, _ := convertTranscriptLines(transcriptLines)
 	transcriptModel := &db.Transcript{
-		VideoID:  videoModel.ID,
-		Language: lang,
-		Content:  dbSegments,
+		VideoID:   videoModel.ID,
+		Language:  lang,
+		Content:   dbSegments,
+		CreatedBy: userIDFromContext(ctx),
 	}
 
 	if err := s.transcriptRepo.SaveTranscript(ctx, transcriptModel); err != nil {
-		log.Printf("ERROR [%s %s] save transcript: %v", r.Method, r.URL.Path, err)
+		slog.ErrorContext(r.Context(), "save transcript failed", "error", err)
 		if isDatabaseUnavailableError(err) {
 			writeStructuredError(w, http.StatusServiceUnavailable, err, "Database unavailable. Please try again later.")
 			return
@@ -144,12 +146,21 @@ func (s *Server) handleFetchTranscript(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if userID := userIDFromContext(ctx); userID != "" && s.library != nil {
+		if err := s.library.AddToLibrary(ctx, userID, transcriptModel.ID); err != nil {
+			slog.ErrorContext(r.Context(), "add to library failed", "error", err)
+			writeRepositoryError(w, err, "Failed to add transcript to library")
+			return
+		}
+	}
+
+	// The transcript may already have been stored by another user, so answer with the stored copy.
 	resp := TranscriptResponse{
 		TranscriptID: transcriptModel.ID,
 		VideoID:      metadata.ID,
 		Title:        metadata.Title,
-		Language:     lang,
-		Transcript:   apiLines,
+		Language:     transcriptModel.Language,
+		Transcript:   convertSegmentsToLines(transcriptModel.Content),
 	}
 
 	writeJSON(w, http.StatusOK, resp)
@@ -166,7 +177,7 @@ func (s *Server) handleGetTranscript(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	transcript, video, apiErr := s.lookupTranscriptBundle(ctx, r.Method, r.URL.Path, transcriptID)
+	transcript, video, apiErr := s.lookupTranscriptBundle(ctx, transcriptID)
 	if apiErr != nil {
 		writeStructuredError(w, apiErr.status, apiErr.err, apiErr.message)
 		return
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+const cleanTimeout = 90 * time.Second
+
+type cleanTranscriptRequest struct {
+	// Punctuate forces the AI punctuation pass on or off. When omitted the pass runs only
+	// if the transcript looks like unpunctuated auto captions.
+	Punctuate *bool `json:"punctuate,omitempty"`
+}
+
+type cleanTranscriptResponse struct {
+	TranscriptID  string                  `json:"transcript_id"`
+	Punctuated    bool                    `json:"punctuated"`
+	Model         string                  `json:"model,omitempty"`
+	TokensUsed    int                     `json:"tokens_used"`
+	PromptVersion string                  `json:"prompt_version,omitempty"`
+	Paragraphs    []cleanParagraphMessage `json:"paragraphs"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at"`
+}
+
+type cleanParagraphMessage struct {
+	Start     int64                  `json:"start"`
+	End       int64                  `json:"end"`
+	Text      string                 `json:"text"`
+	Sentences []cleanSentenceMessage `json:"sentences"`
+}
+
+type cleanSentenceMessage struct {
+	Start int64  `json:"start"`
+	End   int64  `json:"end"`
+	Text  string `json:"text"`
+}
+
+// handleCleanTranscript handles POST /api/v1/transcripts/{id}/clean by rebuilding the
+// sentence/paragraph view of a transcript and storing it.
+func (s *Server) handleCleanTranscript(w http.ResponseWriter, r *http.Request) {
+	transcriptID := strings.TrimSpace(chi.URLParam(r, "id"))
+	if transcriptID == "" {
+		writeStructuredError(w, http.StatusBadRequest, nil, "transcript id is required")
+		return
+	}
+	if s.transcriptCleanRepo == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "Clean transcripts are not configured on this server")
+		return
+	}
+
+	var req cleanTranscriptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeStructuredError(w, http.StatusBadRequest, err, "Invalid JSON request body")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), cleanTimeout)
+	defer cancel()
+
+	transcript, err := s.transcriptRepo.GetTranscriptByID(ctx, transcriptID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeStructuredError(w, http.StatusNotFound, err, "Transcript not found")
+			return
+		}
+		if isDatabaseUnavailableError(err) {
+			writeStructuredError(w, http.StatusServiceUnavailable, err, "Database unavailable. Please try again later.")
+			return
+		}
+		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to load transcript")
+		return
+	}
+
+	sentences := services.ReconstructSentences(convertSegmentsToServiceLines(transcript.Content))
+	if len(sentences) == 0 {
+		writeStructuredError(w, http.StatusNotFound, nil, "Transcript is empty or unavailable")
+		return
+	}
+
+	punctuate := services.NeedsPunctuation(sentences)
+	if req.Punctuate != nil {
+		punctuate = *req.Punctuate
+	}
+
+	view := &db.TranscriptCleanView{TranscriptID: transcriptID}
+	if punctuate {
+		restored, usage, err := services.RestorePunctuation(services.WithTranscriptID(ctx, transcriptID), s.aiService, sentences)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "AI punctuation failed",
+				"transcript_id", transcriptID, "error", err)
+			writeAIError(w, err, "restore punctuation", "Retry without punctuation or try again later.")
+			return
+		}
+		sentences = restored
+		view.Punctuated = true
+		view.Model = usage.Model
+		view.TokensUsed = usage.TokensUsed
+		view.PromptVersion = usage.PromptVersion
+	}
+	view.Paragraphs = convertCleanParagraphs(services.GroupParagraphs(sentences))
+
+	if err := s.transcriptCleanRepo.SaveTranscriptCleanView(ctx, view); err != nil {
+		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to store clean transcript")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, buildCleanTranscriptResponse(view))
+}
+
+// handleGetCleanTranscript handles GET /api/v1/transcripts/{id}/clean by returning the stored clean view.
+func (s *Server) handleGetCleanTranscript(w http.ResponseWriter, r *http.Request) {
+	transcriptID := strings.TrimSpace(chi.URLParam(r, "id"))
+	if transcriptID == "" {
+		writeStructuredError(w, http.StatusBadRequest, nil, "transcript id is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	view, apiErr := s.lookupCleanView(ctx, transcriptID)
+	if apiErr != nil {
+		writeStructuredError(w, apiErr.status, apiErr.err, apiErr.message)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, buildCleanTranscriptResponse(view))
+}
+
+func (s *Server) lookupCleanView(ctx context.Context, transcriptID string) (*db.TranscriptCleanView, *apiError) {
+	if s.transcriptCleanRepo == nil {
+		return nil, &apiError{
+			status:  http.StatusServiceUnavailable,
+			message: "Clean transcripts are not configured on this server",
+		}
+	}
+
+	view, err := s.transcriptCleanRepo.GetTranscriptCleanView(ctx, transcriptID)
+	if err != nil {
+		if errorsIsNotFound(err) {
+			return nil, &apiError{
+				status:  http.StatusNotFound,
+				err:     err,
+				message: "Clean transcript has not been generated yet",
+			}
+		}
+		logAPILookupError(ctx, "get clean transcript", err)
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			err:     err,
+			message: "Failed to load clean transcript",
+		}
+	}
+
+	return view, nil
+}
+
+func convertSegmentsToServiceLines(segments db.TranscriptSegments) []services.TranscriptLine {
+	lines := make([]services.TranscriptLine, 0, len(segments))
+	for _, segment := range segments {
+		lines = append(lines, services.TranscriptLine{
+			Start:    time.Duration(segment.StartMs) * time.Millisecond,
+			Duration: time.Duration(segment.DurationMs) * time.Millisecond,
+			Text:     segment.Text,
+		})
+	}
+	return lines
+}
+
+func convertCleanParagraphs(paragraphs []services.CleanParagraph) []db.CleanParagraph {
+	result := make([]db.CleanParagraph, 0, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		sentences := make([]db.CleanSentence, 0, len(paragraph.Sentences))
+		for _, sentence := range paragraph.Sentences {
+			sentences = append(sentences, db.CleanSentence{
+				StartMs: sentence.Start.Milliseconds(),
+				EndMs:   sentence.End.Milliseconds(),
+				Text:    sentence.Text,
+			})
+		}
+		result = append(result, db.CleanParagraph{
+			StartMs:   paragraph.Start.Milliseconds(),
+			EndMs:     paragraph.End.Milliseconds(),
+			Sentences: sentences,
+		})
+	}
+	return result
+}
+
+func buildCleanTranscriptResponse(view *db.TranscriptCleanView) cleanTranscriptResponse {
+	paragraphs := make([]cleanParagraphMessage, 0, len(view.Paragraphs))
+	for _, paragraph := range view.Paragraphs {
+		sentences := make([]cleanSentenceMessage, 0, len(paragraph.Sentences))
+		for _, sentence := range paragraph.Sentences {
+			sentences = append(sentences, cleanSentenceMessage{
+				Start: sentence.StartMs,
+				End:   sentence.EndMs,
+				Text:  sentence.Text,
+			})
+		}
+		paragraphs = append(paragraphs, cleanParagraphMessage{
+			Start:     paragraph.StartMs,
+			End:       paragraph.EndMs,
+			Text:      cleanParagraphText(paragraph),
+			Sentences: sentences,
+		})
+	}
+
+	return cleanTranscriptResponse{
+		TranscriptID:  view.TranscriptID,
+		Punctuated:    view.Punctuated,
+		Model:         view.Model,
+		TokensUsed:    view.TokensUsed,
+		PromptVersion: view.PromptVersion,
+		Paragraphs:    paragraphs,
+		CreatedAt:     view.CreatedAt,
+		UpdatedAt:     view.UpdatedAt,
+	}
+}
+
+func cleanParagraphText(paragraph db.CleanParagraph) string {
+	parts := make([]string, 0, len(paragraph.Sentences))
+	for _, sentence := range paragraph.Sentences {
+		parts = append(parts, sentence.Text)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+type stubPunctuationAIService struct {
+	noopAIService
+	calls int
+}
+
+func (s *stubPunctuationAIService) Punctuate(_ context.Context, lines []string) (*services.AIPunctuation, error) {
+	s.calls++
+	restored := make([]string, 0, len(lines))
+	for _, line := range lines {
+		restored = append(restored, strings.ToUpper(line[:1])+line[1:]+".")
+	}
+	return &services.AIPunctuation{Lines: restored, Model: "stub-model", TokensUsed: 42}, nil
+}
+
+type inMemoryTranscriptCleanRepo struct {
+	store map[string]*db.TranscriptCleanView
+}
+
+func newInMemoryTranscriptCleanRepo() *inMemoryTranscriptCleanRepo {
+	return &inMemoryTranscriptCleanRepo{store: make(map[string]*db.TranscriptCleanView)}
+}
+
+func (r *inMemoryTranscriptCleanRepo) SaveTranscriptCleanView(_ context.Context, view *db.TranscriptCleanView) error {
+	now := time.Now().UTC()
+	if existing, ok := r.store[view.TranscriptID]; ok {
+		view.ID = existing.ID
+		view.CreatedAt = existing.CreatedAt
+	} else {
+		view.ID = "clean-" + view.TranscriptID
+		view.CreatedAt = now
+	}
+	view.UpdatedAt = now
+	clone := *view
+	r.store[view.TranscriptID] = &clone
+	return nil
+}
+
+func (r *inMemoryTranscriptCleanRepo) GetTranscriptCleanView(_ context.Context, transcriptID string) (*db.TranscriptCleanView, error) {
+	view, ok := r.store[transcriptID]
+	if !ok {
+		return nil, db.ErrNotFound
+	}
+	clone := *view
+	return &clone, nil
+}
+
+func newCleanTestServer(t *testing.T, ai aiService, cleanRepo transcriptCleanRepository, segments db.TranscriptSegments) *Server {
+	t.Helper()
+
+	videoRepo := &recordingVideoRepo{}
+	videoRepo.saved = append(videoRepo.saved, &db.Video{ID: "video-uuid", YouTubeID: "dQw4w9WgXcQ", Title: "Sample Title"})
+	transcriptRepo := &recordingTranscriptRepo{}
+	transcriptRepo.saved = append(transcriptRepo.saved, &db.Transcript{
+		ID:       "transcript-uuid",
+		VideoID:  "video-uuid",
+		Language: "en",
+		Content:  segments,
+	})
+
+	var opts []ServerOption
+	if cleanRepo != nil {
+		opts = append(opts, WithTranscriptCleanRepository(cleanRepo))
+	}
+	server, err := NewServer(&config.Config{APIPort: 8080}, &mockDB{}, noopYouTubeService{}, videoRepo, transcriptRepo, ai, noopAISummaryRepo{}, noopAIExtractionRepo{}, opts...)
+	require.NoError(t, err)
+	return server
+}
+
+func TestHandleCleanTranscript_PunctuatesAutoCaptions(t *testing.T) {
+	ai := &stubPunctuationAIService{}
+	cleanRepo := newInMemoryTranscriptCleanRepo()
+	server := newCleanTestServer(t, ai, cleanRepo, db.TranscriptSegments{
+		{StartMs: 0, DurationMs: 2000, Text: "so today we are"},
+		{StartMs: 2000, DurationMs: 2000, Text: "looking at go"},
+		{StartMs: 9000, DurationMs: 2000, Text: "first the basics"},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/transcript-uuid/clean", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 1, ai.calls)
+
+	var resp cleanTranscriptResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.True(t, resp.Punctuated)
+	assert.Equal(t, "stub-model", resp.Model)
+	assert.Equal(t, 42, resp.TokensUsed)
+	require.Len(t, resp.Paragraphs, 2)
+	assert.Equal(t, "So today we are looking at go.", resp.Paragraphs[0].Text)
+	assert.Equal(t, int64(0), resp.Paragraphs[0].Start)
+	assert.Equal(t, int64(4000), resp.Paragraphs[0].End)
+	assert.Equal(t, "First the basics.", resp.Paragraphs[1].Text)
+	assert.Equal(t, int64(9000), resp.Paragraphs[1].Start)
+
+	getReq := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/transcript-uuid/clean", nil)
+	getRec := httptest.NewRecorder()
+	server.router.ServeHTTP(getRec, getReq)
+	require.Equal(t, http.StatusOK, getRec.Code)
+
+	exportReq := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/transcript-uuid/export?format=text&view=clean", nil)
+	exportRec := httptest.NewRecorder()
+	server.router.ServeHTTP(exportRec, exportReq)
+	require.Equal(t, http.StatusOK, exportRec.Code)
+	assert.Contains(t, exportRec.Body.String(), "[00:00:00] So today we are looking at go.\n\n[00:00:09] First the basics.\n")
+}
+
+func TestHandleCleanTranscript_SkipsPunctuationWhenDisabled(t *testing.T) {
+	ai := &stubPunctuationAIService{}
+	server := newCleanTestServer(t, ai, newInMemoryTranscriptCleanRepo(), db.TranscriptSegments{
+		{StartMs: 0, DurationMs: 2000, Text: "no punctuation here"},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/transcript-uuid/clean", bytes.NewBufferString(`{"punctuate":false}`))
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 0, ai.calls)
+
+	var resp cleanTranscriptResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.False(t, resp.Punctuated)
+	require.Len(t, resp.Paragraphs, 1)
+	assert.Equal(t, "no punctuation here", resp.Paragraphs[0].Text)
+}
+
+func TestHandleGetCleanTranscript_NotGenerated(t *testing.T) {
+	server := newCleanTestServer(t, noopAIService{}, newInMemoryTranscriptCleanRepo(), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transcripts/transcript-uuid/clean", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandleCleanTranscript_NotConfigured(t *testing.T) {
+	server := newCleanTestServer(t, noopAIService{}, nil, db.TranscriptSegments{{StartMs: 0, DurationMs: 1000, Text: "Hi."}})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transcripts/transcript-uuid/clean", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -19,6 +19,7 @@ type TranscriptLine struct {
 	Start    int64  `json:"start"`
 	Duration int64  `json:"duration"`
 	Text     string `json:"text"`
+	Speaker  string `json:"speaker,omitempty"`
 }
 
 // TranscriptResponse is the API response for transcript fetch requests.
@@ -38,7 +39,7 @@ func fetchMetadataWithContext(ctx context.Context, yt youtubeService, videoID st
 
 	resultCh := make(chan result, 1)
 	go func() {
-		meta, err := yt.GetVideoMetadata(videoID)
+		meta, err := yt.GetVideoMetadata(ctx, videoID)
 		resultCh <- result{meta: meta, err: err}
 	}()
 
@@ -58,7 +59,7 @@ func fetchTranscriptWithContext(ctx context.Context, yt youtubeService, videoID,
 
 	resultCh := make(chan result, 1)
 	go func() {
-		lines, err := yt.GetTranscript(videoID, language)
+		lines, err := yt.GetTranscript(ctx, videoID, language)
 		resultCh <- result{lines: lines, err: err}
 	}()
 
@@ -99,6 +100,7 @@ func convertSegmentsToLines(segments db.TranscriptSegments) []TranscriptLine {
 			Start:    segment.StartMs,
 			Duration: segment.DurationMs,
 			Text:     strings.TrimSpace(segment.Text),
+			Speaker:  segment.Speaker,
 		})
 	}
 	return lines
@@ -18,7 +18,7 @@ type fakeYouTubeService struct {
 	lastLanguage   string
 }
 
-func (f *fakeYouTubeService) GetVideoMetadata(videoID string) (*services.VideoMetadata, error) {
+func (f *fakeYouTubeService) GetVideoMetadata(_ context.Context, videoID string) (*services.VideoMetadata, error) {
 	f.lastMetaInput = videoID
 	if f.metaErr != nil {
 		return nil, f.metaErr
@@ -26,7 +26,7 @@ func (f *fakeYouTubeService) GetVideoMetadata(videoID string) (*services.VideoMe
 	return f.meta, nil
 }
 
-func (f *fakeYouTubeService) GetTranscript(videoID, language string) ([]services.TranscriptLine, error) {
+func (f *fakeYouTubeService) GetTranscript(_ context.Context, videoID, language string) ([]services.TranscriptLine, error) {
 	f.lastTransVideo = videoID
 	f.lastLanguage = language
 	if f.transcriptErr != nil {
@@ -79,6 +79,12 @@ func (r *recordingTranscriptRepo) SaveTranscript(_ context.Context, transcript *
 	}
 	transcript.CreatedAt = time.Now()
 	clone := *transcript
+	for i, existing := range r.saved {
+		if existing.ID == transcript.ID {
+			r.saved[i] = &clone
+			return nil
+		}
+	}
 	r.saved = append(r.saved, &clone)
 	return nil
 }
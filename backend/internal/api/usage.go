@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+)
+
+type usageRepository interface {
+	AggregateAIUsage(ctx context.Context, query db.AIUsageQuery) ([]*db.AIUsageAggregate, error)
+}
+
+// WithUsageRepository enables the AI usage ledger endpoint.
+func WithUsageRepository(repo usageRepository) ServerOption {
+	return func(s *Server) {
+		s.usageRepo = repo
+	}
+}
+
+type usageResponse struct {
+	From    *time.Time          `json:"from,omitempty"`
+	To      *time.Time          `json:"to,omitempty"`
+	GroupBy []string            `json:"group_by"`
+	Totals  usageTotalsResponse `json:"totals"`
+	Groups  []usageGroupMessage `json:"groups"`
+}
+
+type usageTotalsResponse struct {
+	Calls            int64   `json:"calls"`
+	FailedCalls      int64   `json:"failed_calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+type usageGroupMessage struct {
+	Day           string `json:"day,omitempty"`
+	Provider      string `json:"provider,omitempty"`
+	Model         string `json:"model,omitempty"`
+	Operation     string `json:"operation,omitempty"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+	usageTotalsResponse
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// handleGetUsage serves GET /api/v1/usage: AI calls, tokens and cost from the usage ledger,
+// optionally limited by from/to (RFC 3339 or YYYY-MM-DD, to is inclusive) and grouped by any of
+// day, provider, model, operation and prompt_version (group_by, comma separated, default day).
+func (s *Server) handleGetUsage(w http.ResponseWriter, r *http.Request) {
+	if s.usageRepo == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "Usage ledger is not configured on this server")
+		return
+	}
+
+	queryParams := r.URL.Query()
+	query := db.AIUsageQuery{GroupBy: []string{db.AIUsageGroupDay}}
+	response := usageResponse{}
+
+	if raw := strings.TrimSpace(queryParams.Get("from")); raw != "" {
+		from, _, err := parseArchiveDate(raw)
+		if err != nil {
+			writeStructuredError(w, http.StatusBadRequest, err, "Invalid 'from' date. Use RFC 3339 or YYYY-MM-DD.")
+			return
+		}
+		query.From = from
+		response.From = &from
+	}
+	if raw := strings.TrimSpace(queryParams.Get("to")); raw != "" {
+		to, dateOnly, err := parseArchiveDate(raw)
+		if err != nil {
+			writeStructuredError(w, http.StatusBadRequest, err, "Invalid 'to' date. Use RFC 3339 or YYYY-MM-DD.")
+			return
+		}
+		response.To = &to
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		query.To = to
+	}
+
+	if raw, ok := queryParams["group_by"]; ok {
+		query.GroupBy = make([]string, 0)
+		seen := make(map[string]bool)
+		for _, part := range strings.Split(strings.Join(raw, ","), ",") {
+			name := strings.ToLower(strings.TrimSpace(part))
+			if name == "" || seen[name] {
+				continue
+			}
+			if !db.IsAIUsageGroup(name) {
+				writeStructuredError(w, http.StatusBadRequest, nil, "Invalid group_by. Use any of day, provider, model, operation, prompt_version.")
+				return
+			}
+			seen[name] = true
+			query.GroupBy = append(query.GroupBy, name)
+		}
+	}
+	response.GroupBy = query.GroupBy
+
+	aggregates, err := s.usageRepo.AggregateAIUsage(r.Context(), query)
+	if err != nil {
+		if isDatabaseUnavailableError(err) {
+			writeStructuredError(w, http.StatusServiceUnavailable, err, "Database unavailable. Please try again later.")
+			return
+		}
+		logAPILookupError(r.Context(), "aggregate usage", err)
+		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to load usage")
+		return
+	}
+
+	response.Groups = make([]usageGroupMessage, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		totals := usageTotalsResponse{
+			Calls:            aggregate.Calls,
+			FailedCalls:      aggregate.FailedCalls,
+			PromptTokens:     aggregate.PromptTokens,
+			CompletionTokens: aggregate.CompletionTokens,
+			TotalTokens:      aggregate.PromptTokens + aggregate.CompletionTokens,
+			CostUSD:          aggregate.CostUSD,
+		}
+		response.Totals.Calls += totals.Calls
+		response.Totals.FailedCalls += totals.FailedCalls
+		response.Totals.PromptTokens += totals.PromptTokens
+		response.Totals.CompletionTokens += totals.CompletionTokens
+		response.Totals.TotalTokens += totals.TotalTokens
+		response.Totals.CostUSD += totals.CostUSD
+
+		response.Groups = append(response.Groups, usageGroupMessage{
+			Day:                 aggregate.Day,
+			Provider:            aggregate.Provider,
+			Model:               aggregate.Model,
+			Operation:           aggregate.Operation,
+			PromptVersion:       aggregate.PromptVersion,
+			usageTotalsResponse: totals,
+			AvgLatencyMs:        aggregate.AvgLatencyMs,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
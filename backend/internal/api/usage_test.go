@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+)
+
+type stubUsageRepo struct {
+	query      db.AIUsageQuery
+	aggregates []*db.AIUsageAggregate
+	err        error
+}
+
+func (r *stubUsageRepo) AggregateAIUsage(_ context.Context, query db.AIUsageQuery) ([]*db.AIUsageAggregate, error) {
+	r.query = query
+	return r.aggregates, r.err
+}
+
+func newUsageTestServer(t *testing.T, repo usageRepository) *Server {
+	t.Helper()
+	server, err := NewServer(&config.Config{APIPort: 8080}, &mockDB{}, noopYouTubeService{}, &recordingVideoRepo{}, &recordingTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{},
+		WithUsageRepository(repo))
+	require.NoError(t, err)
+	return server
+}
+
+func TestHandleGetUsage_AggregatesGroups(t *testing.T) {
+	repo := &stubUsageRepo{aggregates: []*db.AIUsageAggregate{
+		{Day: "2025-03-01", Model: "gpt-4", Calls: 3, FailedCalls: 1, PromptTokens: 1000, CompletionTokens: 200, CostUSD: 0.042, AvgLatencyMs: 850},
+		{Day: "2025-03-02", Model: "claude-3-5-sonnet-20241022", Calls: 2, PromptTokens: 500, CompletionTokens: 100, CostUSD: 0.003, AvgLatencyMs: 400},
+	}}
+	server := newUsageTestServer(t, repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/usage?from=2025-03-01&to=2025-03-02&group_by=day,Model,day", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), repo.query.From)
+	assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), repo.query.To, "date-only to is inclusive")
+	assert.Equal(t, []string{db.AIUsageGroupDay, db.AIUsageGroupModel}, repo.query.GroupBy)
+
+	var response usageResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, []string{"day", "model"}, response.GroupBy)
+	assert.Equal(t, int64(5), response.Totals.Calls)
+	assert.Equal(t, int64(1), response.Totals.FailedCalls)
+	assert.Equal(t, int64(1800), response.Totals.TotalTokens)
+	assert.InDelta(t, 0.045, response.Totals.CostUSD, 1e-9)
+	require.Len(t, response.Groups, 2)
+	assert.Equal(t, "gpt-4", response.Groups[0].Model)
+	assert.Equal(t, int64(1200), response.Groups[0].TotalTokens)
+	assert.NotContains(t, rec.Body.String(), `"operation"`)
+}
+
+func TestHandleGetUsage_DefaultsToDailyGroups(t *testing.T) {
+	repo := &stubUsageRepo{}
+	server := newUsageTestServer(t, repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/usage", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	assert.True(t, repo.query.From.IsZero())
+	assert.True(t, repo.query.To.IsZero())
+	assert.Equal(t, []string{db.AIUsageGroupDay}, repo.query.GroupBy)
+	assert.JSONEq(t, `{"group_by":["day"],"totals":{"calls":0,"failed_calls":0,"prompt_tokens":0,"completion_tokens":0,"total_tokens":0,"cost_usd":0},"groups":[]}`, rec.Body.String())
+}
+
+func TestHandleGetUsage_InvalidParameters(t *testing.T) {
+	server := newUsageTestServer(t, &stubUsageRepo{})
+
+	for _, query := range []string{"from=yesterday", "to=03/02/2025", "group_by=week", "group_by=day,user"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/usage?"+query, nil)
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestHandleGetUsage_RepositoryError(t *testing.T) {
+	server := newUsageTestServer(t, &stubUsageRepo{err: errors.New("boom")})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/usage", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestHandleGetUsage_NotConfigured(t *testing.T) {
+	server, err := NewServer(&config.Config{APIPort: 8080}, &mockDB{}, noopYouTubeService{}, &recordingVideoRepo{}, &recordingTranscriptRepo{}, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/usage", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,297 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+)
+
+const (
+	sessionTokenPrefix    = "yts_"
+	defaultSessionTTL     = 30 * 24 * time.Hour
+	minPasswordLength     = 8
+	maxPasswordLength     = 72 // bcrypt ignores anything longer
+	maxDisplayNameLength  = 100
+	maxEmailAddressLength = 320
+)
+
+// dummyPasswordHash is compared against when an email is unknown, so a login takes as long for
+// an unknown email as for a wrong password.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+type userRepository interface {
+	CreateUser(ctx context.Context, user *db.User) error
+	GetUserByEmail(ctx context.Context, email string) (*db.User, error)
+	GetUserByIdentity(ctx context.Context, issuer, subject string) (*db.User, error)
+	LinkIdentity(ctx context.Context, userID, issuer, subject string) error
+	CreateSession(ctx context.Context, session *db.UserSession) error
+	GetActiveSession(ctx context.Context, tokenHash string) (*db.UserSession, *db.User, error)
+	TouchSession(ctx context.Context, id string, usedAt time.Time) error
+	DeleteSession(ctx context.Context, id string) error
+}
+
+type libraryRepository interface {
+	AddToLibrary(ctx context.Context, userID, transcriptID string) error
+	RemoveFromLibrary(ctx context.Context, userID, transcriptID string) error
+	InLibrary(ctx context.Context, userID, transcriptID string) (bool, error)
+	ListLibrary(ctx context.Context, userID string) ([]*db.LibraryEntry, error)
+}
+
+// WithUserRepositories enables user accounts, sessions and per-user libraries.
+func WithUserRepositories(users userRepository, library libraryRepository) ServerOption {
+	return func(s *Server) {
+		s.users = users
+		s.library = library
+	}
+}
+
+type registerRequest struct {
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	DisplayName string `json:"display_name"`
+}
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type userResponse struct {
+	ID          string    `json:"id"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Role        string    `json:"role,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type sessionResponse struct {
+	User      userResponse `json:"user"`
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// handleRegister serves POST /api/v1/auth/register and signs the new user in.
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if s.users == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "User accounts are not configured on this server")
+		return
+	}
+	if !s.config.AuthSignupEnabled {
+		writeStructuredError(w, http.StatusForbidden, nil, "Sign-up is disabled on this server")
+		return
+	}
+
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeStructuredError(w, http.StatusBadRequest, err, "Invalid JSON request body")
+		return
+	}
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		writeStructuredError(w, http.StatusBadRequest, err, "A valid email is required")
+		return
+	}
+	if len(req.Password) < minPasswordLength || len(req.Password) > maxPasswordLength {
+		writeStructuredError(w, http.StatusBadRequest, nil, fmt.Sprintf("password must be %d to %d characters", minPasswordLength, maxPasswordLength))
+		return
+	}
+	displayName := strings.TrimSpace(req.DisplayName)
+	if len(displayName) > maxDisplayNameLength {
+		writeStructuredError(w, http.StatusBadRequest, nil, fmt.Sprintf("display_name must be at most %d characters", maxDisplayNameLength))
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		writeStructuredError(w, http.StatusInternalServerError, err, "Failed to create account")
+		return
+	}
+	user := &db.User{Email: email, PasswordHash: string(hash), DisplayName: displayName}
+	if err := s.users.CreateUser(r.Context(), user); err != nil {
+		if errors.Is(err, db.ErrAlreadyExists) {
+			writeStructuredError(w, http.StatusConflict, err, "An account with this email already exists")
+			return
+		}
+		writeRepositoryError(w, err, "Failed to create account")
+		return
+	}
+
+	session, err := s.startSession(r.Context(), user, "")
+	if err != nil {
+		writeRepositoryError(w, err, "Failed to start session")
+		return
+	}
+	writeJSON(w, http.StatusCreated, session)
+}
+
+// handleLogin serves POST /api/v1/auth/login.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if s.users == nil {
+		writeStructuredError(w, http.StatusServiceUnavailable, nil, "User accounts are not configured on this server")
+		return
+	}
+
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeStructuredError(w, http.StatusBadRequest, err, "Invalid JSON request body")
+		return
+	}
+
+	if strings.TrimSpace(req.Email) == "" || req.Password == "" {
+		writeStructuredError(w, http.StatusBadRequest, nil, "email and password are required")
+		return
+	}
+
+	user, err := s.users.GetUserByEmail(r.Context(), req.Email)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		writeRepositoryError(w, err, "Failed to sign in")
+		return
+	}
+	hash := dummyPasswordHash()
+	if user != nil && user.PasswordHash != "" {
+		hash = []byte(user.PasswordHash)
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(req.Password)) != nil || user == nil || user.PasswordHash == "" {
+		writeUnauthorized(w, "Invalid email or password")
+		return
+	}
+
+	session, err := s.startSession(r.Context(), user, "")
+	if err != nil {
+		writeRepositoryError(w, err, "Failed to start session")
+		return
+	}
+	writeJSON(w, http.StatusOK, session)
+}
+
+// handleLogout serves POST /api/v1/auth/logout, ending the current session.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	principal := principalFromContext(r.Context())
+	if err := s.users.DeleteSession(r.Context(), principal.SessionID); err != nil {
+		writeRepositoryError(w, err, "Failed to sign out")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetCurrentUser serves GET /api/v1/auth/me.
+func (s *Server) handleGetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	principal := principalFromContext(r.Context())
+	writeJSON(w, http.StatusOK, buildUserResponse(principal.user, principal.Role))
+}
+
+// startSession signs user in. role is set for single sign-on and empty for password sign-in.
+func (s *Server) startSession(ctx context.Context, user *db.User, role string) (sessionResponse, error) {
+	token, err := generateSessionToken()
+	if err != nil {
+		return sessionResponse{}, err
+	}
+	ttl := defaultSessionTTL
+	if hours := s.config.AuthSessionTTLHours; hours > 0 {
+		ttl = time.Duration(hours) * time.Hour
+	}
+	session := &db.UserSession{UserID: user.ID, TokenHash: hashAPIKey(token), ExpiresAt: time.Now().Add(ttl), Role: role}
+	if err := s.users.CreateSession(ctx, session); err != nil {
+		return sessionResponse{}, err
+	}
+
+	return sessionResponse{User: buildUserResponse(user, role), Token: token, ExpiresAt: session.ExpiresAt}, nil
+}
+
+// authenticateSession resolves a session token to its user.
+func (s *Server) authenticateSession(ctx context.Context, token string) (*apiPrincipal, error) {
+	if s.users == nil {
+		return nil, errInvalidSession
+	}
+	session, user, err := s.users.GetActiveSession(ctx, hashAPIKey(token))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, errInvalidSession
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if session.LastUsedAt == nil || now.Sub(*session.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.users.TouchSession(ctx, session.ID, now); err != nil {
+			slog.WarnContext(ctx, "failed to record use of session", "session_id", session.ID, "error", err)
+		}
+	}
+	scopes := s.passwordUserScopes()
+	if session.Role != "" {
+		scopes = roleScopes[session.Role]
+	}
+	return &apiPrincipal{UserID: user.ID, SessionID: session.ID, Name: user.Email, Role: session.Role, Scopes: scopes, user: user}, nil
+}
+
+// passwordUserScopes are the scopes of users signed in with a password: AUTH_USER_ROLE's, which
+// lets them fetch transcripts into their library unless set to viewer. Usage and key management
+// stay with API keys.
+func (s *Server) passwordUserScopes() []string {
+	if s.config.AuthUserRole == roleViewer {
+		return roleScopes[roleViewer]
+	}
+	return roleScopes[roleEditor]
+}
+
+// userIDFromContext returns the signed-in user, or "" for API keys and anonymous callers.
+func userIDFromContext(ctx context.Context) string {
+	if principal := principalFromContext(ctx); principal != nil {
+		return principal.UserID
+	}
+	return ""
+}
+
+// libraryOwnerFromContext returns the user whose library limits what the caller sees: every
+// signed-in user except admins, who see the whole catalogue like keys with transcripts:all.
+func libraryOwnerFromContext(ctx context.Context) string {
+	if principal := principalFromContext(ctx); principal != nil && !principal.hasScope(scopeTranscriptsAll) {
+		return principal.UserID
+	}
+	return ""
+}
+
+func normalizeEmail(raw string) (string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	if len(address.Address) > maxEmailAddressLength || address.Name != "" {
+		return "", errors.New("invalid email")
+	}
+	return strings.ToLower(address.Address), nil
+}
+
+func generateSessionToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return sessionTokenPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func buildUserResponse(user *db.User, role string) userResponse {
+	return userResponse{
+		ID:          user.ID,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Role:        role,
+		CreatedAt:   user.CreatedAt,
+	}
+}
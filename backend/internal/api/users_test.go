@@ -0,0 +1,342 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/config"
+	"github.com/yourusername/yt-transcript-downloader/internal/db"
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+// stubUserRepo keeps users, identities and sessions in memory.
+type stubUserRepo struct {
+	users      []*db.User
+	sessions   []*db.UserSession
+	identities map[string]string // issuer + " " + subject -> user ID
+}
+
+func (r *stubUserRepo) CreateUser(_ context.Context, user *db.User) error {
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			return db.ErrAlreadyExists
+		}
+	}
+	user.ID = uuid.NewString()
+	user.CreatedAt = time.Now()
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *stubUserRepo) GetUserByEmail(_ context.Context, email string) (*db.User, error) {
+	for _, user := range r.users {
+		if user.Email == strings.ToLower(email) {
+			return user, nil
+		}
+	}
+	return nil, db.ErrNotFound
+}
+
+func (r *stubUserRepo) GetUserByIdentity(_ context.Context, issuer, subject string) (*db.User, error) {
+	for _, user := range r.users {
+		if user.ID == r.identities[issuer+" "+subject] {
+			return user, nil
+		}
+	}
+	return nil, db.ErrNotFound
+}
+
+func (r *stubUserRepo) LinkIdentity(_ context.Context, userID, issuer, subject string) error {
+	if r.identities == nil {
+		r.identities = make(map[string]string)
+	}
+	if _, ok := r.identities[issuer+" "+subject]; ok {
+		return db.ErrAlreadyExists
+	}
+	r.identities[issuer+" "+subject] = userID
+	return nil
+}
+
+func (r *stubUserRepo) CreateSession(_ context.Context, session *db.UserSession) error {
+	session.ID = uuid.NewString()
+	r.sessions = append(r.sessions, session)
+	return nil
+}
+
+func (r *stubUserRepo) GetActiveSession(_ context.Context, tokenHash string) (*db.UserSession, *db.User, error) {
+	for _, session := range r.sessions {
+		if session.TokenHash != tokenHash || !session.ExpiresAt.After(time.Now()) {
+			continue
+		}
+		for _, user := range r.users {
+			if user.ID == session.UserID {
+				return session, user, nil
+			}
+		}
+	}
+	return nil, nil, db.ErrNotFound
+}
+
+func (r *stubUserRepo) TouchSession(_ context.Context, id string, usedAt time.Time) error {
+	for _, session := range r.sessions {
+		if session.ID == id {
+			session.LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func (r *stubUserRepo) DeleteSession(_ context.Context, id string) error {
+	for i, session := range r.sessions {
+		if session.ID == id {
+			r.sessions = append(r.sessions[:i], r.sessions[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// stubLibraryRepo keeps library entries as user ID -> transcript IDs.
+type stubLibraryRepo struct {
+	entries map[string][]string
+}
+
+func (r *stubLibraryRepo) AddToLibrary(_ context.Context, userID, transcriptID string) error {
+	if r.entries == nil {
+		r.entries = make(map[string][]string)
+	}
+	if ok, _ := r.InLibrary(context.Background(), userID, transcriptID); !ok {
+		r.entries[userID] = append(r.entries[userID], transcriptID)
+	}
+	return nil
+}
+
+func (r *stubLibraryRepo) RemoveFromLibrary(_ context.Context, userID, transcriptID string) error {
+	for i, id := range r.entries[userID] {
+		if id == transcriptID {
+			r.entries[userID] = append(r.entries[userID][:i], r.entries[userID][i+1:]...)
+			return nil
+		}
+	}
+	return db.ErrNotFound
+}
+
+func (r *stubLibraryRepo) InLibrary(_ context.Context, userID, transcriptID string) (bool, error) {
+	for _, id := range r.entries[userID] {
+		if id == transcriptID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *stubLibraryRepo) ListLibrary(_ context.Context, userID string) ([]*db.LibraryEntry, error) {
+	entries := make([]*db.LibraryEntry, 0, len(r.entries[userID]))
+	for _, id := range r.entries[userID] {
+		entries = append(entries, &db.LibraryEntry{TranscriptID: id, Language: "en"})
+	}
+	return entries, nil
+}
+
+func newUserTestServer(t *testing.T, cfg *config.Config, youTube youtubeService, transcripts *recordingTranscriptRepo) (*Server, *stubUserRepo, *stubLibraryRepo) {
+	t.Helper()
+	users := &stubUserRepo{}
+	library := &stubLibraryRepo{}
+	server, err := NewServer(cfg, &mockDB{}, youTube, &recordingVideoRepo{}, transcripts, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{},
+		WithUserRepositories(users, library))
+	require.NoError(t, err)
+	return server, users, library
+}
+
+func registerTestUser(t *testing.T, server *Server, email string) sessionResponse {
+	t.Helper()
+	rec := serveWithKey(server, http.MethodPost, "/api/v1/auth/register", "", `{"email": "`+email+`", "password": "correct horse"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var session sessionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &session))
+	return session
+}
+
+func TestUserAuthEndpoints(t *testing.T) {
+	cfg := &config.Config{APIPort: 8080, AuthSignupEnabled: true}
+	server, users, _ := newUserTestServer(t, cfg, noopYouTubeService{}, &recordingTranscriptRepo{})
+
+	session := registerTestUser(t, server, " Ada@Example.com ")
+	assert.True(t, strings.HasPrefix(session.Token, sessionTokenPrefix))
+	assert.Equal(t, "ada@example.com", session.User.Email)
+	assert.NotEqual(t, "correct horse", users.users[0].PasswordHash, "passwords are hashed")
+	assert.Equal(t, hashAPIKey(session.Token), users.sessions[0].TokenHash, "only the token digest is stored")
+
+	rec := serveWithKey(server, http.MethodPost, "/api/v1/auth/register", "", `{"email": "ada@example.com", "password": "another one"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = serveWithKey(server, http.MethodPost, "/api/v1/auth/register", "", `{"email": "not-an-email", "password": "correct horse"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serveWithKey(server, http.MethodPost, "/api/v1/auth/register", "", `{"email": "bob@example.com", "password": "short"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serveWithKey(server, http.MethodPost, "/api/v1/auth/login", "", `{"email": "ada@example.com", "password": "wrong password"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = serveWithKey(server, http.MethodPost, "/api/v1/auth/login", "", `{"email": "nobody@example.com", "password": "correct horse"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Invalid email or password")
+
+	rec = serveWithKey(server, http.MethodPost, "/api/v1/auth/login", "", `{"email": "ADA@example.com", "password": "correct horse"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var login sessionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &login))
+
+	rec = serveWithKey(server, http.MethodGet, "/api/v1/auth/me", login.Token, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"email":"ada@example.com"`)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(server, http.MethodGet, "/api/v1/auth/me", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(server, http.MethodGet, "/api/v1/auth/me", "ytk_not-a-session", "").Code)
+
+	assert.Equal(t, http.StatusNoContent, serveWithKey(server, http.MethodPost, "/api/v1/auth/logout", login.Token, "").Code)
+	rec = serveWithKey(server, http.MethodGet, "/api/v1/auth/me", login.Token, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Session expired")
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, "/api/v1/auth/me", session.Token, "").Code, "other sessions stay signed in")
+}
+
+func TestUserAuthEndpoints_SignupDisabled(t *testing.T) {
+	server, _, _ := newUserTestServer(t, &config.Config{APIPort: 8080}, noopYouTubeService{}, &recordingTranscriptRepo{})
+
+	rec := serveWithKey(server, http.MethodPost, "/api/v1/auth/register", "", `{"email": "ada@example.com", "password": "correct horse"}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestUserScopes_EditorUnlessConfigured(t *testing.T) {
+	cfg := &config.Config{APIPort: 8080, AuthSignupEnabled: true}
+	server, _, _ := newUserTestServer(t, cfg, noopYouTubeService{}, &recordingTranscriptRepo{})
+	ada := registerTestUser(t, server, "ada@example.com")
+
+	principal, err := server.authenticateSession(context.Background(), ada.Token)
+	require.NoError(t, err)
+	assert.Equal(t, roleScopes[roleEditor], principal.Scopes, "self-registered users can fill their library")
+
+	cfg.AuthUserRole = roleViewer
+	rec := serveWithKey(server, http.MethodPost, "/api/v1/transcripts/fetch", ada.Token, `{"video_url": "https://youtu.be/dQw4w9WgXcQ"}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "viewers cannot fetch or spend AI budget")
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, "/api/v1/library", ada.Token, "").Code)
+}
+
+func TestLibrary_FetchAddsTranscriptAndHidesOthers(t *testing.T) {
+	const sampleVideoID = "dQw4w9WgXcQ"
+	youTube := &fakeYouTubeService{
+		meta:       &services.VideoMetadata{ID: sampleVideoID, Title: "Sample Title", Author: "Sample Channel", Duration: time.Minute},
+		transcript: []services.TranscriptLine{{Start: 0, Duration: time.Second, Text: "Hello"}},
+	}
+	otherID := uuid.NewString()
+	transcripts := &recordingTranscriptRepo{saved: []*db.Transcript{{ID: otherID, VideoID: "video-uuid", Language: "de"}}}
+	cfg := &config.Config{APIPort: 8080, AuthSignupEnabled: true}
+	server, _, library := newUserTestServer(t, cfg, youTube, transcripts)
+
+	ada := registerTestUser(t, server, "ada@example.com")
+	bob := registerTestUser(t, server, "bob@example.com")
+
+	rec := serveWithKey(server, http.MethodPost, "/api/v1/transcripts/fetch", ada.Token, `{"video_url": "https://youtu.be/`+sampleVideoID+`"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var fetched TranscriptResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fetched))
+	assert.Equal(t, ada.User.ID, transcripts.saved[1].CreatedBy)
+	assert.Equal(t, []string{fetched.TranscriptID}, library.entries[ada.User.ID])
+
+	rec = serveWithKey(server, http.MethodGet, "/api/v1/library", ada.Token, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), fetched.TranscriptID)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(server, http.MethodGet, "/api/v1/library", "", "").Code)
+
+	// Transcripts outside the caller's library look missing to signed-in users only.
+	require.NoError(t, library.AddToLibrary(context.Background(), bob.User.ID, otherID))
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, "/api/v1/transcripts/"+otherID, bob.Token, "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(server, http.MethodGet, "/api/v1/transcripts/"+otherID, ada.Token, "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(server, http.MethodPost, "/api/v1/transcripts/"+otherID+"/summarize", ada.Token, `{}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(server, http.MethodGet, "/api/v1/transcripts/"+otherID, "", "").Code)
+
+	assert.Equal(t, http.StatusNoContent, serveWithKey(server, http.MethodDelete, "/api/v1/library/"+otherID, bob.Token, "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(server, http.MethodDelete, "/api/v1/library/"+otherID, bob.Token, "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(server, http.MethodGet, "/api/v1/transcripts/"+otherID, bob.Token, "").Code)
+}
+
+func TestRequireTranscriptAccess_CallersWithoutAUser(t *testing.T) {
+	transcriptID := uuid.NewString()
+	newServer := func(t *testing.T, cfg *config.Config) *Server {
+		transcripts := &recordingTranscriptRepo{saved: []*db.Transcript{{ID: transcriptID, VideoID: "video-uuid", Language: "en"}}}
+		videos := &recordingVideoRepo{saved: []*db.Video{{ID: "video-uuid", YouTubeID: "dQw4w9WgXcQ", Title: "Sample Title"}}}
+		cfg.APIPort, cfg.AuthAdminKey = 8080, testAdminKey
+		server, err := NewServer(cfg, &mockDB{}, noopYouTubeService{}, videos, transcripts, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{},
+			WithAPIKeyRepository(&stubAPIKeyRepo{}), WithUserRepositories(&stubUserRepo{}, &stubLibraryRepo{}))
+		require.NoError(t, err)
+		return server
+	}
+	path := "/api/v1/transcripts/" + transcriptID
+
+	t.Run("API keys", func(t *testing.T) {
+		server := newServer(t, &config.Config{AuthSignupEnabled: true})
+		reader := createTestKey(t, server, scopeTranscriptsRead, scopeAIWrite)
+		everything := createTestKey(t, server, scopeTranscriptsRead, scopeTranscriptsAll)
+
+		rec := serveWithKey(server, http.MethodGet, path, reader.Key, "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), scopeTranscriptsAll)
+		assert.Equal(t, http.StatusForbidden, serveWithKey(server, http.MethodPost, path+"/summarize", reader.Key, `{}`).Code)
+		assert.Equal(t, http.StatusForbidden, serveWithKey(server, http.MethodGet, "/api/v1/export/archive", reader.Key, "").Code)
+		assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, path, everything.Key, "").Code)
+		assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, path, testAdminKey, "").Code)
+	})
+
+	t.Run("API keys without accounts", func(t *testing.T) {
+		server := newServer(t, &config.Config{})
+		reader := createTestKey(t, server, scopeTranscriptsRead)
+		writer := createTestKey(t, server, scopeTranscriptsWrite, scopeAIWrite)
+
+		assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, path, reader.Key, "").Code, "a server without accounts has no libraries to keep apart")
+		assert.NotEqual(t, http.StatusForbidden, serveWithKey(server, http.MethodGet, "/api/v1/export/archive", reader.Key, "").Code)
+		assert.NotEqual(t, http.StatusForbidden, serveWithKey(server, http.MethodPost, path+"/summarize", writer.Key, `{}`).Code)
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		server := newServer(t, &config.Config{AuthSignupEnabled: true})
+		rec := serveWithKey(server, http.MethodGet, path, "", "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, http.StatusUnauthorized, serveWithKey(server, http.MethodPost, path+"/summarize", "", `{}`).Code)
+
+		server = newServer(t, &config.Config{})
+		assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, path, "", "").Code, "a server without accounts has no libraries to keep apart")
+	})
+}
+
+func TestRequireTranscriptAccess_SingleSignOnSessions(t *testing.T) {
+	transcriptID := uuid.NewString()
+	transcripts := &recordingTranscriptRepo{saved: []*db.Transcript{{ID: transcriptID, VideoID: "video-uuid", Language: "en"}}}
+	videos := &recordingVideoRepo{saved: []*db.Video{{ID: "video-uuid", YouTubeID: "dQw4w9WgXcQ", Title: "Sample Title"}}}
+	users, library := &stubUserRepo{}, &stubLibraryRepo{}
+	server, err := NewServer(&config.Config{APIPort: 8080, AuthSignupEnabled: true}, &mockDB{}, noopYouTubeService{}, videos, transcripts, noopAIService{}, noopAISummaryRepo{}, noopAIExtractionRepo{},
+		WithUserRepositories(users, library))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	signIn := func(email, role string) sessionResponse {
+		user := &db.User{Email: email}
+		require.NoError(t, users.CreateUser(ctx, user))
+		session, err := server.startSession(ctx, user, role)
+		require.NoError(t, err)
+		return session
+	}
+	viewer := signIn("ada@example.com", roleViewer)
+	admin := signIn("cy@example.com", roleAdmin)
+	path := "/api/v1/transcripts/" + transcriptID
+
+	assert.Equal(t, http.StatusNotFound, serveWithKey(server, http.MethodGet, path, viewer.Token, "").Code, "single sign-on users only see their library")
+	require.NoError(t, library.AddToLibrary(ctx, viewer.User.ID, transcriptID))
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, path, viewer.Token, "").Code)
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, path, admin.Token, "").Code, "admins see every transcript")
+}
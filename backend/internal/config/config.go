@@ -2,33 +2,158 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/joho/godotenv"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
 )
 
 // Config holds the application configuration
 type Config struct {
-	DBHost     string
-	DBPort     int
-	DBName     string
-	DBUser     string
-	DBPassword string
-	APIPort    int
+	DBHost        string
+	DBPort        int
+	DBName        string
+	DBUser        string
+	DBPassword    string
+	DBAutoMigrate bool // apply pending migrations on startup
+	APIPort       int
 
 	// AI Configuration
 	OpenAIAPIKey    string
 	AnthropicAPIKey string
 	GoogleAPIKey    string
-	AIProvider      string // "openai", "anthropic", "google"
-	AIModel         string // "gpt-4", "gpt-3.5-turbo", "claude-3-opus", "gemini-1.5-flash", etc.
+	AIProvider      string // "openai", "anthropic", "google", "local", "mock"
+	AIModel         string // "gpt-4", "gpt-3.5-turbo", "claude-3-opus", "gemini-1.5-flash", "llama3.1", etc.
 	AIMaxTokens     int
 	AITemperature   float64
 
+	// LocalAIBaseURL is the OpenAI-compatible endpoint (Ollama, llama.cpp server, vLLM) used by
+	// the "local" provider; LocalAIAPIKey is only needed if that server requires one.
+	LocalAIBaseURL string
+	LocalAIAPIKey  string
+
+	// The "mock" provider answers offline from the transcript text. MockAILatencyMs delays every
+	// call; MockAIFailure injects "rate_limit", "quota", "unavailable" or "malformed_json" on every
+	// MockAIFailureEvery-th call (every call when 0 or 1).
+	MockAILatencyMs    int
+	MockAIFailure      string
+	MockAIFailureEvery int
+
+	// AIPromptsDir holds prompt versions that add to or override the embedded ones, as
+	// "<dir>/<version>/<prompt>.txt". AIPromptVersion is the version in use; when
+	// AIPromptCandidate is set, AIPromptCandidatePercent of the transcripts use it instead.
+	AIPromptsDir             string
+	AIPromptVersion          string
+	AIPromptCandidate        string
+	AIPromptCandidatePercent int
+
+	// AIFallbackProviders lists "provider[:model]" specs tried in order after AIProvider
+	// when it is rate limited, out of quota or unavailable.
+	AIFallbackProviders []string
+	// AIRoutes maps an operation ("summarize", "extract", "answer", ...) or an operation
+	// and type ("summarize.detailed") to the "provider[:model]" spec that handles it first.
+	AIRoutes map[string]string
+
+	// Client-side AI rate limits, applied separately to every provider/model. Zero disables a limit.
+	AIRequestsPerMinute int
+	AITokensPerMinute   int
+	AIMaxConcurrency    int
+	// AIRateLimitMaxWaitSeconds is how long a call may queue for a limit before the API answers 429.
+	AIRateLimitMaxWaitSeconds int
+	// AIRateLimits overrides the limits for a "provider" or "provider:model" key.
+	AIRateLimits map[string]AIRateLimit
+	// AIDistributedLocks coordinates identical AI generations across instances with Postgres
+	// advisory locks; concurrent requests within one instance are always coalesced.
+	AIDistributedLocks bool
+	// AIPrices overrides the built-in per-model token prices used for the usage ledger, keyed
+	// by model name or model prefix.
+	AIPrices map[string]AIPrice
+	// AIBudget caps AI spend across all callers; AIKeyBudget caps it for each authenticated API key
+	// or user. Anonymous calls only count against AIBudget.
+	AIBudget    AIBudget
+	AIKeyBudget AIBudget
+
+	// AuthRequired rejects API requests without a valid API key; when off, keys are optional and
+	// only the admin endpoints need one. AuthAdminKey is a key with the admin scope that is not
+	// stored in the database, used to create the first keys.
+	AuthRequired bool
+	AuthAdminKey string
+	// AuthSignupEnabled allows anyone to register an account; AuthSessionTTLHours is how long a
+	// login stays valid. AuthUserRole is the role of accounts signed in with a password: editor
+	// unless set to viewer (read-only).
+	AuthSignupEnabled   bool
+	AuthSessionTTLHours int
+	AuthUserRole        string
+	// OIDC single sign-on. Users get the highest role whose groups (read from OIDCGroupsClaim)
+	// they belong to, or OIDCDefaultRole; without a role they cannot sign in.
+	OIDCIssuerURL    string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCScopes       []string
+	OIDCGroupsClaim  string
+	OIDCAdminGroups  []string
+	OIDCEditorGroups []string
+	OIDCViewerGroups []string
+	OIDCDefaultRole  string
+	// OIDCPostLoginURL receives the session token in its fragment after sign-in; without it the
+	// callback answers with JSON.
+	OIDCPostLoginURL string
+
+	// Inbound rate limits in requests per minute per API key, user or client IP. Fetch, AI and
+	// sign-in routes have their own stricter limits; 0 disables a limit. RateLimitStore is
+	// "memory" or "postgres" (shared by all instances).
+	RateLimitPerMinute      int
+	RateLimitFetchPerMinute int
+	RateLimitAIPerMinute    int
+	RateLimitAuthPerMinute  int
+	RateLimitStore          string
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For and X-Real-IP headers name
+	// the client. Requests from any other address are identified by the connection's address.
+	TrustedProxies []netip.Prefix
+
+	// OpenTelemetry tracing. Spans are exported over OTLP/HTTP to TracingEndpoint; tracing is off
+	// when it is empty. TracingSampleRatio is the share of new traces kept (0 to 1).
+	TracingEndpoint    string
+	TracingSampleRatio float64
+	TracingServiceName string
+
+	// LogLevel is the lowest level written: debug, info, warn or error. LogFormat is json or text.
+	LogLevel  string
+	LogFormat string
+
 	// CORS configuration
 	CORSAllowedOrigins []string
+
+	// ExportArchiveMaxMB caps the uncompressed size of a single library archive export.
+	ExportArchiveMaxMB int
+}
+
+// AIPrice is the USD cost per million prompt and completion tokens of a model.
+type AIPrice struct {
+	PromptPerMillion     float64
+	CompletionPerMillion float64
+}
+
+// AIBudget caps AI tokens and USD per UTC day and month. Zero values mean unlimited.
+type AIBudget struct {
+	DailyTokens   int64
+	MonthlyTokens int64
+	DailyUSD      float64
+	MonthlyUSD    float64
+}
+
+// AIRateLimit holds the client-side limits for one provider or provider/model.
+type AIRateLimit struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+	MaxConcurrency    int
 }
 
 // Load reads configuration from environment variables
@@ -52,6 +177,11 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
 	}
 
+	config.DBAutoMigrate, err = getEnvBoolWithDefault("DB_AUTO_MIGRATE", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_AUTO_MIGRATE: %w", err)
+	}
+
 	config.APIPort, err = getEnvIntWithDefault("API_PORT", 8080)
 	if err != nil {
 		return nil, fmt.Errorf("invalid API_PORT: %w", err)
@@ -61,8 +191,28 @@ func Load() (*Config, error) {
 	config.OpenAIAPIKey = os.Getenv("OPENAI_API_KEY")
 	config.AnthropicAPIKey = os.Getenv("ANTHROPIC_API_KEY")
 	config.GoogleAPIKey = os.Getenv("GOOGLE_API_KEY")
+	config.LocalAIBaseURL = getEnvWithDefault("LOCAL_AI_BASE_URL", services.DefaultLocalBaseURL)
+	config.LocalAIAPIKey = os.Getenv("LOCAL_AI_API_KEY")
 	config.AIProvider = getEnvWithDefault("AI_PROVIDER", "openai")
-	config.AIModel = getEnvWithDefault("AI_MODEL", "gpt-4")
+	config.AIModel = getEnvWithDefault("AI_MODEL", defaultAIModel(config.AIProvider))
+
+	config.MockAILatencyMs, err = getEnvIntWithDefault("MOCK_AI_LATENCY_MS", 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MOCK_AI_LATENCY_MS: %w", err)
+	}
+	config.MockAIFailure = strings.ToLower(strings.TrimSpace(os.Getenv("MOCK_AI_FAILURE")))
+	config.MockAIFailureEvery, err = getEnvIntWithDefault("MOCK_AI_FAILURE_EVERY", 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MOCK_AI_FAILURE_EVERY: %w", err)
+	}
+
+	config.AIPromptsDir = os.Getenv("AI_PROMPTS_DIR")
+	config.AIPromptVersion = getEnvWithDefault("AI_PROMPT_VERSION", "v1")
+	config.AIPromptCandidate = os.Getenv("AI_PROMPT_CANDIDATE")
+	config.AIPromptCandidatePercent, err = getEnvIntWithDefault("AI_PROMPT_CANDIDATE_PERCENT", 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_PROMPT_CANDIDATE_PERCENT: %w", err)
+	}
 
 	config.AIMaxTokens, err = getEnvIntWithDefault("AI_MAX_TOKENS", 4000)
 	if err != nil {
@@ -74,6 +224,101 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid AI_TEMPERATURE: %w", err)
 	}
 
+	config.AIFallbackProviders = getEnvList("AI_FALLBACK_PROVIDERS")
+	config.AIRoutes, err = getEnvMap("AI_ROUTES")
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_ROUTES: %w", err)
+	}
+
+	if err := loadAIRateLimits(config); err != nil {
+		return nil, err
+	}
+
+	config.AIDistributedLocks, err = getEnvBoolWithDefault("AI_DISTRIBUTED_LOCKS", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_DISTRIBUTED_LOCKS: %w", err)
+	}
+
+	config.AIPrices, err = loadAIPrices()
+	if err != nil {
+		return nil, err
+	}
+
+	config.AIBudget, err = getEnvAIBudget("AI_BUDGET")
+	if err != nil {
+		return nil, err
+	}
+
+	config.AIKeyBudget, err = getEnvAIBudget("AI_KEY_BUDGET")
+	if err != nil {
+		return nil, err
+	}
+
+	config.ExportArchiveMaxMB, err = getEnvIntWithDefault("EXPORT_ARCHIVE_MAX_MB", 512)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORT_ARCHIVE_MAX_MB: %w", err)
+	}
+
+	config.AuthRequired, err = getEnvBoolWithDefault("AUTH_REQUIRED", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_REQUIRED: %w", err)
+	}
+	config.AuthAdminKey = os.Getenv("AUTH_ADMIN_KEY")
+	config.AuthSignupEnabled, err = getEnvBoolWithDefault("AUTH_SIGNUP_ENABLED", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_SIGNUP_ENABLED: %w", err)
+	}
+	config.AuthSessionTTLHours, err = getEnvIntWithDefault("AUTH_SESSION_TTL_HOURS", 720)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_SESSION_TTL_HOURS: %w", err)
+	}
+	config.AuthUserRole = strings.ToLower(strings.TrimSpace(getEnvWithDefault("AUTH_USER_ROLE", "editor")))
+	config.OIDCIssuerURL = strings.TrimSuffix(strings.TrimSpace(os.Getenv("OIDC_ISSUER_URL")), "/")
+	config.OIDCClientID = os.Getenv("OIDC_CLIENT_ID")
+	config.OIDCClientSecret = os.Getenv("OIDC_CLIENT_SECRET")
+	config.OIDCRedirectURL = os.Getenv("OIDC_REDIRECT_URL")
+	config.OIDCScopes = getEnvList("OIDC_SCOPES")
+	if len(config.OIDCScopes) == 0 {
+		config.OIDCScopes = []string{"openid", "email", "profile"}
+	}
+	config.OIDCGroupsClaim = getEnvWithDefault("OIDC_GROUPS_CLAIM", "groups")
+	config.OIDCAdminGroups = getEnvList("OIDC_ADMIN_GROUPS")
+	config.OIDCEditorGroups = getEnvList("OIDC_EDITOR_GROUPS")
+	config.OIDCViewerGroups = getEnvList("OIDC_VIEWER_GROUPS")
+	config.OIDCDefaultRole = strings.ToLower(strings.TrimSpace(os.Getenv("OIDC_DEFAULT_ROLE")))
+	config.OIDCPostLoginURL = os.Getenv("OIDC_POST_LOGIN_URL")
+	config.RateLimitPerMinute, err = getEnvIntWithDefault("RATE_LIMIT_PER_MINUTE", 120)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_PER_MINUTE: %w", err)
+	}
+	config.RateLimitFetchPerMinute, err = getEnvIntWithDefault("RATE_LIMIT_FETCH_PER_MINUTE", 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_FETCH_PER_MINUTE: %w", err)
+	}
+	config.RateLimitAIPerMinute, err = getEnvIntWithDefault("RATE_LIMIT_AI_PER_MINUTE", 20)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_AI_PER_MINUTE: %w", err)
+	}
+	config.RateLimitAuthPerMinute, err = getEnvIntWithDefault("RATE_LIMIT_AUTH_PER_MINUTE", 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_AUTH_PER_MINUTE: %w", err)
+	}
+	config.RateLimitStore = strings.ToLower(getEnvWithDefault("RATE_LIMIT_STORE", "memory"))
+	config.TrustedProxies, err = getEnvPrefixes("TRUSTED_PROXIES")
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
+	config.TracingEndpoint = strings.TrimSpace(os.Getenv("TRACING_ENDPOINT"))
+	config.TracingSampleRatio, err = getEnvFloatWithDefault("TRACING_SAMPLE_RATIO", 1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO: %w", err)
+	}
+	config.TracingServiceName = getEnvWithDefault("TRACING_SERVICE_NAME", "yt-transcript-downloader")
+
+	config.LogLevel = strings.ToLower(getEnvWithDefault("LOG_LEVEL", "info"))
+	config.LogFormat = strings.ToLower(getEnvWithDefault("LOG_FORMAT", "json"))
+
 	config.CORSAllowedOrigins = getCORSAllowedOrigins()
 
 	// Validate the configuration
@@ -84,8 +329,156 @@ func Load() (*Config, error) {
 	return config, nil
 }
 
+// LoadDatabase reads only the database and logging settings, for commands such as
+// `server migrate` that must run before the rest of the configuration is complete.
+func LoadDatabase() (*Config, error) {
+	_ = godotenv.Load()
+
+	config := &Config{
+		DBHost:     getEnvWithDefault("DB_HOST", "localhost"),
+		DBName:     getEnvWithDefault("DB_NAME", "yt_transcripts"),
+		DBUser:     getEnvWithDefault("DB_USER", "postgres"),
+		DBPassword: os.Getenv("DB_PASSWORD"),
+		LogLevel:   strings.ToLower(getEnvWithDefault("LOG_LEVEL", "info")),
+		LogFormat:  strings.ToLower(getEnvWithDefault("LOG_FORMAT", "json")),
+	}
+
+	var err error
+	config.DBPort, err = getEnvIntWithDefault("DB_PORT", 5432)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
+	}
+
+	errors := append(config.databaseErrors(), config.loggingErrors()...)
+	if len(errors) > 0 {
+		return nil, fmt.Errorf("configuration validation failed: %w", validationError(errors))
+	}
+	return config, nil
+}
+
 // Validate checks that all required configuration values are present and valid
 func (c *Config) Validate() error {
+	errors := c.databaseErrors()
+
+	if c.APIPort <= 0 || c.APIPort > 65535 {
+		errors = append(errors, "API_PORT must be between 1 and 65535")
+	}
+
+	// AI configuration validation; a local model server and the mock provider need no API key
+	if c.AIProvider != "local" && c.AIProvider != "mock" && c.OpenAIAPIKey == "" && c.AnthropicAPIKey == "" && c.GoogleAPIKey == "" {
+		errors = append(errors, "at least one AI provider API key required")
+	}
+
+	switch c.AIProvider {
+	case "openai":
+		if c.OpenAIAPIKey == "" {
+			errors = append(errors, "OPENAI_API_KEY is required when AI_PROVIDER is 'openai'")
+		}
+	case "anthropic":
+		if c.AnthropicAPIKey == "" {
+			errors = append(errors, "ANTHROPIC_API_KEY is required when AI_PROVIDER is 'anthropic'")
+		}
+	case "google":
+		if c.GoogleAPIKey == "" {
+			errors = append(errors, "GOOGLE_API_KEY is required when AI_PROVIDER is 'google'")
+		}
+	case "local":
+		if c.LocalAIBaseURL == "" {
+			errors = append(errors, "LOCAL_AI_BASE_URL is required when AI_PROVIDER is 'local'")
+		}
+	case "mock":
+	default:
+		errors = append(errors, "AI_PROVIDER must be 'openai', 'anthropic', 'google', 'local', or 'mock'")
+	}
+
+	switch c.MockAIFailure {
+	case "", "rate_limit", "quota", "unavailable", "malformed_json":
+	default:
+		errors = append(errors, "MOCK_AI_FAILURE must be 'rate_limit', 'quota', 'unavailable', or 'malformed_json'")
+	}
+	if c.MockAILatencyMs < 0 || c.MockAIFailureEvery < 0 {
+		errors = append(errors, "MOCK_AI_LATENCY_MS and MOCK_AI_FAILURE_EVERY must not be negative")
+	}
+
+	if c.AIPromptCandidatePercent < 0 || c.AIPromptCandidatePercent > 100 {
+		errors = append(errors, "AI_PROMPT_CANDIDATE_PERCENT must be between 0 and 100")
+	}
+	if c.AIPromptCandidatePercent > 0 && c.AIPromptCandidate == "" {
+		errors = append(errors, "AI_PROMPT_CANDIDATE is required when AI_PROMPT_CANDIDATE_PERCENT is set")
+	}
+
+	for _, spec := range c.AIFallbackProviders {
+		if msg := c.validateProviderSpec(spec); msg != "" {
+			errors = append(errors, "AI_FALLBACK_PROVIDERS: "+msg)
+		}
+	}
+	for operation, spec := range c.AIRoutes {
+		if msg := c.validateProviderSpec(spec); msg != "" {
+			errors = append(errors, fmt.Sprintf("AI_ROUTES[%s]: %s", operation, msg))
+		}
+	}
+
+	if c.AIRequestsPerMinute < 0 || c.AITokensPerMinute < 0 || c.AIMaxConcurrency < 0 {
+		errors = append(errors, "AI_REQUESTS_PER_MINUTE, AI_TOKENS_PER_MINUTE and AI_MAX_CONCURRENCY must not be negative")
+	}
+	if c.AIRateLimitMaxWaitSeconds < 0 {
+		errors = append(errors, "AI_RATE_LIMIT_MAX_WAIT_SECONDS must not be negative")
+	}
+
+	if c.ExportArchiveMaxMB < 0 {
+		errors = append(errors, "EXPORT_ARCHIVE_MAX_MB must not be negative")
+	}
+
+	if c.AuthAdminKey != "" && len(c.AuthAdminKey) < 32 {
+		errors = append(errors, "AUTH_ADMIN_KEY must be at least 32 characters")
+	}
+	if c.AuthSessionTTLHours < 0 {
+		errors = append(errors, "AUTH_SESSION_TTL_HOURS must not be negative")
+	}
+	switch c.AuthUserRole {
+	case "", "viewer", "editor":
+	default:
+		errors = append(errors, "AUTH_USER_ROLE must be viewer or editor")
+	}
+	if c.OIDCIssuerURL != "" {
+		if c.OIDCClientID == "" || c.OIDCRedirectURL == "" {
+			errors = append(errors, "OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_ISSUER_URL is set")
+		}
+		if !slices.Contains(c.OIDCScopes, "openid") {
+			errors = append(errors, "OIDC_SCOPES must include openid")
+		}
+	}
+	switch c.OIDCDefaultRole {
+	case "", "viewer", "editor", "admin":
+	default:
+		errors = append(errors, "OIDC_DEFAULT_ROLE must be viewer, editor or admin")
+	}
+	if c.RateLimitPerMinute < 0 || c.RateLimitFetchPerMinute < 0 || c.RateLimitAIPerMinute < 0 || c.RateLimitAuthPerMinute < 0 {
+		errors = append(errors, "RATE_LIMIT_PER_MINUTE, RATE_LIMIT_FETCH_PER_MINUTE, RATE_LIMIT_AI_PER_MINUTE and RATE_LIMIT_AUTH_PER_MINUTE must not be negative")
+	}
+	if c.RateLimitStore != "" && c.RateLimitStore != "memory" && c.RateLimitStore != "postgres" {
+		errors = append(errors, "RATE_LIMIT_STORE must be memory or postgres")
+	}
+	if c.TracingEndpoint != "" {
+		if endpoint, err := url.Parse(c.TracingEndpoint); err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			errors = append(errors, "TRACING_ENDPOINT must be an http(s) URL")
+		}
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		errors = append(errors, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	errors = append(errors, c.loggingErrors()...)
+
+	// Return combined errors if any
+	if len(errors) > 0 {
+		return validationError(errors)
+	}
+
+	return nil
+}
+
+// databaseErrors lists the problems with the database settings.
+func (c *Config) databaseErrors() []string {
 	var errors []string
 
 	// Check required fields
@@ -123,45 +516,90 @@ func (c *Config) Validate() error {
 		errors = append(errors, "DB_PORT must be between 1 and 65535")
 	}
 
-	if c.APIPort <= 0 || c.APIPort > 65535 {
-		errors = append(errors, "API_PORT must be between 1 and 65535")
+	return errors
+}
+
+// loggingErrors lists the problems with the logging settings.
+func (c *Config) loggingErrors() []string {
+	var errors []string
+	if c.LogLevel != "" && !slices.Contains([]string{"debug", "info", "warn", "error"}, c.LogLevel) {
+		errors = append(errors, "LOG_LEVEL must be debug, info, warn or error")
+	}
+	if c.LogFormat != "" && c.LogFormat != "json" && c.LogFormat != "text" {
+		errors = append(errors, "LOG_FORMAT must be json or text")
 	}
+	return errors
+}
 
-	// AI configuration validation
-	if c.OpenAIAPIKey == "" && c.AnthropicAPIKey == "" && c.GoogleAPIKey == "" {
-		errors = append(errors, "at least one AI provider API key required")
+// validationError combines validation problems into one error.
+func validationError(errors []string) error {
+	errorMsg := "validation errors: "
+	for i, err := range errors {
+		if i > 0 {
+			errorMsg += "; "
+		}
+		errorMsg += err
 	}
+	return fmt.Errorf("%s", errorMsg)
+}
 
-	switch c.AIProvider {
+// ParseProviderSpec splits a "provider[:model]" spec. The model is empty when not specified.
+func ParseProviderSpec(spec string) (provider, model string) {
+	provider, model, _ = strings.Cut(strings.TrimSpace(spec), ":")
+	return strings.ToLower(strings.TrimSpace(provider)), strings.TrimSpace(model)
+}
+
+// APIKeyFor returns the configured API key for a provider name.
+func (c *Config) APIKeyFor(provider string) string {
+	switch provider {
 	case "openai":
-		if c.OpenAIAPIKey == "" {
-			errors = append(errors, "OPENAI_API_KEY is required when AI_PROVIDER is 'openai'")
-		}
+		return c.OpenAIAPIKey
 	case "anthropic":
-		if c.AnthropicAPIKey == "" {
-			errors = append(errors, "ANTHROPIC_API_KEY is required when AI_PROVIDER is 'anthropic'")
-		}
+		return c.AnthropicAPIKey
 	case "google":
-		if c.GoogleAPIKey == "" {
-			errors = append(errors, "GOOGLE_API_KEY is required when AI_PROVIDER is 'google'")
-		}
+		return c.GoogleAPIKey
+	case "local":
+		return c.LocalAIAPIKey
 	default:
-		errors = append(errors, "AI_PROVIDER must be 'openai', 'anthropic', or 'google'")
+		return ""
 	}
+}
 
-	// Return combined errors if any
-	if len(errors) > 0 {
-		errorMsg := "validation errors: "
-		for i, err := range errors {
-			if i > 0 {
-				errorMsg += "; "
-			}
-			errorMsg += err
-		}
-		return fmt.Errorf("%s", errorMsg)
+// BaseURLFor returns the configured endpoint for a provider name, or "" for the provider's own.
+func (c *Config) BaseURLFor(provider string) string {
+	if provider == "local" {
+		return c.LocalAIBaseURL
 	}
+	return ""
+}
 
-	return nil
+// defaultAIModel is the AI_MODEL default. Other providers than OpenAI fall back to their own
+// default model instead of inheriting "gpt-4".
+func defaultAIModel(provider string) string {
+	if provider == "openai" {
+		return "gpt-4"
+	}
+	return ""
+}
+
+func (c *Config) validateProviderSpec(spec string) string {
+	provider, _ := ParseProviderSpec(spec)
+	switch provider {
+	case "openai", "anthropic", "google":
+	case "local":
+		if c.LocalAIBaseURL == "" {
+			return fmt.Sprintf("provider %q has no LOCAL_AI_BASE_URL configured", provider)
+		}
+		return ""
+	case "mock":
+		return ""
+	default:
+		return fmt.Sprintf("unknown provider %q in %q", provider, spec)
+	}
+	if c.APIKeyFor(provider) == "" {
+		return fmt.Sprintf("provider %q has no API key configured", provider)
+	}
+	return ""
 }
 
 // ConnectionString builds a PostgreSQL connection string from the configuration
@@ -211,6 +649,184 @@ func getEnvFloatWithDefault(key string, defaultValue float64) (float64, error) {
 	return defaultValue, nil
 }
 
+// getEnvBoolWithDefault gets a boolean environment variable or returns a default value
+func getEnvBoolWithDefault(key string, defaultValue bool) (bool, error) {
+	if value := os.Getenv(key); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse %s as boolean: %w", key, err)
+		}
+		return parsed, nil
+	}
+	return defaultValue, nil
+}
+
+// loadAIRateLimits reads the default client-side AI limits and the per provider/model overrides
+// from AI_RATE_LIMITS, e.g. "openai:gpt-4=500/30000/4,anthropic=50/40000" where each value is
+// requests per minute, tokens per minute and an optional concurrency cap.
+func loadAIRateLimits(config *Config) error {
+	var err error
+	config.AIRequestsPerMinute, err = getEnvIntWithDefault("AI_REQUESTS_PER_MINUTE", 0)
+	if err != nil {
+		return fmt.Errorf("invalid AI_REQUESTS_PER_MINUTE: %w", err)
+	}
+
+	config.AITokensPerMinute, err = getEnvIntWithDefault("AI_TOKENS_PER_MINUTE", 0)
+	if err != nil {
+		return fmt.Errorf("invalid AI_TOKENS_PER_MINUTE: %w", err)
+	}
+
+	config.AIMaxConcurrency, err = getEnvIntWithDefault("AI_MAX_CONCURRENCY", 8)
+	if err != nil {
+		return fmt.Errorf("invalid AI_MAX_CONCURRENCY: %w", err)
+	}
+
+	config.AIRateLimitMaxWaitSeconds, err = getEnvIntWithDefault("AI_RATE_LIMIT_MAX_WAIT_SECONDS", 10)
+	if err != nil {
+		return fmt.Errorf("invalid AI_RATE_LIMIT_MAX_WAIT_SECONDS: %w", err)
+	}
+
+	overrides, err := getEnvMap("AI_RATE_LIMITS")
+	if err != nil {
+		return fmt.Errorf("invalid AI_RATE_LIMITS: %w", err)
+	}
+	config.AIRateLimits = make(map[string]AIRateLimit, len(overrides))
+	for key, value := range overrides {
+		limit, err := parseAIRateLimit(value)
+		if err != nil {
+			return fmt.Errorf("invalid AI_RATE_LIMITS entry %s: %w", key, err)
+		}
+		config.AIRateLimits[key] = limit
+	}
+	return nil
+}
+
+// loadAIPrices reads per-model token prices from AI_PRICES, e.g. "gpt-4o=2.5/10,claude-3-5-sonnet=3/15"
+// where each value is the USD price per million prompt and completion tokens.
+func loadAIPrices() (map[string]AIPrice, error) {
+	entries, err := getEnvMap("AI_PRICES")
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_PRICES: %w", err)
+	}
+
+	prices := make(map[string]AIPrice, len(entries))
+	for model, value := range entries {
+		parts := strings.Split(value, "/")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid AI_PRICES entry %s: expected prompt/completion, got %q", model, value)
+		}
+		prompt, promptErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		completion, completionErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if promptErr != nil || completionErr != nil || prompt < 0 || completion < 0 {
+			return nil, fmt.Errorf("invalid AI_PRICES entry %s: expected non-negative numbers, got %q", model, value)
+		}
+		prices[model] = AIPrice{PromptPerMillion: prompt, CompletionPerMillion: completion}
+	}
+	return prices, nil
+}
+
+// getEnvAIBudget parses budget caps such as "daily_tokens=2000000,monthly_usd=300". Keys are
+// daily_tokens, monthly_tokens, daily_usd and monthly_usd.
+func getEnvAIBudget(key string) (AIBudget, error) {
+	entries, err := getEnvMap(key)
+	if err != nil {
+		return AIBudget{}, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	var budget AIBudget
+	for name, value := range entries {
+		switch name {
+		case "daily_tokens", "monthly_tokens":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || n < 0 {
+				return AIBudget{}, fmt.Errorf("invalid %s: %s must be a non-negative integer, got %q", key, name, value)
+			}
+			if name == "daily_tokens" {
+				budget.DailyTokens = n
+			} else {
+				budget.MonthlyTokens = n
+			}
+		case "daily_usd", "monthly_usd":
+			f, err := strconv.ParseFloat(strings.TrimPrefix(value, "$"), 64)
+			if err != nil || f < 0 {
+				return AIBudget{}, fmt.Errorf("invalid %s: %s must be a non-negative amount, got %q", key, name, value)
+			}
+			if name == "daily_usd" {
+				budget.DailyUSD = f
+			} else {
+				budget.MonthlyUSD = f
+			}
+		default:
+			return AIBudget{}, fmt.Errorf("invalid %s: unknown cap %q (use daily_tokens, monthly_tokens, daily_usd or monthly_usd)", key, name)
+		}
+	}
+	return budget, nil
+}
+
+// parseAIRateLimit parses "rpm/tpm[/concurrency]".
+func parseAIRateLimit(value string) (AIRateLimit, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return AIRateLimit{}, fmt.Errorf("expected rpm/tpm[/concurrency], got %q", value)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 0 {
+			return AIRateLimit{}, fmt.Errorf("expected non-negative integers, got %q", value)
+		}
+		numbers[i] = n
+	}
+	return AIRateLimit{RequestsPerMinute: numbers[0], TokensPerMinute: numbers[1], MaxConcurrency: numbers[2]}, nil
+}
+
+// getEnvList splits a comma separated environment variable, dropping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if value := strings.TrimSpace(part); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvPrefixes parses a comma separated list of CIDR prefixes; a bare IP is a single address.
+func getEnvPrefixes(key string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range getEnvList(key) {
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// getEnvMap parses a comma separated list of key=value pairs
+func getEnvMap(key string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, entry := range getEnvList(key) {
+		name, value, ok := strings.Cut(entry, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("failed to parse %s entry %q as key=value", key, entry)
+		}
+		values[strings.ToLower(name)] = value
+	}
+	return values, nil
+}
+
 // MustLoad loads configuration and panics if it fails
 // Use this only in main() or init() functions where failure should be fatal
 func MustLoad() *Config {
@@ -240,6 +856,11 @@ func LoadWithEnvFile(envFile string) (*Config, error) {
 		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
 	}
 
+	config.DBAutoMigrate, err = getEnvBoolWithDefault("DB_AUTO_MIGRATE", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_AUTO_MIGRATE: %w", err)
+	}
+
 	config.APIPort, err = getEnvIntWithDefault("API_PORT", 8080)
 	if err != nil {
 		return nil, fmt.Errorf("invalid API_PORT: %w", err)
@@ -249,8 +870,28 @@ func LoadWithEnvFile(envFile string) (*Config, error) {
 	config.OpenAIAPIKey = os.Getenv("OPENAI_API_KEY")
 	config.AnthropicAPIKey = os.Getenv("ANTHROPIC_API_KEY")
 	config.GoogleAPIKey = os.Getenv("GOOGLE_API_KEY")
+	config.LocalAIBaseURL = getEnvWithDefault("LOCAL_AI_BASE_URL", services.DefaultLocalBaseURL)
+	config.LocalAIAPIKey = os.Getenv("LOCAL_AI_API_KEY")
 	config.AIProvider = getEnvWithDefault("AI_PROVIDER", "openai")
-	config.AIModel = getEnvWithDefault("AI_MODEL", "gpt-4")
+	config.AIModel = getEnvWithDefault("AI_MODEL", defaultAIModel(config.AIProvider))
+
+	config.MockAILatencyMs, err = getEnvIntWithDefault("MOCK_AI_LATENCY_MS", 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MOCK_AI_LATENCY_MS: %w", err)
+	}
+	config.MockAIFailure = strings.ToLower(strings.TrimSpace(os.Getenv("MOCK_AI_FAILURE")))
+	config.MockAIFailureEvery, err = getEnvIntWithDefault("MOCK_AI_FAILURE_EVERY", 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MOCK_AI_FAILURE_EVERY: %w", err)
+	}
+
+	config.AIPromptsDir = os.Getenv("AI_PROMPTS_DIR")
+	config.AIPromptVersion = getEnvWithDefault("AI_PROMPT_VERSION", "v1")
+	config.AIPromptCandidate = os.Getenv("AI_PROMPT_CANDIDATE")
+	config.AIPromptCandidatePercent, err = getEnvIntWithDefault("AI_PROMPT_CANDIDATE_PERCENT", 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_PROMPT_CANDIDATE_PERCENT: %w", err)
+	}
 
 	config.AIMaxTokens, err = getEnvIntWithDefault("AI_MAX_TOKENS", 4000)
 	if err != nil {
@@ -262,6 +903,101 @@ func LoadWithEnvFile(envFile string) (*Config, error) {
 		return nil, fmt.Errorf("invalid AI_TEMPERATURE: %w", err)
 	}
 
+	config.AIFallbackProviders = getEnvList("AI_FALLBACK_PROVIDERS")
+	config.AIRoutes, err = getEnvMap("AI_ROUTES")
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_ROUTES: %w", err)
+	}
+
+	if err := loadAIRateLimits(config); err != nil {
+		return nil, err
+	}
+
+	config.AIDistributedLocks, err = getEnvBoolWithDefault("AI_DISTRIBUTED_LOCKS", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_DISTRIBUTED_LOCKS: %w", err)
+	}
+
+	config.AIPrices, err = loadAIPrices()
+	if err != nil {
+		return nil, err
+	}
+
+	config.AIBudget, err = getEnvAIBudget("AI_BUDGET")
+	if err != nil {
+		return nil, err
+	}
+
+	config.AIKeyBudget, err = getEnvAIBudget("AI_KEY_BUDGET")
+	if err != nil {
+		return nil, err
+	}
+
+	config.ExportArchiveMaxMB, err = getEnvIntWithDefault("EXPORT_ARCHIVE_MAX_MB", 512)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORT_ARCHIVE_MAX_MB: %w", err)
+	}
+
+	config.AuthRequired, err = getEnvBoolWithDefault("AUTH_REQUIRED", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_REQUIRED: %w", err)
+	}
+	config.AuthAdminKey = os.Getenv("AUTH_ADMIN_KEY")
+	config.AuthSignupEnabled, err = getEnvBoolWithDefault("AUTH_SIGNUP_ENABLED", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_SIGNUP_ENABLED: %w", err)
+	}
+	config.AuthSessionTTLHours, err = getEnvIntWithDefault("AUTH_SESSION_TTL_HOURS", 720)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_SESSION_TTL_HOURS: %w", err)
+	}
+	config.AuthUserRole = strings.ToLower(strings.TrimSpace(getEnvWithDefault("AUTH_USER_ROLE", "editor")))
+	config.OIDCIssuerURL = strings.TrimSuffix(strings.TrimSpace(os.Getenv("OIDC_ISSUER_URL")), "/")
+	config.OIDCClientID = os.Getenv("OIDC_CLIENT_ID")
+	config.OIDCClientSecret = os.Getenv("OIDC_CLIENT_SECRET")
+	config.OIDCRedirectURL = os.Getenv("OIDC_REDIRECT_URL")
+	config.OIDCScopes = getEnvList("OIDC_SCOPES")
+	if len(config.OIDCScopes) == 0 {
+		config.OIDCScopes = []string{"openid", "email", "profile"}
+	}
+	config.OIDCGroupsClaim = getEnvWithDefault("OIDC_GROUPS_CLAIM", "groups")
+	config.OIDCAdminGroups = getEnvList("OIDC_ADMIN_GROUPS")
+	config.OIDCEditorGroups = getEnvList("OIDC_EDITOR_GROUPS")
+	config.OIDCViewerGroups = getEnvList("OIDC_VIEWER_GROUPS")
+	config.OIDCDefaultRole = strings.ToLower(strings.TrimSpace(os.Getenv("OIDC_DEFAULT_ROLE")))
+	config.OIDCPostLoginURL = os.Getenv("OIDC_POST_LOGIN_URL")
+	config.RateLimitPerMinute, err = getEnvIntWithDefault("RATE_LIMIT_PER_MINUTE", 120)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_PER_MINUTE: %w", err)
+	}
+	config.RateLimitFetchPerMinute, err = getEnvIntWithDefault("RATE_LIMIT_FETCH_PER_MINUTE", 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_FETCH_PER_MINUTE: %w", err)
+	}
+	config.RateLimitAIPerMinute, err = getEnvIntWithDefault("RATE_LIMIT_AI_PER_MINUTE", 20)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_AI_PER_MINUTE: %w", err)
+	}
+	config.RateLimitAuthPerMinute, err = getEnvIntWithDefault("RATE_LIMIT_AUTH_PER_MINUTE", 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_AUTH_PER_MINUTE: %w", err)
+	}
+	config.RateLimitStore = strings.ToLower(getEnvWithDefault("RATE_LIMIT_STORE", "memory"))
+	config.TrustedProxies, err = getEnvPrefixes("TRUSTED_PROXIES")
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
+	config.TracingEndpoint = strings.TrimSpace(os.Getenv("TRACING_ENDPOINT"))
+	config.TracingSampleRatio, err = getEnvFloatWithDefault("TRACING_SAMPLE_RATIO", 1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO: %w", err)
+	}
+	config.TracingServiceName = getEnvWithDefault("TRACING_SERVICE_NAME", "yt-transcript-downloader")
+
+	config.LogLevel = strings.ToLower(getEnvWithDefault("LOG_LEVEL", "info"))
+	config.LogFormat = strings.ToLower(getEnvWithDefault("LOG_FORMAT", "json"))
+
 	config.CORSAllowedOrigins = getCORSAllowedOrigins()
 
 	if err := config.Validate(); err != nil {
@@ -287,7 +1023,14 @@ func GetDefaults() *Config {
 		AIMaxTokens:   4000,
 		AITemperature: 0.7,
 
+		LocalAIBaseURL: services.DefaultLocalBaseURL,
+
+		AIMaxConcurrency:          8,
+		AIRateLimitMaxWaitSeconds: 10,
+
 		CORSAllowedOrigins: DefaultCORSOrigins(),
+
+		ExportArchiveMaxMB: 512,
 	}
 }
 
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// AdvisoryLocker serializes work across server instances with Postgres session advisory locks.
+// Each held lock pins one pooled connection until it is released.
+type AdvisoryLocker struct {
+	db DB
+}
+
+// NewAdvisoryLocker creates a new advisory locker
+func NewAdvisoryLocker(db DB) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db}
+}
+
+const (
+	acquireAdvisoryLockSQL = `SELECT pg_advisory_lock(hashtextextended($1, 0));`
+	releaseAdvisoryLockSQL = `SELECT pg_advisory_unlock(hashtextextended($1, 0));`
+)
+
+// Lock blocks until the lock for key is held or ctx ends. The returned function releases it.
+func (l *AdvisoryLocker) Lock(ctx context.Context, key string) (func(), error) {
+	if l == nil || l.db == nil {
+		return nil, errors.New("advisory locker is nil")
+	}
+	if key == "" {
+		return nil, errors.New("lock key is required")
+	}
+
+	conn, err := l.db.Acquire(ctx)
+	if err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, acquireAdvisoryLockSQL, key); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("acquire advisory lock: %w", err)
+	}
+
+	return func() {
+		// Unlock even when the caller's context is done; the lock would otherwise live as long
+		// as the pooled connection.
+		unlockCtx, cancel := withQueryTimeout(context.WithoutCancel(ctx))
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, releaseAdvisoryLockSQL, key); err != nil {
+			// Closing the session is the only other way to drop the lock. Back in the pool, the
+			// connection would keep it and every later Lock on key would wait for it.
+			slog.WarnContext(ctx, "failed to release advisory lock; closing connection", "lock", key, "error", err)
+			if err := conn.Discard(unlockCtx); err != nil {
+				slog.WarnContext(ctx, "failed to close connection holding advisory lock", "lock", key, "error", err)
+			}
+			return
+		}
+		conn.Release()
+	}, nil
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvisoryLocker_SerializesHolders(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	locker := NewAdvisoryLocker(database)
+
+	unlock, err := locker.Lock(ctx, "summary:t1:brief")
+	require.NoError(t, err)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	_, err = locker.Lock(waitCtx, "summary:t1:brief")
+	assert.Error(t, err, "second holder waits until its context ends")
+
+	other, err := locker.Lock(ctx, "summary:t1:detailed")
+	require.NoError(t, err, "different keys do not contend")
+	other()
+
+	unlock()
+	again, err := locker.Lock(ctx, "summary:t1:brief")
+	require.NoError(t, err)
+	again()
+}
+
+// lockConn records how a locker hands its connection back.
+type lockConn struct {
+	Conn
+	unlockErr error
+	released  bool
+	discarded bool
+}
+
+func (c *lockConn) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	if sql == releaseAdvisoryLockSQL {
+		return pgconn.CommandTag{}, c.unlockErr
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (c *lockConn) Release() { c.released = true }
+
+func (c *lockConn) Discard(context.Context) error {
+	c.discarded = true
+	return nil
+}
+
+type lockDB struct {
+	DB
+	conn *lockConn
+}
+
+func (d *lockDB) Acquire(context.Context) (Conn, error) { return d.conn, nil }
+
+func TestAdvisoryLocker_ClosesConnectionWhenUnlockFails(t *testing.T) {
+	conn := &lockConn{}
+	unlock, err := NewAdvisoryLocker(&lockDB{conn: conn}).Lock(context.Background(), "summary:t1:brief")
+	require.NoError(t, err)
+	unlock()
+	assert.True(t, conn.released)
+	assert.False(t, conn.discarded)
+
+	conn = &lockConn{unlockErr: context.DeadlineExceeded}
+	unlock, err = NewAdvisoryLocker(&lockDB{conn: conn}).Lock(context.Background(), "summary:t1:brief")
+	require.NoError(t, err)
+	unlock()
+	assert.False(t, conn.released, "a connection still holding the lock must not go back to the pool")
+	assert.True(t, conn.discarded)
+}
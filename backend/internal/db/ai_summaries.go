@@ -12,14 +12,16 @@ import (
 
 // AISummary represents an AI-generated summary stored in the database
 type AISummary struct {
-	ID           string
-	TranscriptID string
-	SummaryType  string
-	Content      SummaryContent
-	Model        string
-	TokensUsed   int
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID            string
+	TranscriptID  string
+	SummaryType   string
+	Content       SummaryContent
+	Model         string
+	TokensUsed    int
+	PromptVersion string
+	CreatedBy     string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
 
 // SummaryContent represents the structured JSON content of a summary
@@ -27,6 +29,7 @@ type SummaryContent struct {
 	Text      string    `json:"text"`
 	KeyPoints []string  `json:"key_points,omitempty"`
 	Sections  []Section `json:"sections,omitempty"`
+	Chapters  []Chapter `json:"chapters,omitempty"`
 }
 
 // Section represents a section within a summary
@@ -35,6 +38,13 @@ type Section struct {
 	Content string `json:"content"`
 }
 
+// Chapter represents a timestamped chapter within a chapters summary
+type Chapter struct {
+	Title   string `json:"title"`
+	StartMs int64  `json:"start_ms"`
+	EndMs   int64  `json:"end_ms"`
+}
+
 // AIExtraction represents extracted content from a transcript
 type AIExtraction struct {
 	ID             string
@@ -43,6 +53,8 @@ type AIExtraction struct {
 	Content        json.RawMessage
 	Model          string
 	TokensUsed     int
+	PromptVersion  string
+	CreatedBy      string
 	CreatedAt      time.Time
 }
 
@@ -68,20 +80,20 @@ func NewAISummaryRepository(db DB) *AISummaryRepository {
 }
 
 const insertAISummarySQL = `
-INSERT INTO ai_summaries (transcript_id, summary_type, content, model, tokens_used)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, transcript_id, summary_type, content, model, tokens_used, created_at, updated_at;
+INSERT INTO ai_summaries (transcript_id, summary_type, content, model, tokens_used, prompt_version, created_by)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, '')::uuid)
+RETURNING id, transcript_id, summary_type, content, model, tokens_used, COALESCE(prompt_version, ''), COALESCE(created_by::text, ''), created_at, updated_at;
 `
 
 const selectAISummarySQL = `
-SELECT id, transcript_id, summary_type, content, model, tokens_used, created_at, updated_at
+SELECT id, transcript_id, summary_type, content, model, tokens_used, COALESCE(prompt_version, ''), COALESCE(created_by::text, ''), created_at, updated_at
 FROM ai_summaries
 WHERE transcript_id = $1 AND summary_type = $2
 LIMIT 1;
 `
 
 const listAISummariesSQL = `
-SELECT id, transcript_id, summary_type, content, model, tokens_used, created_at, updated_at
+SELECT id, transcript_id, summary_type, content, model, tokens_used, COALESCE(prompt_version, ''), COALESCE(created_by::text, ''), created_at, updated_at
 FROM ai_summaries
 WHERE transcript_id = $1
 ORDER BY created_at DESC;
@@ -122,6 +134,8 @@ func (r *AISummaryRepository) CreateAISummary(ctx context.Context, summary *AISu
 		payload,
 		summary.Model,
 		summary.TokensUsed,
+		summary.PromptVersion,
+		summary.CreatedBy,
 	)
 
 	if err := scanAISummaryRow(row, summary); err != nil {
@@ -243,6 +257,8 @@ func scanAISummaryRow(row pgx.Row, summary *AISummary) error {
 		&contentBytes,
 		&summary.Model,
 		&summary.TokensUsed,
+		&summary.PromptVersion,
+		&summary.CreatedBy,
 		&summary.CreatedAt,
 		&summary.UpdatedAt,
 	); err != nil {
@@ -261,6 +277,8 @@ func scanAISummaryRows(rows pgx.Rows, summary *AISummary) error {
 		&contentBytes,
 		&summary.Model,
 		&summary.TokensUsed,
+		&summary.PromptVersion,
+		&summary.CreatedBy,
 		&summary.CreatedAt,
 		&summary.UpdatedAt,
 	); err != nil {
@@ -294,20 +312,20 @@ func NewAIExtractionRepository(db DB) *AIExtractionRepository {
 }
 
 const insertAIExtractionSQL = `
-INSERT INTO ai_extractions (transcript_id, extraction_type, content, model, tokens_used)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, transcript_id, extraction_type, content, model, tokens_used, created_at;
+INSERT INTO ai_extractions (transcript_id, extraction_type, content, model, tokens_used, prompt_version, created_by)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, '')::uuid)
+RETURNING id, transcript_id, extraction_type, content, model, tokens_used, COALESCE(prompt_version, ''), COALESCE(created_by::text, ''), created_at;
 `
 
 const selectAIExtractionSQL = `
-SELECT id, transcript_id, extraction_type, content, model, tokens_used, created_at
+SELECT id, transcript_id, extraction_type, content, model, tokens_used, COALESCE(prompt_version, ''), COALESCE(created_by::text, ''), created_at
 FROM ai_extractions
 WHERE transcript_id = $1 AND extraction_type = $2
 LIMIT 1;
 `
 
 const listAIExtractionsSQL = `
-SELECT id, transcript_id, extraction_type, content, model, tokens_used, created_at
+SELECT id, transcript_id, extraction_type, content, model, tokens_used, COALESCE(prompt_version, ''), COALESCE(created_by::text, ''), created_at
 FROM ai_extractions
 WHERE transcript_id = $1
 ORDER BY created_at DESC;
@@ -338,6 +356,8 @@ func (r *AIExtractionRepository) CreateAIExtraction(ctx context.Context, extract
 		extraction.Content,
 		extraction.Model,
 		extraction.TokensUsed,
+		extraction.PromptVersion,
+		extraction.CreatedBy,
 	)
 
 	if err := scanAIExtractionRow(row, extraction); err != nil {
@@ -436,6 +456,8 @@ func scanAIExtractionRow(row pgx.Row, extraction *AIExtraction) error {
 		&extraction.Content,
 		&extraction.Model,
 		&extraction.TokensUsed,
+		&extraction.PromptVersion,
+		&extraction.CreatedBy,
 		&extraction.CreatedAt,
 	)
 }
@@ -448,6 +470,8 @@ func scanAIExtractionRows(rows pgx.Rows, extraction *AIExtraction) error {
 		&extraction.Content,
 		&extraction.Model,
 		&extraction.TokensUsed,
+		&extraction.PromptVersion,
+		&extraction.CreatedBy,
 		&extraction.CreatedAt,
 	); err != nil {
 		return fmt.Errorf("scan ai extraction: %w", err)
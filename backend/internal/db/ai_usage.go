@@ -0,0 +1,265 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AIUsage is one AI provider call recorded in the usage ledger.
+type AIUsage struct {
+	ID               string
+	TranscriptID     string
+	ClientKey        string
+	Provider         string
+	Model            string
+	Operation        string
+	PromptVersion    string
+	PromptTokens     int
+	CompletionTokens int
+	LatencyMs        int64
+	CostUSD          float64
+	Success          bool
+	Error            string
+	CreatedAt        time.Time
+}
+
+// AIUsageQuery selects and groups ledger rows. GroupBy accepts the AIUsageGroup* dimensions;
+// without any the result is a single total row. To is exclusive.
+type AIUsageQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy []string
+}
+
+// Usage ledger grouping dimensions.
+const (
+	AIUsageGroupDay           = "day"
+	AIUsageGroupProvider      = "provider"
+	AIUsageGroupModel         = "model"
+	AIUsageGroupOperation     = "operation"
+	AIUsageGroupPromptVersion = "prompt_version"
+)
+
+// aiUsageGroupColumns maps grouping dimensions to SQL in a fixed output order.
+var aiUsageGroupColumns = []struct {
+	name string
+	expr string
+}{
+	{AIUsageGroupDay, "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')"},
+	{AIUsageGroupProvider, "provider"},
+	{AIUsageGroupModel, "model"},
+	{AIUsageGroupOperation, "operation"},
+	{AIUsageGroupPromptVersion, "COALESCE(prompt_version, '')"},
+}
+
+// IsAIUsageGroup reports whether name is a supported grouping dimension.
+func IsAIUsageGroup(name string) bool {
+	for _, column := range aiUsageGroupColumns {
+		if column.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AIUsageAggregate sums the ledger rows of one group. Dimensions that were not grouped are empty.
+type AIUsageAggregate struct {
+	Day              string
+	Provider         string
+	Model            string
+	Operation        string
+	PromptVersion    string
+	Calls            int64
+	FailedCalls      int64
+	PromptTokens     int64
+	CompletionTokens int64
+	CostUSD          float64
+	AvgLatencyMs     float64
+}
+
+// AIUsageRepository handles database operations for the AI usage ledger
+type AIUsageRepository struct {
+	db DB
+}
+
+// NewAIUsageRepository creates a new AI usage repository
+func NewAIUsageRepository(db DB) *AIUsageRepository {
+	return &AIUsageRepository{db: db}
+}
+
+const insertAIUsageSQL = `
+INSERT INTO ai_usage (transcript_id, provider, model, operation, prompt_tokens, completion_tokens, latency_ms, cost_usd, success, error, client_key, prompt_version)
+VALUES (NULLIF($1, '')::uuid, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''), NULLIF($12, ''))
+RETURNING id, created_at;
+`
+
+// CreateAIUsage appends a call to the ledger.
+func (r *AIUsageRepository) CreateAIUsage(ctx context.Context, usage *AIUsage) error {
+	if r == nil || r.db == nil {
+		return errors.New("ai usage repository is nil")
+	}
+	if usage == nil {
+		return errors.New("usage is nil")
+	}
+	if usage.Provider == "" || usage.Operation == "" {
+		return errors.New("provider and operation are required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	err := r.db.QueryRow(queryCtx, insertAIUsageSQL,
+		usage.TranscriptID,
+		usage.Provider,
+		usage.Model,
+		usage.Operation,
+		usage.PromptTokens,
+		usage.CompletionTokens,
+		usage.LatencyMs,
+		usage.CostUSD,
+		usage.Success,
+		usage.Error,
+		usage.ClientKey,
+		usage.PromptVersion,
+	).Scan(&usage.ID, &usage.CreatedAt)
+	if err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("database connection failed: %w", err)
+		}
+		return fmt.Errorf("create ai usage: %w", err)
+	}
+	return nil
+}
+
+// AggregateAIUsage sums calls, tokens, cost and latency per requested group.
+func (r *AIUsageRepository) AggregateAIUsage(ctx context.Context, query AIUsageQuery) ([]*AIUsageAggregate, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("ai usage repository is nil")
+	}
+
+	grouped := make(map[string]bool, len(query.GroupBy))
+	for _, name := range query.GroupBy {
+		if !IsAIUsageGroup(name) {
+			return nil, fmt.Errorf("unsupported usage grouping %q", name)
+		}
+		grouped[name] = true
+	}
+
+	selects := make([]string, 0, len(aiUsageGroupColumns))
+	var groupBy []string
+	for _, column := range aiUsageGroupColumns {
+		if grouped[column.name] {
+			selects = append(selects, column.expr)
+			groupBy = append(groupBy, column.expr)
+		} else {
+			selects = append(selects, "''")
+		}
+	}
+
+	sql := `SELECT ` + strings.Join(selects, ", ") + `,
+       COUNT(*),
+       COUNT(*) FILTER (WHERE NOT success),
+       COALESCE(SUM(prompt_tokens), 0),
+       COALESCE(SUM(completion_tokens), 0),
+       COALESCE(SUM(cost_usd), 0)::float8,
+       COALESCE(AVG(latency_ms), 0)::float8
+FROM ai_usage
+WHERE ($1::timestamptz IS NULL OR created_at >= $1)
+  AND ($2::timestamptz IS NULL OR created_at < $2)`
+	if len(groupBy) > 0 {
+		sql += "\nGROUP BY " + strings.Join(groupBy, ", ") + "\nORDER BY " + strings.Join(groupBy, ", ")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.Query(queryCtx, sql, nullableTime(query.From), nullableTime(query.To))
+	if err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("aggregate ai usage: %w", err)
+	}
+	defer rows.Close()
+
+	aggregates := make([]*AIUsageAggregate, 0)
+	for rows.Next() {
+		aggregate := &AIUsageAggregate{}
+		if err := rows.Scan(
+			&aggregate.Day,
+			&aggregate.Provider,
+			&aggregate.Model,
+			&aggregate.Operation,
+			&aggregate.PromptVersion,
+			&aggregate.Calls,
+			&aggregate.FailedCalls,
+			&aggregate.PromptTokens,
+			&aggregate.CompletionTokens,
+			&aggregate.CostUSD,
+			&aggregate.AvgLatencyMs,
+		); err != nil {
+			return nil, fmt.Errorf("scan ai usage aggregate: %w", err)
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+
+	if err := rows.Err(); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("iterate ai usage aggregates: %w", err)
+	}
+
+	// An ungrouped query over an empty ledger still returns one all-zero row; drop it.
+	if len(groupBy) == 0 && len(aggregates) == 1 && aggregates[0].Calls == 0 {
+		return aggregates[:0], nil
+	}
+	return aggregates, nil
+}
+
+// AISpend is the ledger total since the start of the current day and month.
+type AISpend struct {
+	DailyTokens    int64
+	DailyCostUSD   float64
+	MonthlyTokens  int64
+	MonthlyCostUSD float64
+}
+
+const selectAISpendSQL = `
+SELECT COALESCE(SUM(prompt_tokens + completion_tokens) FILTER (WHERE created_at >= $1), 0),
+       COALESCE(SUM(cost_usd) FILTER (WHERE created_at >= $1), 0)::float8,
+       COALESCE(SUM(prompt_tokens + completion_tokens), 0),
+       COALESCE(SUM(cost_usd), 0)::float8
+FROM ai_usage
+WHERE created_at >= $2
+  AND ($3::text = '' OR client_key = $3)
+`
+
+// GetAISpend sums tokens and cost since dayStart and monthStart for one client key, or for all
+// callers when clientKey is empty.
+func (r *AIUsageRepository) GetAISpend(ctx context.Context, clientKey string, dayStart, monthStart time.Time) (*AISpend, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("ai usage repository is nil")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	spend := &AISpend{}
+	err := r.db.QueryRow(queryCtx, selectAISpendSQL, dayStart, monthStart, clientKey).Scan(
+		&spend.DailyTokens,
+		&spend.DailyCostUSD,
+		&spend.MonthlyTokens,
+		&spend.MonthlyCostUSD,
+	)
+	if err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("get ai spend: %w", err)
+	}
+	return spend, nil
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIUsageRepository_RecordAndAggregate(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	applyMigrations(t, database)
+
+	videoRepo := NewVideoRepository(database)
+	transcriptRepo := NewTranscriptRepository(database)
+	usageRepo := NewAIUsageRepository(database)
+
+	video := &Video{YouTubeID: uuid.NewString(), Title: "Usage Test"}
+	require.NoError(t, videoRepo.SaveVideo(ctx, video))
+	transcript := &Transcript{VideoID: video.ID, Language: "en", Content: TranscriptSegments{{Text: "hi"}}}
+	require.NoError(t, transcriptRepo.SaveTranscript(ctx, transcript))
+
+	entries := []*AIUsage{
+		{TranscriptID: transcript.ID, Provider: "openai", Model: "gpt-4", Operation: "summarize", PromptVersion: "v1", PromptTokens: 1000, CompletionTokens: 200, LatencyMs: 1200, CostUSD: 0.042, Success: true},
+		{TranscriptID: transcript.ID, Provider: "openai", Model: "gpt-4", Operation: "answer", PromptVersion: "v2", PromptTokens: 500, CompletionTokens: 100, LatencyMs: 800, CostUSD: 0.021, Success: true},
+		{Provider: "anthropic", Model: "claude-3-5-sonnet-20241022", Operation: "summarize", LatencyMs: 300, Success: false, Error: "rate limited"},
+	}
+	for _, entry := range entries {
+		require.NoError(t, usageRepo.CreateAIUsage(ctx, entry))
+		assert.NotEmpty(t, entry.ID)
+	}
+
+	totals, err := usageRepo.AggregateAIUsage(ctx, AIUsageQuery{})
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.Equal(t, int64(3), totals[0].Calls)
+	assert.Equal(t, int64(1), totals[0].FailedCalls)
+	assert.Equal(t, int64(1500), totals[0].PromptTokens)
+	assert.Equal(t, int64(300), totals[0].CompletionTokens)
+	assert.InDelta(t, 0.063, totals[0].CostUSD, 1e-9)
+
+	byModel, err := usageRepo.AggregateAIUsage(ctx, AIUsageQuery{GroupBy: []string{AIUsageGroupModel, AIUsageGroupDay}})
+	require.NoError(t, err)
+	require.Len(t, byModel, 2)
+	assert.Equal(t, "claude-3-5-sonnet-20241022", byModel[0].Model)
+	assert.Equal(t, "gpt-4", byModel[1].Model)
+	assert.Equal(t, int64(2), byModel[1].Calls)
+	assert.Equal(t, time.Now().UTC().Format(time.DateOnly), byModel[1].Day)
+	assert.Empty(t, byModel[1].Operation)
+
+	byPrompt, err := usageRepo.AggregateAIUsage(ctx, AIUsageQuery{GroupBy: []string{AIUsageGroupPromptVersion}})
+	require.NoError(t, err)
+	require.Len(t, byPrompt, 3)
+	assert.Equal(t, []string{"", "v1", "v2"}, []string{byPrompt[0].PromptVersion, byPrompt[1].PromptVersion, byPrompt[2].PromptVersion})
+
+	future, err := usageRepo.AggregateAIUsage(ctx, AIUsageQuery{From: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, future)
+
+	_, err = usageRepo.AggregateAIUsage(ctx, AIUsageQuery{GroupBy: []string{"user"}})
+	assert.Error(t, err)
+}
+
+func TestAIUsageRepository_GetAISpend(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	applyMigrations(t, database)
+
+	usageRepo := NewAIUsageRepository(database)
+	entries := []*AIUsage{
+		{ClientKey: "key-a", Provider: "openai", Model: "gpt-4", Operation: "summarize", PromptTokens: 1000, CompletionTokens: 200, CostUSD: 0.042, Success: true},
+		{ClientKey: "key-b", Provider: "openai", Model: "gpt-4", Operation: "answer", PromptTokens: 500, CompletionTokens: 100, CostUSD: 0.021, Success: true},
+		{Provider: "openai", Model: "gpt-4", Operation: "extract", PromptTokens: 100, Success: true},
+	}
+	for _, entry := range entries {
+		require.NoError(t, usageRepo.CreateAIUsage(ctx, entry))
+	}
+	_, err = database.Exec(ctx, `UPDATE ai_usage SET created_at = NOW() - INTERVAL '3 days' WHERE operation = 'answer'`)
+	require.NoError(t, err)
+
+	dayStart := time.Now().Add(-time.Hour)
+	monthStart := time.Now().AddDate(0, 0, -10)
+
+	all, err := usageRepo.GetAISpend(ctx, "", dayStart, monthStart)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1300), all.DailyTokens)
+	assert.Equal(t, int64(1900), all.MonthlyTokens)
+	assert.InDelta(t, 0.042, all.DailyCostUSD, 1e-9)
+	assert.InDelta(t, 0.063, all.MonthlyCostUSD, 1e-9)
+
+	keyB, err := usageRepo.GetAISpend(ctx, "key-b", dayStart, monthStart)
+	require.NoError(t, err)
+	assert.Zero(t, keyB.DailyTokens)
+	assert.Equal(t, int64(600), keyB.MonthlyTokens)
+}
@@ -0,0 +1,240 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// APIKey is a client credential. Only the SHA-256 digest of the key is stored; KeyPrefix keeps
+// its first characters so an administrator can tell keys apart.
+type APIKey struct {
+	ID         string
+	Name       string
+	KeyPrefix  string
+	KeyHash    string
+	Scopes     []string
+	CreatedAt  time.Time
+	RotatedAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// APIKeyRepository handles database operations for API keys
+type APIKeyRepository struct {
+	db DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, name, key_prefix, key_hash, scopes, created_at, rotated_at, last_used_at, revoked_at`
+
+const insertAPIKeySQL = `
+INSERT INTO api_keys (name, key_prefix, key_hash, scopes)
+VALUES ($1, $2, $3, $4)
+RETURNING ` + apiKeyColumns + `;
+`
+
+const selectActiveAPIKeyByHashSQL = `
+SELECT ` + apiKeyColumns + `
+FROM api_keys
+WHERE key_hash = $1 AND revoked_at IS NULL
+LIMIT 1;
+`
+
+const selectAPIKeysSQL = `
+SELECT ` + apiKeyColumns + `
+FROM api_keys
+ORDER BY created_at DESC;
+`
+
+const revokeAPIKeySQL = `
+UPDATE api_keys
+SET revoked_at = COALESCE(revoked_at, NOW())
+WHERE id = $1
+RETURNING ` + apiKeyColumns + `;
+`
+
+const rotateAPIKeySQL = `
+UPDATE api_keys
+SET key_prefix = $2, key_hash = $3, rotated_at = NOW()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING ` + apiKeyColumns + `;
+`
+
+const touchAPIKeySQL = `
+UPDATE api_keys SET last_used_at = $2 WHERE id = $1;
+`
+
+// CreateAPIKey stores a new key. Name and KeyHash are required.
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	if r == nil || r.db == nil {
+		return errors.New("api key repository is nil")
+	}
+	if key == nil {
+		return errors.New("api key is nil")
+	}
+	if key.Name == "" || key.KeyHash == "" {
+		return errors.New("name and key hash are required")
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRow(queryCtx, insertAPIKeySQL, key.Name, key.KeyPrefix, key.KeyHash, key.Scopes)
+	if err := scanAPIKeyRow(row, key); err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("database connection failed: %w", err)
+		}
+		if isDuplicateKeyError(err) {
+			return fmt.Errorf("api key already exists: %w", err)
+		}
+		return fmt.Errorf("create api key: %w", err)
+	}
+	return nil
+}
+
+// GetActiveAPIKeyByHash finds the key with the given digest unless it was revoked.
+func (r *APIKeyRepository) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("api key repository is nil")
+	}
+	if keyHash == "" {
+		return nil, errors.New("key hash is required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	key := &APIKey{}
+	if err := scanAPIKeyRow(r.db.QueryRow(queryCtx, selectActiveAPIKeyByHashSQL, keyHash), key); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns all keys, including revoked ones, newest first.
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("api key repository is nil")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.Query(queryCtx, selectAPIKeysSQL)
+	if err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*APIKey, 0)
+	for rows.Next() {
+		key := &APIKey{}
+		if err := scanAPIKeyRow(rows, key); err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("iterate api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey disables a key for good. Revoking a revoked key keeps its original revocation time.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id string) (*APIKey, error) {
+	return r.updateAPIKey(ctx, "revoke api key", revokeAPIKeySQL, id)
+}
+
+// RotateAPIKey replaces the secret of an active key, keeping its ID, name and scopes so usage and
+// budgets stay attributed to it. The previous secret stops working immediately.
+func (r *APIKeyRepository) RotateAPIKey(ctx context.Context, id, keyPrefix, keyHash string) (*APIKey, error) {
+	if keyHash == "" {
+		return nil, errors.New("key hash is required")
+	}
+	return r.updateAPIKey(ctx, "rotate api key", rotateAPIKeySQL, id, keyPrefix, keyHash)
+}
+
+func (r *APIKeyRepository) updateAPIKey(ctx context.Context, operation, sql, id string, args ...any) (*APIKey, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("api key repository is nil")
+	}
+	if id == "" {
+		return nil, errors.New("api key id is required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	key := &APIKey{}
+	if err := scanAPIKeyRow(r.db.QueryRow(queryCtx, sql, append([]any{id}, args...)...), key); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		if isDuplicateKeyError(err) {
+			return nil, fmt.Errorf("api key already exists: %w", err)
+		}
+		return nil, fmt.Errorf("%s: %w", operation, err)
+	}
+	return key, nil
+}
+
+// TouchAPIKey records that a key was used at usedAt.
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("api key repository is nil")
+	}
+	if id == "" {
+		return errors.New("api key id is required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.Exec(queryCtx, touchAPIKeySQL, id, usedAt); err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("database connection failed: %w", err)
+		}
+		return fmt.Errorf("touch api key: %w", err)
+	}
+	return nil
+}
+
+func scanAPIKeyRow(row pgx.Row, key *APIKey) error {
+	return row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.KeyPrefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.CreatedAt,
+		&key.RotatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+}
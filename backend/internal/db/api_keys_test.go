@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRepository_Lifecycle(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	applyMigrations(t, database)
+
+	repo := NewAPIKeyRepository(database)
+	hash := "a" + time.Now().Format("150405.000000000")
+	key := &APIKey{Name: "ci", KeyPrefix: "ytk_abcdefgh", KeyHash: hash, Scopes: []string{"transcripts:read", "ai:write"}}
+	require.NoError(t, repo.CreateAPIKey(ctx, key))
+	assert.NotEmpty(t, key.ID)
+	assert.Nil(t, key.LastUsedAt)
+
+	duplicate := &APIKey{Name: "other", KeyHash: hash}
+	assert.ErrorContains(t, repo.CreateAPIKey(ctx, duplicate), "api key already exists")
+
+	found, err := repo.GetActiveAPIKeyByHash(ctx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, found.ID)
+	assert.Equal(t, []string{"transcripts:read", "ai:write"}, found.Scopes)
+
+	usedAt := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.TouchAPIKey(ctx, key.ID, usedAt))
+
+	rotated, err := repo.RotateAPIKey(ctx, key.ID, "ytk_ijklmnop", hash+"-rotated")
+	require.NoError(t, err)
+	assert.Equal(t, "ytk_ijklmnop", rotated.KeyPrefix)
+	require.NotNil(t, rotated.RotatedAt)
+	require.NotNil(t, rotated.LastUsedAt)
+	assert.True(t, usedAt.Equal(*rotated.LastUsedAt))
+	_, err = repo.GetActiveAPIKeyByHash(ctx, hash)
+	assert.ErrorIs(t, err, ErrNotFound, "the old secret no longer matches")
+
+	revoked, err := repo.RevokeAPIKey(ctx, key.ID)
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+	_, err = repo.GetActiveAPIKeyByHash(ctx, hash+"-rotated")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.RotateAPIKey(ctx, key.ID, "ytk_qrstuvwx", hash+"-again")
+	assert.ErrorIs(t, err, ErrNotFound, "revoked keys cannot be rotated")
+
+	again, err := repo.RevokeAPIKey(ctx, key.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked.RevokedAt.Equal(*again.RevokedAt), "revoking twice keeps the first time")
+
+	keys, err := repo.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, key.ID, keys[0].ID)
+}
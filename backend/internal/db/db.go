@@ -44,6 +44,7 @@ type Conn interface {
 
 	// Connection management
 	Release()
+	Discard(ctx context.Context) error
 	Ping(ctx context.Context) error
 
 	// Batch operations
@@ -149,6 +150,12 @@ func (c *PostgresConn) Release() {
 	c.conn.Release()
 }
 
+// Discard closes the connection instead of returning it to the pool, dropping any session
+// state such as advisory locks
+func (c *PostgresConn) Discard(ctx context.Context) error {
+	return c.conn.Hijack().Close(ctx)
+}
+
 // Ping verifies the connection is alive
 func (c *PostgresConn) Ping(ctx context.Context) error {
 	return c.conn.Ping(ctx)
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LibraryEntry is a transcript in a user's library with its video.
+type LibraryEntry struct {
+	TranscriptID string
+	Language     string
+	Video        Video
+	AddedAt      time.Time
+}
+
+// LibraryRepository handles the per-user libraries. Transcripts are shared between users; a
+// library only records which of them each user added.
+type LibraryRepository struct {
+	db DB
+}
+
+// NewLibraryRepository creates a new library repository
+func NewLibraryRepository(db DB) *LibraryRepository {
+	return &LibraryRepository{db: db}
+}
+
+const insertLibraryEntrySQL = `
+INSERT INTO user_transcripts (user_id, transcript_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, transcript_id) DO NOTHING;
+`
+
+const deleteLibraryEntrySQL = `
+DELETE FROM user_transcripts WHERE user_id = $1 AND transcript_id = $2;
+`
+
+const selectLibraryEntryExistsSQL = `
+SELECT EXISTS (SELECT 1 FROM user_transcripts WHERE user_id = $1 AND transcript_id = $2);
+`
+
+const listLibraryEntriesSQL = `
+SELECT t.id, t.language, ut.created_at,
+       v.id, v.youtube_id, v.title, v.channel, v.duration, COALESCE(v.created_by::text, ''), v.created_at
+FROM user_transcripts ut
+JOIN transcripts t ON t.id = ut.transcript_id
+JOIN videos v ON v.id = t.video_id
+WHERE ut.user_id = $1
+ORDER BY ut.created_at DESC, t.id ASC;
+`
+
+// AddToLibrary adds a transcript to a user's library. Adding it again is a no-op.
+func (r *LibraryRepository) AddToLibrary(ctx context.Context, userID, transcriptID string) error {
+	if r == nil || r.db == nil {
+		return errors.New("library repository is nil")
+	}
+	if userID == "" || transcriptID == "" {
+		return errors.New("user id and transcript id are required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.Exec(queryCtx, insertLibraryEntrySQL, userID, transcriptID); err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("database connection failed: %w", err)
+		}
+		return fmt.Errorf("add library entry: %w", err)
+	}
+	return nil
+}
+
+// RemoveFromLibrary removes a transcript from a user's library; the shared transcript stays.
+func (r *LibraryRepository) RemoveFromLibrary(ctx context.Context, userID, transcriptID string) error {
+	if r == nil || r.db == nil {
+		return errors.New("library repository is nil")
+	}
+	if userID == "" || transcriptID == "" {
+		return errors.New("user id and transcript id are required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.db.Exec(queryCtx, deleteLibraryEntrySQL, userID, transcriptID)
+	if err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("database connection failed: %w", err)
+		}
+		return fmt.Errorf("remove library entry: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// InLibrary reports whether a transcript is in a user's library.
+func (r *LibraryRepository) InLibrary(ctx context.Context, userID, transcriptID string) (bool, error) {
+	if r == nil || r.db == nil {
+		return false, errors.New("library repository is nil")
+	}
+	if userID == "" || transcriptID == "" {
+		return false, errors.New("user id and transcript id are required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	if err := r.db.QueryRow(queryCtx, selectLibraryEntryExistsSQL, userID, transcriptID).Scan(&exists); err != nil {
+		if isConnectionError(err) {
+			return false, fmt.Errorf("database connection failed: %w", err)
+		}
+		return false, fmt.Errorf("check library entry: %w", err)
+	}
+	return exists, nil
+}
+
+// ListLibrary returns a user's library, most recently added first.
+func (r *LibraryRepository) ListLibrary(ctx context.Context, userID string) ([]*LibraryEntry, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("library repository is nil")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.Query(queryCtx, listLibraryEntriesSQL, userID)
+	if err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("list library: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*LibraryEntry, 0)
+	for rows.Next() {
+		entry := &LibraryEntry{}
+		if err := rows.Scan(
+			&entry.TranscriptID,
+			&entry.Language,
+			&entry.AddedAt,
+			&entry.Video.ID,
+			&entry.Video.YouTubeID,
+			&entry.Video.Title,
+			&entry.Video.Channel,
+			&entry.Video.Duration,
+			&entry.Video.CreatedBy,
+			&entry.Video.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan library entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("iterate library entries: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,427 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey serializes migrations across instances with an advisory lock.
+const migrationLockKey = "schema_migrations"
+
+// Migration is one numbered schema change with its rollback.
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+// checksum identifies the up script, so edits to an applied migration show up in Status.
+func (m Migration) checksum() string {
+	sum := sha256.Sum256([]byte(m.up))
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationStatus is a migration and when it was applied, if it was.
+type MigrationStatus struct {
+	Migration
+	// AppliedAt is nil for pending migrations.
+	AppliedAt *time.Time
+	// Baselined migrations were recorded by Baseline rather than run by the migrator.
+	Baselined bool
+	// Modified reports that the script changed after it was applied.
+	Modified bool
+}
+
+var migrationFileName = regexp.MustCompile(`^(\d{3})_([a-z0-9_]+)_(up|down)\.sql$`)
+
+// migrations are the embedded scripts in version order, numbered from 1 without gaps.
+var migrations = mustLoadMigrations(migrationFiles)
+
+func mustLoadMigrations(fsys fs.FS) []Migration {
+	loaded, err := loadMigrations(fsys)
+	if err != nil {
+		panic("db: " + err.Error())
+	}
+	return loaded
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, name := range names {
+		match := migrationFileName.FindStringSubmatch(path.Base(name))
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named NNN_name_up.sql or NNN_name_down.sql", name)
+		}
+		version, _ := strconv.Atoi(match[1])
+		script, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %03d has two names: %s and %s", version, migration.Name, match[2])
+		}
+		if keyword, ok := transactionControl(string(script)); ok {
+			return nil, fmt.Errorf("migration file %s contains %s; the migrator runs each script in its own transaction", name, keyword)
+		}
+		if match[3] == "up" {
+			migration.up = string(script)
+		} else {
+			migration.down = string(script)
+		}
+	}
+
+	loaded := make([]Migration, 0, len(byVersion))
+	for version := 1; version <= len(byVersion); version++ {
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %03d is missing", version)
+		}
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %03d_%s needs both an up and a down script", version, migration.Name)
+		}
+		loaded = append(loaded, *migration)
+	}
+	return loaded, nil
+}
+
+// transactionStatements start statements that would end or nest the migrator's transaction.
+var transactionStatements = regexp.MustCompile(`(?i)^(BEGIN|START\s+TRANSACTION|COMMIT|END|ROLLBACK|ABORT)\b`)
+
+// dollarQuoteTag matches the opening of a dollar-quoted string, such as a DO $$ ... $$ body.
+var dollarQuoteTag = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// transactionControl reports the first top-level statement in script that controls the
+// transaction, such as BEGIN or COMMIT. Comments, quoted strings and dollar-quoted bodies are
+// skipped, so the BEGIN ... END of a DO block is allowed.
+func transactionControl(script string) (string, bool) {
+	var statement strings.Builder
+	check := func() (string, bool) {
+		if match := transactionStatements.FindString(strings.TrimSpace(statement.String())); match != "" {
+			return strings.ToUpper(strings.Join(strings.Fields(match), " ")), true
+		}
+		statement.Reset()
+		return "", false
+	}
+	for i := 0; i < len(script); {
+		rest := script[i:]
+		switch {
+		case strings.HasPrefix(rest, "--"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			i += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return "", false
+			}
+			i += end + 4
+			statement.WriteByte(' ')
+		case rest[0] == '\'' || rest[0] == '"':
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end < 0 {
+				return "", false
+			}
+			statement.WriteString(rest[:end+2])
+			i += end + 2
+		case rest[0] == ';':
+			if keyword, ok := check(); ok {
+				return keyword, true
+			}
+			i++
+		default:
+			if tag := dollarQuoteTag.FindString(rest); tag != "" {
+				end := strings.Index(rest[len(tag):], tag)
+				if end < 0 {
+					return "", false
+				}
+				statement.WriteString(rest[:len(tag)+end+len(tag)])
+				i += len(tag) + end + len(tag)
+				continue
+			}
+			statement.WriteByte(rest[0])
+			i++
+		}
+	}
+	return check()
+}
+
+// Migrator applies the embedded migrations and records them in schema_migrations. Concurrent
+// migrators, in this process or another instance, take turns on an advisory lock.
+type Migrator struct {
+	db         DB
+	migrations []Migration
+}
+
+// NewMigrator creates a new migrator for the embedded migrations
+func NewMigrator(db DB) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+const createSchemaMigrationsSQL = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    baselined BOOLEAN NOT NULL DEFAULT FALSE,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);`
+
+const (
+	schemaMigrationsExistsSQL = `SELECT to_regclass('schema_migrations') IS NOT NULL;`
+	otherTablesExistSQL       = `SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_tables
+    WHERE schemaname = current_schema() AND tablename <> 'schema_migrations');`
+	listSchemaMigrationsSQL  = `SELECT version, checksum, baselined, applied_at FROM schema_migrations ORDER BY version;`
+	insertSchemaMigrationSQL = `INSERT INTO schema_migrations (version, name, checksum, baselined) VALUES ($1, $2, $3, $4);`
+	deleteSchemaMigrationSQL = `DELETE FROM schema_migrations WHERE version = $1;`
+)
+
+type appliedMigration struct {
+	checksum  string
+	baselined bool
+	appliedAt time.Time
+}
+
+// ErrNotBaselined is returned by Up for a database that already has tables but no recorded
+// migrations, such as one migrated by hand before the runner existed.
+var ErrNotBaselined = errors.New("database has tables but no recorded migrations; " +
+	"record the migrations already applied with `migrate baseline <version>` first")
+
+// Up applies every pending migration in order, each in its own transaction, and returns the
+// versions applied. It refuses to start on a database that has tables but no recorded
+// migrations, rather than guess which of them were applied.
+func (m *Migrator) Up(ctx context.Context) ([]int, error) {
+	var applied []int
+	err := m.locked(ctx, func() error {
+		recorded, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		if len(recorded) == 0 {
+			if err := m.checkBaselined(ctx); err != nil {
+				return err
+			}
+		}
+		for _, migration := range m.migrations {
+			if _, ok := recorded[migration.Version]; ok {
+				continue
+			}
+			if err := m.run(ctx, migration, migration.up, insertSchemaMigrationSQL,
+				migration.Version, migration.Name, migration.checksum(), false); err != nil {
+				return err
+			}
+			slog.InfoContext(ctx, "applied migration", "version", migration.Version, "name", migration.Name)
+			applied = append(applied, migration.Version)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Baseline records migrations 1 through version as applied without running them, for a
+// database whose schema was set up before schema_migrations existed. It only runs on a
+// database with no recorded migrations.
+func (m *Migrator) Baseline(ctx context.Context, version int) error {
+	if version < 1 || version > len(m.migrations) {
+		return fmt.Errorf("baseline version must be between 1 and %d", len(m.migrations))
+	}
+	return m.locked(ctx, func() error {
+		recorded, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		if len(recorded) > 0 {
+			return errors.New("schema_migrations already records applied migrations; baseline only applies to an unrecorded database")
+		}
+		tx, err := m.db.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("begin baseline: %w", err)
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+		for _, migration := range m.migrations[:version] {
+			if _, err := tx.Exec(ctx, insertSchemaMigrationSQL,
+				migration.Version, migration.Name, migration.checksum(), true); err != nil {
+				return fmt.Errorf("baseline migration %03d: %w", migration.Version, err)
+			}
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("commit baseline: %w", err)
+		}
+		slog.InfoContext(ctx, "baselined existing schema", "version", version)
+		return nil
+	})
+}
+
+// Down rolls back the last steps applied migrations, newest first, and returns the versions
+// rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]int, error) {
+	if steps < 1 {
+		return nil, errors.New("steps must be at least 1")
+	}
+	var reverted []int
+	err := m.locked(ctx, func() error {
+		recorded, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := recorded[migration.Version]; !ok {
+				continue
+			}
+			if err := m.run(ctx, migration, migration.down, deleteSchemaMigrationSQL, migration.Version); err != nil {
+				return err
+			}
+			slog.InfoContext(ctx, "rolled back migration", "version", migration.Version, "name", migration.Name)
+			reverted = append(reverted, migration.Version)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every embedded migration with when it was applied. It changes nothing, so a
+// database without schema_migrations shows every migration pending.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	recorded, err := m.recorded(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = MigrationStatus{Migration: migration}
+		if applied, ok := recorded[migration.Version]; ok {
+			statuses[i].AppliedAt = &applied.appliedAt
+			statuses[i].Baselined = applied.baselined
+			statuses[i].Modified = applied.checksum != migration.checksum()
+		}
+	}
+	return statuses, nil
+}
+
+// SchemaVersion returns the last migration recorded in schema_migrations without gaps and the
+// newest embedded one. A database with no recorded migrations is at version 0, whatever tables
+// it holds, until it is migrated or baselined; if it has tables, ErrNotBaselined is returned
+// along with the versions.
+func (m *Migrator) SchemaVersion(ctx context.Context) (current, latest int, err error) {
+	latest = len(m.migrations)
+	recorded, err := m.recorded(ctx)
+	if err != nil {
+		return 0, latest, err
+	}
+	if len(recorded) == 0 {
+		return 0, latest, m.checkBaselined(ctx)
+	}
+	for _, migration := range m.migrations {
+		if _, ok := recorded[migration.Version]; !ok {
+			break
+		}
+		current = migration.Version
+	}
+	return current, latest, nil
+}
+
+// checkBaselined returns ErrNotBaselined if the database has tables other than
+// schema_migrations. Call it only when no migrations are recorded.
+func (m *Migrator) checkBaselined(ctx context.Context) error {
+	var tables bool
+	if err := m.db.QueryRow(ctx, otherTablesExistSQL).Scan(&tables); err != nil {
+		return fmt.Errorf("check existing tables: %w", err)
+	}
+	if tables {
+		return ErrNotBaselined
+	}
+	return nil
+}
+
+// locked runs fn while holding the migration lock, with schema_migrations in place.
+func (m *Migrator) locked(ctx context.Context, fn func() error) error {
+	unlock, err := NewAdvisoryLocker(m.db).Lock(ctx, migrationLockKey)
+	if err != nil {
+		return fmt.Errorf("lock migrations: %w", err)
+	}
+	defer unlock()
+
+	if _, err := m.db.Exec(ctx, createSchemaMigrationsSQL); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return fn()
+}
+
+// recorded reads schema_migrations, which may not exist yet.
+func (m *Migrator) recorded(ctx context.Context) (map[int]appliedMigration, error) {
+	var exists bool
+	if err := m.db.QueryRow(ctx, schemaMigrationsExistsSQL).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check schema_migrations: %w", err)
+	}
+	if !exists {
+		return map[int]appliedMigration{}, nil
+	}
+	return m.applied(ctx)
+}
+
+// applied reads schema_migrations.
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedMigration, error) {
+	rows, err := m.db.Query(ctx, listSchemaMigrationsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("list schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	recorded := make(map[int]appliedMigration)
+	for rows.Next() {
+		var (
+			version int
+			applied appliedMigration
+		)
+		if err := rows.Scan(&version, &applied.checksum, &applied.baselined, &applied.appliedAt); err != nil {
+			return nil, fmt.Errorf("scan schema_migrations: %w", err)
+		}
+		recorded[version] = applied
+	}
+	return recorded, rows.Err()
+}
+
+// run executes script and the schema_migrations update in one transaction.
+func (m *Migrator) run(ctx context.Context, migration Migration, script, recordSQL string, recordArgs ...any) error {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin migration %03d: %w", migration.Version, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return fmt.Errorf("migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.Exec(ctx, recordSQL, recordArgs...); err != nil {
+		return fmt.Errorf("record migration %03d: %w", migration.Version, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit migration %03d: %w", migration.Version, err)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	script := &fstest.MapFile{Data: []byte("SELECT 1;")}
+
+	t.Run("embedded", func(t *testing.T) {
+		require.NotEmpty(t, migrations)
+		for i, migration := range migrations {
+			assert.Equal(t, i+1, migration.Version)
+			assert.NotContains(t, migration.up, "BEGIN;", "the migrator runs each migration in its own transaction")
+			assert.NotContains(t, migration.down, "BEGIN;", "the migrator runs each migration in its own transaction")
+		}
+	})
+
+	t.Run("in order", func(t *testing.T) {
+		loaded, err := loadMigrations(fstest.MapFS{
+			"migrations/002_second_up.sql":   script,
+			"migrations/002_second_down.sql": script,
+			"migrations/001_first_up.sql":    script,
+			"migrations/001_first_down.sql":  script,
+		})
+		require.NoError(t, err)
+		require.Len(t, loaded, 2)
+		assert.Equal(t, "first", loaded[0].Name)
+		assert.Equal(t, "second", loaded[1].Name)
+	})
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"gap": {
+			"migrations/001_first_up.sql":   script,
+			"migrations/001_first_down.sql": script,
+			"migrations/003_third_up.sql":   script,
+			"migrations/003_third_down.sql": script,
+		},
+		"missing down": {
+			"migrations/001_first_up.sql": script,
+		},
+		"two names": {
+			"migrations/001_first_up.sql":     script,
+			"migrations/001_another_down.sql": script,
+		},
+		"bad name": {
+			"migrations/first.sql": script,
+		},
+		"own transaction": {
+			"migrations/001_first_up.sql":   &fstest.MapFile{Data: []byte("BEGIN;\nCREATE TABLE t (id INT);\nCOMMIT;\n")},
+			"migrations/001_first_down.sql": script,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadMigrations(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTransactionControl(t *testing.T) {
+	for script, want := range map[string]string{
+		"CREATE TABLE t (id INT);\nCOMMIT;":              "COMMIT",
+		"begin;\nCREATE TABLE t (id INT);":               "BEGIN",
+		"-- setup\nSTART  TRANSACTION;":                  "START TRANSACTION",
+		"CREATE TABLE t (id INT); rollback":              "ROLLBACK",
+		"DO $$ BEGIN RAISE NOTICE 'x'; END $$;\nEND;":    "END",
+		"CREATE TABLE t (id INT);\n/* note */ COMMIT;\n": "COMMIT",
+	} {
+		keyword, ok := transactionControl(script)
+		assert.True(t, ok, script)
+		assert.Equal(t, want, keyword, script)
+	}
+
+	for _, script := range []string{
+		"CREATE TABLE t (id INT);",
+		"DO $$\nBEGIN\n    IF true THEN\n        RAISE NOTICE 'ok';\n    END IF;\nEND\n$$;",
+		"CREATE FUNCTION f() RETURNS void AS $body$ BEGIN COMMIT; END $body$ LANGUAGE plpgsql;",
+		"-- BEGIN;\nSELECT 'COMMIT;';",
+		"INSERT INTO notes (body) VALUES ('begin; end');",
+	} {
+		_, ok := transactionControl(script)
+		assert.False(t, ok, script)
+	}
+}
+
+func TestMigrator_UpStatusDown(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	latest := len(migrations)
+	migrator := NewMigrator(database)
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, latest)
+	assert.Nil(t, statuses[0].AppliedAt)
+	current, latest, err := migrator.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, current)
+	assert.Equal(t, latest, latest)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, latest)
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied, "a second run has nothing to do")
+
+	statuses, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, "migration %03d", status.Version)
+		assert.False(t, status.Baselined)
+		assert.False(t, status.Modified)
+	}
+
+	reverted, err := migrator.Down(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{latest, latest - 1}, reverted)
+	current, latest, err = migrator.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest-2, current)
+	assert.Equal(t, latest, latest)
+
+	_, err = database.Exec(ctx, `UPDATE schema_migrations SET checksum = 'edited' WHERE version = 1`)
+	require.NoError(t, err)
+	statuses, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Modified)
+
+	reverted, err = migrator.Down(ctx, latest)
+	require.NoError(t, err)
+	assert.Len(t, reverted, latest-2)
+	var videos bool
+	require.NoError(t, database.QueryRow(ctx, `SELECT to_regclass('videos') IS NOT NULL`).Scan(&videos))
+	assert.False(t, videos)
+}
+
+func TestMigrator_BaselinesExistingSchema(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	// A database migrated by hand up to 010, before schema_migrations existed.
+	for _, migration := range migrations[:10] {
+		_, err := database.Exec(ctx, migration.up)
+		require.NoError(t, err, "apply migration %03d", migration.Version)
+	}
+	_, err = database.Exec(ctx, `INSERT INTO videos (youtube_id, title) VALUES ('dQw4w9WgXcQ', 'kept')`)
+	require.NoError(t, err)
+
+	migrator := NewMigrator(database)
+	current, _, err := migrator.SchemaVersion(ctx)
+	require.ErrorIs(t, err, ErrNotBaselined)
+	assert.Zero(t, current, "the version comes from schema_migrations, not from the tables present")
+
+	_, err = migrator.Up(ctx)
+	require.ErrorIs(t, err, ErrNotBaselined, "up does not guess which migrations were applied")
+
+	require.NoError(t, migrator.Baseline(ctx, 10))
+	require.Error(t, migrator.Baseline(ctx, 10), "a recorded database cannot be baselined again")
+	current, _, err = migrator.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 10, current)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int{11}, applied)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Baselined)
+	assert.False(t, statuses[10].Baselined)
+
+	var title string
+	require.NoError(t, database.QueryRow(ctx, `SELECT title FROM videos WHERE youtube_id = 'dQw4w9WgXcQ'`).Scan(&title))
+	assert.Equal(t, "kept", title)
+}
+
+func TestMigrator_BaselineRejectsUnknownVersions(t *testing.T) {
+	migrator := NewMigrator(nil)
+	for _, version := range []int{0, len(migrations) + 1} {
+		assert.Error(t, migrator.Baseline(context.Background(), version), "version %d", version)
+	}
+}
+
+func TestMigration009_MergesDuplicateTranscripts(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	for _, migration := range migrations[:8] {
+		_, err := database.Exec(ctx, migration.up)
+		require.NoError(t, err, "apply migration %03d", migration.Version)
+	}
+	var videoID, kept, duplicate string
+	require.NoError(t, database.QueryRow(ctx, `INSERT INTO videos (youtube_id, title) VALUES ('dQw4w9WgXcQ', 'Video') RETURNING id`).Scan(&videoID))
+	require.NoError(t, database.QueryRow(ctx, `INSERT INTO transcripts (video_id, language, content, created_at) VALUES ($1, 'en', '[]', NOW() - INTERVAL '1 day') RETURNING id`, videoID).Scan(&kept))
+	require.NoError(t, database.QueryRow(ctx, `INSERT INTO transcripts (video_id, language, content) VALUES ($1, 'en', '[]') RETURNING id`, videoID).Scan(&duplicate))
+	_, err = database.Exec(ctx, `INSERT INTO ai_summaries (transcript_id, summary_type, content, model) VALUES ($1, 'brief', '{}', 'm'), ($2, 'detailed', '{}', 'm')`, kept, duplicate)
+	require.NoError(t, err)
+	_, err = database.Exec(ctx, `INSERT INTO ai_extractions (transcript_id, extraction_type, content, model) VALUES ($1, 'code', '[]', 'm')`, duplicate)
+	require.NoError(t, err)
+	var keyID string
+	require.NoError(t, database.QueryRow(ctx, `INSERT INTO api_keys (name, key_prefix, key_hash, scopes) VALUES ('reader', 'ytk_abc', 'hash', '{transcripts:read}') RETURNING id`).Scan(&keyID))
+
+	up := func() error {
+		tx, err := database.Begin(ctx)
+		require.NoError(t, err)
+		defer func() { _ = tx.Rollback(ctx) }()
+		if _, err := tx.Exec(ctx, migrations[8].up); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	// Both copies have a brief summary: the migration refuses to choose and changes nothing.
+	_, err = database.Exec(ctx, `INSERT INTO ai_summaries (transcript_id, summary_type, content, model) VALUES ($1, 'brief', '{}', 'm')`, duplicate)
+	require.NoError(t, err)
+	require.ErrorContains(t, up(), "more than one copy of the same transcript")
+	var transcripts int
+	require.NoError(t, database.QueryRow(ctx, `SELECT COUNT(*) FROM transcripts`).Scan(&transcripts))
+	assert.Equal(t, 2, transcripts)
+
+	_, err = database.Exec(ctx, `DELETE FROM ai_summaries WHERE transcript_id = $1 AND summary_type = 'brief'`, duplicate)
+	require.NoError(t, err)
+	require.NoError(t, up())
+
+	require.NoError(t, database.QueryRow(ctx, `SELECT COUNT(*) FROM transcripts`).Scan(&transcripts))
+	assert.Equal(t, 1, transcripts)
+	var summaries, extractions int
+	require.NoError(t, database.QueryRow(ctx, `SELECT COUNT(*) FROM ai_summaries WHERE transcript_id = $1`, kept).Scan(&summaries))
+	require.NoError(t, database.QueryRow(ctx, `SELECT COUNT(*) FROM ai_extractions WHERE transcript_id = $1`, kept).Scan(&extractions))
+	assert.Equal(t, 2, summaries, "the duplicate's summary moved to the kept transcript")
+	assert.Equal(t, 1, extractions)
+	var scopes []string
+	require.NoError(t, database.QueryRow(ctx, `SELECT scopes FROM api_keys WHERE id = $1`, keyID).Scan(&scopes))
+	assert.Equal(t, []string{"transcripts:read", "transcripts:all"}, scopes, "existing keys keep access to every transcript")
+}
@@ -3,8 +3,6 @@
 -- Author: Database Agent
 -- Date: 2024-10-18
 
-BEGIN;
-
 DROP INDEX IF EXISTS idx_ai_summaries_transcript_id;
 DROP TABLE IF EXISTS ai_summaries;
 
@@ -13,5 +11,3 @@ DROP TABLE IF EXISTS transcripts;
 
 DROP INDEX IF EXISTS idx_videos_youtube_id;
 DROP TABLE IF EXISTS videos;
-
-COMMIT;
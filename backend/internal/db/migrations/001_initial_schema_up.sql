@@ -3,8 +3,6 @@
 -- Author: Database Agent
 -- Date: 2024-10-18
 
-BEGIN;
-
 -- Ensure pgcrypto is available for gen_random_uuid()
 CREATE EXTENSION IF NOT EXISTS pgcrypto;
 
@@ -42,5 +40,3 @@ CREATE TABLE IF NOT EXISTS ai_summaries (
 );
 
 CREATE INDEX IF NOT EXISTS idx_ai_summaries_transcript_id ON ai_summaries (transcript_id);
-
-COMMIT;
@@ -1,6 +1,14 @@
 -- Migration 003: AI Summaries and Features
 
-DROP TABLE IF EXISTS ai_summaries;
+-- Replace the placeholder ai_summaries table from 001 (it has a provider column); a table that
+-- already has the shape below is kept, with its summaries.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'ai_summaries' AND column_name = 'provider') THEN
+        DROP TABLE ai_summaries;
+    END IF;
+END $$;
 
 CREATE TABLE IF NOT EXISTS ai_summaries (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
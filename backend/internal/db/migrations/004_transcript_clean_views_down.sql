@@ -0,0 +1,3 @@
+-- Migration 004 Rollback: Drop clean transcript views
+
+DROP TABLE IF EXISTS transcript_clean_views;
@@ -0,0 +1,14 @@
+-- Migration 004: Clean transcript views
+-- Stores sentence/paragraph reconstructions of transcripts (optionally punctuated by an AI model)
+
+CREATE TABLE IF NOT EXISTS transcript_clean_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transcript_id UUID NOT NULL REFERENCES transcripts(id) ON DELETE CASCADE,
+    paragraphs JSONB NOT NULL,               -- Paragraphs with timed sentences
+    punctuated BOOLEAN NOT NULL DEFAULT FALSE,
+    model VARCHAR(100),                      -- Set when an AI punctuation pass was used
+    tokens_used INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(transcript_id)
+);
@@ -0,0 +1,3 @@
+-- Migration 005 Rollback: Drop AI usage ledger
+
+DROP TABLE IF EXISTS ai_usage;
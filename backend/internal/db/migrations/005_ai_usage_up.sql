@@ -0,0 +1,20 @@
+-- Migration 005: AI usage ledger
+-- One row per AI provider call with its token usage, latency and computed cost
+
+CREATE TABLE IF NOT EXISTS ai_usage (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transcript_id UUID REFERENCES transcripts(id) ON DELETE SET NULL,
+    provider VARCHAR(50) NOT NULL,             -- 'openai', 'anthropic', 'google'
+    model VARCHAR(100) NOT NULL,
+    operation VARCHAR(50) NOT NULL,            -- 'summarize', 'extract', 'answer', etc.
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    cost_usd NUMERIC(14, 8) NOT NULL DEFAULT 0,
+    success BOOLEAN NOT NULL DEFAULT TRUE,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_created_at ON ai_usage(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_transcript_id ON ai_usage(transcript_id);
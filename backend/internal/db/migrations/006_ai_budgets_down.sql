@@ -0,0 +1,4 @@
+-- Migration 006 Rollback: Drop API key attribution from the AI usage ledger
+
+DROP INDEX IF EXISTS idx_ai_usage_client_key_created_at;
+ALTER TABLE ai_usage DROP COLUMN IF EXISTS client_key;
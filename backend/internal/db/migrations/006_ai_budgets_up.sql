@@ -0,0 +1,8 @@
+-- Migration 006: AI budgets
+-- Attributes ledger rows to the calling API key so per-key spending caps can be enforced
+
+ALTER TABLE ai_usage ADD COLUMN IF NOT EXISTS client_key VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_client_key_created_at
+    ON ai_usage(client_key, created_at DESC)
+    WHERE client_key IS NOT NULL;
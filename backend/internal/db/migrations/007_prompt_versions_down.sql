@@ -0,0 +1,6 @@
+-- Migration 007 Rollback: Drop prompt versions
+
+ALTER TABLE ai_usage DROP COLUMN IF EXISTS prompt_version;
+ALTER TABLE transcript_clean_views DROP COLUMN IF EXISTS prompt_version;
+ALTER TABLE ai_extractions DROP COLUMN IF EXISTS prompt_version;
+ALTER TABLE ai_summaries DROP COLUMN IF EXISTS prompt_version;
@@ -0,0 +1,7 @@
+-- Migration 007: Prompt versions
+-- Records which prompt version produced each AI artifact and ledger row, so prompt versions can be compared
+
+ALTER TABLE ai_summaries ADD COLUMN IF NOT EXISTS prompt_version VARCHAR(50);
+ALTER TABLE ai_extractions ADD COLUMN IF NOT EXISTS prompt_version VARCHAR(50);
+ALTER TABLE transcript_clean_views ADD COLUMN IF NOT EXISTS prompt_version VARCHAR(50);
+ALTER TABLE ai_usage ADD COLUMN IF NOT EXISTS prompt_version VARCHAR(50);
@@ -0,0 +1,3 @@
+-- Migration 008 Rollback: Drop API keys
+
+DROP TABLE IF EXISTS api_keys;
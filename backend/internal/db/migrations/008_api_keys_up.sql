@@ -0,0 +1,16 @@
+-- Migration 008: API keys
+-- Authenticates API clients; only a SHA-256 digest of each key is stored
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,           -- first characters of the key, to recognize it in listings
+    key_hash VARCHAR(64) NOT NULL UNIQUE,      -- hex SHA-256 of the key
+    scopes TEXT[] NOT NULL DEFAULT '{}',       -- 'transcripts:read', 'transcripts:write', 'ai:write', 'usage:read', 'admin'
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_created_at ON api_keys(created_at DESC);
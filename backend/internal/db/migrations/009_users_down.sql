@@ -0,0 +1,14 @@
+-- Migration 009 Rollback: Drop users, sessions and libraries
+
+DROP INDEX IF EXISTS idx_transcripts_video_language_unique;
+
+ALTER TABLE ai_extractions DROP COLUMN IF EXISTS created_by;
+ALTER TABLE ai_summaries DROP COLUMN IF EXISTS created_by;
+ALTER TABLE transcripts DROP COLUMN IF EXISTS created_by;
+ALTER TABLE videos DROP COLUMN IF EXISTS created_by;
+
+DROP TABLE IF EXISTS user_transcripts;
+DROP TABLE IF EXISTS user_sessions;
+DROP TABLE IF EXISTS users;
+
+UPDATE api_keys SET scopes = array_remove(scopes, 'transcripts:all');
@@ -0,0 +1,97 @@
+-- Migration 009: Users
+-- User accounts with opaque session tokens and per-user libraries. Videos and transcripts stay
+-- shared and deduplicated by youtube_id + language; user_transcripts records which users added
+-- each transcript, and created_by records who first imported or generated a row.
+
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(320) NOT NULL,               -- stored lowercased
+    password_hash TEXT,                        -- bcrypt; NULL for accounts without a password
+    display_name VARCHAR(100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email);
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,    -- hex SHA-256 of the session token
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_expires_at ON user_sessions(expires_at);
+
+CREATE TABLE IF NOT EXISTS user_transcripts (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transcript_id UUID NOT NULL REFERENCES transcripts(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, transcript_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_transcripts_transcript_id ON user_transcripts(transcript_id);
+
+-- Keys without a user now need transcripts:all to see every transcript once accounts are
+-- enabled; existing keys keep the access they had.
+UPDATE api_keys SET scopes = array_append(scopes, 'transcripts:all')
+WHERE NOT 'transcripts:all' = ANY(scopes);
+
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE transcripts ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE ai_summaries ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE ai_extractions ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- Every fetch used to insert a new transcript row. Keep the oldest row per video and language
+-- (its ID is the one clients saw first), move the artifacts of the later copies onto it and only
+-- then drop the copies. When a copy and the kept row both have the same artifact (say, a brief
+-- summary), the migration stops rather than pick one: run the data fix in
+-- docs/deployment/DEPLOYMENT.md ("Duplicate transcripts") first.
+CREATE TEMPORARY TABLE transcript_duplicates AS
+SELECT t.id AS duplicate_id, kept.id AS kept_id
+FROM transcripts t
+JOIN LATERAL (
+    SELECT k.id FROM transcripts k
+    WHERE k.video_id = t.video_id AND k.language = t.language
+    ORDER BY k.created_at, k.id
+    LIMIT 1
+) kept ON kept.id <> t.id;
+
+DO $$
+DECLARE
+    conflicts INTEGER;
+BEGIN
+    SELECT
+        (SELECT COUNT(*) FROM (
+            SELECT 1 FROM ai_summaries a LEFT JOIN transcript_duplicates d ON d.duplicate_id = a.transcript_id
+            GROUP BY COALESCE(d.kept_id, a.transcript_id), a.summary_type HAVING COUNT(*) > 1) c)
+      + (SELECT COUNT(*) FROM (
+            SELECT 1 FROM ai_extractions a LEFT JOIN transcript_duplicates d ON d.duplicate_id = a.transcript_id
+            GROUP BY COALESCE(d.kept_id, a.transcript_id), a.extraction_type HAVING COUNT(*) > 1) c)
+      + (SELECT COUNT(*) FROM (
+            SELECT 1 FROM ai_translations a LEFT JOIN transcript_duplicates d ON d.duplicate_id = a.transcript_id
+            GROUP BY COALESCE(d.kept_id, a.transcript_id), a.target_language HAVING COUNT(*) > 1) c)
+      + (SELECT COUNT(*) FROM (
+            SELECT 1 FROM transcript_clean_views a LEFT JOIN transcript_duplicates d ON d.duplicate_id = a.transcript_id
+            GROUP BY COALESCE(d.kept_id, a.transcript_id) HAVING COUNT(*) > 1) c)
+    INTO conflicts;
+
+    IF conflicts > 0 THEN
+        RAISE EXCEPTION 'migration 009: % AI artifacts exist on more than one copy of the same transcript', conflicts
+            USING HINT = 'Run the "Duplicate transcripts" data fix in docs/deployment/DEPLOYMENT.md, then migrate again.';
+    END IF;
+END $$;
+
+UPDATE ai_summaries a SET transcript_id = d.kept_id FROM transcript_duplicates d WHERE a.transcript_id = d.duplicate_id;
+UPDATE ai_extractions a SET transcript_id = d.kept_id FROM transcript_duplicates d WHERE a.transcript_id = d.duplicate_id;
+UPDATE ai_translations a SET transcript_id = d.kept_id FROM transcript_duplicates d WHERE a.transcript_id = d.duplicate_id;
+UPDATE transcript_clean_views a SET transcript_id = d.kept_id FROM transcript_duplicates d WHERE a.transcript_id = d.duplicate_id;
+UPDATE ai_usage a SET transcript_id = d.kept_id FROM transcript_duplicates d WHERE a.transcript_id = d.duplicate_id;
+
+DELETE FROM transcripts WHERE id IN (SELECT duplicate_id FROM transcript_duplicates);
+DROP TABLE transcript_duplicates;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transcripts_video_language_unique ON transcripts(video_id, language);
@@ -0,0 +1,5 @@
+-- Migration 010 Rollback: Drop user identities and session roles
+
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS role;
+
+DROP TABLE IF EXISTS user_identities;
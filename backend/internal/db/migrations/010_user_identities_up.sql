@@ -0,0 +1,16 @@
+-- Migration 010: User identities
+-- Links users to OIDC identities (issuer + subject) for single sign-on. Sessions started through
+-- single sign-on carry the role mapped from the user's groups at sign-in.
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS role VARCHAR(20);  -- viewer, editor or admin; NULL for password sign-in
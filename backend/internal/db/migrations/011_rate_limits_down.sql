@@ -0,0 +1,3 @@
+-- Migration 011 Rollback: Drop rate limit buckets
+
+DROP TABLE IF EXISTS rate_limit_buckets;
@@ -0,0 +1,12 @@
+-- Migration 011: Rate limits
+-- Token buckets for inbound rate limiting shared by all API instances. The table is unlogged:
+-- losing buckets on a crash only resets the limits.
+
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY,                      -- route class and client, e.g. "fetch:key:<id>"
+    tokens DOUBLE PRECISION NOT NULL,          -- tokens left after the last request
+    allowed BOOLEAN NOT NULL,                  -- whether the last request got a token
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
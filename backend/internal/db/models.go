@@ -11,6 +11,9 @@ import (
 // ErrNotFound indicates a database record was not found.
 var ErrNotFound = errors.New("record not found")
 
+// ErrAlreadyExists indicates a record violates a uniqueness constraint.
+var ErrAlreadyExists = errors.New("record already exists")
+
 // Video represents a persisted YouTube video record.
 type Video struct {
 	ID        string    `json:"id"`
@@ -18,6 +21,7 @@ type Video struct {
 	Title     string    `json:"title"`
 	Channel   string    `json:"channel"`
 	Duration  int       `json:"duration"`
+	CreatedBy string    `json:"created_by,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -26,6 +30,7 @@ type TranscriptSegment struct {
 	StartMs    int64  `json:"start_ms"`
 	DurationMs int64  `json:"duration_ms"`
 	Text       string `json:"text"`
+	Speaker    string `json:"speaker,omitempty"`
 }
 
 // TranscriptSegments is a JSONB backed slice of transcript segments.
@@ -81,5 +86,6 @@ type Transcript struct {
 	VideoID   string             `json:"video_id"`
 	Language  string             `json:"language"`
 	Content   TranscriptSegments `json:"content"`
+	CreatedBy string             `json:"created_by,omitempty"`
 	CreatedAt time.Time          `json:"created_at"`
 }
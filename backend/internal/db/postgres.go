@@ -23,6 +23,7 @@ func NewPool(ctx context.Context, connString string) (*pgxpool.Pool, error) {
 	config.MaxConnLifetime = time.Hour
 	config.MaxConnIdleTime = 30 * time.Minute
 	config.HealthCheckPeriod = time.Minute
+	config.ConnConfig.Tracer = queryTracer{}
 
 	// Set connection timeout context
 	connCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -127,6 +128,7 @@ func NewPoolWithConfig(ctx context.Context, connString string, poolConfig *PoolC
 	config.MaxConnLifetime = poolConfig.MaxConnLifetime
 	config.MaxConnIdleTime = poolConfig.MaxConnIdleTime
 	config.HealthCheckPeriod = time.Minute
+	config.ConnConfig.Tracer = queryTracer{}
 
 	// Set connection timeout context
 	connCtx, cancel := context.WithTimeout(ctx, poolConfig.ConnTimeout)
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// rateLimitPruneInterval is how often idle buckets are deleted. A bucket idle for a minute has
+// refilled completely, so dropping it loses nothing.
+const rateLimitPruneInterval = 10 * time.Minute
+
+// RateLimitRepository keeps inbound rate limit token buckets in Postgres so every API instance
+// enforces the same limits.
+type RateLimitRepository struct {
+	db DB
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewRateLimitRepository creates a new rate limit repository
+func NewRateLimitRepository(db DB) *RateLimitRepository {
+	return &RateLimitRepository{db: db}
+}
+
+// rateLimitRefilledTokens is the bucket level at $3 before this request: the stored level plus
+// the refill since the last request, capped at the limit $2.
+const rateLimitRefilledTokens = `LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM ($3::timestamptz - b.updated_at)), 0) * $2::float8 / 60)`
+
+const takeRateLimitTokenSQL = `
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+VALUES ($1, $2::float8 - 1, TRUE, $3)
+ON CONFLICT (key) DO UPDATE
+SET allowed = ` + rateLimitRefilledTokens + ` >= 1,
+    tokens = CASE WHEN ` + rateLimitRefilledTokens + ` >= 1
+                  THEN ` + rateLimitRefilledTokens + ` - 1
+                  ELSE ` + rateLimitRefilledTokens + ` END,
+    updated_at = GREATEST(b.updated_at, $3)
+RETURNING tokens, allowed;
+`
+
+const deleteIdleRateLimitBucketsSQL = `
+DELETE FROM rate_limit_buckets WHERE updated_at < $1;
+`
+
+// TakeRateLimitToken takes a token from the bucket for key. Buckets hold limit tokens and
+// refill at limit tokens per minute. It returns the tokens left and whether one was available.
+func (r *RateLimitRepository) TakeRateLimitToken(ctx context.Context, key string, limit int, now time.Time) (float64, bool, error) {
+	if r == nil || r.db == nil {
+		return 0, false, errors.New("rate limit repository is nil")
+	}
+	if key == "" || limit <= 0 {
+		return 0, false, errors.New("key and a positive limit are required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var (
+		remaining float64
+		allowed   bool
+	)
+	if err := r.db.QueryRow(queryCtx, takeRateLimitTokenSQL, key, limit, now).Scan(&remaining, &allowed); err != nil {
+		if isConnectionError(err) {
+			return 0, false, fmt.Errorf("database connection failed: %w", err)
+		}
+		return 0, false, fmt.Errorf("take rate limit token: %w", err)
+	}
+
+	// Pruning is best effort; the next interval tries again.
+	if r.shouldPrune(now) {
+		if _, err := r.db.Exec(queryCtx, deleteIdleRateLimitBucketsSQL, now.Add(-time.Minute)); err != nil {
+			slog.WarnContext(ctx, "failed to delete idle rate limit buckets", "error", err)
+		}
+	}
+	return remaining, allowed, nil
+}
+
+func (r *RateLimitRepository) shouldPrune(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now.Sub(r.lastPruned) < rateLimitPruneInterval {
+		return false
+	}
+	r.lastPruned = now
+	return true
+}
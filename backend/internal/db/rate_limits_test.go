@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitRepository_TokenBucket(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	applyMigrations(t, database)
+
+	repo := NewRateLimitRepository(database)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	for i := 2; i >= 0; i-- {
+		remaining, allowed, err := repo.TakeRateLimitToken(ctx, "fetch:ip:203.0.113.7", 3, now)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.InDelta(t, float64(i), remaining, 0.001)
+	}
+
+	remaining, allowed, err := repo.TakeRateLimitToken(ctx, "fetch:ip:203.0.113.7", 3, now)
+	require.NoError(t, err)
+	assert.False(t, allowed, "the bucket is empty")
+	assert.InDelta(t, 0, remaining, 0.001)
+
+	_, allowed, err = repo.TakeRateLimitToken(ctx, "fetch:ip:198.51.100.1", 3, now)
+	require.NoError(t, err)
+	assert.True(t, allowed, "buckets are per key")
+
+	// Three per minute refill one token every 20 seconds.
+	remaining, allowed, err = repo.TakeRateLimitToken(ctx, "fetch:ip:203.0.113.7", 3, now.Add(25*time.Second))
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.InDelta(t, 0.25, remaining, 0.001)
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/tracing/tracingtest"
+)
+
+func TestQueryTracer_SpansQueries(t *testing.T) {
+	tracingtest.Install()
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+
+	tracer := queryTracer{}
+	queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "\n  select id from videos where youtube_id = $1", Args: []any{"secret"}})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+	queryCtx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "DELETE FROM videos"})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: errors.New("permission denied")})
+	parent.End()
+
+	spans := tracingtest.Spans(parent.SpanContext().TraceID())
+	require.Len(t, spans, 3)
+	assert.Equal(t, "SELECT", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.Contains(t, spans[0].Attributes, semconv.DBQueryText("select id from videos where youtube_id = $1"))
+	assert.Contains(t, spans[0].Attributes, semconv.DBResponseReturnedRows(1))
+	for _, attr := range spans[0].Attributes {
+		assert.NotEqual(t, "secret", attr.Value.Emit(), "argument values are not recorded")
+	}
+	assert.Equal(t, "DELETE", spans[1].Name)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CleanSentence is a reconstructed sentence with the time range it covers.
+type CleanSentence struct {
+	StartMs int64  `json:"start_ms"`
+	EndMs   int64  `json:"end_ms"`
+	Text    string `json:"text"`
+}
+
+// CleanParagraph groups consecutive clean sentences.
+type CleanParagraph struct {
+	StartMs   int64           `json:"start_ms"`
+	EndMs     int64           `json:"end_ms"`
+	Sentences []CleanSentence `json:"sentences"`
+}
+
+// TranscriptCleanView is the derived, readable view of a transcript built from its raw segments.
+type TranscriptCleanView struct {
+	ID            string
+	TranscriptID  string
+	Paragraphs    []CleanParagraph
+	Punctuated    bool
+	Model         string
+	TokensUsed    int
+	PromptVersion string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TranscriptCleanViewRepository handles database operations for clean transcript views
+type TranscriptCleanViewRepository struct {
+	db DB
+}
+
+// NewTranscriptCleanViewRepository creates a new clean transcript view repository
+func NewTranscriptCleanViewRepository(db DB) *TranscriptCleanViewRepository {
+	return &TranscriptCleanViewRepository{db: db}
+}
+
+const upsertTranscriptCleanViewSQL = `
+INSERT INTO transcript_clean_views (transcript_id, paragraphs, punctuated, model, tokens_used, prompt_version)
+VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''))
+ON CONFLICT (transcript_id) DO UPDATE
+SET paragraphs = EXCLUDED.paragraphs,
+    punctuated = EXCLUDED.punctuated,
+    model = EXCLUDED.model,
+    tokens_used = EXCLUDED.tokens_used,
+    prompt_version = EXCLUDED.prompt_version,
+    updated_at = NOW()
+RETURNING id, transcript_id, paragraphs, punctuated, COALESCE(model, ''), COALESCE(tokens_used, 0), COALESCE(prompt_version, ''), created_at, updated_at;
+`
+
+const selectTranscriptCleanViewSQL = `
+SELECT id, transcript_id, paragraphs, punctuated, COALESCE(model, ''), COALESCE(tokens_used, 0), COALESCE(prompt_version, ''), created_at, updated_at
+FROM transcript_clean_views
+WHERE transcript_id = $1
+LIMIT 1;
+`
+
+// SaveTranscriptCleanView creates or replaces the clean view for a transcript.
+func (r *TranscriptCleanViewRepository) SaveTranscriptCleanView(ctx context.Context, view *TranscriptCleanView) error {
+	if r == nil || r.db == nil {
+		return errors.New("transcript clean view repository is nil")
+	}
+	if view == nil {
+		return errors.New("clean view is nil")
+	}
+	if view.TranscriptID == "" {
+		return errors.New("transcript id is required")
+	}
+
+	payload, err := json.Marshal(view.Paragraphs)
+	if err != nil {
+		return fmt.Errorf("marshal clean paragraphs: %w", err)
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRow(queryCtx, upsertTranscriptCleanViewSQL,
+		view.TranscriptID,
+		payload,
+		view.Punctuated,
+		view.Model,
+		view.TokensUsed,
+		view.PromptVersion,
+	)
+
+	if err := scanTranscriptCleanViewRow(row, view); err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("database connection failed: %w", err)
+		}
+		return fmt.Errorf("save transcript clean view: %w", err)
+	}
+
+	return nil
+}
+
+// GetTranscriptCleanView retrieves the clean view for a transcript.
+func (r *TranscriptCleanViewRepository) GetTranscriptCleanView(ctx context.Context, transcriptID string) (*TranscriptCleanView, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("transcript clean view repository is nil")
+	}
+	if transcriptID == "" {
+		return nil, errors.New("transcript id is required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	view := &TranscriptCleanView{}
+	if err := scanTranscriptCleanViewRow(r.db.QueryRow(queryCtx, selectTranscriptCleanViewSQL, transcriptID), view); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("get transcript clean view: %w", err)
+	}
+
+	return view, nil
+}
+
+func scanTranscriptCleanViewRow(row pgx.Row, view *TranscriptCleanView) error {
+	var paragraphBytes []byte
+	if err := row.Scan(
+		&view.ID,
+		&view.TranscriptID,
+		&paragraphBytes,
+		&view.Punctuated,
+		&view.Model,
+		&view.TokensUsed,
+		&view.PromptVersion,
+		&view.CreatedAt,
+		&view.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	view.Paragraphs = nil
+	if len(paragraphBytes) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(paragraphBytes, &view.Paragraphs); err != nil {
+		return fmt.Errorf("unmarshal clean paragraphs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptCleanViewRepository_SaveAndReplace(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	applyMigrations(t, database)
+
+	videoRepo := NewVideoRepository(database)
+	transcriptRepo := NewTranscriptRepository(database)
+	cleanRepo := NewTranscriptCleanViewRepository(database)
+
+	video := &Video{YouTubeID: uuid.NewString(), Title: "Clean View Test"}
+	require.NoError(t, videoRepo.SaveVideo(ctx, video))
+
+	transcript := &Transcript{
+		VideoID:  video.ID,
+		Language: "en",
+		Content:  TranscriptSegments{{StartMs: 0, DurationMs: 2000, Text: "hello world"}},
+	}
+	require.NoError(t, transcriptRepo.SaveTranscript(ctx, transcript))
+
+	_, err = cleanRepo.GetTranscriptCleanView(ctx, transcript.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	view := &TranscriptCleanView{
+		TranscriptID: transcript.ID,
+		Paragraphs: []CleanParagraph{{
+			StartMs:   0,
+			EndMs:     2000,
+			Sentences: []CleanSentence{{StartMs: 0, EndMs: 2000, Text: "hello world"}},
+		}},
+	}
+	require.NoError(t, cleanRepo.SaveTranscriptCleanView(ctx, view))
+	assert.NotEmpty(t, view.ID)
+	assert.False(t, view.Punctuated)
+	assert.Empty(t, view.Model)
+
+	view.Paragraphs[0].Sentences[0].Text = "Hello world."
+	view.Punctuated = true
+	view.Model = "gpt-4"
+	view.TokensUsed = 42
+	require.NoError(t, cleanRepo.SaveTranscriptCleanView(ctx, view))
+
+	stored, err := cleanRepo.GetTranscriptCleanView(ctx, transcript.ID)
+	require.NoError(t, err)
+	assert.Equal(t, view.ID, stored.ID)
+	assert.True(t, stored.Punctuated)
+	assert.Equal(t, "gpt-4", stored.Model)
+	assert.Equal(t, 42, stored.TokensUsed)
+	require.Len(t, stored.Paragraphs, 1)
+	assert.Equal(t, "Hello world.", stored.Paragraphs[0].Sentences[0].Text)
+}
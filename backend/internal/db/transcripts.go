@@ -19,22 +19,26 @@ func NewTranscriptRepository(database DB) *TranscriptRepository {
 }
 
 const insertTranscriptSQL = `
-INSERT INTO transcripts (video_id, language, content)
-VALUES ($1, $2, $3)
-RETURNING id, video_id, language, content, created_at;
+INSERT INTO transcripts (video_id, language, content, created_by)
+VALUES ($1, $2, $3, NULLIF($4, '')::uuid)
+ON CONFLICT (video_id, language) DO UPDATE
+SET video_id = EXCLUDED.video_id
+RETURNING id, video_id, language, content, COALESCE(created_by::text, ''), created_at;
 `
 
 const upsertTranscriptSQL = `
-INSERT INTO transcripts (id, video_id, language, content)
-VALUES ($1, $2, $3, $4)
+INSERT INTO transcripts (id, video_id, language, content, created_by)
+VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid)
 ON CONFLICT (id) DO UPDATE
 SET video_id = EXCLUDED.video_id,
     language = EXCLUDED.language,
     content = EXCLUDED.content
-RETURNING id, video_id, language, content, created_at;
+RETURNING id, video_id, language, content, COALESCE(created_by::text, ''), created_at;
 `
 
 // SaveTranscript creates or updates a transcript row, including its JSONB content payload.
+// Transcripts are shared per video and language: saving a new transcript for a video and
+// language that already has one loads the stored transcript into transcript instead.
 func (r *TranscriptRepository) SaveTranscript(ctx context.Context, transcript *Transcript) error {
 	if r == nil || r.db == nil {
 		return errors.New("transcript repository is nil")
@@ -54,12 +58,12 @@ func (r *TranscriptRepository) SaveTranscript(ctx context.Context, transcript *T
 
 	var row pgx.Row
 	if transcript.ID == "" {
-		row = r.db.QueryRow(queryCtx, insertTranscriptSQL, transcript.VideoID, transcript.Language, transcript.Content)
+		row = r.db.QueryRow(queryCtx, insertTranscriptSQL, transcript.VideoID, transcript.Language, transcript.Content, transcript.CreatedBy)
 	} else {
-		row = r.db.QueryRow(queryCtx, upsertTranscriptSQL, transcript.ID, transcript.VideoID, transcript.Language, transcript.Content)
+		row = r.db.QueryRow(queryCtx, upsertTranscriptSQL, transcript.ID, transcript.VideoID, transcript.Language, transcript.Content, transcript.CreatedBy)
 	}
 
-	if err := row.Scan(&transcript.ID, &transcript.VideoID, &transcript.Language, &transcript.Content, &transcript.CreatedAt); err != nil {
+	if err := row.Scan(&transcript.ID, &transcript.VideoID, &transcript.Language, &transcript.Content, &transcript.CreatedBy, &transcript.CreatedAt); err != nil {
 		switch {
 		case isDuplicateKeyError(err):
 			return fmt.Errorf("transcript already exists for video %s (%s): %w", transcript.VideoID, transcript.Language, err)
@@ -74,7 +78,7 @@ func (r *TranscriptRepository) SaveTranscript(ctx context.Context, transcript *T
 }
 
 const selectTranscriptsByVideoIDSQL = `
-SELECT id, video_id, language, content, created_at
+SELECT id, video_id, language, content, COALESCE(created_by::text, ''), created_at
 FROM transcripts
 WHERE video_id = $1
 ORDER BY created_at ASC;
@@ -104,7 +108,7 @@ func (r *TranscriptRepository) GetTranscriptsByVideoID(ctx context.Context, vide
 	transcripts := make([]*Transcript, 0)
 	for rows.Next() {
 		transcript := &Transcript{}
-		if err := rows.Scan(&transcript.ID, &transcript.VideoID, &transcript.Language, &transcript.Content, &transcript.CreatedAt); err != nil {
+		if err := rows.Scan(&transcript.ID, &transcript.VideoID, &transcript.Language, &transcript.Content, &transcript.CreatedBy, &transcript.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan transcript: %w", err)
 		}
 		transcripts = append(transcripts, transcript)
@@ -121,7 +125,7 @@ func (r *TranscriptRepository) GetTranscriptsByVideoID(ctx context.Context, vide
 }
 
 const selectTranscriptByIDSQL = `
-SELECT id, video_id, language, content, created_at
+SELECT id, video_id, language, content, COALESCE(created_by::text, ''), created_at
 FROM transcripts
 WHERE id = $1
 LIMIT 1;
@@ -141,7 +145,7 @@ func (r *TranscriptRepository) GetTranscriptByID(ctx context.Context, id string)
 	defer cancel()
 
 	err := r.db.QueryRow(queryCtx, selectTranscriptByIDSQL, id).
-		Scan(&transcript.ID, &transcript.VideoID, &transcript.Language, &transcript.Content, &transcript.CreatedAt)
+		Scan(&transcript.ID, &transcript.VideoID, &transcript.Language, &transcript.Content, &transcript.CreatedBy, &transcript.CreatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -9,7 +9,7 @@ import (
 )
 
 const selectTranscriptByVideoIDAndLanguageSQL = `
-SELECT id, video_id, language, content, created_at
+SELECT id, video_id, language, content, COALESCE(created_by::text, ''), created_at
 FROM transcripts
 WHERE video_id = $1 AND language = $2
 ORDER BY created_at DESC
@@ -33,7 +33,7 @@ func (r *TranscriptRepository) GetTranscriptByVideoIDAndLanguage(ctx context.Con
 
 	var transcript Transcript
 	err := r.db.QueryRow(queryCtx, selectTranscriptByVideoIDAndLanguageSQL, videoID, language).
-		Scan(&transcript.ID, &transcript.VideoID, &transcript.Language, &transcript.Content, &transcript.CreatedAt)
+		Scan(&transcript.ID, &transcript.VideoID, &transcript.Language, &transcript.Content, &transcript.CreatedBy, &transcript.CreatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -45,7 +45,7 @@ func (r *TranscriptRepository) GetTranscriptByVideoIDAndLanguage(ctx context.Con
 }
 
 const selectTranscriptsByVideoIDPaginatedSQL = `
-SELECT id, video_id, language, content, created_at
+SELECT id, video_id, language, content, COALESCE(created_by::text, ''), created_at
 FROM transcripts
 WHERE video_id = $1
 ORDER BY created_at DESC
@@ -79,7 +79,7 @@ func (r *TranscriptRepository) GetTranscriptsByVideoIDWithPagination(ctx context
 	transcripts := make([]*Transcript, 0, limit)
 	for rows.Next() {
 		transcript := &Transcript{}
-		if err := rows.Scan(&transcript.ID, &transcript.VideoID, &transcript.Language, &transcript.Content, &transcript.CreatedAt); err != nil {
+		if err := rows.Scan(&transcript.ID, &transcript.VideoID, &transcript.Language, &transcript.Content, &transcript.CreatedBy, &transcript.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan transcript: %w", err)
 		}
 		transcripts = append(transcripts, transcript)
@@ -70,6 +70,16 @@ func TestTranscriptRepository_SaveAndRetrieve(t *testing.T) {
 	assert.Equal(t, transcript.ID, list[0].ID)
 	assert.Equal(t, transcript.Content, list[0].Content)
 
+	// A second transcript for the same video and language resolves to the stored one.
+	duplicate := &Transcript{
+		VideoID:  video.ID,
+		Language: "en",
+		Content:  TranscriptSegments{{StartMs: 0, DurationMs: 500, Text: "Other"}},
+	}
+	require.NoError(t, transcriptRepo.SaveTranscript(ctx, duplicate))
+	assert.Equal(t, transcript.ID, duplicate.ID)
+	assert.Equal(t, transcript.Content, duplicate.Content)
+
 	// Update content to exercise JSONB upsert handling.
 	transcript.Language = "en-US"
 	transcript.Content = TranscriptSegments{
@@ -0,0 +1,332 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// User is an account. PasswordHash is a bcrypt hash, empty for accounts that cannot log in with
+// a password.
+type User struct {
+	ID           string
+	Email        string
+	PasswordHash string
+	DisplayName  string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// UserSession is a login. Only the SHA-256 digest of the session token is stored.
+type UserSession struct {
+	ID         string
+	UserID     string
+	TokenHash  string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastUsedAt *time.Time
+	// Role is the role mapped from the user's groups at single sign-on; empty for password
+	// sign-in.
+	Role string
+}
+
+// UserRepository handles database operations for users and their sessions
+type UserRepository struct {
+	db DB
+}
+
+// NewUserRepository creates a new user repository
+func NewUserRepository(db DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+const userColumns = `id, email, COALESCE(password_hash, ''), COALESCE(display_name, ''), created_at, updated_at`
+
+const insertUserSQL = `
+INSERT INTO users (email, password_hash, display_name)
+VALUES ($1, NULLIF($2, ''), NULLIF($3, ''))
+RETURNING ` + userColumns + `;
+`
+
+const selectUserByEmailSQL = `
+SELECT ` + userColumns + `
+FROM users
+WHERE email = $1
+LIMIT 1;
+`
+
+const selectUserByIDSQL = `
+SELECT ` + userColumns + `
+FROM users
+WHERE id = $1
+LIMIT 1;
+`
+
+const selectUserByIdentitySQL = `
+SELECT u.id, u.email, COALESCE(u.password_hash, ''), COALESCE(u.display_name, ''), u.created_at, u.updated_at
+FROM user_identities i
+JOIN users u ON u.id = i.user_id
+WHERE i.issuer = $1 AND i.subject = $2
+LIMIT 1;
+`
+
+const insertUserIdentitySQL = `
+INSERT INTO user_identities (user_id, issuer, subject)
+VALUES ($1, $2, $3);
+`
+
+const insertUserSessionSQL = `
+INSERT INTO user_sessions (user_id, token_hash, expires_at, role)
+VALUES ($1, $2, $3, NULLIF($4, ''))
+RETURNING id, user_id, token_hash, created_at, expires_at, last_used_at, COALESCE(role, '');
+`
+
+const selectActiveUserSessionSQL = `
+SELECT s.id, s.user_id, s.token_hash, s.created_at, s.expires_at, s.last_used_at, COALESCE(s.role, ''),
+       u.id, u.email, COALESCE(u.password_hash, ''), COALESCE(u.display_name, ''), u.created_at, u.updated_at
+FROM user_sessions s
+JOIN users u ON u.id = s.user_id
+WHERE s.token_hash = $1 AND s.expires_at > $2
+LIMIT 1;
+`
+
+const touchUserSessionSQL = `
+UPDATE user_sessions SET last_used_at = $2 WHERE id = $1;
+`
+
+const deleteUserSessionSQL = `
+DELETE FROM user_sessions WHERE id = $1;
+`
+
+const deleteExpiredUserSessionsSQL = `
+DELETE FROM user_sessions WHERE user_id = $1 AND expires_at <= $2;
+`
+
+// CreateUser stores a new user. Emails are unique regardless of case; a taken email returns an
+// error wrapping ErrAlreadyExists.
+func (r *UserRepository) CreateUser(ctx context.Context, user *User) error {
+	if r == nil || r.db == nil {
+		return errors.New("user repository is nil")
+	}
+	if user == nil {
+		return errors.New("user is nil")
+	}
+	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+	if user.Email == "" {
+		return errors.New("email is required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRow(queryCtx, insertUserSQL, user.Email, user.PasswordHash, user.DisplayName)
+	if err := scanUserRow(row, user); err != nil {
+		switch {
+		case isDuplicateKeyError(err):
+			return fmt.Errorf("user %s: %w", user.Email, ErrAlreadyExists)
+		case isConnectionError(err):
+			return fmt.Errorf("database connection failed: %w", err)
+		default:
+			return fmt.Errorf("create user: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetUserByEmail finds a user by email, ignoring case.
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, errors.New("email is required")
+	}
+	return r.getUser(ctx, selectUserByEmailSQL, email)
+}
+
+// GetUserByID finds a user by ID.
+func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*User, error) {
+	if id == "" {
+		return nil, errors.New("user id is required")
+	}
+	return r.getUser(ctx, selectUserByIDSQL, id)
+}
+
+// GetUserByIdentity finds the user linked to an OIDC issuer and subject.
+func (r *UserRepository) GetUserByIdentity(ctx context.Context, issuer, subject string) (*User, error) {
+	if issuer == "" || subject == "" {
+		return nil, errors.New("issuer and subject are required")
+	}
+	return r.getUser(ctx, selectUserByIdentitySQL, issuer, subject)
+}
+
+// LinkIdentity links an OIDC issuer and subject to a user. An identity already linked to any
+// user returns an error wrapping ErrAlreadyExists.
+func (r *UserRepository) LinkIdentity(ctx context.Context, userID, issuer, subject string) error {
+	if r == nil || r.db == nil {
+		return errors.New("user repository is nil")
+	}
+	if userID == "" || issuer == "" || subject == "" {
+		return errors.New("user id, issuer and subject are required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.Exec(queryCtx, insertUserIdentitySQL, userID, issuer, subject); err != nil {
+		switch {
+		case isDuplicateKeyError(err):
+			return fmt.Errorf("identity %s at %s: %w", subject, issuer, ErrAlreadyExists)
+		case isConnectionError(err):
+			return fmt.Errorf("database connection failed: %w", err)
+		default:
+			return fmt.Errorf("link identity: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *UserRepository) getUser(ctx context.Context, sql string, args ...any) (*User, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("user repository is nil")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	user := &User{}
+	if err := scanUserRow(r.db.QueryRow(queryCtx, sql, args...), user); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	return user, nil
+}
+
+// CreateSession stores a new session and drops the user's expired ones.
+func (r *UserRepository) CreateSession(ctx context.Context, session *UserSession) error {
+	if r == nil || r.db == nil {
+		return errors.New("user repository is nil")
+	}
+	if session == nil {
+		return errors.New("session is nil")
+	}
+	if session.UserID == "" || session.TokenHash == "" {
+		return errors.New("user id and token hash are required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.Exec(queryCtx, deleteExpiredUserSessionsSQL, session.UserID, time.Now()); err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("database connection failed: %w", err)
+		}
+		return fmt.Errorf("delete expired sessions: %w", err)
+	}
+
+	err := r.db.QueryRow(queryCtx, insertUserSessionSQL, session.UserID, session.TokenHash, session.ExpiresAt, session.Role).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.TokenHash,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.LastUsedAt,
+		&session.Role,
+	)
+	if err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("database connection failed: %w", err)
+		}
+		return fmt.Errorf("create session: %w", err)
+	}
+	return nil
+}
+
+// GetActiveSession finds the unexpired session with the given token digest and its user.
+func (r *UserRepository) GetActiveSession(ctx context.Context, tokenHash string) (*UserSession, *User, error) {
+	if r == nil || r.db == nil {
+		return nil, nil, errors.New("user repository is nil")
+	}
+	if tokenHash == "" {
+		return nil, nil, errors.New("token hash is required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	session := &UserSession{}
+	user := &User{}
+	err := r.db.QueryRow(queryCtx, selectActiveUserSessionSQL, tokenHash, time.Now()).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.TokenHash,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.LastUsedAt,
+		&session.Role,
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.DisplayName,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrNotFound
+		}
+		if isConnectionError(err) {
+			return nil, nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, nil, fmt.Errorf("get session: %w", err)
+	}
+	return session, user, nil
+}
+
+// TouchSession records that a session was used at usedAt.
+func (r *UserRepository) TouchSession(ctx context.Context, id string, usedAt time.Time) error {
+	return r.execSession(ctx, "touch session", touchUserSessionSQL, id, usedAt)
+}
+
+// DeleteSession logs a session out.
+func (r *UserRepository) DeleteSession(ctx context.Context, id string) error {
+	return r.execSession(ctx, "delete session", deleteUserSessionSQL, id)
+}
+
+func (r *UserRepository) execSession(ctx context.Context, operation, sql, id string, args ...any) error {
+	if r == nil || r.db == nil {
+		return errors.New("user repository is nil")
+	}
+	if id == "" {
+		return errors.New("session id is required")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.Exec(queryCtx, sql, append([]any{id}, args...)...); err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("database connection failed: %w", err)
+		}
+		return fmt.Errorf("%s: %w", operation, err)
+	}
+	return nil
+}
+
+func scanUserRow(row pgx.Row, user *User) error {
+	return row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.DisplayName,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+}
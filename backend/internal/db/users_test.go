@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository_UsersAndSessions(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	applyMigrations(t, database)
+
+	repo := NewUserRepository(database)
+	user := &User{Email: " Ada@Example.com", PasswordHash: "hash", DisplayName: "Ada"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	assert.NotEmpty(t, user.ID)
+	assert.Equal(t, "ada@example.com", user.Email)
+
+	err = repo.CreateUser(ctx, &User{Email: "ADA@example.com"})
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+
+	found, err := repo.GetUserByEmail(ctx, "ADA@EXAMPLE.COM")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+	_, err = repo.GetUserByEmail(ctx, "nobody@example.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	expired := &UserSession{UserID: user.ID, TokenHash: "expired-" + uuid.NewString(), ExpiresAt: time.Now().Add(-time.Minute)}
+	require.NoError(t, repo.CreateSession(ctx, expired))
+	_, _, err = repo.GetActiveSession(ctx, expired.TokenHash)
+	assert.ErrorIs(t, err, ErrNotFound, "expired sessions are not active")
+
+	session := &UserSession{UserID: user.ID, TokenHash: "active-" + uuid.NewString(), ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, repo.CreateSession(ctx, session))
+	active, sessionUser, err := repo.GetActiveSession(ctx, session.TokenHash)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, active.ID)
+	assert.Equal(t, user.ID, sessionUser.ID)
+	assert.Nil(t, active.LastUsedAt)
+
+	require.NoError(t, repo.TouchSession(ctx, session.ID, time.Now()))
+	active, _, err = repo.GetActiveSession(ctx, session.TokenHash)
+	require.NoError(t, err)
+	assert.NotNil(t, active.LastUsedAt)
+
+	require.NoError(t, repo.DeleteSession(ctx, session.ID))
+	_, _, err = repo.GetActiveSession(ctx, session.TokenHash)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	sso := &UserSession{UserID: user.ID, TokenHash: "sso-" + uuid.NewString(), ExpiresAt: time.Now().Add(time.Hour), Role: "editor"}
+	require.NoError(t, repo.CreateSession(ctx, sso))
+	active, _, err = repo.GetActiveSession(ctx, sso.TokenHash)
+	require.NoError(t, err)
+	assert.Equal(t, "editor", active.Role)
+}
+
+func TestUserRepository_Identities(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	applyMigrations(t, database)
+
+	repo := NewUserRepository(database)
+	user := &User{Email: "ada@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	_, err = repo.GetUserByIdentity(ctx, "https://idp.example.com", "sub-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, repo.LinkIdentity(ctx, user.ID, "https://idp.example.com", "sub-1"))
+	found, err := repo.GetUserByIdentity(ctx, "https://idp.example.com", "sub-1")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+
+	_, err = repo.GetUserByIdentity(ctx, "https://other.example.com", "sub-1")
+	assert.ErrorIs(t, err, ErrNotFound, "subjects are scoped to their issuer")
+	assert.ErrorIs(t, repo.LinkIdentity(ctx, user.ID, "https://idp.example.com", "sub-1"), ErrAlreadyExists)
+}
+
+func TestLibraryRepository_PerUserLibraries(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	applyMigrations(t, database)
+
+	users := NewUserRepository(database)
+	ada := &User{Email: "ada@example.com"}
+	bob := &User{Email: "bob@example.com"}
+	require.NoError(t, users.CreateUser(ctx, ada))
+	require.NoError(t, users.CreateUser(ctx, bob))
+
+	video := &Video{YouTubeID: uuid.NewString(), Title: "Library Video", Channel: "Channel", Duration: 60, CreatedBy: ada.ID}
+	require.NoError(t, NewVideoRepository(database).SaveVideo(ctx, video))
+	transcript := &Transcript{VideoID: video.ID, Language: "en", CreatedBy: ada.ID}
+	require.NoError(t, NewTranscriptRepository(database).SaveTranscript(ctx, transcript))
+	assert.Equal(t, ada.ID, transcript.CreatedBy)
+
+	library := NewLibraryRepository(database)
+	require.NoError(t, library.AddToLibrary(ctx, ada.ID, transcript.ID))
+	require.NoError(t, library.AddToLibrary(ctx, ada.ID, transcript.ID), "adding twice is a no-op")
+
+	inLibrary, err := library.InLibrary(ctx, ada.ID, transcript.ID)
+	require.NoError(t, err)
+	assert.True(t, inLibrary)
+	inLibrary, err = library.InLibrary(ctx, bob.ID, transcript.ID)
+	require.NoError(t, err)
+	assert.False(t, inLibrary)
+
+	entries, err := library.ListLibrary(ctx, ada.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, transcript.ID, entries[0].TranscriptID)
+	assert.Equal(t, video.YouTubeID, entries[0].Video.YouTubeID)
+
+	videos, err := NewVideoRepository(database).ListVideos(ctx, VideoFilter{UserID: bob.ID})
+	require.NoError(t, err)
+	assert.Empty(t, videos, "videos are filtered to the user's library")
+
+	assert.ErrorIs(t, library.RemoveFromLibrary(ctx, bob.ID, transcript.ID), ErrNotFound)
+	require.NoError(t, library.RemoveFromLibrary(ctx, ada.ID, transcript.ID))
+	entries, err = library.ListLibrary(ctx, ada.ID)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
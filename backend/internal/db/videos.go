@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -19,16 +20,17 @@ func NewVideoRepository(database DB) *VideoRepository {
 }
 
 const upsertVideoSQL = `
-INSERT INTO videos (youtube_id, title, channel, duration)
-VALUES ($1, $2, $3, $4)
+INSERT INTO videos (youtube_id, title, channel, duration, created_by)
+VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid)
 ON CONFLICT (youtube_id) DO UPDATE
 SET title = EXCLUDED.title,
     channel = EXCLUDED.channel,
     duration = EXCLUDED.duration
-RETURNING id, youtube_id, title, channel, duration, created_at;
+RETURNING id, youtube_id, title, channel, duration, COALESCE(created_by::text, ''), created_at;
 `
 
-// SaveVideo inserts or updates a video using youtube_id as the uniqueness constraint.
+// SaveVideo inserts or updates a video using youtube_id as the uniqueness constraint. CreatedBy
+// is only stored when the video is first inserted.
 func (r *VideoRepository) SaveVideo(ctx context.Context, video *Video) error {
 	if r == nil || r.db == nil {
 		return errors.New("video repository is nil")
@@ -43,8 +45,8 @@ func (r *VideoRepository) SaveVideo(ctx context.Context, video *Video) error {
 	queryCtx, cancel := withQueryTimeout(ctx)
 	defer cancel()
 
-	row := r.db.QueryRow(queryCtx, upsertVideoSQL, video.YouTubeID, video.Title, video.Channel, video.Duration)
-	if err := row.Scan(&video.ID, &video.YouTubeID, &video.Title, &video.Channel, &video.Duration, &video.CreatedAt); err != nil {
+	row := r.db.QueryRow(queryCtx, upsertVideoSQL, video.YouTubeID, video.Title, video.Channel, video.Duration, video.CreatedBy)
+	if err := row.Scan(&video.ID, &video.YouTubeID, &video.Title, &video.Channel, &video.Duration, &video.CreatedBy, &video.CreatedAt); err != nil {
 		switch {
 		case isDuplicateKeyError(err):
 			return fmt.Errorf("video with youtube_id %s already exists: %w", video.YouTubeID, err)
@@ -59,7 +61,7 @@ func (r *VideoRepository) SaveVideo(ctx context.Context, video *Video) error {
 }
 
 const selectVideoByYouTubeIDSQL = `
-SELECT id, youtube_id, title, channel, duration, created_at
+SELECT id, youtube_id, title, channel, duration, COALESCE(created_by::text, ''), created_at
 FROM videos
 WHERE youtube_id = $1
 LIMIT 1;
@@ -79,7 +81,7 @@ func (r *VideoRepository) GetVideoByYouTubeID(ctx context.Context, youtubeID str
 	defer cancel()
 
 	err := r.db.QueryRow(queryCtx, selectVideoByYouTubeIDSQL, youtubeID).
-		Scan(&video.ID, &video.YouTubeID, &video.Title, &video.Channel, &video.Duration, &video.CreatedAt)
+		Scan(&video.ID, &video.YouTubeID, &video.Title, &video.Channel, &video.Duration, &video.CreatedBy, &video.CreatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -94,7 +96,7 @@ func (r *VideoRepository) GetVideoByYouTubeID(ctx context.Context, youtubeID str
 }
 
 const selectVideoByIDSQL = `
-SELECT id, youtube_id, title, channel, duration, created_at
+SELECT id, youtube_id, title, channel, duration, COALESCE(created_by::text, ''), created_at
 FROM videos
 WHERE id = $1
 LIMIT 1;
@@ -114,7 +116,7 @@ func (r *VideoRepository) GetVideoByID(ctx context.Context, id string) (*Video,
 	defer cancel()
 
 	err := r.db.QueryRow(queryCtx, selectVideoByIDSQL, id).
-		Scan(&video.ID, &video.YouTubeID, &video.Title, &video.Channel, &video.Duration, &video.CreatedAt)
+		Scan(&video.ID, &video.YouTubeID, &video.Title, &video.Channel, &video.Duration, &video.CreatedBy, &video.CreatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -127,3 +129,69 @@ func (r *VideoRepository) GetVideoByID(ctx context.Context, id string) (*Video,
 
 	return &video, nil
 }
+
+// VideoFilter narrows ListVideos results. Zero values disable the corresponding filter;
+// CreatedBefore is exclusive. UserID keeps the videos with a transcript in that user's library.
+type VideoFilter struct {
+	Channel       string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UserID        string
+}
+
+const listVideosSQL = `
+SELECT id, youtube_id, title, channel, duration, COALESCE(created_by::text, ''), created_at
+FROM videos
+WHERE ($1 = '' OR channel = $1)
+  AND ($2::timestamptz IS NULL OR created_at >= $2)
+  AND ($3::timestamptz IS NULL OR created_at < $3)
+  AND ($4 = '' OR EXISTS (
+      SELECT 1 FROM user_transcripts ut
+      JOIN transcripts t ON t.id = ut.transcript_id
+      WHERE t.video_id = videos.id AND ut.user_id::text = $4))
+ORDER BY created_at ASC, id ASC;
+`
+
+// ListVideos returns every video matching the filter, oldest first.
+func (r *VideoRepository) ListVideos(ctx context.Context, filter VideoFilter) ([]*Video, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("video repository is nil")
+	}
+
+	queryCtx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.Query(queryCtx, listVideosSQL, filter.Channel, nullableTime(filter.CreatedAfter), nullableTime(filter.CreatedBefore), filter.UserID)
+	if err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("list videos: %w", err)
+	}
+	defer rows.Close()
+
+	videos := make([]*Video, 0)
+	for rows.Next() {
+		video := &Video{}
+		if err := rows.Scan(&video.ID, &video.YouTubeID, &video.Title, &video.Channel, &video.Duration, &video.CreatedBy, &video.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan video: %w", err)
+		}
+		videos = append(videos, video)
+	}
+
+	if err := rows.Err(); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("database connection failed: %w", err)
+		}
+		return nil, fmt.Errorf("iterate videos: %w", err)
+	}
+
+	return videos, nil
+}
+
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -93,3 +94,41 @@ func TestVideoRepository_NotFound(t *testing.T) {
 	require.Error(t, err)
 	assert.ErrorIs(t, err, ErrNotFound)
 }
+
+func TestVideoRepository_ListVideos(t *testing.T) {
+	container := setupPostgresContainer(t)
+
+	ctx := context.Background()
+	database, err := Connect(ctx, container.ConnectionString)
+	require.NoError(t, err)
+	defer database.Close()
+
+	applyMigrations(t, database)
+
+	repo := NewVideoRepository(database)
+
+	first := &Video{YouTubeID: uuid.NewString(), Title: "First", Channel: "Gophers"}
+	second := &Video{YouTubeID: uuid.NewString(), Title: "Second", Channel: "Rustaceans"}
+	third := &Video{YouTubeID: uuid.NewString(), Title: "Third", Channel: "Gophers"}
+	for _, video := range []*Video{first, second, third} {
+		require.NoError(t, repo.SaveVideo(ctx, video))
+	}
+
+	all, err := repo.ListVideos(ctx, VideoFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, first.ID, all[0].ID)
+
+	gophers, err := repo.ListVideos(ctx, VideoFilter{Channel: "Gophers"})
+	require.NoError(t, err)
+	require.Len(t, gophers, 2)
+	assert.Equal(t, third.ID, gophers[1].ID)
+
+	none, err := repo.ListVideos(ctx, VideoFilter{CreatedAfter: third.CreatedAt.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	beforeThird, err := repo.ListVideos(ctx, VideoFilter{CreatedBefore: third.CreatedAt})
+	require.NoError(t, err)
+	assert.Len(t, beforeThird, 2)
+}
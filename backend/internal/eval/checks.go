@@ -0,0 +1,267 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+// Check names.
+const (
+	CheckSchema    = "schema"
+	CheckGrounding = "grounding"
+	CheckCoverage  = "coverage"
+	CheckNotFound  = "not_found"
+	CheckJudge     = "judge"
+)
+
+const (
+	// groundedShare is the share of a citation's word trigrams that must occur in the transcript.
+	groundedShare = 0.8
+	// coveredShare is the share of an expected point's keywords the output must contain.
+	coveredShare = 0.5
+	// maxDetailItems bounds the failures listed in a check detail.
+	maxDetailItems = 3
+)
+
+// CheckResult is one scored check of a case. Scores range from 0 to 1.
+type CheckResult struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// ratioCheck scores passed out of total, listing up to maxDetailItems failures.
+func ratioCheck(name string, passed, total int, failures []string) CheckResult {
+	check := CheckResult{Name: name, Score: 1}
+	if total > 0 {
+		check.Score = float64(passed) / float64(total)
+	}
+	if len(failures) > 0 {
+		shown := failures
+		if len(shown) > maxDetailItems {
+			shown = shown[:maxDetailItems]
+		}
+		check.Detail = strings.Join(shown, "; ")
+		if len(failures) > len(shown) {
+			check.Detail += fmt.Sprintf("; and %d more", len(failures)-len(shown))
+		}
+	}
+	return check
+}
+
+// summarySchemaCheck verifies that the fields required by the summary type are filled and that
+// chapters start at 0, are ordered and begin on a segment.
+func summarySchemaCheck(fixture *Fixture, summaryType string, content services.SummaryContent) CheckResult {
+	var failures []string
+	rules := 0
+	require := func(ok bool, failure string) {
+		rules++
+		if !ok {
+			failures = append(failures, failure)
+		}
+	}
+
+	switch summaryType {
+	case "brief":
+		require(strings.TrimSpace(content.Text) != "", "text is empty")
+	case "key_points":
+		require(len(content.KeyPoints) > 0, "no key points")
+	case "detailed":
+		require(len(content.Sections) > 0, "no sections")
+		for i, section := range content.Sections {
+			require(section.Title != "" && section.Content != "", fmt.Sprintf("section %d is incomplete", i+1))
+		}
+	case "chapters":
+		require(len(content.Chapters) > 0, "no chapters")
+		starts := make(map[int64]bool, len(fixture.Segments))
+		for _, segment := range fixture.Segments {
+			starts[segment.StartMs] = true
+		}
+		for i, chapter := range content.Chapters {
+			require(chapter.Title != "", fmt.Sprintf("chapter %d has no title", i+1))
+			require(starts[chapter.StartMs], fmt.Sprintf("chapter %d starts at %d, not on a segment", i+1, chapter.StartMs))
+			if i == 0 {
+				require(chapter.StartMs == 0, "first chapter does not start at 0")
+			} else {
+				require(chapter.StartMs > content.Chapters[i-1].StartMs, fmt.Sprintf("chapter %d is out of order", i+1))
+			}
+		}
+	default:
+		require(strings.TrimSpace(content.Text) != "", "text is empty")
+	}
+	return ratioCheck(CheckSchema, rules-len(failures), rules, failures)
+}
+
+var (
+	levels               = map[string]bool{"high": true, "medium": true, "low": true}
+	actionItemCategories = map[string]bool{"task": true, "recommendation": true, "step": true}
+)
+
+// extractionSchemaCheck scores the share of items with their required field and valid enums.
+func extractionSchemaCheck(extractionType string, items []services.ExtractionItem) CheckResult {
+	var failures []string
+	for i, item := range items {
+		var problem string
+		switch extractionType {
+		case "code":
+			if strings.TrimSpace(item.Code) == "" {
+				problem = "no code"
+			}
+		case "quotes":
+			if strings.TrimSpace(item.Quote) == "" {
+				problem = "no quote"
+			} else if !levels[item.Importance] {
+				problem = fmt.Sprintf("importance %q", item.Importance)
+			}
+		case "action_items":
+			if strings.TrimSpace(item.Action) == "" {
+				problem = "no action"
+			} else if !levels[item.Priority] {
+				problem = fmt.Sprintf("priority %q", item.Priority)
+			} else if !actionItemCategories[item.Category] {
+				problem = fmt.Sprintf("category %q", item.Category)
+			}
+		}
+		if problem != "" {
+			failures = append(failures, fmt.Sprintf("item %d: %s", i+1, problem))
+		}
+	}
+	return ratioCheck(CheckSchema, len(items)-len(failures), len(items), failures)
+}
+
+// answerSchemaCheck verifies the answer, its confidence and that not-found answers cite nothing.
+func answerSchemaCheck(answer *services.AIAnswer) CheckResult {
+	var failures []string
+	if strings.TrimSpace(answer.Answer) == "" {
+		failures = append(failures, "answer is empty")
+	}
+	if !levels[answer.Confidence] {
+		failures = append(failures, fmt.Sprintf("confidence %q", answer.Confidence))
+	}
+	if answer.NotFound && len(answer.Sources) > 0 {
+		failures = append(failures, "not found answer cites sources")
+	}
+	return ratioCheck(CheckSchema, 3-len(failures), 3, failures)
+}
+
+// groundingCheck scores the share of citations that occur in the transcript. Citations match
+// when most of their word trigrams do, so punctuation, casing and a stray word are tolerated.
+// It returns false when there is nothing to check.
+func groundingCheck(transcript string, citations []string) (CheckResult, bool) {
+	source := make(map[string]bool)
+	for _, trigram := range trigrams(words(transcript)) {
+		source[trigram] = true
+	}
+
+	checked := 0
+	var failures []string
+	for _, citation := range citations {
+		citationWords := words(citation)
+		if len(citationWords) == 0 {
+			continue
+		}
+		checked++
+		if !grounded(citationWords, source) {
+			failures = append(failures, fmt.Sprintf("%q", truncate(citation, 60)))
+		}
+	}
+	if checked == 0 {
+		return CheckResult{}, false
+	}
+	return ratioCheck(CheckGrounding, checked-len(failures), checked, failures), true
+}
+
+func grounded(citationWords []string, source map[string]bool) bool {
+	citationTrigrams := trigrams(citationWords)
+	found := 0
+	for _, trigram := range citationTrigrams {
+		if source[trigram] {
+			found++
+		}
+	}
+	return float64(found) >= groundedShare*float64(len(citationTrigrams))
+}
+
+// trigrams returns the word trigrams of words, or the words joined when there are fewer.
+func trigrams(words []string) []string {
+	if len(words) < 3 {
+		return []string{strings.Join(words, " ")}
+	}
+	out := make([]string, 0, len(words)-2)
+	for i := 0; i+3 <= len(words); i++ {
+		out = append(out, strings.Join(words[i:i+3], " "))
+	}
+	return out
+}
+
+// coverageCheck scores the share of expected points whose keywords the output mostly contains.
+// It returns false when nothing is expected.
+func coverageCheck(expected []string, output string) (CheckResult, bool) {
+	if len(expected) == 0 {
+		return CheckResult{}, false
+	}
+	available := make(map[string]bool)
+	for _, keyword := range keywords(output) {
+		available[keyword] = true
+	}
+
+	var failures []string
+	for _, point := range expected {
+		pointKeywords := keywords(point)
+		found := 0
+		for _, keyword := range pointKeywords {
+			if available[keyword] {
+				found++
+			}
+		}
+		if len(pointKeywords) == 0 || float64(found) < coveredShare*float64(len(pointKeywords)) {
+			failures = append(failures, fmt.Sprintf("missing %q", truncate(point, 60)))
+		}
+	}
+	return ratioCheck(CheckCoverage, len(expected)-len(failures), len(expected), failures), true
+}
+
+// words lowercases text and splits it into letter and digit runs.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// keywords returns the content words of text with a plural "s" removed.
+func keywords(text string) []string {
+	var out []string
+	for _, word := range words(text) {
+		if len(word) < 3 || stopWords[word] {
+			continue
+		}
+		if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			word = strings.TrimSuffix(word, "s")
+		}
+		out = append(out, word)
+	}
+	return out
+}
+
+var stopWords = func() map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(`about after again all also and any are because been before
+		but can could did does doing for from had has have how into its just more most not now off
+		once only other our out over own same should some such than that the their them then there
+		these they this those through too under until very was were what when where which while who
+		why will with would you your`) {
+		set[word] = true
+	}
+	return set
+}()
+
+func truncate(text string, max int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= max {
+		return string(runes)
+	}
+	return string(runes[:max]) + "…"
+}
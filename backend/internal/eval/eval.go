@@ -0,0 +1,239 @@
+// Package eval scores AI providers and prompt versions against a golden dataset of transcripts.
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+// DefaultTimeout bounds each provider call.
+const DefaultTimeout = 2 * time.Minute
+
+// Runner evaluates a provider on fixtures. The prompt version is taken from the context the
+// runner is called with (see services.WithPromptSet).
+type Runner struct {
+	Provider services.AIProvider
+	// Judge optionally adds a model-graded check to every case.
+	Judge   *Judge
+	Timeout time.Duration
+}
+
+// Case is the evaluation of one task (an operation and its type) on one fixture.
+type Case struct {
+	Fixture string          `json:"fixture"`
+	Task    string          `json:"task"`
+	Score   float64         `json:"score"`
+	Checks  []CheckResult   `json:"checks,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Tokens  int             `json:"tokens"`
+	Output  json.RawMessage `json:"output,omitempty"`
+}
+
+// Task names are "summary:<type>", "extraction:<type>" and "qa:<question number>".
+const (
+	taskSummary    = "summary"
+	taskExtraction = "extraction"
+	taskQA         = "qa"
+)
+
+// Run evaluates every task of every fixture, in fixture order.
+func (r *Runner) Run(ctx context.Context, fixtures []Fixture) []Case {
+	var cases []Case
+	for i := range fixtures {
+		fixture := &fixtures[i]
+		for _, summaryType := range sortedKeys(fixture.Summaries) {
+			cases = append(cases, r.runSummary(ctx, fixture, summaryType))
+		}
+		for _, extractionType := range sortedKeys(fixture.Extractions) {
+			cases = append(cases, r.runExtraction(ctx, fixture, extractionType))
+		}
+		for n, question := range fixture.Questions {
+			cases = append(cases, r.runQuestion(ctx, fixture, n+1, question))
+		}
+	}
+	return cases
+}
+
+func (r *Runner) runSummary(ctx context.Context, fixture *Fixture, summaryType string) Case {
+	result := Case{Fixture: fixture.ID, Task: taskSummary + ":" + summaryType}
+	text := fixture.text()
+	if summaryType == "chapters" {
+		text = fixture.timestampedText()
+	}
+
+	summary, err := call(ctx, r.timeout(), func(ctx context.Context) (*services.AISummary, error) {
+		return r.Provider.Summarize(ctx, text, summaryType)
+	})
+	if err != nil {
+		return result.failed(err)
+	}
+	result.Tokens = summary.TokensUsed
+	result.Output = marshalOutput(summary.Content)
+
+	result.Checks = append(result.Checks, summarySchemaCheck(fixture, summaryType, summary.Content))
+	if check, ok := coverageCheck(fixture.Summaries[summaryType].Expected, summaryText(summary.Content)); ok {
+		result.Checks = append(result.Checks, check)
+	}
+	r.judge(ctx, &result, fixture, summaryType+" summary")
+	return result.scored()
+}
+
+func (r *Runner) runExtraction(ctx context.Context, fixture *Fixture, extractionType string) Case {
+	result := Case{Fixture: fixture.ID, Task: taskExtraction + ":" + extractionType}
+	transcript := fixture.text()
+
+	extraction, err := call(ctx, r.timeout(), func(ctx context.Context) (*services.AIExtraction, error) {
+		return r.Provider.Extract(ctx, transcript, extractionType)
+	})
+	if err != nil {
+		return result.failed(err)
+	}
+	result.Tokens = extraction.TokensUsed
+	result.Output = marshalOutput(extraction.Items)
+
+	result.Checks = append(result.Checks, extractionSchemaCheck(extractionType, extraction.Items))
+	var citations, contents []string
+	for _, item := range extraction.Items {
+		switch extractionType {
+		case "code":
+			citations = append(citations, item.Code)
+			contents = append(contents, item.Code)
+		case "quotes":
+			citations = append(citations, item.Quote)
+			contents = append(contents, item.Quote)
+		default:
+			contents = append(contents, item.Action, item.Content)
+		}
+	}
+	if check, ok := groundingCheck(transcript, citations); ok {
+		result.Checks = append(result.Checks, check)
+	}
+	if check, ok := coverageCheck(fixture.Extractions[extractionType].Expected, strings.Join(contents, "\n")); ok {
+		result.Checks = append(result.Checks, check)
+	}
+	r.judge(ctx, &result, fixture, strings.ReplaceAll(extractionType, "_", " ")+" extraction")
+	return result.scored()
+}
+
+func (r *Runner) runQuestion(ctx context.Context, fixture *Fixture, n int, question Question) Case {
+	result := Case{Fixture: fixture.ID, Task: fmt.Sprintf("%s:%d", taskQA, n)}
+	transcript := fixture.text()
+
+	answer, err := call(ctx, r.timeout(), func(ctx context.Context) (*services.AIAnswer, error) {
+		return r.Provider.Answer(ctx, transcript, question.Question)
+	})
+	if err != nil {
+		return result.failed(err)
+	}
+	result.Tokens = answer.TokensUsed
+	result.Output = marshalOutput(map[string]any{
+		"question":   question.Question,
+		"answer":     answer.Answer,
+		"confidence": answer.Confidence,
+		"sources":    answer.Sources,
+		"not_found":  answer.NotFound,
+	})
+
+	result.Checks = append(result.Checks, answerSchemaCheck(answer))
+	notFound := CheckResult{Name: CheckNotFound, Score: 1}
+	if answer.NotFound != question.NotFound {
+		notFound = CheckResult{Name: CheckNotFound, Score: 0, Detail: fmt.Sprintf("not_found is %t, expected %t", answer.NotFound, question.NotFound)}
+	}
+	result.Checks = append(result.Checks, notFound)
+	if check, ok := groundingCheck(transcript, answer.Sources); ok {
+		result.Checks = append(result.Checks, check)
+	}
+	if !question.NotFound {
+		if check, ok := coverageCheck(question.Expected, answer.Answer); ok {
+			result.Checks = append(result.Checks, check)
+		}
+	}
+	r.judge(ctx, &result, fixture, fmt.Sprintf("answer to the question %q", question.Question))
+	return result.scored()
+}
+
+// judge adds the judge's grade of the case output. A failed grading only skips the check.
+func (r *Runner) judge(ctx context.Context, result *Case, fixture *Fixture, task string) {
+	if r.Judge == nil {
+		return
+	}
+	check, err := call(ctx, r.timeout(), func(ctx context.Context) (CheckResult, error) {
+		return r.Judge.Grade(ctx, fixture.text(), task, string(result.Output))
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "eval judge failed", "fixture", result.Fixture, "task", result.Task, "error", err)
+		return
+	}
+	result.Checks = append(result.Checks, check)
+}
+
+func (r *Runner) timeout() time.Duration {
+	if r.Timeout > 0 {
+		return r.Timeout
+	}
+	return DefaultTimeout
+}
+
+func call[T any](ctx context.Context, timeout time.Duration, fn func(context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+func (c Case) failed(err error) Case {
+	c.Error = err.Error()
+	c.Score = 0
+	return c
+}
+
+// scored sets the case score to the mean of its checks.
+func (c Case) scored() Case {
+	if len(c.Checks) == 0 {
+		c.Score = 0
+		return c
+	}
+	total := 0.0
+	for i := range c.Checks {
+		c.Checks[i].Score = round(c.Checks[i].Score)
+		total += c.Checks[i].Score
+	}
+	c.Score = round(total / float64(len(c.Checks)))
+	return c
+}
+
+// summaryText flattens every field of a summary for keyword coverage.
+func summaryText(content services.SummaryContent) string {
+	parts := []string{content.Text}
+	parts = append(parts, content.KeyPoints...)
+	for _, section := range content.Sections {
+		parts = append(parts, section.Title, section.Content)
+	}
+	for _, chapter := range content.Chapters {
+		parts = append(parts, chapter.Title)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func marshalOutput(output any) json.RawMessage {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func sortedKeys(m map[string]Expectation) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
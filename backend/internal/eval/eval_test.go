@@ -0,0 +1,184 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+func TestDefaultFixtures(t *testing.T) {
+	fixtures, err := DefaultFixtures()
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+	for _, fixture := range fixtures {
+		assert.NotEmpty(t, fixture.Title, fixture.ID)
+		assert.NotEmpty(t, fixture.Questions, fixture.ID)
+	}
+}
+
+func TestLoadFixtures_Validates(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"id": "same", "segments": [{"start_ms": 0, "text": "hi"}]}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"id": "same", "segments": [{"start_ms": 0, "text": "hi"}]}`), 0o644))
+	_, err := LoadFixtures(dir)
+	assert.ErrorContains(t, err, `fixture id "same" is also used by a.json`)
+
+	dir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.json"), []byte(`{"segments": []}`), 0o644))
+	_, err = LoadFixtures(dir)
+	assert.ErrorContains(t, err, "empty.json: fixture has no segments")
+}
+
+func TestRunner_MockProviderIsReproducible(t *testing.T) {
+	fixtures, err := DefaultFixtures()
+	require.NoError(t, err)
+	provider, err := services.NewMockProvider(services.MockOptions{})
+	require.NoError(t, err)
+	runner := &Runner{Provider: provider}
+
+	first := NewReport(runner.Run(context.Background(), fixtures))
+	second := NewReport(runner.Run(context.Background(), fixtures))
+	assert.Equal(t, first, second)
+
+	for _, c := range first.Cases {
+		assert.Empty(t, c.Error, c.Fixture+" "+c.Task)
+		require.NotEmpty(t, c.Checks)
+		assert.Equal(t, CheckSchema, c.Checks[0].Name)
+		assert.Equal(t, 1.0, c.Checks[0].Score, c.Fixture+" "+c.Task)
+	}
+	assert.Greater(t, first.Score, 0.5)
+}
+
+func TestRunner_RecordsProviderErrors(t *testing.T) {
+	provider, err := services.NewMockProvider(services.MockOptions{Failure: services.MockFailureUnavailable})
+	require.NoError(t, err)
+	fixture := Fixture{ID: "f", Segments: []Segment{{Text: "hello"}}, Summaries: map[string]Expectation{"brief": {}}}
+
+	cases := (&Runner{Provider: provider}).Run(context.Background(), []Fixture{fixture})
+	require.Len(t, cases, 1)
+	assert.Equal(t, "summary:brief", cases[0].Task)
+	assert.Equal(t, 0.0, cases[0].Score)
+	assert.Contains(t, cases[0].Error, "unavailable")
+
+	report := NewReport(cases)
+	assert.Equal(t, []TaskScore{{Task: "summary:brief", Cases: 1, Errors: 1}}, report.Tasks)
+}
+
+func TestGroundingCheck(t *testing.T) {
+	transcript := "Alice: Ship small changes often. Tests are the cheapest documentation you will ever write."
+
+	check, ok := groundingCheck(transcript, []string{
+		"TESTS are the cheapest documentation you will ever write",
+		"Ship small changes, often!",
+		"Always deploy on Fridays to keep the team sharp.",
+	})
+	require.True(t, ok)
+	assert.InDelta(t, 2.0/3, check.Score, 1e-9)
+	assert.Contains(t, check.Detail, "Always deploy on Fridays")
+
+	_, ok = groundingCheck(transcript, []string{"", "  "})
+	assert.False(t, ok, "nothing to check")
+}
+
+func TestCoverageCheck(t *testing.T) {
+	check, ok := coverageCheck([]string{"use the chi router", "validate request bodies", "deploy with Kubernetes"},
+		"The tutorial uses the chi router and validates each request body.")
+	require.True(t, ok)
+	assert.InDelta(t, 2.0/3, check.Score, 1e-9)
+	assert.Equal(t, `missing "deploy with Kubernetes"`, check.Detail)
+
+	_, ok = coverageCheck(nil, "anything")
+	assert.False(t, ok)
+}
+
+func TestSummarySchemaCheck_Chapters(t *testing.T) {
+	fixture := &Fixture{Segments: []Segment{{StartMs: 0, Text: "a"}, {StartMs: 5000, Text: "b"}}}
+
+	valid := summarySchemaCheck(fixture, "chapters", services.SummaryContent{Chapters: []services.SummaryChapter{
+		{Title: "Intro", StartMs: 0}, {Title: "Main", StartMs: 5000},
+	}})
+	assert.Equal(t, 1.0, valid.Score)
+
+	invalid := summarySchemaCheck(fixture, "chapters", services.SummaryContent{Chapters: []services.SummaryChapter{
+		{Title: "Intro", StartMs: 1000},
+	}})
+	assert.Less(t, invalid.Score, 1.0)
+	assert.Contains(t, invalid.Detail, "not on a segment")
+	assert.Contains(t, invalid.Detail, "does not start at 0")
+}
+
+// judgeGrader returns grade for every output.
+type judgeGrader struct {
+	grade services.AIGrade
+	err   error
+}
+
+func (g judgeGrader) Grade(context.Context, string, string, string) (*services.AIGrade, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	return &g.grade, nil
+}
+
+func TestJudge_Grade(t *testing.T) {
+	check, err := NewJudge(judgeGrader{grade: services.AIGrade{Score: 4, Reasoning: "Accurate but misses the deploy step."}}).Grade(context.Background(), "transcript", "brief summary", "{}")
+	require.NoError(t, err)
+	assert.Equal(t, CheckJudge, check.Name)
+	assert.Equal(t, 0.75, check.Score)
+	assert.Equal(t, "4/5: Accurate but misses the deploy step.", check.Detail)
+
+	provider, err := services.NewMockProvider(services.MockOptions{})
+	require.NoError(t, err)
+	fixture := Fixture{ID: "f", Segments: []Segment{{Text: "Go is a language."}}, Summaries: map[string]Expectation{"brief": {}}}
+	runner := &Runner{Provider: provider, Judge: NewJudge(judgeGrader{err: errors.New("judge down")})}
+	cases := runner.Run(context.Background(), []Fixture{fixture})
+	require.Len(t, cases, 1)
+	assert.Len(t, cases[0].Checks, 1, "a failed grading is skipped")
+
+	runner.Judge = NewJudge(provider)
+	cases = runner.Run(context.Background(), []Fixture{fixture})
+	require.Len(t, cases, 1)
+	require.Len(t, cases[0].Checks, 2)
+	assert.Equal(t, CheckJudge, cases[0].Checks[1].Name)
+}
+
+func TestReport_MarkdownComparesWithBaseline(t *testing.T) {
+	baseline := NewReport([]Case{
+		{Fixture: "f", Task: "summary:brief", Score: 1},
+		{Fixture: "f", Task: "qa:1", Score: 0.5},
+	})
+	baseline.Model, baseline.PromptVersion = "mock-1", "v1"
+
+	report := NewReport([]Case{
+		{Fixture: "f", Task: "summary:brief", Score: 0.75, Checks: []CheckResult{{Name: CheckCoverage, Score: 0.75, Detail: `missing "x"`}}},
+		{Fixture: "f", Task: "qa:1", Score: 0.5},
+		{Fixture: "f", Task: "qa:2", Score: 1},
+	})
+	report.Provider, report.Model, report.PromptVersion = "mock", "mock-1", "v2"
+	assert.Equal(t, 0.75, report.Score)
+
+	var markdown bytes.Buffer
+	require.NoError(t, report.WriteMarkdown(&markdown, baseline))
+	out := markdown.String()
+	assert.Contains(t, out, "- Score: **0.750** (=")
+	assert.Contains(t, out, "| qa | 2 | 0 | 0.750 | +0.250 |")
+	assert.Contains(t, out, "| f | summary:brief | 0.750 | -0.250 | coverage 0.75 |")
+	assert.Contains(t, out, "| f | qa:2 | 1.000 | new |")
+	assert.Contains(t, out, `- f summary:brief: coverage: missing "x"`)
+
+	var encoded bytes.Buffer
+	require.NoError(t, report.WriteJSON(&encoded))
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(path, encoded.Bytes(), 0o644))
+	decoded, err := ReadReport(path)
+	require.NoError(t, err)
+	assert.Equal(t, report, decoded)
+}
@@ -0,0 +1,157 @@
+package eval
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// embeddedFixtures is the built-in golden dataset.
+//
+//go:embed fixtures/*.json
+var embeddedFixtures embed.FS
+
+// Fixture is a golden transcript with the outputs expected from each AI operation.
+type Fixture struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Segments []Segment `json:"segments"`
+
+	// Summaries and Extractions are keyed by summary and extraction type. Every listed type is
+	// evaluated, even when it expects nothing.
+	Summaries   map[string]Expectation `json:"summaries,omitempty"`
+	Extractions map[string]Expectation `json:"extractions,omitempty"`
+	Questions   []Question             `json:"questions,omitempty"`
+}
+
+// Segment is one caption line of a fixture transcript.
+type Segment struct {
+	StartMs int64  `json:"start_ms"`
+	Speaker string `json:"speaker,omitempty"`
+	Text    string `json:"text"`
+}
+
+// Expectation lists points a good output covers, matched by keyword overlap.
+type Expectation struct {
+	Expected []string `json:"expected,omitempty"`
+}
+
+// Question is a Q&A case. NotFound questions are not answered by the transcript.
+type Question struct {
+	Question string   `json:"question"`
+	Expected []string `json:"expected,omitempty"`
+	NotFound bool     `json:"not_found,omitempty"`
+}
+
+// DefaultFixtures returns the embedded golden dataset.
+func DefaultFixtures() ([]Fixture, error) {
+	fixtures, err := fs.Sub(embeddedFixtures, "fixtures")
+	if err != nil {
+		return nil, err
+	}
+	return readFixtures(fixtures)
+}
+
+// LoadFixtures reads every *.json fixture in dir.
+func LoadFixtures(dir string) ([]Fixture, error) {
+	return readFixtures(os.DirFS(dir))
+}
+
+func readFixtures(fsys fs.FS) ([]Fixture, error) {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no fixtures found")
+	}
+
+	fixtures := make([]Fixture, 0, len(names))
+	seen := make(map[string]string, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if fixture.ID == "" {
+			fixture.ID = strings.TrimSuffix(path.Base(name), ".json")
+		}
+		if err := fixture.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if other, ok := seen[fixture.ID]; ok {
+			return nil, fmt.Errorf("%s: fixture id %q is also used by %s", name, fixture.ID, other)
+		}
+		seen[fixture.ID] = name
+		fixtures = append(fixtures, fixture)
+	}
+
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].ID < fixtures[j].ID })
+	return fixtures, nil
+}
+
+func (f *Fixture) validate() error {
+	if len(f.Segments) == 0 {
+		return errors.New("fixture has no segments")
+	}
+	for i, segment := range f.Segments {
+		if strings.TrimSpace(segment.Text) == "" {
+			return fmt.Errorf("segment %d has no text", i)
+		}
+		if i > 0 && segment.StartMs < f.Segments[i-1].StartMs {
+			return fmt.Errorf("segment %d starts before the previous one", i)
+		}
+	}
+	for i, question := range f.Questions {
+		if strings.TrimSpace(question.Question) == "" {
+			return fmt.Errorf("question %d is empty", i)
+		}
+	}
+	return nil
+}
+
+// text joins the segments the way the API sends transcripts: every speaker turn on its own line
+// prefixed with "Speaker: ".
+func (f *Fixture) text() string {
+	var builder strings.Builder
+	currentSpeaker := ""
+	for _, segment := range f.Segments {
+		text := strings.TrimSpace(segment.Text)
+		if segment.Speaker != "" && segment.Speaker != currentSpeaker {
+			if builder.Len() > 0 {
+				builder.WriteString("\n")
+			}
+			builder.WriteString(segment.Speaker)
+			builder.WriteString(": ")
+			currentSpeaker = segment.Speaker
+		} else if builder.Len() > 0 {
+			builder.WriteString(" ")
+		}
+		builder.WriteString(text)
+	}
+	return builder.String()
+}
+
+// timestampedText prefixes every segment with its start offset, as the API does for chapters.
+func (f *Fixture) timestampedText() string {
+	var builder strings.Builder
+	for _, segment := range f.Segments {
+		text := strings.TrimSpace(segment.Text)
+		if segment.Speaker != "" {
+			fmt.Fprintf(&builder, "[%d] %s: %s\n", segment.StartMs, segment.Speaker, text)
+			continue
+		}
+		fmt.Fprintf(&builder, "[%d] %s\n", segment.StartMs, text)
+	}
+	return strings.TrimSpace(builder.String())
+}
@@ -0,0 +1,33 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/services"
+)
+
+// Judge grades outputs with a second model. The model grades with its provider's dedicated
+// grading prompt and returns a structured score, so the prompt version under evaluation does
+// not frame the grading.
+type Judge struct {
+	grader services.Grader
+}
+
+// NewJudge grades outputs with grader.
+func NewJudge(grader services.Grader) *Judge {
+	return &Judge{grader: grader}
+}
+
+// Grade scores output, described by task (e.g. "brief summary"), from 0 to 1.
+func (j *Judge) Grade(ctx context.Context, transcript, task, output string) (CheckResult, error) {
+	grade, err := j.grader.Grade(ctx, transcript, task, output)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("judge: %w", err)
+	}
+	return CheckResult{
+		Name:   CheckJudge,
+		Score:  float64(grade.Score-1) / 4,
+		Detail: truncate(fmt.Sprintf("%d/5: %s", grade.Score, grade.Reasoning), 200),
+	}, nil
+}
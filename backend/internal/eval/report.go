@@ -0,0 +1,212 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Report is the result of an evaluation run. It records no timestamps or latencies, so reports
+// of two runs can be diffed, and a Markdown report can show the score changes to a baseline.
+type Report struct {
+	Provider      string      `json:"provider"`
+	Model         string      `json:"model"`
+	PromptVersion string      `json:"prompt_version"`
+	Judge         string      `json:"judge,omitempty"`
+	Score         float64     `json:"score"`
+	Tasks         []TaskScore `json:"tasks"`
+	Cases         []Case      `json:"cases"`
+}
+
+// TaskScore aggregates the cases of a task over all fixtures. Q&A cases form a single "qa" task.
+type TaskScore struct {
+	Task   string  `json:"task"`
+	Cases  int     `json:"cases"`
+	Errors int     `json:"errors"`
+	Score  float64 `json:"score"`
+}
+
+// NewReport scores cases per task and overall. Callers fill in the run description.
+func NewReport(cases []Case) *Report {
+	report := &Report{Cases: cases, Tasks: []TaskScore{}}
+	index := make(map[string]int)
+	total := 0.0
+	for _, c := range cases {
+		task := taskGroup(c.Task)
+		i, ok := index[task]
+		if !ok {
+			i = len(report.Tasks)
+			index[task] = i
+			report.Tasks = append(report.Tasks, TaskScore{Task: task})
+		}
+		report.Tasks[i].Cases++
+		if c.Error != "" {
+			report.Tasks[i].Errors++
+		}
+		report.Tasks[i].Score += c.Score
+		total += c.Score
+	}
+	for i := range report.Tasks {
+		report.Tasks[i].Score = round(report.Tasks[i].Score / float64(report.Tasks[i].Cases))
+	}
+	sort.Slice(report.Tasks, func(i, j int) bool { return report.Tasks[i].Task < report.Tasks[j].Task })
+	if len(cases) > 0 {
+		report.Score = round(total / float64(len(cases)))
+	}
+	return report
+}
+
+func taskGroup(task string) string {
+	if strings.HasPrefix(task, taskQA+":") {
+		return taskQA
+	}
+	return task
+}
+
+// ReadReport loads a JSON report, e.g. a baseline to compare with.
+func ReadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteMarkdown writes the task and case scores as Markdown tables, with the change to baseline
+// when it is not nil, followed by the details of every failed check.
+func (r *Report) WriteMarkdown(w io.Writer, baseline *Report) error {
+	var b strings.Builder
+	b.WriteString("# Evaluation report\n\n")
+	fmt.Fprintf(&b, "- Provider: `%s`", r.Provider)
+	if r.Model != "" {
+		fmt.Fprintf(&b, " (`%s`)", r.Model)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "- Prompt version: `%s`\n", r.PromptVersion)
+	if r.Judge != "" {
+		fmt.Fprintf(&b, "- Judge: `%s`\n", r.Judge)
+	}
+	fmt.Fprintf(&b, "- Score: **%.3f**", r.Score)
+	if baseline != nil {
+		fmt.Fprintf(&b, " (%s vs `%s` `%s`)", delta(r.Score, baseline.Score, true), baseline.Model, baseline.PromptVersion)
+	}
+	b.WriteString("\n\n")
+
+	var baseTasks map[string]TaskScore
+	var baseCases map[string]Case
+	if baseline != nil {
+		baseTasks = make(map[string]TaskScore, len(baseline.Tasks))
+		for _, task := range baseline.Tasks {
+			baseTasks[task.Task] = task
+		}
+		baseCases = make(map[string]Case, len(baseline.Cases))
+		for _, c := range baseline.Cases {
+			baseCases[c.Fixture+"/"+c.Task] = c
+		}
+	}
+
+	b.WriteString("## Tasks\n\n")
+	b.WriteString("| Task | Cases | Errors | Score |")
+	if baseline != nil {
+		b.WriteString(" Δ |")
+	}
+	b.WriteString("\n|---|---:|---:|---:|")
+	if baseline != nil {
+		b.WriteString("---:|")
+	}
+	b.WriteString("\n")
+	for _, task := range r.Tasks {
+		fmt.Fprintf(&b, "| %s | %d | %d | %.3f |", task.Task, task.Cases, task.Errors, task.Score)
+		if baseline != nil {
+			base, ok := baseTasks[task.Task]
+			fmt.Fprintf(&b, " %s |", delta(task.Score, base.Score, ok))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n## Cases\n\n")
+	b.WriteString("| Fixture | Task | Score |")
+	if baseline != nil {
+		b.WriteString(" Δ |")
+	}
+	b.WriteString(" Checks |\n|---|---|---:|")
+	if baseline != nil {
+		b.WriteString("---:|")
+	}
+	b.WriteString("---|\n")
+	for _, c := range r.Cases {
+		fmt.Fprintf(&b, "| %s | %s | %.3f |", c.Fixture, c.Task, c.Score)
+		if baseline != nil {
+			base, ok := baseCases[c.Fixture+"/"+c.Task]
+			fmt.Fprintf(&b, " %s |", delta(c.Score, base.Score, ok))
+		}
+		fmt.Fprintf(&b, " %s |\n", checkSummary(c))
+	}
+
+	var failures []string
+	for _, c := range r.Cases {
+		if c.Error != "" {
+			failures = append(failures, fmt.Sprintf("- %s %s: error: %s", c.Fixture, c.Task, markdownEscape(c.Error)))
+		}
+		for _, check := range c.Checks {
+			if check.Score < 1 && check.Detail != "" {
+				failures = append(failures, fmt.Sprintf("- %s %s: %s: %s", c.Fixture, c.Task, check.Name, markdownEscape(check.Detail)))
+			}
+		}
+	}
+	if len(failures) > 0 {
+		b.WriteString("\n## Failures\n\n")
+		b.WriteString(strings.Join(failures, "\n"))
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func checkSummary(c Case) string {
+	if c.Error != "" {
+		return "error"
+	}
+	parts := make([]string, 0, len(c.Checks))
+	for _, check := range c.Checks {
+		parts = append(parts, fmt.Sprintf("%s %.2f", check.Name, check.Score))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// delta formats the change from base, or "new" when there is no base.
+func delta(score, base float64, ok bool) string {
+	if !ok {
+		return "new"
+	}
+	diff := round(score - base)
+	if diff == 0 {
+		return "="
+	}
+	return fmt.Sprintf("%+.3f", diff)
+}
+
+func markdownEscape(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
+
+// round keeps three decimals so reports do not churn on float noise.
+func round(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
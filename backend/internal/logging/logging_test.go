@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line), buf.String())
+	return line
+}
+
+func TestNew_RejectsUnknownLevelAndFormat(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "verbose", "json")
+	assert.ErrorContains(t, err, "invalid log level")
+
+	_, err = New(&bytes.Buffer{}, "info", "xml")
+	assert.ErrorContains(t, err, "invalid log format")
+}
+
+func TestNew_FiltersByLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", "json")
+	require.NoError(t, err)
+
+	logger.Info("not written")
+	assert.Empty(t, buf.String())
+
+	logger.Warn("written", "count", 3)
+	line := decodeLine(t, &buf)
+	assert.Equal(t, "WARN", line["level"])
+	assert.Equal(t, "written", line["msg"])
+	assert.Equal(t, 3.0, line["count"])
+}
+
+func TestHandler_AddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "json")
+	require.NoError(t, err)
+
+	var ctx context.Context
+	handler := middleware.RequestID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	logger.InfoContext(ctx, "in request")
+	line := decodeLine(t, &buf)
+	assert.Equal(t, middleware.GetReqID(ctx), line["request_id"])
+	assert.NotEmpty(t, line["request_id"])
+}
+
+func TestHandler_RedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "json")
+	require.NoError(t, err)
+
+	logger.With("password", "hunter2").Error(
+		"request to https://generativelanguage.googleapis.com/v1beta/models/x:generateContent?key=AIzaSecret failed",
+		"dsn", "postgres://app:s3cret@db:5432/yt?sslmode=disable",
+		"error", errors.New(`Post "https://example.com/?alt=json&api_key=abc123": EOF`),
+		slog.Group("request", "authorization", "Bearer tok", "header", "Authorization: Bearer tok"),
+		"key", "ytk_abcdef123456",
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "AIzaSecret", "s3cret", "abc123", "tok\"", "abcdef123456"} {
+		assert.NotContains(t, out, secret)
+	}
+	line := decodeLine(t, &buf)
+	assert.Equal(t, Redacted, line["password"])
+	assert.Equal(t, "postgres://app:"+Redacted+"@db:5432/yt?sslmode=disable", line["dsn"])
+	assert.Contains(t, line["msg"], "generateContent?key="+Redacted+" failed")
+	assert.Contains(t, line["error"], "alt=json&api_key="+Redacted)
+	assert.Equal(t, map[string]any{"authorization": Redacted, "header": "Authorization: Bearer " + Redacted}, line["request"])
+	assert.Equal(t, "ytk_"+Redacted, line["key"])
+}
+
+func TestRedact_MasksSessionTokens(t *testing.T) {
+	assert.Equal(t, "session yts_"+Redacted+" expired", Redact("session yts_Zm9vYmFyLWJhei0x expired"))
+	assert.Equal(t, "cookie=yts_"+Redacted+"; key ytk_"+Redacted,
+		Redact("cookie=yts_abc-123_def; key ytk_abcdef123456"))
+}
+
+func TestRedact_LeavesOrdinaryTextAlone(t *testing.T) {
+	for _, s := range []string{
+		"http://localhost:8080/api/v1/transcripts?language=en",
+		"transcript 3f2c not found",
+		"postgres://localhost:5432/yt",
+	} {
+		assert.Equal(t, s, Redact(s))
+	}
+}
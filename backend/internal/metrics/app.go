@@ -0,0 +1,45 @@
+// Package metrics defines the application's Prometheus collectors. They are registered with
+// the default registry, which also carries the Go runtime and process collectors.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Application metrics, all registered with prometheus.DefaultRegisterer.
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "yt_http_requests_total",
+		Help: "HTTP requests served, by route pattern and status code.",
+	}, []string{"method", "route", "status"})
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "yt_http_request_duration_seconds",
+		Help:    "Time to serve HTTP requests, by route pattern.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	YouTubeFetches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "yt_youtube_fetches_total",
+		Help: "Calls to YouTube, by operation (metadata, transcript) and outcome (ok or the error class).",
+	}, []string{"operation", "outcome"})
+
+	AICalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "yt_ai_calls_total",
+		Help: "AI provider calls, by provider, model, operation and outcome (ok, error).",
+	}, []string{"provider", "model", "operation", "outcome"})
+	AICallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "yt_ai_call_duration_seconds",
+		Help:    "AI provider call latency, by provider, model and operation.",
+		Buckets: []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 80, 160},
+	}, []string{"provider", "model", "operation"})
+	AITokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "yt_ai_tokens_total",
+		Help: "Tokens used by AI provider calls, by provider, model, operation and kind (prompt, completion).",
+	}, []string{"provider", "model", "operation", "kind"})
+
+	AICacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "yt_ai_cache_lookups_total",
+		Help: "Lookups of stored AI results before generating, by cache (summary, extraction) and result (hit, miss).",
+	}, []string{"cache", "result"})
+)
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	poolConnections = prometheus.NewDesc("yt_db_pool_connections_total",
+		"Connections currently open in the pool.", nil, nil)
+	poolAcquired = prometheus.NewDesc("yt_db_pool_connections_acquired",
+		"Connections currently in use.", nil, nil)
+	poolIdle = prometheus.NewDesc("yt_db_pool_connections_idle",
+		"Idle connections in the pool.", nil, nil)
+	poolMax = prometheus.NewDesc("yt_db_pool_connections_max",
+		"Maximum size of the pool.", nil, nil)
+	poolAcquires = prometheus.NewDesc("yt_db_pool_acquires_total",
+		"Connections acquired from the pool.", nil, nil)
+	poolEmptyAcquires = prometheus.NewDesc("yt_db_pool_empty_acquires_total",
+		"Acquires that waited because the pool was empty.", nil, nil)
+	poolCanceledAcquires = prometheus.NewDesc("yt_db_pool_canceled_acquires_total",
+		"Acquires canceled before a connection was available.", nil, nil)
+	poolAcquireDuration = prometheus.NewDesc("yt_db_pool_acquire_duration_seconds_total",
+		"Time spent waiting for connections.", nil, nil)
+)
+
+// PoolCollector reports pgx connection pool statistics read at scrape time. It reports
+// nothing while stat returns nil, e.g. when the server runs without a pool.
+type PoolCollector struct {
+	stat func() *pgxpool.Stat
+}
+
+// NewPoolCollector creates a collector over stat, usually db.DB.Stat.
+func NewPoolCollector(stat func() *pgxpool.Stat) *PoolCollector {
+	return &PoolCollector{stat: stat}
+}
+
+// Describe implements prometheus.Collector.
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolConnections
+	ch <- poolAcquired
+	ch <- poolIdle
+	ch <- poolMax
+	ch <- poolAcquires
+	ch <- poolEmptyAcquires
+	ch <- poolCanceledAcquires
+	ch <- poolAcquireDuration
+}
+
+// Collect implements prometheus.Collector.
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.stat()
+	if stat == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(poolConnections, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolMax, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolCollector_SkipsWithoutPool(t *testing.T) {
+	collector := NewPoolCollector(func() *pgxpool.Stat { return nil })
+
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+
+	descs := make(chan *prometheus.Desc, 16)
+	collector.Describe(descs)
+	close(descs)
+	assert.Len(t, descs, 8)
+}
+
+func TestAppMetrics_PassLint(t *testing.T) {
+	HTTPRequests.WithLabelValues("GET", "/healthz", "200").Inc()
+	HTTPRequestDuration.WithLabelValues("GET", "/healthz").Observe(0.01)
+
+	problems, err := testutil.GatherAndLint(prometheus.DefaultGatherer)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Operation names used as AIService routing keys. A route key is either an operation or an
+// operation and type joined by a dot, e.g. "summarize.detailed" or "extract.code".
+const (
+	OperationSummarize     = "summarize"
+	OperationExtract       = "extract"
+	OperationTranslate     = "translate"
+	OperationAnswer        = "answer"
+	OperationPunctuate     = "punctuate"
+	OperationLabelSpeakers = "label_speakers"
+)
+
+// NamedProvider is a provider in an AIService chain. Name and Model identify it in logs.
+type NamedProvider struct {
+	Name     string
+	Model    string
+	Provider AIProvider
+}
+
+func (p NamedProvider) String() string {
+	if p.Model == "" {
+		return p.Name
+	}
+	return p.Name + ":" + p.Model
+}
+
+// WithRoute sends an operation (or operation.type) to the given providers first. The default
+// chain is appended as fallback, skipping providers already listed.
+func WithRoute(key string, providers ...NamedProvider) AIServiceOption {
+	return func(s *AIService) {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			return
+		}
+		s.routes[key] = compactChain(append(append([]NamedProvider{}, providers...), s.chain...))
+	}
+}
+
+// chainFor returns the providers for an operation, preferring the most specific route.
+func (s *AIService) chainFor(operation, kind string) []NamedProvider {
+	if s == nil {
+		return nil
+	}
+	if kind != "" {
+		if chain, ok := s.routes[operation+"."+strings.ToLower(kind)]; ok {
+			return chain
+		}
+	}
+	if chain, ok := s.routes[operation]; ok {
+		return chain
+	}
+	return s.chain
+}
+
+// IsRetryableAIError reports whether another provider may succeed where this one failed.
+func IsRetryableAIError(err error) bool {
+	return errors.Is(err, ErrAIRateLimited) ||
+		errors.Is(err, ErrAIQuotaExceeded) ||
+		errors.Is(err, ErrAIServiceUnavailable)
+}
+
+// withFailover calls each provider in order until one succeeds or returns a non-retryable error.
+// When every provider fails the last error is returned so callers can map it as before.
+func withFailover[T any](ctx context.Context, chain []NamedProvider, operation string, call func(AIProvider) (T, error)) (T, error) {
+	var zero T
+	if len(chain) == 0 {
+		return zero, ErrAIProviderNotConfigured
+	}
+
+	var lastErr error
+	for i, entry := range chain {
+		result, err := call(entry.Provider)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		if !IsRetryableAIError(err) || ctx.Err() != nil {
+			return zero, err
+		}
+		if i+1 < len(chain) {
+			slog.WarnContext(ctx, "AI call failed, falling back", "operation", operation, "provider", entry.String(), "fallback", chain[i+1].String(), "error", err)
+		}
+	}
+
+	if len(chain) > 1 {
+		return zero, fmt.Errorf("all %d AI providers failed: %w", len(chain), lastErr)
+	}
+	return zero, lastErr
+}
+
+// compactChain drops nil providers and repeated name/model pairs while keeping order.
+func compactChain(chain []NamedProvider) []NamedProvider {
+	result := make([]NamedProvider, 0, len(chain))
+	seen := make(map[string]bool)
+	for _, entry := range chain {
+		if entry.Provider == nil {
+			continue
+		}
+		key := entry.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, entry)
+	}
+	return result
+}
+
+// defaultProviderModels is used for fallback and routed providers configured without a model.
+var defaultProviderModels = map[string]string{
+	"openai":    "gpt-4",
+	"anthropic": "claude-3-5-sonnet-20241022",
+	"google":    "gemini-1.5-flash",
+	"local":     "llama3.1",
+	"mock":      DefaultMockModel,
+}
+
+// NewNamedProvider constructs a provider by name ("openai", "anthropic", "google", "local" or
+// "mock"). An empty model selects the provider's default model. baseURL is the endpoint of the
+// "local" provider, defaulting to DefaultLocalBaseURL, and is ignored by the others. The "mock"
+// provider is created without latency or failures; use NewMockProvider to configure them.
+func NewNamedProvider(name, apiKey, baseURL, model string, maxTokens int, temperature float64) (NamedProvider, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if model == "" {
+		model = defaultProviderModels[name]
+	}
+
+	var (
+		provider AIProvider
+		err      error
+	)
+	switch name {
+	case "openai":
+		provider, err = NewOpenAIProvider(apiKey, model, maxTokens, temperature)
+	case "anthropic":
+		provider, err = NewAnthropicProvider(apiKey, model, maxTokens, temperature)
+	case "google":
+		provider, err = NewGeminiProvider(apiKey, model, maxTokens, temperature)
+	case "local":
+		if baseURL == "" {
+			baseURL = DefaultLocalBaseURL
+		}
+		provider, err = NewLocalProvider(baseURL, apiKey, model, maxTokens, temperature)
+	case "mock":
+		provider, err = NewMockProvider(MockOptions{Model: model})
+	default:
+		return NamedProvider{}, fmt.Errorf("%w: %s", ErrInvalidAIProvider, name)
+	}
+	if err != nil {
+		return NamedProvider{}, err
+	}
+
+	return NamedProvider{Name: name, Model: model, Provider: provider}, nil
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider answers every operation with its model name or the configured error.
+type fakeProvider struct {
+	model string
+	err   error
+	calls int
+}
+
+func (p *fakeProvider) Summarize(context.Context, string, string) (*AISummary, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &AISummary{Model: p.model}, nil
+}
+
+func (p *fakeProvider) Extract(context.Context, string, string) (*AIExtraction, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &AIExtraction{Model: p.model}, nil
+}
+
+func (p *fakeProvider) Translate(context.Context, string, string) (*AITranslation, error) {
+	p.calls++
+	return nil, p.err
+}
+
+func (p *fakeProvider) Answer(context.Context, string, string) (*AIAnswer, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &AIAnswer{Model: p.model}, nil
+}
+
+func (p *fakeProvider) Punctuate(_ context.Context, lines []string) (*AIPunctuation, error) {
+	p.calls++
+	return &AIPunctuation{Lines: lines, Model: p.model}, p.err
+}
+
+func (p *fakeProvider) LabelSpeakers(context.Context, []string, []string) (*AISpeakerTurns, error) {
+	p.calls++
+	return &AISpeakerTurns{Model: p.model}, p.err
+}
+
+func TestAIService_FailsOverOnRetryableErrors(t *testing.T) {
+	for _, retryable := range []error{ErrAIRateLimited, ErrAIQuotaExceeded, ErrAIServiceUnavailable} {
+		t.Run(retryable.Error(), func(t *testing.T) {
+			primary := &fakeProvider{model: "gpt-4", err: fmt.Errorf("openai: %w", retryable)}
+			fallback := &fakeProvider{model: "claude"}
+			svc := NewAIServiceWithChain([]NamedProvider{
+				{Name: "openai", Model: "gpt-4", Provider: primary},
+				{Name: "anthropic", Model: "claude", Provider: fallback},
+			})
+
+			summary, err := svc.Summarize(context.Background(), "text", "brief")
+			require.NoError(t, err)
+			assert.Equal(t, "claude", summary.Model, "stored model reflects the provider that answered")
+			assert.Equal(t, 1, primary.calls)
+			assert.Equal(t, 1, fallback.calls)
+		})
+	}
+}
+
+func TestAIService_DoesNotFailOverOnOtherErrors(t *testing.T) {
+	primary := &fakeProvider{err: errors.New("parse summary response: bad json")}
+	fallback := &fakeProvider{model: "claude"}
+	svc := NewAIServiceWithChain([]NamedProvider{
+		{Name: "openai", Provider: primary},
+		{Name: "anthropic", Provider: fallback},
+	})
+
+	_, err := svc.Summarize(context.Background(), "text", "brief")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad json")
+	assert.Equal(t, 0, fallback.calls)
+}
+
+func TestAIService_ReturnsLastErrorWhenChainExhausted(t *testing.T) {
+	svc := NewAIServiceWithChain([]NamedProvider{
+		{Name: "openai", Provider: &fakeProvider{err: ErrAIRateLimited}},
+		{Name: "google", Provider: &fakeProvider{err: ErrAIServiceUnavailable}},
+	})
+
+	_, err := svc.Answer(context.Background(), "text", "question")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrAIServiceUnavailable)
+}
+
+func TestAIService_RoutesByOperationAndType(t *testing.T) {
+	primary := &fakeProvider{model: "gpt-4"}
+	gemini := &fakeProvider{model: "gemini"}
+	claude := &fakeProvider{model: "claude"}
+	svc := NewAIServiceWithChain(
+		[]NamedProvider{{Name: "openai", Model: "gpt-4", Provider: primary}},
+		WithRoute("extract", NamedProvider{Name: "google", Model: "gemini", Provider: gemini}),
+		WithRoute("Summarize.Detailed", NamedProvider{Name: "anthropic", Model: "claude", Provider: claude}),
+	)
+
+	extraction, err := svc.Extract(context.Background(), "text", "code")
+	require.NoError(t, err)
+	assert.Equal(t, "gemini", extraction.Model)
+
+	detailed, err := svc.Summarize(context.Background(), "text", "detailed")
+	require.NoError(t, err)
+	assert.Equal(t, "claude", detailed.Model)
+
+	brief, err := svc.Summarize(context.Background(), "text", "brief")
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4", brief.Model)
+}
+
+func TestAIService_RouteFallsBackToDefaultChain(t *testing.T) {
+	primary := &fakeProvider{model: "gpt-4"}
+	gemini := &fakeProvider{err: ErrAIRateLimited}
+	svc := NewAIServiceWithChain(
+		[]NamedProvider{{Name: "openai", Model: "gpt-4", Provider: primary}},
+		WithRoute("extract", NamedProvider{Name: "google", Model: "gemini", Provider: gemini}),
+	)
+
+	extraction, err := svc.Extract(context.Background(), "text", "quotes")
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4", extraction.Model)
+	assert.Equal(t, 1, gemini.calls)
+}
+
+func TestAIService_NoProviders(t *testing.T) {
+	svc := NewAIService(nil, "")
+	_, err := svc.Summarize(context.Background(), "text", "brief")
+	assert.ErrorIs(t, err, ErrAIProviderNotConfigured)
+}
+
+func TestNewNamedProvider(t *testing.T) {
+	provider, err := NewNamedProvider("Anthropic", "key", "", "", 1000, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic", provider.Name)
+	assert.Equal(t, defaultProviderModels["anthropic"], provider.Model)
+
+	_, err = NewNamedProvider("cohere", "key", "", "", 1000, 0.5)
+	assert.ErrorIs(t, err, ErrInvalidAIProvider)
+
+	local, err := NewNamedProvider("local", "", "", "", 1000, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, "local:llama3.1", local.String())
+	assert.Equal(t, DefaultLocalBaseURL, local.Provider.(*LocalProvider).BaseURL())
+
+	mock, err := NewNamedProvider("mock", "", "", "", 1000, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, "mock:mock-1", mock.String())
+	assert.IsType(t, &MockProvider{}, mock.Provider)
+}
@@ -20,14 +20,43 @@ type AIProvider interface {
 	Extract(ctx context.Context, text string, extractionType string) (*AIExtraction, error)
 	Translate(ctx context.Context, text string, targetLang string) (*AITranslation, error)
 	Answer(ctx context.Context, text string, question string) (*AIAnswer, error)
+	Punctuate(ctx context.Context, lines []string) (*AIPunctuation, error)
+	LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error)
+}
+
+// Grader grades an output produced from a transcript, for evaluations. Providers grade with the
+// default prompt version's grading prompt whatever version the context selects, so the prompts
+// under evaluation do not frame their own grading.
+type Grader interface {
+	Grade(ctx context.Context, transcript, task, output string) (*AIGrade, error)
+}
+
+// TokenUsage splits the tokens of a provider call into prompt (input) and completion (output) tokens.
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Total returns prompt plus completion tokens.
+func (u TokenUsage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+func (u TokenUsage) add(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+	}
 }
 
 // AISummary represents an AI-generated summary
 type AISummary struct {
-	Content    SummaryContent
-	Model      string
-	TokensUsed int
-	Type       string // 'brief', 'detailed', 'key_points'
+	Content       SummaryContent
+	Model         string
+	TokensUsed    int
+	Usage         TokenUsage
+	PromptVersion string
+	Type          string // 'brief', 'detailed', 'key_points', 'chapters'
 }
 
 // SummaryContent captures structured summary data returned by providers.
@@ -35,6 +64,7 @@ type SummaryContent struct {
 	Text      string           `json:"text"`
 	KeyPoints []string         `json:"key_points,omitempty"`
 	Sections  []SummarySection `json:"sections,omitempty"`
+	Chapters  []SummaryChapter `json:"chapters,omitempty"`
 }
 
 // SummarySection represents a titled section of a detailed summary.
@@ -43,12 +73,22 @@ type SummarySection struct {
 	Content string `json:"content"`
 }
 
+// SummaryChapter represents a titled chapter anchored to a transcript timestamp.
+// EndMs is derived from the next chapter (or the video duration) once anchored.
+type SummaryChapter struct {
+	Title   string `json:"title"`
+	StartMs int64  `json:"start_ms"`
+	EndMs   int64  `json:"end_ms,omitempty"`
+}
+
 // AIExtraction represents extracted content (code, quotes, action items)
 type AIExtraction struct {
-	Items      []ExtractionItem
-	Model      string
-	TokensUsed int
-	Type       string // 'code', 'quotes', 'action_items'
+	Items         []ExtractionItem
+	Model         string
+	TokensUsed    int
+	Usage         TokenUsage
+	PromptVersion string
+	Type          string // 'code', 'quotes', 'action_items'
 }
 
 // ExtractionItem represents a single extracted item with flexible structure
@@ -85,64 +125,159 @@ type AITranslation struct {
 	TranslatedText string
 	Model          string
 	TokensUsed     int
+	Usage          TokenUsage
 	TargetLanguage string
 }
 
+// AIPunctuation represents caption lines with restored punctuation and capitalization.
+// Lines correspond one-to-one with the lines sent to the provider.
+type AIPunctuation struct {
+	Lines         []string
+	Model         string
+	TokensUsed    int
+	Usage         TokenUsage
+	PromptVersion string
+}
+
+// AISpeakerTurns represents the speaker changes detected in a batch of transcript lines.
+type AISpeakerTurns struct {
+	Turns         []SpeakerTurn
+	Model         string
+	TokensUsed    int
+	Usage         TokenUsage
+	PromptVersion string
+}
+
+// SpeakerTurn marks the 1-based line at which Speaker starts talking.
+type SpeakerTurn struct {
+	Line    int    `json:"line"`
+	Speaker string `json:"speaker"`
+}
+
+// AIGrade represents a judge model's grade of an output, from 1 (poor) to 5 (excellent).
+type AIGrade struct {
+	Score         int
+	Reasoning     string
+	Model         string
+	TokensUsed    int
+	Usage         TokenUsage
+	PromptVersion string
+}
+
 // AIAnswer represents a Q&A response
 type AIAnswer struct {
-	ID           string
-	TranscriptID string
-	Question     string
-	Answer       string
-	Confidence   string   // "high", "medium", "low"
-	Sources      []string // Relevant quotes from transcript
-	NotFound     bool     // True if answer not in transcript
-	Model        string
-	TokensUsed   int
-}
-
-// AIService manages AI operations with a configured provider
+	ID            string
+	TranscriptID  string
+	Question      string
+	Answer        string
+	Confidence    string   // "high", "medium", "low"
+	Sources       []string // Relevant quotes from transcript
+	NotFound      bool     // True if answer not in transcript
+	Model         string
+	TokensUsed    int
+	Usage         TokenUsage
+	PromptVersion string
+}
+
+// AIService manages AI operations over an ordered chain of providers. Requests go to the
+// first provider of the chain routed for the operation and fail over to the next one when a
+// provider is rate limited, out of quota or unavailable.
 type AIService struct {
-	provider AIProvider
-	model    string
+	chain   []NamedProvider
+	routes  map[string][]NamedProvider
+	model   string
+	prompts *PromptSelector
+}
+
+// AIServiceOption configures optional AIService behaviour.
+type AIServiceOption func(*AIService)
+
+// WithPromptSelector makes the service pick the prompt version of each call with selector.
+// Calls whose context already carries prompts (see WithPromptSet) keep them.
+func WithPromptSelector(selector *PromptSelector) AIServiceOption {
+	return func(s *AIService) {
+		s.prompts = selector
+	}
 }
 
 // NewAIService creates a new AI service with the given provider and model
-func NewAIService(provider AIProvider, model string) *AIService {
-	return &AIService{
-		provider: provider,
-		model:    model,
+func NewAIService(provider AIProvider, model string, opts ...AIServiceOption) *AIService {
+	var chain []NamedProvider
+	if provider != nil {
+		chain = []NamedProvider{{Name: "primary", Model: model, Provider: provider}}
+	}
+	return NewAIServiceWithChain(chain, opts...)
+}
+
+// NewAIServiceWithChain creates an AI service that tries providers in order.
+func NewAIServiceWithChain(chain []NamedProvider, opts ...AIServiceOption) *AIService {
+	s := &AIService{
+		chain:  compactChain(chain),
+		routes: make(map[string][]NamedProvider),
+	}
+	if len(s.chain) > 0 {
+		s.model = s.chain[0].Model
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // Summarize generates a summary of the given text
 func (s *AIService) Summarize(ctx context.Context, text string, summaryType string) (*AISummary, error) {
-	if s.provider == nil {
-		return nil, ErrAIProviderNotConfigured
-	}
-	return s.provider.Summarize(ctx, text, summaryType)
+	ctx = s.selectPrompts(ctx)
+	return withFailover(ctx, s.chainFor(OperationSummarize, summaryType), OperationSummarize, func(p AIProvider) (*AISummary, error) {
+		return p.Summarize(ctx, text, summaryType)
+	})
 }
 
 // Extract extracts specific content from the text (code, quotes, action items)
 func (s *AIService) Extract(ctx context.Context, text string, extractionType string) (*AIExtraction, error) {
-	if s.provider == nil {
-		return nil, ErrAIProviderNotConfigured
-	}
-	return s.provider.Extract(ctx, text, extractionType)
+	ctx = s.selectPrompts(ctx)
+	return withFailover(ctx, s.chainFor(OperationExtract, extractionType), OperationExtract, func(p AIProvider) (*AIExtraction, error) {
+		return p.Extract(ctx, text, extractionType)
+	})
 }
 
 // Translate translates the text to the target language
 func (s *AIService) Translate(ctx context.Context, text string, targetLang string) (*AITranslation, error) {
-	if s.provider == nil {
-		return nil, ErrAIProviderNotConfigured
-	}
-	return s.provider.Translate(ctx, text, targetLang)
+	return withFailover(ctx, s.chainFor(OperationTranslate, ""), OperationTranslate, func(p AIProvider) (*AITranslation, error) {
+		return p.Translate(ctx, text, targetLang)
+	})
 }
 
 // Answer answers a question about the text
 func (s *AIService) Answer(ctx context.Context, text string, question string) (*AIAnswer, error) {
-	if s.provider == nil {
-		return nil, ErrAIProviderNotConfigured
+	ctx = s.selectPrompts(ctx)
+	return withFailover(ctx, s.chainFor(OperationAnswer, ""), OperationAnswer, func(p AIProvider) (*AIAnswer, error) {
+		return p.Answer(ctx, text, question)
+	})
+}
+
+// Punctuate restores punctuation and capitalization for caption lines
+func (s *AIService) Punctuate(ctx context.Context, lines []string) (*AIPunctuation, error) {
+	ctx = s.selectPrompts(ctx)
+	return withFailover(ctx, s.chainFor(OperationPunctuate, ""), OperationPunctuate, func(p AIProvider) (*AIPunctuation, error) {
+		return p.Punctuate(ctx, lines)
+	})
+}
+
+// LabelSpeakers detects speaker turns in transcript lines
+func (s *AIService) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error) {
+	ctx = s.selectPrompts(ctx)
+	return withFailover(ctx, s.chainFor(OperationLabelSpeakers, ""), OperationLabelSpeakers, func(p AIProvider) (*AISpeakerTurns, error) {
+		return p.LabelSpeakers(ctx, lines, knownSpeakers)
+	})
+}
+
+// selectPrompts adds the prompts chosen by the service's selector to ctx.
+func (s *AIService) selectPrompts(ctx context.Context) context.Context {
+	if s.prompts == nil {
+		return ctx
+	}
+	if _, ok := ctx.Value(promptSetContextKey{}).(*PromptSet); ok {
+		return ctx
 	}
-	return s.provider.Answer(ctx, text, question)
+	return WithPromptSet(ctx, s.prompts.Select(ctx))
 }
@@ -31,16 +31,34 @@ func NewAnthropicProvider(apiKey, model string, maxTokens int, temperature float
 		model:       model,
 		maxTokens:   maxTokens,
 		temperature: temperature,
-		httpClient:  &http.Client{},
+		httpClient:  newRetryingHTTPClient("anthropic", DefaultRetryPolicy),
 	}, nil
 }
 
+// Ping lists one model, which checks the endpoint and the key without a completion.
+func (p *AnthropicProvider) Ping(ctx context.Context) error {
+	return pingHTTP(ctx, p.httpClient, "https://api.anthropic.com/v1/models?limit=1", http.Header{
+		"X-Api-Key":         {p.apiKey},
+		"Anthropic-Version": {"2023-06-01"},
+	})
+}
+
 type anthropicRequest struct {
-	Model       string              `json:"model"`
-	Messages    []anthropicMessage  `json:"messages"`
-	MaxTokens   int                 `json:"max_tokens"`
-	Temperature float64             `json:"temperature"`
-	System      string              `json:"system,omitempty"`
+	Model       string             `json:"model"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+	System      string             `json:"system,omitempty"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	ToolChoice  *anthropicTool     `json:"tool_choice,omitempty"`
+}
+
+// anthropicTool is a tool definition, or with Type "tool" a tool_choice forcing that tool.
+type anthropicTool struct {
+	Type        string       `json:"type,omitempty"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	InputSchema schemaObject `json:"input_schema,omitempty"`
 }
 
 type anthropicMessage struct {
@@ -54,8 +72,9 @@ type anthropicResponse struct {
 }
 
 type anthropicContent struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	Input json.RawMessage `json:"input,omitempty"`
 }
 
 type anthropicUsage struct {
@@ -63,7 +82,12 @@ type anthropicUsage struct {
 	OutputTokens int `json:"output_tokens"`
 }
 
-func (p *AnthropicProvider) complete(ctx context.Context, systemPrompt, userPrompt string) (string, int, error) {
+// complete calls the Messages API. With a schema, Claude is forced to call a tool whose input
+// schema it is, and the tool input is returned as the reply.
+func (p *AnthropicProvider) complete(ctx context.Context, systemPrompt, userPrompt string, schema *outputSchema) (_ string, usage TokenUsage, err error) {
+	ctx, span := startCompletionSpan(ctx, "anthropic", p.model, schema)
+	defer func() { endCompletionSpan(span, usage, err) }()
+
 	reqBody := anthropicRequest{
 		Model: p.model,
 		Messages: []anthropicMessage{
@@ -76,15 +100,23 @@ func (p *AnthropicProvider) complete(ctx context.Context, systemPrompt, userProm
 		Temperature: p.temperature,
 		System:      systemPrompt,
 	}
+	if schema != nil {
+		reqBody.Tools = []anthropicTool{{
+			Name:        schema.name,
+			Description: fmt.Sprintf("Record the %s. Always call this tool with the complete result.", schema.name),
+			InputSchema: schema.render(schemaDialectJSON),
+		}}
+		reqBody.ToolChoice = &anthropicTool{Type: "tool", Name: schema.name}
+	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", 0, fmt.Errorf("marshal request: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", 0, fmt.Errorf("create request: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -93,30 +125,40 @@ func (p *AnthropicProvider) complete(ctx context.Context, systemPrompt, userProm
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return "", 0, fmt.Errorf("anthropic completion: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("anthropic completion: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", 0, fmt.Errorf("read response: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", 0, fmt.Errorf("anthropic API error (status %d): %s", resp.StatusCode, string(body))
+		return "", TokenUsage{}, fmt.Errorf("anthropic API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var anthropicResp anthropicResponse
 	if err := json.Unmarshal(body, &anthropicResp); err != nil {
-		return "", 0, fmt.Errorf("unmarshal response: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("unmarshal response: %w", err)
 	}
 
 	if len(anthropicResp.Content) == 0 {
-		return "", 0, errors.New("no content in response")
+		return "", TokenUsage{}, errors.New("no content in response")
 	}
 
-	totalTokens := anthropicResp.Usage.InputTokens + anthropicResp.Usage.OutputTokens
-	return anthropicResp.Content[0].Text, totalTokens, nil
+	usage = TokenUsage{
+		PromptTokens:     anthropicResp.Usage.InputTokens,
+		CompletionTokens: anthropicResp.Usage.OutputTokens,
+	}
+	if schema != nil {
+		for _, content := range anthropicResp.Content {
+			if content.Type == "tool_use" {
+				return string(content.Input), usage, nil
+			}
+		}
+	}
+	return anthropicResp.Content[0].Text, usage, nil
 }
 
 // Summarize generates a summary of the given text
@@ -130,7 +172,8 @@ func (p *AnthropicProvider) Summarize(ctx context.Context, text string, summaryT
 		return nil, errors.New("summary type is required")
 	}
 
-	systemInstructions, ok := summarySystemPrompts[cleanType]
+	prompts := promptsFromContext(ctx)
+	systemPrompt, ok := prompts.summary(cleanType)
 	if !ok {
 		return nil, fmt.Errorf("unsupported summary type: %s", summaryType)
 	}
@@ -139,17 +182,11 @@ func (p *AnthropicProvider) Summarize(ctx context.Context, text string, summaryT
 		return nil, errors.New("text to summarize is required")
 	}
 
-	systemPrompt := fmt.Sprintf(baseSystemPrompt, systemInstructions)
 	userPrompt := buildUserPrompt(cleanType, text)
 
-	raw, tokensUsed, err := p.complete(ctx, systemPrompt, userPrompt)
-	if err != nil {
-		return nil, translateAnthropicError(err)
-	}
-
-	payload, err := decodeSummaryPayload(raw)
+	payload, usage, err := completeStructured(ctx, p.complete, translateAnthropicError, systemPrompt, userPrompt, summaryOutput(ctx))
 	if err != nil {
-		return nil, fmt.Errorf("parse summary response: %w", err)
+		return nil, err
 	}
 
 	return &AISummary{
@@ -157,10 +194,13 @@ func (p *AnthropicProvider) Summarize(ctx context.Context, text string, summaryT
 			Text:      payload.Text,
 			KeyPoints: payload.KeyPoints,
 			Sections:  convertPayloadSections(payload.Sections),
+			Chapters:  convertPayloadChapters(payload.Chapters),
 		},
-		Model:      p.model,
-		TokensUsed: tokensUsed,
-		Type:       cleanType,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+		Type:          cleanType,
 	}, nil
 }
 
@@ -175,7 +215,8 @@ func (p *AnthropicProvider) Extract(ctx context.Context, text string, extraction
 		return nil, errors.New("extraction type is required")
 	}
 
-	systemPrompt, ok := extractionSystemPrompts[cleanType]
+	prompts := promptsFromContext(ctx)
+	systemPrompt, ok := prompts.extraction(cleanType)
 	if !ok {
 		return nil, fmt.Errorf("unsupported extraction type: %s", extractionType)
 	}
@@ -186,21 +227,18 @@ func (p *AnthropicProvider) Extract(ctx context.Context, text string, extraction
 
 	userPrompt := fmt.Sprintf("Extract %s from the following transcript:\n\n%s", cleanType, strings.TrimSpace(text))
 
-	raw, tokensUsed, err := p.complete(ctx, systemPrompt, userPrompt)
+	items, usage, err := completeStructured(ctx, p.complete, translateAnthropicError, systemPrompt, userPrompt, extractionOutput(cleanType))
 	if err != nil {
-		return nil, translateAnthropicError(err)
-	}
-
-	items, err := decodeExtractionPayload(raw)
-	if err != nil {
-		return nil, fmt.Errorf("parse extraction response: %w", err)
+		return nil, err
 	}
 
 	return &AIExtraction{
-		Items:      items,
-		Model:      p.model,
-		TokensUsed: tokensUsed,
-		Type:       cleanType,
+		Items:         items,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+		Type:          cleanType,
 	}, nil
 }
 
@@ -218,27 +256,100 @@ func (p *AnthropicProvider) Answer(ctx context.Context, text string, question st
 		return nil, errors.New("text is required")
 	}
 
-	systemPrompt := qaSystemPrompt
+	prompts := promptsFromContext(ctx)
+	systemPrompt := prompts.answer()
 	userPrompt := buildQAUserPrompt(question, text)
 
-	raw, tokensUsed, err := p.complete(ctx, systemPrompt, userPrompt)
+	answer, usage, err := completeStructured(ctx, p.complete, translateAnthropicError, systemPrompt, userPrompt, answerOutput)
 	if err != nil {
-		return nil, translateAnthropicError(err)
+		return nil, err
+	}
+
+	return &AIAnswer{
+		Question:      question,
+		Answer:        answer.Answer,
+		Confidence:    answer.Confidence,
+		Sources:       answer.Sources,
+		NotFound:      answer.NotFound,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+	}, nil
+}
+
+// LabelSpeakers detects speaker turns in transcript lines
+func (p *AnthropicProvider) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error) {
+	if p == nil {
+		return nil, errors.New("anthropic provider is nil")
 	}
 
-	answer, err := decodeAnswerPayload(raw)
+	if len(lines) == 0 {
+		return nil, errors.New("lines to label are required")
+	}
+
+	prompts := promptsFromContext(ctx)
+	turns, usage, err := completeStructured(ctx, p.complete, translateAnthropicError, prompts.speakers(), buildSpeakerUserPrompt(lines, knownSpeakers), speakerOutput(len(lines)))
 	if err != nil {
-		return nil, fmt.Errorf("parse answer response: %w", err)
+		return nil, err
 	}
 
-	return &AIAnswer{
-		Question:   question,
-		Answer:     answer.Answer,
-		Confidence: answer.Confidence,
-		Sources:    answer.Sources,
-		NotFound:   answer.NotFound,
-		Model:      p.model,
-		TokensUsed: tokensUsed,
+	return &AISpeakerTurns{
+		Turns:         turns,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+	}, nil
+}
+
+// Grade grades an output produced from the transcript with the default grading prompt
+func (p *AnthropicProvider) Grade(ctx context.Context, transcript, task, output string) (*AIGrade, error) {
+	if p == nil {
+		return nil, errors.New("anthropic provider is nil")
+	}
+
+	if strings.TrimSpace(transcript) == "" {
+		return nil, errors.New("transcript is required")
+	}
+
+	grade, usage, err := completeStructured(ctx, p.complete, translateAnthropicError, defaultPrompts.grade(), buildGradeUserPrompt(transcript, task, output), gradeOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIGrade{
+		Score:         grade.Score,
+		Reasoning:     grade.Reasoning,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: defaultPrompts.Version,
+	}, nil
+}
+
+// Punctuate restores punctuation and capitalization for caption lines
+func (p *AnthropicProvider) Punctuate(ctx context.Context, lines []string) (*AIPunctuation, error) {
+	if p == nil {
+		return nil, errors.New("anthropic provider is nil")
+	}
+
+	if len(lines) == 0 {
+		return nil, errors.New("lines to punctuate are required")
+	}
+
+	prompts := promptsFromContext(ctx)
+	punctuated, usage, err := completeStructured(ctx, p.complete, translateAnthropicError, prompts.punctuation(), buildPunctuationUserPrompt(lines), punctuationOutput(len(lines)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIPunctuation{
+		Lines:         punctuated,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
 	}, nil
 }
 
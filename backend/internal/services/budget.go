@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrAIBudgetExceeded is returned when a call would push spend past a configured budget. Unlike
+// ErrAIQuotaExceeded it does not fail over, because budgets span every provider.
+var ErrAIBudgetExceeded = errors.New("AI budget exceeded")
+
+// Budget scopes, periods and units reported by BudgetError.
+const (
+	BudgetScopeGlobal = "global"
+	BudgetScopeKey    = "key"
+
+	BudgetPeriodDaily   = "daily"
+	BudgetPeriodMonthly = "monthly"
+
+	BudgetUnitTokens = "tokens"
+	BudgetUnitUSD    = "usd"
+)
+
+// Budget caps AI spend per UTC calendar day and month. Zero values mean unlimited.
+type Budget struct {
+	DailyTokens   int64
+	MonthlyTokens int64
+	DailyUSD      float64
+	MonthlyUSD    float64
+}
+
+func (b Budget) enabled() bool {
+	return b.DailyTokens > 0 || b.MonthlyTokens > 0 || b.DailyUSD > 0 || b.MonthlyUSD > 0
+}
+
+// Spend is the tokens and cost used since the start of the current day and month.
+type Spend struct {
+	DailyTokens   int64
+	DailyUSD      float64
+	MonthlyTokens int64
+	MonthlyUSD    float64
+}
+
+func (s Spend) add(other Spend) Spend {
+	return Spend{
+		DailyTokens:   s.DailyTokens + other.DailyTokens,
+		DailyUSD:      s.DailyUSD + other.DailyUSD,
+		MonthlyTokens: s.MonthlyTokens + other.MonthlyTokens,
+		MonthlyUSD:    s.MonthlyUSD + other.MonthlyUSD,
+	}
+}
+
+func (s Spend) sub(other Spend) Spend {
+	return s.add(Spend{
+		DailyTokens:   -other.DailyTokens,
+		DailyUSD:      -other.DailyUSD,
+		MonthlyTokens: -other.MonthlyTokens,
+		MonthlyUSD:    -other.MonthlyUSD,
+	})
+}
+
+// SpendReader reads spend from the usage ledger. An empty clientKey means every caller.
+type SpendReader interface {
+	AISpend(ctx context.Context, clientKey string, dayStart, monthStart time.Time) (Spend, error)
+}
+
+// SpendReaderFunc adapts a function to SpendReader.
+type SpendReaderFunc func(ctx context.Context, clientKey string, dayStart, monthStart time.Time) (Spend, error)
+
+// AISpend calls f.
+func (f SpendReaderFunc) AISpend(ctx context.Context, clientKey string, dayStart, monthStart time.Time) (Spend, error) {
+	return f(ctx, clientKey, dayStart, monthStart)
+}
+
+// BudgetError describes the budget a call would exceed.
+type BudgetError struct {
+	Scope     string
+	Period    string
+	Unit      string
+	Limit     float64
+	Remaining float64
+	ResetsAt  time.Time
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("%v: %s %s %s budget (limit %g, remaining %g, resets %s)",
+		ErrAIBudgetExceeded, e.Scope, e.Period, e.Unit, e.Limit, e.Remaining, e.ResetsAt.Format(time.RFC3339))
+}
+
+func (e *BudgetError) Unwrap() error {
+	return ErrAIBudgetExceeded
+}
+
+// BudgetEnforcer rejects calls that would push global or per-client spend past a budget. Spend
+// is read from the usage ledger, so budgets hold across instances and reset at the start of
+// every UTC day and month. Calls still in flight on this instance count with their estimate.
+type BudgetEnforcer struct {
+	global Budget
+	perKey Budget
+	spend  SpendReader
+	prices PriceTable
+	now    func() time.Time
+
+	mu       sync.Mutex
+	inFlight map[string]*inFlightSpend
+}
+
+type inFlightSpend struct {
+	calls int
+	spend Spend
+}
+
+// NewBudgetEnforcer creates an enforcer. perKey applies to each client set with WithClientKey;
+// estimates are priced with prices.
+func NewBudgetEnforcer(global, perKey Budget, spend SpendReader, prices PriceTable) *BudgetEnforcer {
+	return &BudgetEnforcer{
+		global:   global,
+		perKey:   perKey,
+		spend:    spend,
+		prices:   prices,
+		now:      time.Now,
+		inFlight: make(map[string]*inFlightSpend),
+	}
+}
+
+// Guard wraps a provider so every call is checked against the budgets before it is made. Wrap
+// it around the rate limiter so rejected calls neither queue nor reach the ledger.
+func (e *BudgetEnforcer) Guard(provider NamedProvider) NamedProvider {
+	if e == nil || e.spend == nil || provider.Provider == nil || (!e.global.enabled() && !e.perKey.enabled()) {
+		return provider
+	}
+	provider.Provider = &budgetedProvider{next: provider.Provider, enforcer: e, model: provider.Model}
+	return provider
+}
+
+type budgetScope struct {
+	name   string
+	key    string
+	budget Budget
+}
+
+// reserve counts a call estimated at estimate as in flight and checks every applicable budget.
+// The returned release must be called once the call has been recorded in the ledger.
+func (e *BudgetEnforcer) reserve(ctx context.Context, estimate Spend) (func(), error) {
+	now := e.now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	scopes := make([]budgetScope, 0, 2)
+	if e.global.enabled() {
+		scopes = append(scopes, budgetScope{name: BudgetScopeGlobal, budget: e.global})
+	}
+	clientKey := ClientKeyFromContext(ctx)
+	if clientKey != "" && e.perKey.enabled() {
+		scopes = append(scopes, budgetScope{name: BudgetScopeKey, key: clientKey, budget: e.perKey})
+	}
+
+	// Global in-flight spend is kept under the empty key.
+	inFlightKeys := []string{""}
+	if clientKey != "" {
+		inFlightKeys = append(inFlightKeys, clientKey)
+	}
+	e.mu.Lock()
+	for _, key := range inFlightKeys {
+		pending, ok := e.inFlight[key]
+		if !ok {
+			pending = &inFlightSpend{}
+			e.inFlight[key] = pending
+		}
+		pending.calls++
+		pending.spend = pending.spend.add(estimate)
+	}
+	e.mu.Unlock()
+	release := func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		for _, key := range inFlightKeys {
+			pending := e.inFlight[key]
+			pending.calls--
+			pending.spend = pending.spend.sub(estimate)
+			if pending.calls == 0 {
+				delete(e.inFlight, key)
+			}
+		}
+	}
+
+	for _, scope := range scopes {
+		recorded, err := e.spend.AISpend(ctx, scope.key, dayStart, monthStart)
+		if err != nil {
+			if ctx.Err() != nil {
+				release()
+				return nil, ctx.Err()
+			}
+			slog.WarnContext(ctx, "AI budget not checked, spend unavailable", "scope", scope.name, "error", err)
+			continue
+		}
+
+		e.mu.Lock()
+		used := recorded.add(e.inFlight[scope.key].spend).sub(estimate)
+		e.mu.Unlock()
+
+		if err := scope.budget.check(scope.name, used, estimate, dayStart.AddDate(0, 0, 1), monthStart.AddDate(0, 1, 0)); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// check reports the first limit that used plus estimate would exceed. A limit already used up
+// rejects even calls estimated at zero, such as calls to unpriced models against a dollar cap.
+func (b Budget) check(scope string, used, estimate Spend, dayEnd, monthEnd time.Time) error {
+	limits := []struct {
+		period, unit          string
+		limit, used, estimate float64
+		resetsAt              time.Time
+	}{
+		{BudgetPeriodDaily, BudgetUnitTokens, float64(b.DailyTokens), float64(used.DailyTokens), float64(estimate.DailyTokens), dayEnd},
+		{BudgetPeriodDaily, BudgetUnitUSD, b.DailyUSD, used.DailyUSD, estimate.DailyUSD, dayEnd},
+		{BudgetPeriodMonthly, BudgetUnitTokens, float64(b.MonthlyTokens), float64(used.MonthlyTokens), float64(estimate.MonthlyTokens), monthEnd},
+		{BudgetPeriodMonthly, BudgetUnitUSD, b.MonthlyUSD, used.MonthlyUSD, estimate.MonthlyUSD, monthEnd},
+	}
+	for _, l := range limits {
+		if l.limit <= 0 {
+			continue
+		}
+		if l.used >= l.limit || l.used+l.estimate > l.limit {
+			return &BudgetError{
+				Scope:     scope,
+				Period:    l.period,
+				Unit:      l.unit,
+				Limit:     l.limit,
+				Remaining: math.Max(l.limit-l.used, 0),
+				ResetsAt:  l.resetsAt,
+			}
+		}
+	}
+	return nil
+}
+
+// budgetedProvider checks an AIProvider's calls against a BudgetEnforcer.
+type budgetedProvider struct {
+	next     AIProvider
+	enforcer *BudgetEnforcer
+	model    string
+}
+
+// Unwrap returns the budgeted provider.
+func (p *budgetedProvider) Unwrap() AIProvider {
+	return p.next
+}
+
+// budgetCall estimates a call from its prompt text; completion tokens are not known up front.
+func budgetCall[T any](ctx context.Context, p *budgetedProvider, estimatedTokens int, call func() (T, error)) (T, error) {
+	var zero T
+	cost := p.enforcer.prices.Cost(p.model, TokenUsage{PromptTokens: estimatedTokens})
+	release, err := p.enforcer.reserve(ctx, Spend{
+		DailyTokens:   int64(estimatedTokens),
+		DailyUSD:      cost,
+		MonthlyTokens: int64(estimatedTokens),
+		MonthlyUSD:    cost,
+	})
+	if err != nil {
+		return zero, err
+	}
+	defer release()
+	return call()
+}
+
+func (p *budgetedProvider) Summarize(ctx context.Context, text string, summaryType string) (*AISummary, error) {
+	return budgetCall(ctx, p, estimateTokens(text), func() (*AISummary, error) {
+		return p.next.Summarize(ctx, text, summaryType)
+	})
+}
+
+func (p *budgetedProvider) Extract(ctx context.Context, text string, extractionType string) (*AIExtraction, error) {
+	return budgetCall(ctx, p, estimateTokens(text), func() (*AIExtraction, error) {
+		return p.next.Extract(ctx, text, extractionType)
+	})
+}
+
+func (p *budgetedProvider) Translate(ctx context.Context, text string, targetLang string) (*AITranslation, error) {
+	return budgetCall(ctx, p, estimateTokens(text), func() (*AITranslation, error) {
+		return p.next.Translate(ctx, text, targetLang)
+	})
+}
+
+func (p *budgetedProvider) Answer(ctx context.Context, text string, question string) (*AIAnswer, error) {
+	return budgetCall(ctx, p, estimateTokens(text, question), func() (*AIAnswer, error) {
+		return p.next.Answer(ctx, text, question)
+	})
+}
+
+func (p *budgetedProvider) Punctuate(ctx context.Context, lines []string) (*AIPunctuation, error) {
+	return budgetCall(ctx, p, estimateTokens(lines...), func() (*AIPunctuation, error) {
+		return p.next.Punctuate(ctx, lines)
+	})
+}
+
+func (p *budgetedProvider) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error) {
+	return budgetCall(ctx, p, estimateTokens(lines...), func() (*AISpeakerTurns, error) {
+		return p.next.LabelSpeakers(ctx, lines, knownSpeakers)
+	})
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSpendReader struct {
+	mu         sync.Mutex
+	spend      map[string]Spend
+	err        error
+	dayStart   time.Time
+	monthStart time.Time
+}
+
+func (r *fakeSpendReader) AISpend(_ context.Context, clientKey string, dayStart, monthStart time.Time) (Spend, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dayStart, r.monthStart = dayStart, monthStart
+	return r.spend[clientKey], r.err
+}
+
+func newTestBudgetEnforcer(global, perKey Budget, reader SpendReader) *BudgetEnforcer {
+	enforcer := NewBudgetEnforcer(global, perKey, reader, DefaultPriceTable)
+	enforcer.now = func() time.Time { return time.Date(2025, 3, 14, 15, 30, 0, 0, time.UTC) }
+	return enforcer
+}
+
+func TestBudgetEnforcer_AllowsCallsWithinBudget(t *testing.T) {
+	reader := &fakeSpendReader{spend: map[string]Spend{"": {DailyTokens: 1000, MonthlyTokens: 5000}}}
+	enforcer := newTestBudgetEnforcer(Budget{DailyTokens: 10_000, MonthlyTokens: 100_000}, Budget{}, reader)
+	inner := &fakeProvider{model: "gpt-4"}
+	provider := enforcer.Guard(NamedProvider{Name: "openai", Model: "gpt-4", Provider: inner})
+
+	_, err := provider.Provider.Summarize(context.Background(), "short transcript", "brief")
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), reader.dayStart)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), reader.monthStart)
+	assert.Empty(t, enforcer.inFlight, "reservation released after the call")
+}
+
+func TestBudgetEnforcer_RejectsEstimateOverGlobalBudget(t *testing.T) {
+	reader := &fakeSpendReader{spend: map[string]Spend{"": {DailyTokens: 900, MonthlyTokens: 900}}}
+	enforcer := newTestBudgetEnforcer(Budget{DailyTokens: 1000}, Budget{}, reader)
+	inner := &fakeProvider{model: "gpt-4"}
+	provider := enforcer.Guard(NamedProvider{Name: "openai", Model: "gpt-4", Provider: inner})
+
+	// About 250 tokens estimated from 1000 characters.
+	_, err := provider.Provider.Summarize(context.Background(), string(make([]byte, 1000)), "brief")
+	require.ErrorIs(t, err, ErrAIBudgetExceeded)
+	assert.False(t, IsRetryableAIError(err), "budgets span providers, so no failover")
+	assert.Zero(t, inner.calls)
+
+	var budgetErr *BudgetError
+	require.True(t, errors.As(err, &budgetErr))
+	assert.Equal(t, BudgetScopeGlobal, budgetErr.Scope)
+	assert.Equal(t, BudgetPeriodDaily, budgetErr.Period)
+	assert.Equal(t, BudgetUnitTokens, budgetErr.Unit)
+	assert.Equal(t, 1000.0, budgetErr.Limit)
+	assert.Equal(t, 100.0, budgetErr.Remaining)
+	assert.Equal(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), budgetErr.ResetsAt)
+	assert.Empty(t, enforcer.inFlight)
+}
+
+func TestBudgetEnforcer_PerKeyDollarBudget(t *testing.T) {
+	reader := &fakeSpendReader{spend: map[string]Spend{
+		"":      {MonthlyUSD: 50},
+		"key-a": {MonthlyUSD: 10},
+		"key-b": {MonthlyUSD: 1},
+	}}
+	enforcer := newTestBudgetEnforcer(Budget{}, Budget{MonthlyUSD: 10}, reader)
+	provider := enforcer.Guard(NamedProvider{Name: "openai", Model: "gpt-4", Provider: &fakeProvider{model: "gpt-4"}})
+
+	_, err := provider.Provider.Answer(WithClientKey(context.Background(), "key-a"), "text", "question?")
+	var budgetErr *BudgetError
+	require.True(t, errors.As(err, &budgetErr))
+	assert.Equal(t, BudgetScopeKey, budgetErr.Scope)
+	assert.Equal(t, BudgetPeriodMonthly, budgetErr.Period)
+	assert.Equal(t, BudgetUnitUSD, budgetErr.Unit)
+	assert.Zero(t, budgetErr.Remaining)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), budgetErr.ResetsAt)
+
+	_, err = provider.Provider.Answer(WithClientKey(context.Background(), "key-b"), "text", "question?")
+	assert.NoError(t, err)
+
+	// Without a client key only global budgets apply.
+	_, err = provider.Provider.Answer(context.Background(), "text", "question?")
+	assert.NoError(t, err)
+}
+
+func TestBudgetEnforcer_CountsInFlightCalls(t *testing.T) {
+	reader := &fakeSpendReader{}
+	enforcer := newTestBudgetEnforcer(Budget{DailyTokens: 400}, Budget{}, reader)
+
+	release, err := enforcer.reserve(context.Background(), Spend{DailyTokens: 300, MonthlyTokens: 300})
+	require.NoError(t, err)
+
+	_, err = enforcer.reserve(context.Background(), Spend{DailyTokens: 300, MonthlyTokens: 300})
+	require.ErrorIs(t, err, ErrAIBudgetExceeded)
+
+	release()
+	second, err := enforcer.reserve(context.Background(), Spend{DailyTokens: 300, MonthlyTokens: 300})
+	require.NoError(t, err)
+	second()
+	assert.Empty(t, enforcer.inFlight)
+}
+
+func TestBudgetEnforcer_SpendUnavailableAllowsCall(t *testing.T) {
+	reader := &fakeSpendReader{err: errors.New("database down")}
+	enforcer := newTestBudgetEnforcer(Budget{DailyTokens: 1}, Budget{}, reader)
+	provider := enforcer.Guard(NamedProvider{Name: "openai", Model: "gpt-4", Provider: &fakeProvider{model: "gpt-4"}})
+
+	_, err := provider.Provider.Extract(context.Background(), "text", "code")
+	assert.NoError(t, err)
+}
+
+func TestBudgetEnforcer_GuardWithoutBudgetsIsNoop(t *testing.T) {
+	inner := &fakeProvider{}
+	enforcer := NewBudgetEnforcer(Budget{}, Budget{}, &fakeSpendReader{}, DefaultPriceTable)
+	provider := enforcer.Guard(NamedProvider{Name: "openai", Provider: inner})
+	assert.Same(t, inner, provider.Provider)
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidChapters indicates the provider returned chapters that cannot be mapped onto the transcript timeline.
+var ErrInvalidChapters = errors.New("invalid chapters")
+
+// minChapterLength mirrors YouTube's requirement that description chapters are at least 10 seconds long.
+const minChapterLength = 10 * time.Second
+
+// AnchorChapters snaps provider chapters onto real segment start times and validates the result.
+// boundaries are the start offsets (in milliseconds) of the transcript segments and duration is the
+// video length; when duration is zero the last boundary is treated as the end of the video. The returned
+// chapters are strictly increasing, start at 0, lie inside the video and carry derived end times.
+func AnchorChapters(chapters []SummaryChapter, boundaries []int64, duration time.Duration) ([]SummaryChapter, error) {
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("%w: no chapters returned", ErrInvalidChapters)
+	}
+	if len(boundaries) == 0 {
+		return nil, fmt.Errorf("%w: transcript has no segments", ErrInvalidChapters)
+	}
+
+	sorted := append([]int64(nil), boundaries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	durationMs := duration.Milliseconds()
+	if durationMs <= 0 {
+		durationMs = sorted[len(sorted)-1]
+	}
+
+	anchored := make([]SummaryChapter, 0, len(chapters))
+	for i, chapter := range chapters {
+		title := strings.TrimSpace(chapter.Title)
+		if title == "" {
+			continue
+		}
+		if chapter.StartMs < 0 || chapter.StartMs > durationMs {
+			return nil, fmt.Errorf("%w: chapter %d (%q) starts at %dms outside the video (%dms)", ErrInvalidChapters, i+1, title, chapter.StartMs, durationMs)
+		}
+
+		start := nearestBoundary(sorted, chapter.StartMs)
+		if len(anchored) == 0 {
+			// YouTube only recognises chapter lists that begin at 00:00.
+			start = 0
+		} else {
+			previous := anchored[len(anchored)-1].StartMs
+			if start < previous {
+				return nil, fmt.Errorf("%w: chapter %d (%q) starts before the previous chapter", ErrInvalidChapters, i+1, title)
+			}
+			if time.Duration(start-previous)*time.Millisecond < minChapterLength {
+				// Too close to the previous chapter; fold it into that one.
+				continue
+			}
+		}
+		if start >= durationMs && len(anchored) > 0 {
+			continue
+		}
+
+		anchored = append(anchored, SummaryChapter{Title: title, StartMs: start})
+	}
+
+	if len(anchored) == 0 {
+		return nil, fmt.Errorf("%w: no usable chapters returned", ErrInvalidChapters)
+	}
+
+	for i := range anchored {
+		if i+1 < len(anchored) {
+			anchored[i].EndMs = anchored[i+1].StartMs
+		} else {
+			anchored[i].EndMs = durationMs
+		}
+	}
+
+	return anchored, nil
+}
+
+// nearestBoundary returns the boundary closest to target, preferring the earlier one on ties.
+func nearestBoundary(sorted []int64, target int64) int64 {
+	idx := sort.Search(len(sorted), func(i int) bool { return sorted[i] >= target })
+	switch {
+	case idx == 0:
+		return sorted[0]
+	case idx == len(sorted):
+		return sorted[len(sorted)-1]
+	}
+
+	before, after := sorted[idx-1], sorted[idx]
+	if target-before <= after-target {
+		return before
+	}
+	return after
+}
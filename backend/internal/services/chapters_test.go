@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnchorChapters_SnapsToSegmentBoundaries(t *testing.T) {
+	boundaries := []int64{0, 4000, 31000, 62000, 95000}
+	chapters := []SummaryChapter{
+		{Title: "Intro", StartMs: 1200},
+		{Title: "Setup", StartMs: 30000},
+		{Title: "Deploy", StartMs: 64000},
+	}
+
+	anchored, err := AnchorChapters(chapters, boundaries, 2*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, anchored, 3)
+
+	assert.Equal(t, SummaryChapter{Title: "Intro", StartMs: 0, EndMs: 31000}, anchored[0])
+	assert.Equal(t, SummaryChapter{Title: "Setup", StartMs: 31000, EndMs: 62000}, anchored[1])
+	assert.Equal(t, SummaryChapter{Title: "Deploy", StartMs: 62000, EndMs: 120000}, anchored[2])
+}
+
+func TestAnchorChapters_FoldsChaptersShorterThanMinimum(t *testing.T) {
+	boundaries := []int64{0, 5000, 40000, 70000}
+	chapters := []SummaryChapter{
+		{Title: "Intro", StartMs: 0},
+		{Title: "Too soon", StartMs: 5000},
+		{Title: "Main", StartMs: 40000},
+	}
+
+	anchored, err := AnchorChapters(chapters, boundaries, 0)
+	require.NoError(t, err)
+	require.Len(t, anchored, 2)
+	assert.Equal(t, "Intro", anchored[0].Title)
+	assert.Equal(t, "Main", anchored[1].Title)
+	assert.Equal(t, int64(70000), anchored[1].EndMs, "falls back to last boundary without a duration")
+}
+
+func TestAnchorChapters_Invalid(t *testing.T) {
+	boundaries := []int64{0, 20000, 40000}
+
+	tests := []struct {
+		name     string
+		chapters []SummaryChapter
+		duration time.Duration
+	}{
+		{name: "no chapters", chapters: nil, duration: time.Minute},
+		{name: "not monotonic", chapters: []SummaryChapter{{Title: "A", StartMs: 0}, {Title: "B", StartMs: 40000}, {Title: "C", StartMs: 20000}}, duration: time.Minute},
+		{name: "beyond duration", chapters: []SummaryChapter{{Title: "A", StartMs: 0}, {Title: "B", StartMs: 90000}}, duration: time.Minute},
+		{name: "negative start", chapters: []SummaryChapter{{Title: "A", StartMs: -5}}, duration: time.Minute},
+		{name: "untitled", chapters: []SummaryChapter{{Title: "  ", StartMs: 0}}, duration: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := AnchorChapters(tt.chapters, boundaries, tt.duration)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidChapters))
+		})
+	}
+}
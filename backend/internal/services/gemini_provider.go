@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strings"
 )
 
@@ -37,13 +36,13 @@ func NewGeminiProvider(apiKey, model string, maxTokens int, temperature float64)
 		model:       model,
 		maxTokens:   maxTokens,
 		temperature: temperature,
-		httpClient:  &http.Client{},
+		httpClient:  newRetryingHTTPClient("google", DefaultRetryPolicy),
 	}, nil
 }
 
 type geminiRequest struct {
-	Contents         []geminiContent         `json:"contents"`
-	GenerationConfig geminiGenerationConfig  `json:"generationConfig"`
+	Contents          []geminiContent          `json:"contents"`
+	GenerationConfig  geminiGenerationConfig   `json:"generationConfig"`
 	SystemInstruction *geminiSystemInstruction `json:"systemInstruction,omitempty"`
 }
 
@@ -61,12 +60,14 @@ type geminiSystemInstruction struct {
 }
 
 type geminiGenerationConfig struct {
-	Temperature     float64 `json:"temperature"`
-	MaxOutputTokens int     `json:"maxOutputTokens"`
+	Temperature      float64      `json:"temperature"`
+	MaxOutputTokens  int          `json:"maxOutputTokens"`
+	ResponseMimeType string       `json:"responseMimeType,omitempty"`
+	ResponseSchema   schemaObject `json:"responseSchema,omitempty"`
 }
 
 type geminiResponse struct {
-	Candidates []geminiCandidate `json:"candidates"`
+	Candidates    []geminiCandidate   `json:"candidates"`
 	UsageMetadata geminiUsageMetadata `json:"usageMetadata"`
 }
 
@@ -80,7 +81,19 @@ type geminiUsageMetadata struct {
 	TotalTokenCount      int `json:"totalTokenCount"`
 }
 
-func (p *GeminiProvider) complete(ctx context.Context, systemPrompt, userPrompt string) (string, int, error) {
+// Ping lists one model, which checks the endpoint and the key without generating content.
+func (p *GeminiProvider) Ping(ctx context.Context) error {
+	return pingHTTP(ctx, p.httpClient, "https://generativelanguage.googleapis.com/v1beta/models?pageSize=1", http.Header{
+		"X-Goog-Api-Key": {p.apiKey},
+	})
+}
+
+// complete calls generateContent. With a schema, Gemini's controlled generation constrains the
+// reply to JSON following it.
+func (p *GeminiProvider) complete(ctx context.Context, systemPrompt, userPrompt string, schema *outputSchema) (_ string, usage TokenUsage, err error) {
+	ctx, span := startCompletionSpan(ctx, "gcp.gemini", p.model, schema)
+	defer func() { endCompletionSpan(span, usage, err) }()
+
 	reqBody := geminiRequest{
 		Contents: []geminiContent{
 			{
@@ -95,6 +108,10 @@ func (p *GeminiProvider) complete(ctx context.Context, systemPrompt, userPrompt
 			MaxOutputTokens: p.maxTokens,
 		},
 	}
+	if schema != nil {
+		reqBody.GenerationConfig.ResponseMimeType = "application/json"
+		reqBody.GenerationConfig.ResponseSchema = schema.render(schemaDialectGemini)
+	}
 
 	// Add system instruction if provided
 	if systemPrompt != "" {
@@ -107,48 +124,56 @@ func (p *GeminiProvider) complete(ctx context.Context, systemPrompt, userPrompt
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", 0, fmt.Errorf("marshal request: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", p.model, p.apiKey)
+	// The key goes in a header rather than the query string, where it would end up in error
+	// messages and proxy logs along with the URL.
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent", p.model)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", 0, fmt.Errorf("create request: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", p.apiKey)
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return "", 0, fmt.Errorf("gemini completion: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("gemini completion: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", 0, fmt.Errorf("read response: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", 0, fmt.Errorf("gemini API error (status %d): %s", resp.StatusCode, string(body))
+		return "", TokenUsage{}, fmt.Errorf("gemini API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var geminiResp geminiResponse
 	if err := json.Unmarshal(body, &geminiResp); err != nil {
-		return "", 0, fmt.Errorf("unmarshal response: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("unmarshal response: %w", err)
 	}
 
 	if len(geminiResp.Candidates) == 0 {
-		return "", 0, errors.New("no candidates in response")
+		return "", TokenUsage{}, errors.New("no candidates in response")
 	}
 
 	if len(geminiResp.Candidates[0].Content.Parts) == 0 {
-		return "", 0, errors.New("no content in response")
+		return "", TokenUsage{}, errors.New("no content in response")
 	}
 
-	totalTokens := geminiResp.UsageMetadata.TotalTokenCount
-	return geminiResp.Candidates[0].Content.Parts[0].Text, totalTokens, nil
+	// TotalTokenCount also covers thinking tokens, which are billed as output.
+	metadata := geminiResp.UsageMetadata
+	usage = TokenUsage{PromptTokens: metadata.PromptTokenCount, CompletionTokens: metadata.CandidatesTokenCount}
+	if metadata.TotalTokenCount > usage.Total() {
+		usage.CompletionTokens = metadata.TotalTokenCount - metadata.PromptTokenCount
+	}
+	return geminiResp.Candidates[0].Content.Parts[0].Text, usage, nil
 }
 
 // Summarize generates a summary of the given text
@@ -162,7 +187,8 @@ func (p *GeminiProvider) Summarize(ctx context.Context, text string, summaryType
 		return nil, errors.New("summary type is required")
 	}
 
-	systemInstructions, ok := summarySystemPrompts[cleanType]
+	prompts := promptsFromContext(ctx)
+	systemPrompt, ok := prompts.summary(cleanType)
 	if !ok {
 		return nil, fmt.Errorf("unsupported summary type: %s", summaryType)
 	}
@@ -171,17 +197,11 @@ func (p *GeminiProvider) Summarize(ctx context.Context, text string, summaryType
 		return nil, errors.New("text to summarize is required")
 	}
 
-	systemPrompt := fmt.Sprintf(baseSystemPrompt, systemInstructions)
 	userPrompt := buildUserPrompt(cleanType, text)
 
-	raw, tokensUsed, err := p.complete(ctx, systemPrompt, userPrompt)
-	if err != nil {
-		return nil, translateGeminiError(err)
-	}
-
-	payload, err := decodeSummaryPayload(raw)
+	payload, usage, err := completeStructured(ctx, p.complete, translateGeminiError, systemPrompt, userPrompt, summaryOutput(ctx))
 	if err != nil {
-		return nil, fmt.Errorf("parse summary response: %w", err)
+		return nil, err
 	}
 
 	return &AISummary{
@@ -189,10 +209,13 @@ func (p *GeminiProvider) Summarize(ctx context.Context, text string, summaryType
 			Text:      payload.Text,
 			KeyPoints: payload.KeyPoints,
 			Sections:  convertPayloadSections(payload.Sections),
+			Chapters:  convertPayloadChapters(payload.Chapters),
 		},
-		Model:      p.model,
-		TokensUsed: tokensUsed,
-		Type:       cleanType,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+		Type:          cleanType,
 	}, nil
 }
 
@@ -207,7 +230,8 @@ func (p *GeminiProvider) Extract(ctx context.Context, text string, extractionTyp
 		return nil, errors.New("extraction type is required")
 	}
 
-	systemPrompt, ok := extractionSystemPrompts[cleanType]
+	prompts := promptsFromContext(ctx)
+	systemPrompt, ok := prompts.extraction(cleanType)
 	if !ok {
 		return nil, fmt.Errorf("unsupported extraction type: %s", extractionType)
 	}
@@ -218,22 +242,18 @@ func (p *GeminiProvider) Extract(ctx context.Context, text string, extractionTyp
 
 	userPrompt := fmt.Sprintf("Extract %s from the following transcript:\n\n%s", cleanType, strings.TrimSpace(text))
 
-	raw, tokensUsed, err := p.complete(ctx, systemPrompt, userPrompt)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "DEBUG Gemini Extract - complete() error: %v\n", err)
-		return nil, translateGeminiError(err)
-	}
-
-	items, err := decodeExtractionPayload(raw)
+	items, usage, err := completeStructured(ctx, p.complete, translateGeminiError, systemPrompt, userPrompt, extractionOutput(cleanType))
 	if err != nil {
-		return nil, fmt.Errorf("parse extraction response: %w", err)
+		return nil, err
 	}
 
 	return &AIExtraction{
-		Items:      items,
-		Model:      p.model,
-		TokensUsed: tokensUsed,
-		Type:       cleanType,
+		Items:         items,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+		Type:          cleanType,
 	}, nil
 }
 
@@ -251,27 +271,100 @@ func (p *GeminiProvider) Answer(ctx context.Context, text string, question strin
 		return nil, errors.New("text is required")
 	}
 
-	systemPrompt := qaSystemPrompt
+	prompts := promptsFromContext(ctx)
+	systemPrompt := prompts.answer()
 	userPrompt := buildQAUserPrompt(question, text)
 
-	raw, tokensUsed, err := p.complete(ctx, systemPrompt, userPrompt)
+	answer, usage, err := completeStructured(ctx, p.complete, translateGeminiError, systemPrompt, userPrompt, answerOutput)
 	if err != nil {
-		return nil, translateGeminiError(err)
+		return nil, err
 	}
 
-	answer, err := decodeAnswerPayload(raw)
+	return &AIAnswer{
+		Question:      question,
+		Answer:        answer.Answer,
+		Confidence:    answer.Confidence,
+		Sources:       answer.Sources,
+		NotFound:      answer.NotFound,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+	}, nil
+}
+
+// LabelSpeakers detects speaker turns in transcript lines
+func (p *GeminiProvider) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error) {
+	if p == nil {
+		return nil, errors.New("gemini provider is nil")
+	}
+
+	if len(lines) == 0 {
+		return nil, errors.New("lines to label are required")
+	}
+
+	prompts := promptsFromContext(ctx)
+	turns, usage, err := completeStructured(ctx, p.complete, translateGeminiError, prompts.speakers(), buildSpeakerUserPrompt(lines, knownSpeakers), speakerOutput(len(lines)))
 	if err != nil {
-		return nil, fmt.Errorf("parse answer response: %w", err)
+		return nil, err
 	}
 
-	return &AIAnswer{
-		Question:   question,
-		Answer:     answer.Answer,
-		Confidence: answer.Confidence,
-		Sources:    answer.Sources,
-		NotFound:   answer.NotFound,
-		Model:      p.model,
-		TokensUsed: tokensUsed,
+	return &AISpeakerTurns{
+		Turns:         turns,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+	}, nil
+}
+
+// Grade grades an output produced from the transcript with the default grading prompt
+func (p *GeminiProvider) Grade(ctx context.Context, transcript, task, output string) (*AIGrade, error) {
+	if p == nil {
+		return nil, errors.New("gemini provider is nil")
+	}
+
+	if strings.TrimSpace(transcript) == "" {
+		return nil, errors.New("transcript is required")
+	}
+
+	grade, usage, err := completeStructured(ctx, p.complete, translateGeminiError, defaultPrompts.grade(), buildGradeUserPrompt(transcript, task, output), gradeOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIGrade{
+		Score:         grade.Score,
+		Reasoning:     grade.Reasoning,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: defaultPrompts.Version,
+	}, nil
+}
+
+// Punctuate restores punctuation and capitalization for caption lines
+func (p *GeminiProvider) Punctuate(ctx context.Context, lines []string) (*AIPunctuation, error) {
+	if p == nil {
+		return nil, errors.New("gemini provider is nil")
+	}
+
+	if len(lines) == 0 {
+		return nil, errors.New("lines to punctuate are required")
+	}
+
+	prompts := promptsFromContext(ctx)
+	punctuated, usage, err := completeStructured(ctx, p.complete, translateGeminiError, prompts.punctuation(), buildPunctuationUserPrompt(lines), punctuationOutput(len(lines)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIPunctuation{
+		Lines:         punctuated,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
 	}, nil
 }
 
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Pinger is implemented by providers that can check they are reachable without generating
+// anything, so readiness probes cost no tokens.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingProvider pings provider, looking through the metering, rate limiting and budget
+// wrappers. Providers that cannot be pinged count as reachable.
+func PingProvider(ctx context.Context, provider AIProvider) error {
+	for provider != nil {
+		if pinger, ok := provider.(Pinger); ok {
+			return pinger.Ping(ctx)
+		}
+		wrapper, ok := provider.(interface{ Unwrap() AIProvider })
+		if !ok {
+			return nil
+		}
+		provider = wrapper.Unwrap()
+	}
+	return nil
+}
+
+// Ping checks that the first provider of the default chain is reachable.
+func (s *AIService) Ping(ctx context.Context) error {
+	if s == nil || len(s.chain) == 0 {
+		return ErrAIProviderNotConfigured
+	}
+	if err := PingProvider(ctx, s.chain[0].Provider); err != nil {
+		return fmt.Errorf("%s: %w", s.chain[0], err)
+	}
+	return nil
+}
+
+// pingHTTP sends a GET to url and expects a 2xx answer.
+func pingHTTP(ctx context.Context, client *http.Client, url string, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// youtubePingURL answers 204 without content; it is what browsers use to detect connectivity.
+var youtubePingURL = "https://www.youtube.com/generate_204"
+
+// Ping checks that YouTube answers.
+func (s *YouTubeService) Ping(ctx context.Context) error {
+	if err := pingHTTP(ctx, s.httpClient(), youtubePingURL, nil); err != nil {
+		return fmt.Errorf("youtube: %w", err)
+	}
+	s.lastSuccess.Store(time.Now().UnixNano())
+	return nil
+}
+
+// LastSuccess is when YouTube last answered a fetch or a ping, or the zero time if it has not.
+// Answers about the video itself, such as "not found", count: YouTube was reachable.
+func (s *YouTubeService) LastSuccess() time.Time {
+	nanos := s.lastSuccess.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+func (s *YouTubeService) httpClient() *http.Client {
+	if s.client != nil && s.client.HTTPClient != nil {
+		return s.client.HTTPClient
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pingingProvider is a fakeProvider that can be pinged.
+type pingingProvider struct {
+	fakeProvider
+	pingErr error
+	pings   int
+}
+
+func (p *pingingProvider) Ping(context.Context) error {
+	p.pings++
+	return p.pingErr
+}
+
+func TestAIService_PingLooksThroughWrappers(t *testing.T) {
+	inner := &pingingProvider{pingErr: errors.New("status 401")}
+	provider := NamedProvider{Name: "anthropic", Model: "claude-3-5-sonnet", Provider: inner}
+	provider = NewUsageMeter(&recordedUsage{}, DefaultPriceTable).Meter(provider)
+	provider = NewRateLimiter(RateLimit{RequestsPerMinute: 1}, nil, time.Second).Limit(provider)
+
+	service := NewAIServiceWithChain([]NamedProvider{provider})
+	for range 3 {
+		err := service.Ping(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, "anthropic:claude-3-5-sonnet: status 401", err.Error())
+	}
+	assert.Equal(t, 3, inner.pings, "pings bypass the rate limiter")
+	assert.Zero(t, inner.calls)
+
+	assert.NoError(t, PingProvider(context.Background(), &fakeProvider{}), "providers without Ping count as reachable")
+	assert.ErrorIs(t, NewAIServiceWithChain(nil).Ping(context.Background()), ErrAIProviderNotConfigured)
+}
+
+func TestYouTubeService_PingAndLastSuccess(t *testing.T) {
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	previous := youtubePingURL
+	youtubePingURL = server.URL
+	defer func() { youtubePingURL = previous }()
+
+	service := NewYouTubeService()
+	assert.True(t, service.LastSuccess().IsZero())
+
+	require.NoError(t, service.Ping(context.Background()))
+	pinged := service.LastSuccess()
+	assert.WithinDuration(t, time.Now(), pinged, time.Second)
+
+	status = http.StatusServiceUnavailable
+	assert.EqualError(t, service.Ping(context.Background()), "youtube: status 503")
+	assert.Equal(t, pinged, service.LastSuccess())
+}
+
+func TestYouTubeService_RecordFetchNotesReachability(t *testing.T) {
+	service := NewYouTubeService()
+
+	service.recordFetch("metadata", errors.New("dial tcp: connection refused"))
+	assert.True(t, service.LastSuccess().IsZero())
+	service.recordFetch("metadata", ErrRateLimited)
+	assert.True(t, service.LastSuccess().IsZero())
+
+	service.recordFetch("metadata", ErrVideoNotFound)
+	assert.False(t, service.LastSuccess().IsZero(), "YouTube answered, even if the video is missing")
+}
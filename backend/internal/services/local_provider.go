@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// DefaultLocalBaseURL is Ollama's OpenAI-compatible endpoint on its default port.
+const DefaultLocalBaseURL = "http://localhost:11434/v1"
+
+// LocalProvider implements the AIProvider interface against a self-hosted OpenAI-compatible
+// server such as Ollama, the llama.cpp server or vLLM, so transcripts never leave the network.
+// It shares the OpenAI provider's prompts, request shape and decoders. Support for response
+// formats varies between servers, so structured replies rely on the prompt and the repair retry.
+type LocalProvider struct {
+	*OpenAIProvider
+	baseURL string
+}
+
+// NewLocalProvider creates a provider for the OpenAI-compatible API at baseURL, e.g.
+// "http://localhost:11434/v1". apiKey is optional; Ollama ignores it while llama.cpp and vLLM
+// can be started to require one.
+func NewLocalProvider(baseURL, apiKey, model string, maxTokens int, temperature float64) (*LocalProvider, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return nil, errors.New("local AI base URL is required")
+	}
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid local AI base URL %q: expected http(s)://host[:port]/path", baseURL)
+	}
+	if strings.TrimSpace(model) == "" {
+		return nil, errors.New("local AI model is required")
+	}
+
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.BaseURL = baseURL
+	clientConfig.HTTPClient = newRetryingHTTPClient("local", DefaultRetryPolicy)
+
+	return &LocalProvider{
+		OpenAIProvider: &OpenAIProvider{
+			client:      openai.NewClientWithConfig(clientConfig),
+			model:       model,
+			maxTokens:   maxTokens,
+			temperature: float32(temperature),
+			format:      openAIFormatPrompt,
+		},
+		baseURL: baseURL,
+	}, nil
+}
+
+// BaseURL returns the endpoint the provider talks to.
+func (p *LocalProvider) BaseURL() string {
+	return p.baseURL
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// localChatServer stands in for an OpenAI-compatible server and answers every chat completion
+// with content.
+func localChatServer(t *testing.T, content string) (*httptest.Server, *openai.ChatCompletionRequest, *http.Header) {
+	t.Helper()
+
+	var received openai.ChatCompletionRequest
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		header = r.Header.Clone()
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Model: received.Model,
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content}},
+			},
+			Usage: openai.Usage{PromptTokens: 120, CompletionTokens: 30, TotalTokens: 150},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &received, &header
+}
+
+func TestNewLocalProvider(t *testing.T) {
+	_, err := NewLocalProvider("", "", "llama3.1", 1000, 0.5)
+	assert.Error(t, err)
+
+	_, err = NewLocalProvider("localhost:11434", "", "llama3.1", 1000, 0.5)
+	assert.Error(t, err)
+
+	_, err = NewLocalProvider(DefaultLocalBaseURL, "", "", 1000, 0.5)
+	assert.Error(t, err)
+
+	provider, err := NewLocalProvider("http://gpu-box:8000/v1/", "", "qwen2.5", 1000, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, "http://gpu-box:8000/v1", provider.BaseURL())
+}
+
+func TestLocalProvider_Summarize(t *testing.T) {
+	server, received, header := localChatServer(t, "```json\n{\"text\": \"A local summary.\", \"key_points\": [\"Runs on-prem\"]}\n```")
+
+	provider, err := NewLocalProvider(server.URL+"/v1", "", "llama3.1", 800, 0.2)
+	require.NoError(t, err)
+
+	summary, err := provider.Summarize(context.Background(), "Transcript text", "brief")
+	require.NoError(t, err)
+
+	assert.Equal(t, "A local summary.", summary.Content.Text)
+	assert.Equal(t, []string{"Runs on-prem"}, summary.Content.KeyPoints)
+	assert.Equal(t, "llama3.1", summary.Model)
+	assert.Equal(t, TokenUsage{PromptTokens: 120, CompletionTokens: 30}, summary.Usage)
+	assert.Equal(t, 150, summary.TokensUsed)
+
+	assert.Equal(t, "llama3.1", received.Model)
+	assert.Equal(t, 800, received.MaxTokens)
+	require.Len(t, received.Messages, 2)
+	assert.Equal(t, openai.ChatMessageRoleSystem, received.Messages[0].Role)
+	assert.Contains(t, received.Messages[1].Content, "Transcript text")
+	assert.Empty(t, header.Get("Authorization"), "no key configured, none sent")
+}
+
+func TestLocalProvider_SendsAPIKey(t *testing.T) {
+	server, _, header := localChatServer(t, `{"answer": "Yes.", "confidence": "high", "sources": []}`)
+
+	provider, err := NewLocalProvider(server.URL+"/v1", "vllm-secret", "mistral", 800, 0.2)
+	require.NoError(t, err)
+
+	answer, err := provider.Answer(context.Background(), "Transcript text", "Is it local?")
+	require.NoError(t, err)
+	assert.Equal(t, "Yes.", answer.Answer)
+	assert.Equal(t, "Bearer vllm-secret", header.Get("Authorization"))
+}
+
+func TestLocalProvider_ClientErrorIsNotRetryable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model is loading", http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+
+	provider, err := NewLocalProvider(server.URL+"/v1", "", "llama3.1", 800, 0.2)
+	require.NoError(t, err)
+
+	_, err = provider.Summarize(context.Background(), "Transcript text", "brief")
+	require.Error(t, err)
+	assert.False(t, IsRetryableAIError(err), "a plain 400 is not retryable")
+}
+
+func TestTranslateOpenAIError_SelfHostedFailures(t *testing.T) {
+	unavailable := &openai.RequestError{HTTPStatusCode: http.StatusServiceUnavailable, Err: errors.New("upstream down")}
+	assert.ErrorIs(t, translateOpenAIError(unavailable), ErrAIServiceUnavailable)
+
+	busy := &openai.RequestError{HTTPStatusCode: http.StatusTooManyRequests, Err: errors.New("busy")}
+	assert.ErrorIs(t, translateOpenAIError(busy), ErrAIRateLimited)
+
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")}
+	assert.ErrorIs(t, translateOpenAIError(refused), ErrAIServiceUnavailable,
+		"a local server that is not running lets the chain fall back")
+
+	timedOut := &net.OpError{Op: "dial", Net: "tcp", Err: context.DeadlineExceeded}
+	assert.NotErrorIs(t, translateOpenAIError(timedOut), ErrAIServiceUnavailable)
+}
@@ -0,0 +1,601 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// Failure modes a MockProvider can inject.
+const (
+	MockFailureRateLimit     = "rate_limit"
+	MockFailureQuota         = "quota"
+	MockFailureUnavailable   = "unavailable"
+	MockFailureMalformedJSON = "malformed_json"
+)
+
+// DefaultMockModel is reported by a MockProvider configured without a model.
+const DefaultMockModel = "mock-1"
+
+// IsMockFailure reports whether mode is a supported failure mode.
+func IsMockFailure(mode string) bool {
+	switch mode {
+	case MockFailureRateLimit, MockFailureQuota, MockFailureUnavailable, MockFailureMalformedJSON:
+		return true
+	default:
+		return false
+	}
+}
+
+// MockOptions configures a MockProvider.
+type MockOptions struct {
+	Model string
+	// Latency is added to every call, bounded by the call's context.
+	Latency time.Duration
+	// Failure is one of the MockFailure* modes, or empty to always succeed.
+	Failure string
+	// FailEvery makes only every Nth call fail; 0 or 1 fails every call.
+	FailEvery int
+}
+
+// MockProvider implements the AIProvider interface without any network calls. Responses are
+// derived deterministically from the input text (leading sentences, detected commands and
+// code, keyword overlap with the question) and run through the same decoders as real provider
+// output, so the whole stack can be exercised offline.
+type MockProvider struct {
+	opts  MockOptions
+	calls atomic.Int64
+}
+
+// NewMockProvider creates a mock provider.
+func NewMockProvider(opts MockOptions) (*MockProvider, error) {
+	if opts.Model == "" {
+		opts.Model = DefaultMockModel
+	}
+	if opts.Failure != "" && !IsMockFailure(opts.Failure) {
+		return nil, fmt.Errorf("unknown mock failure mode %q", opts.Failure)
+	}
+	if opts.Latency < 0 || opts.FailEvery < 0 {
+		return nil, errors.New("mock latency and failure interval must not be negative")
+	}
+	return &MockProvider{opts: opts}, nil
+}
+
+// Ping always succeeds; the mock provider has nothing to reach.
+func (p *MockProvider) Ping(context.Context) error {
+	return nil
+}
+
+// Model returns the model name reported in results.
+func (p *MockProvider) Model() string {
+	return p.opts.Model
+}
+
+// respond waits for the configured latency, injects the configured failure and otherwise
+// returns payload encoded as JSON together with an estimated token usage.
+func (p *MockProvider) respond(ctx context.Context, prompt string, payload any) (string, TokenUsage, error) {
+	if p.opts.Latency > 0 {
+		timer := time.NewTimer(p.opts.Latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return "", TokenUsage{}, ctx.Err()
+		}
+	}
+
+	call := p.calls.Add(1)
+	failing := p.opts.Failure != "" && (p.opts.FailEvery <= 1 || call%int64(p.opts.FailEvery) == 0)
+
+	raw := ""
+	if failing && p.opts.Failure == MockFailureMalformedJSON {
+		raw = `{"text": "truncated mock respo`
+	} else if failing {
+		switch p.opts.Failure {
+		case MockFailureRateLimit:
+			return "", TokenUsage{}, fmt.Errorf("%w: mock provider (call %d)", ErrAIRateLimited, call)
+		case MockFailureQuota:
+			return "", TokenUsage{}, fmt.Errorf("%w: mock provider (call %d)", ErrAIQuotaExceeded, call)
+		case MockFailureUnavailable:
+			return "", TokenUsage{}, fmt.Errorf("%w: mock provider (call %d)", ErrAIServiceUnavailable, call)
+		}
+	} else {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return "", TokenUsage{}, fmt.Errorf("encode mock response: %w", err)
+		}
+		raw = string(encoded)
+	}
+
+	return raw, TokenUsage{PromptTokens: estimateTokens(prompt), CompletionTokens: estimateTokens(raw)}, nil
+}
+
+// completer adapts respond to completeStructured, so injected malformed replies take the same
+// repair path as real ones.
+func (p *MockProvider) completer(prompt string, payload any) completeFunc {
+	return func(ctx context.Context, _, _ string, _ *outputSchema) (string, TokenUsage, error) {
+		return p.respond(ctx, prompt, payload)
+	}
+}
+
+// Summarize returns the leading sentences of the text, shaped for the summary type.
+func (p *MockProvider) Summarize(ctx context.Context, text string, summaryType string) (*AISummary, error) {
+	cleanType := strings.ToLower(strings.TrimSpace(summaryType))
+	if !isSummaryType(cleanType) {
+		return nil, fmt.Errorf("unsupported summary type: %s", summaryType)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("text to summarize is required")
+	}
+
+	lines := parseMockLines(text)
+	sentences := mockSentences(lines)
+	payload := summaryPayload{KeyPoints: []string{}, Sections: []summaryPayloadSec{}, Chapters: []summaryPayloadChapter{}}
+	switch cleanType {
+	case "brief":
+		payload.Text = strings.Join(firstN(sentences, 2), " ")
+	case "detailed":
+		payload.Text = strings.Join(firstN(sentences, 1), " ")
+		for i, chunk := range chunkStrings(sentences, 3) {
+			payload.Sections = append(payload.Sections, summaryPayloadSec{
+				Title:   fmt.Sprintf("Part %d: %s", i+1, mockTitle(chunk[0])),
+				Content: strings.Join(chunk, " "),
+			})
+		}
+	case "key_points":
+		payload.KeyPoints = firstN(sentences, 5)
+		payload.Text = "- " + strings.Join(payload.KeyPoints, "\n- ")
+	case "chapters":
+		payload.Text = strings.Join(firstN(sentences, 1), " ")
+		payload.Chapters = mockChapters(lines)
+	}
+
+	decoded, usage, err := completeStructured(ctx, p.completer(text, payload), nil, "", "", summaryOutput(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AISummary{
+		Content: SummaryContent{
+			Text:      decoded.Text,
+			KeyPoints: decoded.KeyPoints,
+			Sections:  convertPayloadSections(decoded.Sections),
+			Chapters:  convertPayloadChapters(decoded.Chapters),
+		},
+		Model:         p.opts.Model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: promptsFromContext(ctx).Version,
+		Type:          cleanType,
+	}, nil
+}
+
+var (
+	// mockCommandPattern needs at least one flag or path-like argument so prose such as "go to"
+	// is not mistaken for a command.
+	mockCommandPattern = regexp.MustCompile(`(?:^|\s)((?:go|npm|pnpm|yarn|pip|git|docker|kubectl|curl|python3?|node|make|cargo)\s+[\w-]+(?:\s+(?:-{1,2}[\w=.-]+|[\w@:=-]*[./][\w./:=@-]*))+)`)
+	mockCodePattern    = regexp.MustCompile(`\b(func|def|function|class)\s+(\w+)\s*\(([^)]*)\)`)
+	mockActionPattern  = regexp.MustCompile(`(?i)\b(should|need to|needs to|must|make sure|don't forget|remember to|let's|try to|you can)\b`)
+	mockUrgentPattern  = regexp.MustCompile(`(?i)\b(must|need to|needs to|make sure|don't forget)\b`)
+)
+
+var mockCodeLanguages = map[string]string{
+	"func":     "go",
+	"def":      "python",
+	"function": "javascript",
+	"class":    "python",
+}
+
+// Extract finds commands and function definitions, the longest sentences as quotes, or
+// sentences with imperative cues as action items.
+func (p *MockProvider) Extract(ctx context.Context, text string, extractionType string) (*AIExtraction, error) {
+	cleanType := strings.ToLower(strings.TrimSpace(extractionType))
+	if _, ok := extractionSchemas[cleanType]; !ok {
+		return nil, fmt.Errorf("unsupported extraction type: %s", extractionType)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("text to extract from is required")
+	}
+
+	lines := parseMockLines(text)
+	items := make([]ExtractionItem, 0)
+	switch cleanType {
+	case "code":
+		for _, sentence := range mockSentences(lines) {
+			for _, match := range mockCommandPattern.FindAllStringSubmatch(sentence, -1) {
+				items = append(items, ExtractionItem{Language: "bash", Code: strings.TrimRight(match[1], ".,;"), Context: sentence})
+			}
+			for _, match := range mockCodePattern.FindAllStringSubmatch(sentence, -1) {
+				items = append(items, ExtractionItem{Language: mockCodeLanguages[match[1]], Code: match[0], Context: sentence})
+			}
+		}
+	case "quotes":
+		type ranked struct {
+			line  mockLine
+			order int
+		}
+		candidates := make([]ranked, 0, len(lines))
+		for i, line := range lines {
+			candidates = append(candidates, ranked{line: line, order: i})
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return len(candidates[i].line.text) > len(candidates[j].line.text)
+		})
+		candidates = candidates[:min(3, len(candidates))]
+		importance := make(map[int]string, len(candidates))
+		for i, candidate := range candidates {
+			importance[candidate.order] = []string{"high", "medium", "low"}[i]
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].order < candidates[j].order })
+		for _, candidate := range candidates {
+			items = append(items, ExtractionItem{
+				Quote:      candidate.line.text,
+				Speaker:    candidate.line.speaker,
+				Context:    "One of the longest statements in the transcript",
+				Importance: importance[candidate.order],
+			})
+		}
+	case "action_items":
+		for _, sentence := range mockSentences(lines) {
+			if !mockActionPattern.MatchString(sentence) {
+				continue
+			}
+			priority, category := "medium", "recommendation"
+			if mockUrgentPattern.MatchString(sentence) {
+				priority, category = "high", "task"
+			}
+			items = append(items, ExtractionItem{Action: sentence, Category: category, Priority: priority, Context: "Stated in the transcript"})
+		}
+	}
+
+	decoded, usage, err := completeStructured(ctx, p.completer(text, extractionPayload{Items: items}), nil, "", "", extractionOutput(cleanType))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIExtraction{
+		Items:         decoded,
+		Model:         p.opts.Model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: promptsFromContext(ctx).Version,
+		Type:          cleanType,
+	}, nil
+}
+
+// Translate tags the text with the target language instead of translating it.
+func (p *MockProvider) Translate(ctx context.Context, text string, targetLang string) (*AITranslation, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("text to translate is required")
+	}
+
+	payload := translationPayload{TranslatedText: fmt.Sprintf("[%s] %s", targetLang, strings.TrimSpace(text))}
+	translated, usage, err := completeStructured(ctx, p.completer(text, payload), nil, "", "", translationOutput)
+	if err != nil {
+		return nil, err
+	}
+	return &AITranslation{
+		TranslatedText: translated,
+		Model:          p.opts.Model,
+		TokensUsed:     usage.Total(),
+		Usage:          usage,
+		TargetLanguage: targetLang,
+	}, nil
+}
+
+// mockStopWords are ignored when matching a question against the transcript.
+var mockStopWords = map[string]bool{
+	"what": true, "when": true, "where": true, "which": true, "who": true, "whom": true, "whose": true,
+	"why": true, "how": true, "does": true, "did": true, "the": true, "and": true, "for": true,
+	"are": true, "was": true, "were": true, "this": true, "that": true, "with": true, "about": true,
+	"they": true, "their": true, "there": true, "have": true, "has": true, "video": true, "speaker": true,
+}
+
+// Answer returns the transcript sentences sharing the most keywords with the question.
+func (p *MockProvider) Answer(ctx context.Context, text string, question string) (*AIAnswer, error) {
+	if strings.TrimSpace(question) == "" {
+		return nil, errors.New("question is required")
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("text is required")
+	}
+
+	keywords := mockKeywords(question)
+	type scored struct {
+		sentence string
+		score    int
+	}
+	var matches []scored
+	for _, sentence := range mockSentences(parseMockLines(text)) {
+		score := 0
+		for word := range mockKeywords(sentence) {
+			if keywords[word] {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{sentence: sentence, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	payload := answerPayload{
+		Answer:     "The transcript does not mention this.",
+		Confidence: "low",
+		Sources:    []string{},
+		NotFound:   true,
+	}
+	if len(matches) > 0 {
+		payload.Answer = matches[0].sentence
+		payload.Confidence = "medium"
+		if matches[0].score >= 2 {
+			payload.Confidence = "high"
+		}
+		payload.NotFound = false
+		for _, match := range matches[:min(2, len(matches))] {
+			payload.Sources = append(payload.Sources, match.sentence)
+		}
+	}
+
+	decoded, usage, err := completeStructured(ctx, p.completer(question+"\n"+text, payload), nil, "", "", answerOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIAnswer{
+		Answer:        decoded.Answer,
+		Confidence:    decoded.Confidence,
+		Sources:       decoded.Sources,
+		NotFound:      decoded.NotFound,
+		Model:         p.opts.Model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: promptsFromContext(ctx).Version,
+	}, nil
+}
+
+// Punctuate capitalizes each line and ends it with a period when it has no final punctuation.
+func (p *MockProvider) Punctuate(ctx context.Context, lines []string) (*AIPunctuation, error) {
+	if len(lines) == 0 {
+		return nil, errors.New("lines to punctuate are required")
+	}
+
+	punctuated := make([]string, len(lines))
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		runes := []rune(line)
+		runes[0] = unicode.ToUpper(runes[0])
+		line = string(runes)
+		if !strings.ContainsAny(line[len(line)-1:], ".!?") {
+			line += "."
+		}
+		punctuated[i] = line
+	}
+
+	decoded, usage, err := completeStructured(ctx, p.completer(strings.Join(lines, "\n"), punctuationPayload{Lines: punctuated}), nil, "", "", punctuationOutput(len(lines)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIPunctuation{
+		Lines:         decoded,
+		Model:         p.opts.Model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: promptsFromContext(ctx).Version,
+	}, nil
+}
+
+// LabelSpeakers assumes an interview: lines ending with a question belong to the first speaker
+// and the lines answering them to the second. The first two known speakers are used when given.
+func (p *MockProvider) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error) {
+	if len(lines) == 0 {
+		return nil, errors.New("lines to label are required")
+	}
+
+	speakers := []string{"Speaker 1", "Speaker 2"}
+	copy(speakers, knownSpeakers)
+
+	turns := []SpeakerTurn{{Line: 1, Speaker: speakers[0]}}
+	current := 0
+	for i := 1; i < len(lines); i++ {
+		next := current
+		if strings.HasSuffix(strings.TrimSpace(lines[i]), "?") {
+			next = 0
+		} else if strings.HasSuffix(strings.TrimSpace(lines[i-1]), "?") {
+			next = 1
+		}
+		if next != current {
+			current = next
+			turns = append(turns, SpeakerTurn{Line: i + 1, Speaker: speakers[current]})
+		}
+	}
+
+	decoded, usage, err := completeStructured(ctx, p.completer(strings.Join(lines, "\n"), speakerPayload{Turns: turns}), nil, "", "", speakerOutput(len(lines)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AISpeakerTurns{
+		Turns:         decoded,
+		Model:         p.opts.Model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: promptsFromContext(ctx).Version,
+	}, nil
+}
+
+// Grade scores the output by the share of its keywords that also appear in the transcript.
+func (p *MockProvider) Grade(ctx context.Context, transcript, task, output string) (*AIGrade, error) {
+	if strings.TrimSpace(transcript) == "" {
+		return nil, errors.New("transcript is required")
+	}
+
+	grounded := mockKeywords(transcript)
+	keywords := mockKeywords(output)
+	found := 0
+	for word := range keywords {
+		if grounded[word] {
+			found++
+		}
+	}
+	payload := gradePayload{Score: 1, Reasoning: "The output has no words from the transcript."}
+	if len(keywords) > 0 {
+		payload.Score = 1 + int(math.Round(4*float64(found)/float64(len(keywords))))
+		payload.Reasoning = fmt.Sprintf("%d of %d keywords in the output appear in the transcript.", found, len(keywords))
+	}
+
+	decoded, usage, err := completeStructured(ctx, p.completer(task+"\n"+transcript+"\n"+output, payload), nil, "", "", gradeOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIGrade{
+		Score:         decoded.Score,
+		Reasoning:     decoded.Reasoning,
+		Model:         p.opts.Model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: defaultPrompts.Version,
+	}, nil
+}
+
+// mockLine is one transcript line with the "[start_ms]" and "Speaker:" prefixes the API adds
+// split off.
+type mockLine struct {
+	startMs int64
+	timed   bool
+	speaker string
+	text    string
+}
+
+var (
+	mockTimestampPrefix = regexp.MustCompile(`^\[(\d+)\]\s*`)
+	mockSpeakerPrefix   = regexp.MustCompile(`^([A-Z][\w .'-]{0,40}):\s+`)
+	mockSentenceEnd     = regexp.MustCompile(`[.!?]+(?:\s+|$)`)
+)
+
+func parseMockLines(text string) []mockLine {
+	var lines []mockLine
+	for _, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		line := mockLine{}
+		if match := mockTimestampPrefix.FindStringSubmatch(raw); match != nil {
+			line.startMs, _ = strconv.ParseInt(match[1], 10, 64)
+			line.timed = true
+			raw = raw[len(match[0]):]
+		}
+		if match := mockSpeakerPrefix.FindStringSubmatch(raw); match != nil {
+			line.speaker = strings.TrimSpace(match[1])
+			raw = raw[len(match[0]):]
+		}
+		if line.text = strings.TrimSpace(raw); line.text != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// mockSentences joins the lines and splits them into sentences. Unpunctuated captions yield
+// one sentence per line instead.
+func mockSentences(lines []mockLine) []string {
+	texts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		texts = append(texts, line.text)
+	}
+	joined := strings.Join(texts, " ")
+	if !mockSentenceEnd.MatchString(joined) {
+		return texts
+	}
+
+	var sentences []string
+	start := 0
+	for _, loc := range mockSentenceEnd.FindAllStringIndex(joined, -1) {
+		if sentence := strings.TrimSpace(joined[start:loc[1]]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = loc[1]
+	}
+	if rest := strings.TrimSpace(joined[start:]); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// mockChapters starts up to four chapters at evenly spaced timed lines, the first at 0.
+func mockChapters(lines []mockLine) []summaryPayloadChapter {
+	timed := make([]mockLine, 0, len(lines))
+	for _, line := range lines {
+		if line.timed {
+			timed = append(timed, line)
+		}
+	}
+	if len(timed) == 0 {
+		return []summaryPayloadChapter{{Title: "Full Video", StartMs: 0}}
+	}
+
+	count := min(4, len(timed))
+	chapters := make([]summaryPayloadChapter, 0, count)
+	for i := 0; i < count; i++ {
+		line := timed[i*len(timed)/count]
+		startMs := line.startMs
+		if i == 0 {
+			startMs = 0
+		}
+		chapters = append(chapters, summaryPayloadChapter{Title: mockTitle(line.text), StartMs: startMs})
+	}
+	return chapters
+}
+
+// mockTitle uses the first four words of a sentence as a title.
+func mockTitle(sentence string) string {
+	words := strings.Fields(strings.TrimRight(sentence, ".!?"))
+	title := strings.Join(words[:min(4, len(words))], " ")
+	if title == "" {
+		return "Untitled"
+	}
+	runes := []rune(title)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func mockKeywords(text string) map[string]bool {
+	keywords := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) >= 3 && !mockStopWords[word] {
+			keywords[word] = true
+		}
+	}
+	return keywords
+}
+
+func firstN(values []string, n int) []string {
+	return append([]string{}, values[:min(n, len(values))]...)
+}
+
+// chunkStrings splits values into at most n consecutive, non-empty chunks.
+func chunkStrings(values []string, n int) [][]string {
+	n = min(n, len(values))
+	chunks := make([][]string, 0, n)
+	for i := 0; i < n; i++ {
+		chunks = append(chunks, values[i*len(values)/n:(i+1)*len(values)/n])
+	}
+	return chunks
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mockTranscript = `[0] Welcome back to the channel. Today we are building a REST API in Go.
+[15000] First install the router with go get github.com/go-chi/chi/v5 and create a main file.
+[42000] The handler is declared as func handleHealth(w http.ResponseWriter, r *http.Request) and returns JSON.
+[90000] You should always validate request bodies before saving them.
+[120000] Make sure to run the tests before every deploy. That is all for today.`
+
+func TestMockProvider_SummarizeIsDeterministic(t *testing.T) {
+	provider, err := NewMockProvider(MockOptions{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	brief, err := provider.Summarize(ctx, mockTranscript, "brief")
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome back to the channel. Today we are building a REST API in Go.", brief.Content.Text)
+	assert.Equal(t, DefaultMockModel, brief.Model)
+	assert.Positive(t, brief.Usage.PromptTokens)
+	assert.Positive(t, brief.Usage.CompletionTokens)
+
+	again, err := provider.Summarize(ctx, mockTranscript, "brief")
+	require.NoError(t, err)
+	assert.Equal(t, brief, again)
+
+	keyPoints, err := provider.Summarize(ctx, mockTranscript, "key_points")
+	require.NoError(t, err)
+	assert.Len(t, keyPoints.Content.KeyPoints, 5)
+
+	detailed, err := provider.Summarize(ctx, mockTranscript, "detailed")
+	require.NoError(t, err)
+	assert.Len(t, detailed.Content.Sections, 3)
+	assert.Equal(t, "Part 1: Welcome back to the", detailed.Content.Sections[0].Title)
+
+	chapters, err := provider.Summarize(ctx, mockTranscript, "chapters")
+	require.NoError(t, err)
+	assert.Equal(t, []SummaryChapter{
+		{Title: "Welcome back to the", StartMs: 0},
+		{Title: "First install the router", StartMs: 15000},
+		{Title: "The handler is declared", StartMs: 42000},
+		{Title: "You should always validate", StartMs: 90000},
+	}, chapters.Content.Chapters)
+
+	_, err = provider.Summarize(ctx, mockTranscript, "haiku")
+	assert.Error(t, err)
+}
+
+func TestMockProvider_Extract(t *testing.T) {
+	provider, err := NewMockProvider(MockOptions{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	code, err := provider.Extract(ctx, mockTranscript, "code")
+	require.NoError(t, err)
+	require.Len(t, code.Items, 2)
+	assert.Equal(t, "bash", code.Items[0].Language)
+	assert.Equal(t, "go get github.com/go-chi/chi/v5", code.Items[0].Code)
+	assert.Equal(t, "go", code.Items[1].Language)
+	assert.Contains(t, code.Items[1].Code, "func handleHealth(")
+
+	actions, err := provider.Extract(ctx, mockTranscript, "action_items")
+	require.NoError(t, err)
+	require.Len(t, actions.Items, 2)
+	assert.Equal(t, "medium", actions.Items[0].Priority)
+	assert.Equal(t, "high", actions.Items[1].Priority)
+
+	quotes, err := provider.Extract(ctx, "Alice: Ship small changes often.\nBob: Tests are the cheapest documentation you will ever write.", "quotes")
+	require.NoError(t, err)
+	require.Len(t, quotes.Items, 2)
+	assert.Equal(t, "Alice", quotes.Items[0].Speaker)
+	assert.Equal(t, "high", quotes.Items[1].Importance)
+}
+
+func TestMockProvider_Answer(t *testing.T) {
+	provider, err := NewMockProvider(MockOptions{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	answer, err := provider.Answer(ctx, mockTranscript, "What should I run before a deploy?")
+	require.NoError(t, err)
+	assert.False(t, answer.NotFound)
+	assert.Equal(t, "Make sure to run the tests before every deploy.", answer.Answer)
+	assert.Equal(t, "high", answer.Confidence)
+
+	missing, err := provider.Answer(ctx, mockTranscript, "Which database is used?")
+	require.NoError(t, err)
+	assert.True(t, missing.NotFound)
+	assert.Empty(t, missing.Sources)
+}
+
+func TestMockProvider_PunctuateAndLabelSpeakers(t *testing.T) {
+	provider, err := NewMockProvider(MockOptions{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	punctuated, err := provider.Punctuate(ctx, []string{"so what is go", "it is a language!"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"So what is go.", "It is a language!"}, punctuated.Lines)
+
+	turns, err := provider.LabelSpeakers(ctx, []string{"what is go?", "a language", "who made it?", "google"}, []string{"Host"})
+	require.NoError(t, err)
+	assert.Equal(t, []SpeakerTurn{
+		{Line: 1, Speaker: "Host"},
+		{Line: 2, Speaker: "Speaker 2"},
+		{Line: 3, Speaker: "Host"},
+		{Line: 4, Speaker: "Speaker 2"},
+	}, turns.Turns)
+}
+
+func TestMockProvider_InjectedFailures(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		mode string
+		want error
+	}{
+		{MockFailureRateLimit, ErrAIRateLimited},
+		{MockFailureQuota, ErrAIQuotaExceeded},
+		{MockFailureUnavailable, ErrAIServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			provider, err := NewMockProvider(MockOptions{Failure: tt.mode})
+			require.NoError(t, err)
+			_, err = provider.Summarize(ctx, mockTranscript, "brief")
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	t.Run("malformed_json", func(t *testing.T) {
+		provider, err := NewMockProvider(MockOptions{Failure: MockFailureMalformedJSON})
+		require.NoError(t, err)
+		_, err = provider.Extract(ctx, mockTranscript, "code")
+		assert.ErrorContains(t, err, "parse extraction response")
+		_, err = provider.Translate(ctx, mockTranscript, "de")
+		assert.ErrorContains(t, err, "parse translation response")
+	})
+
+	t.Run("malformed_json is repaired for translations", func(t *testing.T) {
+		provider, err := NewMockProvider(MockOptions{Failure: MockFailureMalformedJSON, FailEvery: 2})
+		require.NoError(t, err)
+		_, err = provider.Translate(ctx, mockTranscript, "de")
+		require.NoError(t, err)
+		translation, err := provider.Translate(ctx, mockTranscript, "de")
+		require.NoError(t, err)
+		assert.Contains(t, translation.TranslatedText, "[de] ")
+		assert.Equal(t, int64(3), provider.calls.Load())
+	})
+
+	t.Run("malformed_json is repaired", func(t *testing.T) {
+		provider, err := NewMockProvider(MockOptions{Failure: MockFailureMalformedJSON, FailEvery: 2})
+		require.NoError(t, err)
+		_, err = provider.Extract(ctx, mockTranscript, "code")
+		require.NoError(t, err)
+		// The second call is malformed; its repair request is the third.
+		extraction, err := provider.Extract(ctx, mockTranscript, "code")
+		require.NoError(t, err)
+		assert.Len(t, extraction.Items, 2)
+		assert.Equal(t, int64(3), provider.calls.Load())
+	})
+
+	t.Run("every nth call", func(t *testing.T) {
+		provider, err := NewMockProvider(MockOptions{Failure: MockFailureRateLimit, FailEvery: 3})
+		require.NoError(t, err)
+		var failed []int
+		for i := 1; i <= 6; i++ {
+			if _, err := provider.Summarize(ctx, mockTranscript, "brief"); err != nil {
+				failed = append(failed, i)
+			}
+		}
+		assert.Equal(t, []int{3, 6}, failed)
+	})
+
+	_, err := NewMockProvider(MockOptions{Failure: "timeout"})
+	assert.Error(t, err)
+}
+
+func TestMockProvider_LatencyRespectsContext(t *testing.T) {
+	provider, err := NewMockProvider(MockOptions{Latency: time.Minute})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = provider.Answer(ctx, mockTranscript, "What is built?")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMockProvider_FailsOverInChain(t *testing.T) {
+	failing, err := NewMockProvider(MockOptions{Model: "flaky", Failure: MockFailureUnavailable})
+	require.NoError(t, err)
+	healthy, err := NewMockProvider(MockOptions{})
+	require.NoError(t, err)
+
+	service := NewAIServiceWithChain([]NamedProvider{
+		{Name: "mock", Model: "flaky", Provider: failing},
+		{Name: "mock", Model: DefaultMockModel, Provider: healthy},
+	})
+	summary, err := service.Summarize(context.Background(), mockTranscript, "brief")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMockModel, summary.Model)
+}
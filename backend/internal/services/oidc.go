@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrOIDCDiscovery is returned when the issuer's discovery document cannot be used.
+	ErrOIDCDiscovery = errors.New("oidc discovery failed")
+	// ErrOIDCExchange is returned when the token endpoint rejects an authorization code.
+	ErrOIDCExchange = errors.New("oidc code exchange failed")
+	// ErrOIDCInvalidToken is returned when an ID token fails verification.
+	ErrOIDCInvalidToken = errors.New("invalid oidc id token")
+)
+
+// oidcClockSkew is how far the issuer's clock may run ahead of or behind ours.
+const oidcClockSkew = time.Minute
+
+// OIDCConfig configures an OpenID Connect relying party.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// GroupsClaim names the ID token claim holding the user's groups. Dots select nested
+	// claims, e.g. "realm_access.roles" for Keycloak realm roles.
+	GroupsClaim string
+	HTTPClient  *http.Client
+}
+
+// OIDCIdentity is the verified user behind an ID token.
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// OIDCProvider signs users in with the authorization code flow and PKCE against any issuer
+// that publishes a discovery document. ID tokens must be RS256-signed.
+type OIDCProvider struct {
+	config    OIDCConfig
+	client    *http.Client
+	discovery oidcDiscovery
+	now       func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+type oidcDiscovery struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+// NewOIDCProvider fetches the issuer's discovery document and signing keys.
+func NewOIDCProvider(ctx context.Context, config OIDCConfig) (*OIDCProvider, error) {
+	config.IssuerURL = strings.TrimSuffix(config.IssuerURL, "/")
+	if config.IssuerURL == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, errors.New("oidc issuer, client id and redirect url are required")
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	provider := &OIDCProvider{config: config, client: client, now: time.Now}
+	if err := provider.getJSON(ctx, config.IssuerURL+"/.well-known/openid-configuration", &provider.discovery); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+	discovery := provider.discovery
+	switch {
+	case discovery.Issuer != config.IssuerURL:
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrOIDCDiscovery, discovery.Issuer, config.IssuerURL)
+	case discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "":
+		return nil, fmt.Errorf("%w: authorization, token and jwks endpoints are required", ErrOIDCDiscovery)
+	case len(discovery.CodeChallengeMethodsSupported) > 0 && !slices.Contains(discovery.CodeChallengeMethodsSupported, "S256"):
+		return nil, fmt.Errorf("%w: issuer does not support S256 PKCE", ErrOIDCDiscovery)
+	}
+
+	if err := provider.refreshKeys(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+	return provider, nil
+}
+
+// Issuer returns the issuer URL identities are scoped to.
+func (p *OIDCProvider) Issuer() string {
+	return p.config.IssuerURL
+}
+
+// AuthCodeURL returns the issuer URL the user is sent to for signing in.
+func (p *OIDCProvider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(p.discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.discovery.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// Exchange redeems an authorization code and returns the identity from its verified ID token.
+// nonce must match the one sent with the authorization request.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCIdentity, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc token request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("oidc token response: %w", err)
+	}
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("%w: decode token response: %v", ErrOIDCExchange, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if token.Error != "" {
+			return nil, fmt.Errorf("%w: %s %s", ErrOIDCExchange, token.Error, token.ErrorDescription)
+		}
+		return nil, fmt.Errorf("%w: token endpoint returned %d", ErrOIDCExchange, resp.StatusCode)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id_token in response", ErrOIDCExchange)
+	}
+
+	return p.VerifyIDToken(ctx, token.IDToken, nonce)
+}
+
+// VerifyIDToken checks an ID token's signature, issuer, audience, expiry and nonce.
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawToken, nonce string) (*OIDCIdentity, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrOIDCInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrOIDCInvalidToken, err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrOIDCInvalidToken, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrOIDCInvalidToken, err)
+	}
+	key, err := p.signingKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: bad signature", ErrOIDCInvalidToken)
+	}
+
+	var claims map[string]any
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrOIDCInvalidToken, err)
+	}
+	if err := p.validateClaims(claims, nonce); err != nil {
+		return nil, err
+	}
+
+	identity := &OIDCIdentity{
+		Issuer:  p.config.IssuerURL,
+		Subject: claimString(claims, "sub"),
+		Email:   strings.ToLower(claimString(claims, "email")),
+		Name:    claimString(claims, "name"),
+		Groups:  claimStrings(lookupClaim(claims, p.config.GroupsClaim)),
+	}
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrOIDCInvalidToken)
+	}
+	return identity, nil
+}
+
+func (p *OIDCProvider) validateClaims(claims map[string]any, nonce string) error {
+	if claimString(claims, "iss") != p.config.IssuerURL {
+		return fmt.Errorf("%w: unexpected issuer", ErrOIDCInvalidToken)
+	}
+	audiences := claimStrings(claims["aud"])
+	if !slices.Contains(audiences, p.config.ClientID) {
+		return fmt.Errorf("%w: token is for another client", ErrOIDCInvalidToken)
+	}
+	if azp := claimString(claims, "azp"); len(audiences) > 1 && azp != p.config.ClientID {
+		return fmt.Errorf("%w: token is for another client", ErrOIDCInvalidToken)
+	}
+	expiry, ok := claims["exp"].(float64)
+	if !ok || p.now().Add(-oidcClockSkew).After(time.Unix(int64(expiry), 0)) {
+		return fmt.Errorf("%w: token expired", ErrOIDCInvalidToken)
+	}
+	if issuedAt, ok := claims["iat"].(float64); ok && time.Unix(int64(issuedAt), 0).After(p.now().Add(oidcClockSkew)) {
+		return fmt.Errorf("%w: token issued in the future", ErrOIDCInvalidToken)
+	}
+	if nonce == "" || claimString(claims, "nonce") != nonce {
+		return fmt.Errorf("%w: nonce mismatch", ErrOIDCInvalidToken)
+	}
+	return nil
+}
+
+// signingKey returns the key for kid, refetching the key set once when the issuer has rotated
+// keys since the last fetch.
+func (p *OIDCProvider) signingKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.lookupKey(kid)
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, fmt.Errorf("refresh oidc signing keys: %w", err)
+	}
+	p.mu.Lock()
+	key, ok = p.lookupKey(kid)
+	p.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrOIDCInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// lookupKey finds kid in the cached key set; callers hold p.mu. Tokens without a kid are
+// accepted when the issuer publishes a single key.
+func (p *OIDCProvider) lookupKey(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *OIDCProvider) refreshKeys(ctx context.Context) error {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.discovery.JWKSURI, &set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if len(keys) == 0 {
+		return errors.New("issuer publishes no RSA signing keys")
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, endpoint string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(target)
+}
+
+// NewPKCEVerifier returns a random PKCE code verifier (RFC 7636).
+func NewPKCEVerifier() (string, error) {
+	return randomURLToken(32)
+}
+
+// PKCEChallenge derives the S256 code challenge for a verifier.
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// NewOIDCNonce returns a random value for the state and nonce parameters.
+func NewOIDCNonce() (string, error) {
+	return randomURLToken(24)
+}
+
+func randomURLToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func decodeJWTSegment(segment string, target any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// lookupClaim follows a dotted path through nested claims.
+func lookupClaim(claims map[string]any, path string) any {
+	var value any = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	return value
+}
+
+func claimString(claims map[string]any, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimStrings reads a claim that may be a single string or a list of strings.
+func claimStrings(value any) []string {
+	switch typed := value.(type) {
+	case string:
+		return []string{typed}
+	case []any:
+		values := make([]string, 0, len(typed))
+		for _, item := range typed {
+			if text, ok := item.(string); ok {
+				values = append(values, text)
+			}
+		}
+		return values
+	}
+	return nil
+}
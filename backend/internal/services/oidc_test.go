@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/services/oidctest"
+)
+
+func newTestOIDCProvider(t *testing.T, issuer *oidctest.Issuer, groupsClaim string) *OIDCProvider {
+	t.Helper()
+	provider, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		IssuerURL:    issuer.URL() + "/",
+		ClientID:     issuer.ClientID,
+		ClientSecret: issuer.ClientSecret,
+		RedirectURL:  "https://app.example.com/api/v1/auth/oidc/callback",
+		GroupsClaim:  groupsClaim,
+	})
+	require.NoError(t, err)
+	return provider
+}
+
+// authorize follows the authorization URL and returns the code and state sent back.
+func authorize(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestOIDCProvider_CodeFlowWithPKCE(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "yt-app", "s3cr3t/+")
+	issuer.SetUser(map[string]any{
+		"sub":            "user-42",
+		"email":          "Ada@Example.com",
+		"email_verified": true,
+		"name":           "Ada",
+		"realm_access":   map[string]any{"roles": []string{"editors", "staff"}},
+	})
+	provider := newTestOIDCProvider(t, issuer, "realm_access.roles")
+	assert.Equal(t, issuer.URL(), provider.Issuer())
+
+	verifier, err := NewPKCEVerifier()
+	require.NoError(t, err)
+	authURL := provider.AuthCodeURL("state-1", "nonce-1", PKCEChallenge(verifier))
+	assert.Contains(t, authURL, "code_challenge_method=S256")
+	assert.Contains(t, authURL, "scope=openid+email+profile")
+
+	code, state := authorize(t, authURL)
+	assert.Equal(t, "state-1", state)
+
+	identity, err := provider.Exchange(context.Background(), code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, &OIDCIdentity{
+		Issuer:        issuer.URL(),
+		Subject:       "user-42",
+		Email:         "ada@example.com",
+		EmailVerified: true,
+		Name:          "Ada",
+		Groups:        []string{"editors", "staff"},
+	}, identity)
+
+	_, err = provider.Exchange(context.Background(), code, verifier, "nonce-1")
+	assert.ErrorIs(t, err, ErrOIDCExchange, "codes are single use")
+
+	code, _ = authorize(t, provider.AuthCodeURL("state-2", "nonce-2", PKCEChallenge(verifier)))
+	_, err = provider.Exchange(context.Background(), code, verifier+"x", "nonce-2")
+	assert.ErrorIs(t, err, ErrOIDCExchange, "the verifier must match the challenge")
+
+	code, _ = authorize(t, provider.AuthCodeURL("state-3", "nonce-3", PKCEChallenge(verifier)))
+	_, err = provider.Exchange(context.Background(), code, verifier, "other-nonce")
+	assert.ErrorIs(t, err, ErrOIDCInvalidToken)
+}
+
+func TestOIDCProvider_VerifyIDTokenRejectsBadTokens(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "yt-app", "secret")
+	provider := newTestOIDCProvider(t, issuer, "groups")
+	ctx := context.Background()
+
+	valid := issuer.Claims("n")
+	valid["sub"] = "user-1"
+	valid["groups"] = "admins"
+	identity, err := provider.VerifyIDToken(ctx, issuer.SignIDToken(valid), "n")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admins"}, identity.Groups)
+	assert.False(t, identity.EmailVerified)
+
+	cases := map[string]func(claims map[string]any){
+		"wrong issuer":   func(c map[string]any) { c["iss"] = "https://evil.example.com" },
+		"wrong audience": func(c map[string]any) { c["aud"] = "other-app" },
+		"expired":        func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"missing nonce":  func(c map[string]any) { delete(c, "nonce") },
+		"missing sub":    func(c map[string]any) { delete(c, "sub") },
+		"foreign azp": func(c map[string]any) {
+			c["aud"] = []string{"yt-app", "other-app"}
+			c["azp"] = "other-app"
+		},
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			claims := issuer.Claims("n")
+			claims["sub"] = "user-1"
+			mutate(claims)
+			_, err := provider.VerifyIDToken(ctx, issuer.SignIDToken(claims), "n")
+			assert.ErrorIs(t, err, ErrOIDCInvalidToken)
+		})
+	}
+
+	token := issuer.SignIDToken(valid)
+	_, err = provider.VerifyIDToken(ctx, token[:len(token)-4]+"AAAA", "n")
+	assert.ErrorIs(t, err, ErrOIDCInvalidToken, "tampered signature")
+
+	other := oidctest.NewIssuer(t, "yt-app", "secret")
+	_, err = provider.VerifyIDToken(ctx, other.SignIDToken(valid), "n")
+	assert.ErrorIs(t, err, ErrOIDCInvalidToken, "signed by another key")
+}
+
+func TestNewOIDCProvider_RejectsMismatchedIssuer(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "yt-app", "secret")
+	_, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		IssuerURL:   issuer.URL() + "/realms/other",
+		ClientID:    "yt-app",
+		RedirectURL: "https://app.example.com/callback",
+	})
+	assert.ErrorIs(t, err, ErrOIDCDiscovery)
+}
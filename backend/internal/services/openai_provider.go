@@ -5,7 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"strings"
 
 	openai "github.com/sashabaranov/go-openai"
@@ -13,6 +14,7 @@ import (
 
 type openAIClient interface {
 	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+	ListModels(ctx context.Context) (openai.ModelsList, error)
 }
 
 // OpenAIProvider implements the AIProvider interface using OpenAI's API
@@ -21,6 +23,37 @@ type OpenAIProvider struct {
 	model       string
 	maxTokens   int
 	temperature float32
+	format      openAIResponseFormat
+}
+
+// openAIResponseFormat is how structured replies are requested from a chat completions API.
+type openAIResponseFormat int
+
+const (
+	// openAIFormatPrompt relies on the prompt alone, which every model and server accepts.
+	openAIFormatPrompt openAIResponseFormat = iota
+	// openAIFormatJSONObject asks for any valid JSON object (JSON mode).
+	openAIFormatJSONObject
+	// openAIFormatJSONSchema constrains the reply to the schema (structured outputs).
+	openAIFormatJSONSchema
+)
+
+// openAIResponseFormatFor picks the strongest response format model accepts. Models that
+// predate structured outputs, such as gpt-4 and gpt-3.5-turbo, answer 400 to json_schema.
+func openAIResponseFormatFor(model string) openAIResponseFormat {
+	model = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(model)), "ft:")
+	switch {
+	case model == "gpt-4o-2024-05-13":
+		return openAIFormatJSONObject
+	case strings.HasPrefix(model, "gpt-4o"), strings.HasPrefix(model, "gpt-4.1"), strings.HasPrefix(model, "gpt-5"),
+		model == "o1", strings.HasPrefix(model, "o1-20"), strings.HasPrefix(model, "o3"), strings.HasPrefix(model, "o4"):
+		return openAIFormatJSONSchema
+	case strings.HasPrefix(model, "gpt-4-turbo"), strings.HasPrefix(model, "gpt-4-1106"), strings.HasPrefix(model, "gpt-4-0125"),
+		model == "gpt-3.5-turbo", strings.HasPrefix(model, "gpt-3.5-turbo-1106"), strings.HasPrefix(model, "gpt-3.5-turbo-0125"):
+		return openAIFormatJSONObject
+	default:
+		return openAIFormatPrompt
+	}
 }
 
 // NewOpenAIProvider creates a new OpenAI provider with the given configuration
@@ -29,16 +62,28 @@ func NewOpenAIProvider(apiKey, model string, maxTokens int, temperature float64)
 		return nil, errors.New("OpenAI API key is required")
 	}
 
-	client := openai.NewClient(apiKey)
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.HTTPClient = newRetryingHTTPClient("openai", DefaultRetryPolicy)
+	client := openai.NewClientWithConfig(clientConfig)
 
 	return &OpenAIProvider{
 		client:      client,
 		model:       model,
 		maxTokens:   maxTokens,
 		temperature: float32(temperature),
+		format:      openAIResponseFormatFor(model),
 	}, nil
 }
 
+// Ping lists the available models, which checks the endpoint and the key without a completion.
+func (p *OpenAIProvider) Ping(ctx context.Context) error {
+	if p == nil {
+		return errors.New("openai provider is nil")
+	}
+	_, err := p.client.ListModels(ctx)
+	return err
+}
+
 // Summarize generates a summary of the given text
 func (p *OpenAIProvider) Summarize(ctx context.Context, text string, summaryType string) (*AISummary, error) {
 	if p == nil {
@@ -50,7 +95,8 @@ func (p *OpenAIProvider) Summarize(ctx context.Context, text string, summaryType
 		return nil, errors.New("summary type is required")
 	}
 
-	systemInstructions, ok := summarySystemPrompts[cleanType]
+	prompts := promptsFromContext(ctx)
+	systemPrompt, ok := prompts.summary(cleanType)
 	if !ok {
 		return nil, fmt.Errorf("unsupported summary type: %s", summaryType)
 	}
@@ -59,17 +105,11 @@ func (p *OpenAIProvider) Summarize(ctx context.Context, text string, summaryType
 		return nil, errors.New("text to summarize is required")
 	}
 
-	systemPrompt := fmt.Sprintf(baseSystemPrompt, systemInstructions)
 	userPrompt := buildUserPrompt(cleanType, text)
 
-	raw, tokensUsed, err := p.complete(ctx, systemPrompt, userPrompt)
+	payload, usage, err := completeStructured(ctx, p.complete, translateOpenAIError, systemPrompt, userPrompt, summaryOutput(ctx))
 	if err != nil {
-		return nil, translateOpenAIError(err)
-	}
-
-	payload, err := decodeSummaryPayload(raw)
-	if err != nil {
-		return nil, fmt.Errorf("parse summary response: %w", err)
+		return nil, err
 	}
 
 	return &AISummary{
@@ -77,10 +117,13 @@ func (p *OpenAIProvider) Summarize(ctx context.Context, text string, summaryType
 			Text:      payload.Text,
 			KeyPoints: payload.KeyPoints,
 			Sections:  convertPayloadSections(payload.Sections),
+			Chapters:  convertPayloadChapters(payload.Chapters),
 		},
-		Model:      p.model,
-		TokensUsed: tokensUsed,
-		Type:       cleanType,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+		Type:          cleanType,
 	}, nil
 }
 
@@ -95,7 +138,8 @@ func (p *OpenAIProvider) Extract(ctx context.Context, text string, extractionTyp
 		return nil, errors.New("extraction type is required")
 	}
 
-	systemPrompt, ok := extractionSystemPrompts[cleanType]
+	prompts := promptsFromContext(ctx)
+	systemPrompt, ok := prompts.extraction(cleanType)
 	if !ok {
 		return nil, fmt.Errorf("unsupported extraction type: %s", extractionType)
 	}
@@ -106,21 +150,18 @@ func (p *OpenAIProvider) Extract(ctx context.Context, text string, extractionTyp
 
 	userPrompt := fmt.Sprintf("Extract %s from the following transcript:\n\n%s", cleanType, strings.TrimSpace(text))
 
-	raw, tokensUsed, err := p.complete(ctx, systemPrompt, userPrompt)
-	if err != nil {
-		return nil, translateOpenAIError(err)
-	}
-
-	items, err := decodeExtractionPayload(raw)
+	items, usage, err := completeStructured(ctx, p.complete, translateOpenAIError, systemPrompt, userPrompt, extractionOutput(cleanType))
 	if err != nil {
-		return nil, fmt.Errorf("parse extraction response: %w", err)
+		return nil, err
 	}
 
 	return &AIExtraction{
-		Items:      items,
-		Model:      p.model,
-		TokensUsed: tokensUsed,
-		Type:       cleanType,
+		Items:         items,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+		Type:          cleanType,
 	}, nil
 }
 
@@ -144,32 +185,131 @@ func (p *OpenAIProvider) Answer(ctx context.Context, text string, question strin
 		return nil, errors.New("text is required")
 	}
 
-	systemPrompt := qaSystemPrompt
+	prompts := promptsFromContext(ctx)
+	systemPrompt := prompts.answer()
 	userPrompt := buildQAUserPrompt(question, text)
 
-	raw, tokensUsed, err := p.complete(ctx, systemPrompt, userPrompt)
+	answer, usage, err := completeStructured(ctx, p.complete, translateOpenAIError, systemPrompt, userPrompt, answerOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIAnswer{
+		Answer:        answer.Answer,
+		Confidence:    answer.Confidence,
+		Sources:       answer.Sources,
+		NotFound:      answer.NotFound,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+	}, nil
+}
+
+// Punctuate restores punctuation and capitalization for caption lines
+func (p *OpenAIProvider) Punctuate(ctx context.Context, lines []string) (*AIPunctuation, error) {
+	if p == nil {
+		return nil, errors.New("openai provider is nil")
+	}
+
+	if len(lines) == 0 {
+		return nil, errors.New("lines to punctuate are required")
+	}
+
+	prompts := promptsFromContext(ctx)
+	punctuated, usage, err := completeStructured(ctx, p.complete, translateOpenAIError, prompts.punctuation(), buildPunctuationUserPrompt(lines), punctuationOutput(len(lines)))
 	if err != nil {
-		return nil, translateOpenAIError(err)
+		return nil, err
 	}
 
-	answer, err := decodeAnswerPayload(raw)
+	return &AIPunctuation{
+		Lines:         punctuated,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+	}, nil
+}
+
+// LabelSpeakers detects speaker turns in transcript lines
+func (p *OpenAIProvider) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error) {
+	if p == nil {
+		return nil, errors.New("openai provider is nil")
+	}
+
+	if len(lines) == 0 {
+		return nil, errors.New("lines to label are required")
+	}
+
+	prompts := promptsFromContext(ctx)
+	turns, usage, err := completeStructured(ctx, p.complete, translateOpenAIError, prompts.speakers(), buildSpeakerUserPrompt(lines, knownSpeakers), speakerOutput(len(lines)))
 	if err != nil {
-		return nil, fmt.Errorf("parse answer response: %w", err)
+		return nil, err
 	}
 
-	return &AIAnswer{
-		Answer:     answer.Answer,
-		Confidence: answer.Confidence,
-		Sources:    answer.Sources,
-		NotFound:   answer.NotFound,
-		Model:      p.model,
-		TokensUsed: tokensUsed,
+	return &AISpeakerTurns{
+		Turns:         turns,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: prompts.Version,
+	}, nil
+}
+
+// Grade grades an output produced from the transcript with the default grading prompt
+func (p *OpenAIProvider) Grade(ctx context.Context, transcript, task, output string) (*AIGrade, error) {
+	if p == nil {
+		return nil, errors.New("openai provider is nil")
+	}
+
+	if strings.TrimSpace(transcript) == "" {
+		return nil, errors.New("transcript is required")
+	}
+
+	grade, usage, err := completeStructured(ctx, p.complete, translateOpenAIError, defaultPrompts.grade(), buildGradeUserPrompt(transcript, task, output), gradeOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIGrade{
+		Score:         grade.Score,
+		Reasoning:     grade.Reasoning,
+		Model:         p.model,
+		TokensUsed:    usage.Total(),
+		Usage:         usage,
+		PromptVersion: defaultPrompts.Version,
 	}, nil
 }
 
 // complete is a helper function to call the OpenAI API
-// This will be used by the Summarize, Extract, Translate, and Answer methods
-func (p *OpenAIProvider) complete(ctx context.Context, systemPrompt, userPrompt string) (string, int, error) {
+// This will be used by the Summarize, Extract, Translate, and Answer methods. With a schema the
+// reply is constrained to it through a strict json_schema response format when the model
+// supports one; otherwise the schema is added to the system prompt, JSON mode is requested where
+// available, and completeStructured's repair retry catches replies that do not follow it.
+func (p *OpenAIProvider) complete(ctx context.Context, systemPrompt, userPrompt string, schema *outputSchema) (_ string, usage TokenUsage, err error) {
+	ctx, span := startCompletionSpan(ctx, "openai", p.model, schema)
+	defer func() { endCompletionSpan(span, usage, err) }()
+
+	var responseFormat *openai.ChatCompletionResponseFormat
+	if schema != nil {
+		switch p.format {
+		case openAIFormatJSONSchema:
+			responseFormat = &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+				JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+					Name:   schema.name,
+					Schema: schema.render(schemaDialectJSON),
+					Strict: true,
+				},
+			}
+		case openAIFormatJSONObject:
+			responseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+			systemPrompt = withSchemaInstructions(systemPrompt, schema)
+		default:
+			systemPrompt = withSchemaInstructions(systemPrompt, schema)
+		}
+	}
+
 	resp, err := p.client.CreateChatCompletion(
 		ctx,
 		openai.ChatCompletionRequest{
@@ -184,129 +324,26 @@ func (p *OpenAIProvider) complete(ctx context.Context, systemPrompt, userPrompt
 					Content: userPrompt,
 				},
 			},
-			MaxTokens:   p.maxTokens,
-			Temperature: p.temperature,
+			MaxTokens:      p.maxTokens,
+			Temperature:    p.temperature,
+			ResponseFormat: responseFormat,
 		},
 	)
 
 	if err != nil {
-		return "", 0, fmt.Errorf("openai completion: %w", err)
+		return "", TokenUsage{}, fmt.Errorf("openai completion: %w", err)
 	}
 
 	if len(resp.Choices) == 0 {
-		return "", 0, errors.New("no completion choices returned")
-	}
-
-	return resp.Choices[0].Message.Content, resp.Usage.TotalTokens, nil
-}
-
-const baseSystemPrompt = `
-You are an expert summarizer for spoken transcripts. Always respond with strict JSON using the schema:
-{
-  "text": string,
-  "key_points": string[],
-  "sections": [{"title": string, "content": string}]
-}
-Do not include any additional commentary, code fences, or explanations outside the JSON object. %s
-Ensure responses are factual, concise, and written in a professional tone.`
-
-var summarySystemPrompts = map[string]string{
-	"brief":      `Provide a concise 2-3 sentence summary capturing the main topic and key message. Populate only the "text" field and leave "key_points" and "sections" empty arrays.`,
-	"detailed":   `Create a comprehensive summary with an introduction, 3-5 detailed sections, and a conclusion. Fill the "sections" array with informative titles and paragraph content. Include a short overall overview in "text" and leave "key_points" empty.`,
-	"key_points": `Extract 5-10 key takeaways as a bulleted list in markdown format. Populate the "key_points" array with individual bullet strings and provide the combined markdown bullets in "text". Leave "sections" empty.`,
-}
-
-var extractionSystemPrompts = map[string]string{
-	"code": `You are a code extraction specialist. Extract all code snippets, commands, or technical examples from the transcript.
-Return ONLY a valid JSON object with this exact structure (no additional text, no code fences):
-{
-  "items": [
-    {
-      "language": "python",
-      "code": "print('hello')",
-      "context": "Example hello world program",
-      "timestamp_hint": "mentioned at 2:30"
-    }
-  ]
-}
-
-Rules:
-- Extract only actual code, commands, or technical syntax
-- Identify the programming language (python, javascript, bash, sql, etc.)
-- Provide context explaining what the code does
-- Include timestamp hints if the speaker mentions a time
-- If no code is found, return {"items": []}
-- Do not include explanatory text outside the JSON structure`,
-
-	"quotes": `You are a quote extraction specialist. Extract notable quotes, key statements, and memorable phrases from the transcript.
-Return ONLY a valid JSON object with this exact structure (no additional text, no code fences):
-{
-  "items": [
-    {
-      "quote": "Code is read far more often than it is written",
-      "speaker": "Guido van Rossum",
-      "context": "Discussing the importance of readable code",
-      "importance": "high"
-    }
-  ]
-}
-
-Rules:
-- Extract direct quotes that are impactful, memorable, or insightful
-- Identify the speaker if mentioned in the transcript
-- Provide context for why the quote is significant
-- Rate importance as "high", "medium", or "low"
-- If no notable quotes are found, return {"items": []}
-- Do not include explanatory text outside the JSON structure`,
-
-	"action_items": `You are an action item extraction specialist. Extract actionable steps, recommendations, tasks, and to-dos from the transcript.
-Return ONLY a valid JSON object with this exact structure (no additional text, no code fences):
-{
-  "items": [
-    {
-      "action": "Set up automated testing pipeline",
-      "category": "task",
-      "priority": "high",
-      "context": "Required for CI/CD implementation"
-    }
-  ]
-}
-
-Rules:
-- Extract clear, actionable items that listeners should do
-- Categorize as "task" (specific action), "recommendation" (suggestion), or "step" (process step)
-- Assign priority as "high", "medium", or "low"
-- Provide context explaining why this action matters
-- If no action items are found, return {"items": []}
-- Do not include explanatory text outside the JSON structure`,
-}
-
-const qaSystemPrompt = `You are a Q&A specialist analyzing video transcripts. Answer questions accurately based ONLY on the provided transcript content. If the answer is not in the transcript, clearly state that.
-
-Return your response as JSON with this exact structure:
-{
-  "answer": "The detailed answer text",
-  "confidence": "high" | "medium" | "low",
-  "sources": ["relevant quote 1", "relevant quote 2"],
-  "not_found": false
-}
-
-If the answer is NOT in the transcript, return:
-{
-  "answer": "This information is not mentioned in the transcript.",
-  "confidence": "high",
-  "sources": [],
-  "not_found": true
-}
-
-Guidelines:
-- Be concise but complete
-- Quote relevant parts of the transcript in "sources"
-- Use "high" confidence when answer is explicit
-- Use "medium" when inferring from context
-- Use "low" when answer is uncertain
-- NEVER make up information not in the transcript
-- Do not include code fences or additional text outside the JSON object`
+		return "", TokenUsage{}, errors.New("no completion choices returned")
+	}
+
+	usage = TokenUsage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}
+	return resp.Choices[0].Message.Content, resultUsage(usage, resp.Usage.TotalTokens), nil
+}
 
 func buildUserPrompt(summaryType, text string) string {
 	var builder strings.Builder
@@ -326,10 +363,45 @@ func buildQAUserPrompt(question, text string) string {
 	return builder.String()
 }
 
+func buildPunctuationUserPrompt(lines []string) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Restore punctuation for these %d caption lines:\n\n", len(lines))
+	for i, line := range lines {
+		fmt.Fprintf(&builder, "%d. %s\n", i+1, strings.TrimSpace(line))
+	}
+	return builder.String()
+}
+
+func buildGradeUserPrompt(transcript, task, output string) string {
+	var builder strings.Builder
+	builder.WriteString("Task: ")
+	builder.WriteString(strings.TrimSpace(task))
+	builder.WriteString("\n\nTranscript:\n")
+	builder.WriteString(strings.TrimSpace(transcript))
+	builder.WriteString("\n\nOutput to grade:\n")
+	builder.WriteString(strings.TrimSpace(output))
+	return builder.String()
+}
+
+func buildSpeakerUserPrompt(lines []string, knownSpeakers []string) string {
+	var builder strings.Builder
+	if len(knownSpeakers) > 0 {
+		builder.WriteString("Known speakers from earlier in the transcript: ")
+		builder.WriteString(strings.Join(knownSpeakers, ", "))
+		builder.WriteString("\n\n")
+	}
+	fmt.Fprintf(&builder, "Label speaker turns for these %d transcript lines:\n\n", len(lines))
+	for i, line := range lines {
+		fmt.Fprintf(&builder, "%d. %s\n", i+1, strings.TrimSpace(line))
+	}
+	return builder.String()
+}
+
 type summaryPayload struct {
-	Text      string              `json:"text"`
-	KeyPoints []string            `json:"key_points"`
-	Sections  []summaryPayloadSec `json:"sections"`
+	Text      string                  `json:"text"`
+	KeyPoints []string                `json:"key_points"`
+	Sections  []summaryPayloadSec     `json:"sections"`
+	Chapters  []summaryPayloadChapter `json:"chapters"`
 }
 
 type summaryPayloadSec struct {
@@ -337,7 +409,12 @@ type summaryPayloadSec struct {
 	Content string `json:"content"`
 }
 
-func decodeSummaryPayload(raw string) (*summaryPayload, error) {
+type summaryPayloadChapter struct {
+	Title   string `json:"title"`
+	StartMs int64  `json:"start_ms" description:"Start time in milliseconds of the transcript line the chapter begins at"`
+}
+
+func decodeSummaryPayload(ctx context.Context, raw string) (*summaryPayload, error) {
 	normalized := strings.TrimSpace(raw)
 	normalized = strings.TrimPrefix(normalized, "```json")
 	normalized = strings.TrimPrefix(normalized, "```JSON")
@@ -348,8 +425,7 @@ func decodeSummaryPayload(raw string) (*summaryPayload, error) {
 
 	var payload summaryPayload
 	if err := json.Unmarshal([]byte(normalized), &payload); err != nil {
-		// Log the actual response for debugging
-		log.Printf("ERROR: Failed to parse AI summary response. Raw response (first 500 chars): %s", truncateString(normalized, 500))
+		slog.DebugContext(ctx, "failed to parse AI summary response", "raw_response", truncateString(normalized, 500), "error", err)
 		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
 	}
 
@@ -364,6 +440,10 @@ func decodeSummaryPayload(raw string) (*summaryPayload, error) {
 		payload.Sections[i].Content = strings.TrimSpace(payload.Sections[i].Content)
 	}
 
+	for i := range payload.Chapters {
+		payload.Chapters[i].Title = strings.TrimSpace(payload.Chapters[i].Title)
+	}
+
 	return &payload, nil
 }
 
@@ -392,6 +472,24 @@ func convertPayloadSections(sections []summaryPayloadSec) []SummarySection {
 	return result
 }
 
+func convertPayloadChapters(chapters []summaryPayloadChapter) []SummaryChapter {
+	if len(chapters) == 0 {
+		return nil
+	}
+
+	result := make([]SummaryChapter, 0, len(chapters))
+	for _, chapter := range chapters {
+		if chapter.Title == "" {
+			continue
+		}
+		result = append(result, SummaryChapter{
+			Title:   chapter.Title,
+			StartMs: chapter.StartMs,
+		})
+	}
+	return result
+}
+
 type extractionPayload struct {
 	Items []ExtractionItem `json:"items"`
 }
@@ -425,9 +523,76 @@ func decodeExtractionPayload(raw string) ([]ExtractionItem, error) {
 	return payload.Items, nil
 }
 
+type punctuationPayload struct {
+	Lines []string `json:"lines"`
+}
+
+func decodePunctuationPayload(raw string, expected int) ([]string, error) {
+	normalized := strings.TrimSpace(raw)
+	normalized = strings.TrimPrefix(normalized, "```json")
+	normalized = strings.TrimPrefix(normalized, "```JSON")
+	normalized = strings.TrimPrefix(normalized, "```")
+	normalized = strings.TrimSpace(normalized)
+	normalized = strings.TrimSuffix(normalized, "```")
+	normalized = strings.TrimSpace(normalized)
+
+	var payload punctuationPayload
+	if err := json.Unmarshal([]byte(normalized), &payload); err != nil {
+		return nil, err
+	}
+
+	if len(payload.Lines) != expected {
+		return nil, fmt.Errorf("expected %d lines, got %d", expected, len(payload.Lines))
+	}
+
+	for i := range payload.Lines {
+		payload.Lines[i] = strings.TrimSpace(payload.Lines[i])
+	}
+
+	return payload.Lines, nil
+}
+
+type speakerPayload struct {
+	Turns []SpeakerTurn `json:"turns"`
+}
+
+func decodeSpeakerPayload(raw string, lineCount int) ([]SpeakerTurn, error) {
+	normalized := strings.TrimSpace(raw)
+	normalized = strings.TrimPrefix(normalized, "```json")
+	normalized = strings.TrimPrefix(normalized, "```JSON")
+	normalized = strings.TrimPrefix(normalized, "```")
+	normalized = strings.TrimSpace(normalized)
+	normalized = strings.TrimSuffix(normalized, "```")
+	normalized = strings.TrimSpace(normalized)
+
+	var payload speakerPayload
+	if err := json.Unmarshal([]byte(normalized), &payload); err != nil {
+		return nil, err
+	}
+
+	if len(payload.Turns) == 0 {
+		return nil, errors.New("no speaker turns returned")
+	}
+
+	previous := 0
+	for i := range payload.Turns {
+		payload.Turns[i].Speaker = strings.TrimSpace(payload.Turns[i].Speaker)
+		if payload.Turns[i].Speaker == "" {
+			return nil, fmt.Errorf("turn %d has no speaker", i+1)
+		}
+		line := payload.Turns[i].Line
+		if line <= previous || line > lineCount {
+			return nil, fmt.Errorf("turn %d has invalid line %d", i+1, line)
+		}
+		previous = line
+	}
+
+	return payload.Turns, nil
+}
+
 type answerPayload struct {
 	Answer     string   `json:"answer"`
-	Confidence string   `json:"confidence"`
+	Confidence string   `json:"confidence" enum:"high,medium,low"`
 	Sources    []string `json:"sources"`
 	NotFound   bool     `json:"not_found"`
 }
@@ -464,6 +629,31 @@ func decodeAnswerPayload(raw string) (*answerPayload, error) {
 	return &payload, nil
 }
 
+type translationPayload struct {
+	TranslatedText string `json:"translated_text"`
+}
+
+func decodeTranslationPayload(raw string) (string, error) {
+	normalized := strings.TrimSpace(raw)
+	normalized = strings.TrimPrefix(normalized, "```json")
+	normalized = strings.TrimPrefix(normalized, "```JSON")
+	normalized = strings.TrimPrefix(normalized, "```")
+	normalized = strings.TrimSpace(normalized)
+	normalized = strings.TrimSuffix(normalized, "```")
+	normalized = strings.TrimSpace(normalized)
+
+	var payload translationPayload
+	if err := json.Unmarshal([]byte(normalized), &payload); err != nil {
+		return "", err
+	}
+
+	translated := strings.TrimSpace(payload.TranslatedText)
+	if translated == "" {
+		return "", errors.New("translated_text is empty")
+	}
+	return translated, nil
+}
+
 func translateOpenAIError(err error) error {
 	var apiErr *openai.APIError
 	if errors.As(err, &apiErr) {
@@ -477,5 +667,49 @@ func translateOpenAIError(err error) error {
 		}
 	}
 
+	// Servers that answer errors without an OpenAI JSON body, as self-hosted ones often do.
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		switch reqErr.HTTPStatusCode {
+		case 429:
+			return fmt.Errorf("%w: %v", ErrAIRateLimited, err)
+		case 500, 502, 503, 504:
+			return fmt.Errorf("%w: %v", ErrAIServiceUnavailable, err)
+		}
+	}
+
+	// An endpoint that cannot be reached at all, e.g. a local model server that is not running.
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" &&
+		!errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("%w: %v", ErrAIServiceUnavailable, err)
+	}
+
 	return err
 }
+
+type gradePayload struct {
+	Score     int    `json:"score" description:"1 (poor) to 5 (excellent)"`
+	Reasoning string `json:"reasoning" description:"One sentence explaining the score"`
+}
+
+func decodeGradePayload(raw string) (*gradePayload, error) {
+	normalized := strings.TrimSpace(raw)
+	normalized = strings.TrimPrefix(normalized, "```json")
+	normalized = strings.TrimPrefix(normalized, "```JSON")
+	normalized = strings.TrimPrefix(normalized, "```")
+	normalized = strings.TrimSpace(normalized)
+	normalized = strings.TrimSuffix(normalized, "```")
+	normalized = strings.TrimSpace(normalized)
+
+	var payload gradePayload
+	if err := json.Unmarshal([]byte(normalized), &payload); err != nil {
+		return nil, err
+	}
+
+	if payload.Score < 1 || payload.Score > 5 {
+		return nil, fmt.Errorf("score %d is not between 1 and 5", payload.Score)
+	}
+	payload.Reasoning = strings.TrimSpace(payload.Reasoning)
+	return &payload, nil
+}
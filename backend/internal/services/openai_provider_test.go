@@ -24,6 +24,10 @@ func (m *mockChatCompletionClient) CreateChatCompletion(ctx context.Context, req
 	return m.response, nil
 }
 
+func (m *mockChatCompletionClient) ListModels(ctx context.Context) (openai.ModelsList, error) {
+	return openai.ModelsList{}, m.err
+}
+
 func TestNewOpenAIProvider(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -767,3 +771,45 @@ func TestOpenAIProvider_Answer_ConfidenceNormalization(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenAIProvider_LabelSpeakers_Success(t *testing.T) {
+	mockClient := &mockChatCompletionClient{
+		response: openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{
+				Message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleAssistant,
+					Content: "```json\n{\"turns\":[{\"line\":1,\"speaker\":\"Host\"},{\"line\":3,\"speaker\":\" Jane Doe \"}]}\n```",
+				},
+			}},
+			Usage: openai.Usage{TotalTokens: 210},
+		},
+	}
+
+	provider := &OpenAIProvider{client: mockClient, model: "gpt-4", maxTokens: 4000, temperature: 0.7}
+
+	result, err := provider.LabelSpeakers(context.Background(), []string{"welcome back", "today my guest is jane", "thanks for having me"}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []SpeakerTurn{{Line: 1, Speaker: "Host"}, {Line: 3, Speaker: "Jane Doe"}}, result.Turns)
+	assert.Equal(t, "gpt-4", result.Model)
+	assert.Equal(t, 210, result.TokensUsed)
+}
+
+func TestOpenAIProvider_LabelSpeakers_RejectsOutOfRangeLines(t *testing.T) {
+	mockClient := &mockChatCompletionClient{
+		response: openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{
+				Message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleAssistant,
+					Content: `{"turns":[{"line":1,"speaker":"Host"},{"line":7,"speaker":"Guest"}]}`,
+				},
+			}},
+		},
+	}
+
+	provider := &OpenAIProvider{client: mockClient, model: "gpt-4", maxTokens: 4000, temperature: 0.7}
+
+	_, err := provider.LabelSpeakers(context.Background(), []string{"one", "two"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parse speaker response")
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// embeddedPrompts holds the built-in prompt versions, one directory per version with a
+// "<name>.txt" file per prompt.
+//
+//go:embed prompts
+var embeddedPrompts embed.FS
+
+// DefaultPromptVersion is the prompt version used unless another one is selected.
+const DefaultPromptVersion = "v1"
+
+// Prompt names. The summary prompt is shared by all summary types and has an {{instructions}}
+// placeholder for the "summary.<type>" prompt; extraction prompts are named "extraction.<type>".
+const (
+	promptSummary     = "summary"
+	promptAnswer      = "answer"
+	promptPunctuation = "punctuation"
+	promptSpeakers    = "speakers"
+	promptGrade       = "grade"
+
+	promptInstructionsPlaceholder = "{{instructions}}"
+)
+
+// summaryTypes and extractionTypes are the types every prompt version must cover.
+var (
+	summaryTypes    = []string{"brief", "detailed", "key_points", "chapters"}
+	extractionTypes = []string{"code", "quotes", "action_items"}
+)
+
+// promptNames lists every prompt a version consists of.
+func promptNames() []string {
+	names := []string{promptSummary, promptAnswer, promptPunctuation, promptSpeakers, promptGrade}
+	for _, summaryType := range summaryTypes {
+		names = append(names, promptSummary+"."+summaryType)
+	}
+	for _, extractionType := range extractionTypes {
+		names = append(names, "extraction."+extractionType)
+	}
+	return names
+}
+
+func isSummaryType(summaryType string) bool {
+	for _, candidate := range summaryTypes {
+		if candidate == summaryType {
+			return true
+		}
+	}
+	return false
+}
+
+// PromptSet is one version of every prompt.
+type PromptSet struct {
+	Version string
+	prompts map[string]string
+}
+
+// summary returns the system prompt for a summary type.
+func (s *PromptSet) summary(summaryType string) (string, bool) {
+	instructions, ok := s.prompts[promptSummary+"."+summaryType]
+	if !ok {
+		return "", false
+	}
+	return strings.ReplaceAll(s.prompts[promptSummary], promptInstructionsPlaceholder, instructions), true
+}
+
+// extraction returns the system prompt for an extraction type.
+func (s *PromptSet) extraction(extractionType string) (string, bool) {
+	prompt, ok := s.prompts["extraction."+extractionType]
+	return prompt, ok
+}
+
+func (s *PromptSet) answer() string      { return s.prompts[promptAnswer] }
+func (s *PromptSet) punctuation() string { return s.prompts[promptPunctuation] }
+func (s *PromptSet) speakers() string    { return s.prompts[promptSpeakers] }
+func (s *PromptSet) grade() string       { return s.prompts[promptGrade] }
+
+// PromptRegistry holds every known prompt version.
+type PromptRegistry struct {
+	versions map[string]*PromptSet
+}
+
+// LoadPromptRegistry loads the embedded prompt versions and, when dir is not empty, the versions
+// in dir, laid out the same way ("v2/summary.brief.txt"). Files in dir replace embedded prompts
+// of the same version, and prompts a version does not define are inherited from
+// DefaultPromptVersion, so an experiment only needs the prompts it changes.
+func LoadPromptRegistry(dir string) (*PromptRegistry, error) {
+	embedded, err := fs.Sub(embeddedPrompts, "prompts")
+	if err != nil {
+		return nil, err
+	}
+	texts := make(map[string]map[string]string)
+	if err := readPromptVersions(embedded, texts); err != nil {
+		return nil, fmt.Errorf("embedded prompts: %w", err)
+	}
+	if dir != "" {
+		if err := readPromptVersions(os.DirFS(dir), texts); err != nil {
+			return nil, fmt.Errorf("prompts in %s: %w", dir, err)
+		}
+	}
+
+	base := texts[DefaultPromptVersion]
+	registry := &PromptRegistry{versions: make(map[string]*PromptSet, len(texts))}
+	for version, prompts := range texts {
+		for _, name := range promptNames() {
+			if _, ok := prompts[name]; !ok && base[name] != "" {
+				prompts[name] = base[name]
+			}
+			if prompts[name] == "" {
+				return nil, fmt.Errorf("prompt version %s: %s is missing", version, name)
+			}
+		}
+		if !strings.Contains(prompts[promptSummary], promptInstructionsPlaceholder) {
+			return nil, fmt.Errorf("prompt version %s: %s has no %s placeholder", version, promptSummary, promptInstructionsPlaceholder)
+		}
+		registry.versions[version] = &PromptSet{Version: version, prompts: prompts}
+	}
+	return registry, nil
+}
+
+// readPromptVersions adds the prompts of every version directory in fsys to texts.
+func readPromptVersions(fsys fs.FS, texts map[string]map[string]string) error {
+	known := make(map[string]bool)
+	for _, name := range promptNames() {
+		known[name] = true
+	}
+
+	versions, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if !version.IsDir() {
+			continue
+		}
+		files, err := fs.ReadDir(fsys, version.Name())
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			name, ok := strings.CutSuffix(file.Name(), ".txt")
+			if file.IsDir() || !ok {
+				continue
+			}
+			if !known[name] {
+				return fmt.Errorf("unknown prompt %s/%s", version.Name(), file.Name())
+			}
+			content, err := fs.ReadFile(fsys, path.Join(version.Name(), file.Name()))
+			if err != nil {
+				return err
+			}
+			if texts[version.Name()] == nil {
+				texts[version.Name()] = make(map[string]string)
+			}
+			texts[version.Name()][name] = strings.TrimSpace(string(content))
+		}
+	}
+	return nil
+}
+
+// Get returns a prompt version.
+func (r *PromptRegistry) Get(version string) (*PromptSet, bool) {
+	set, ok := r.versions[version]
+	return set, ok
+}
+
+// Versions lists the known prompt versions in order.
+func (r *PromptRegistry) Versions() []string {
+	versions := make([]string, 0, len(r.versions))
+	for version := range r.versions {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// defaultPrompts is the embedded default version, used by providers called without a selected
+// prompt version.
+var defaultPrompts = func() *PromptSet {
+	registry, err := LoadPromptRegistry("")
+	if err != nil {
+		panic(err)
+	}
+	set, ok := registry.Get(DefaultPromptVersion)
+	if !ok {
+		panic("embedded prompts lack version " + DefaultPromptVersion)
+	}
+	return set
+}()
+
+type promptSetContextKey struct{}
+
+// WithPromptSet makes AI calls made with ctx use prompts.
+func WithPromptSet(ctx context.Context, prompts *PromptSet) context.Context {
+	return context.WithValue(ctx, promptSetContextKey{}, prompts)
+}
+
+// promptsFromContext returns the prompts set by WithPromptSet, or the default version.
+func promptsFromContext(ctx context.Context) *PromptSet {
+	if prompts, ok := ctx.Value(promptSetContextKey{}).(*PromptSet); ok && prompts != nil {
+		return prompts
+	}
+	return defaultPrompts
+}
+
+// PromptSelector picks the prompt version for each call. A share of transcripts can be sent to a
+// candidate version to compare it with the active one; a transcript always gets the same version.
+type PromptSelector struct {
+	active    *PromptSet
+	candidate *PromptSet
+	share     float64
+}
+
+// ErrUnknownPromptVersion is returned for a version that is not in the registry.
+var ErrUnknownPromptVersion = errors.New("unknown prompt version")
+
+// NewPromptSelector selects version active, or candidate for share (0 to 1) of the transcripts.
+// candidate may be empty to disable the split.
+func NewPromptSelector(registry *PromptRegistry, active, candidate string, share float64) (*PromptSelector, error) {
+	selector := &PromptSelector{share: share}
+	var ok bool
+	if selector.active, ok = registry.Get(active); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPromptVersion, active)
+	}
+	if candidate != "" {
+		if selector.candidate, ok = registry.Get(candidate); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPromptVersion, candidate)
+		}
+	}
+	if share < 0 || share > 1 {
+		return nil, fmt.Errorf("candidate prompt share %g must be between 0 and 1", share)
+	}
+	return selector, nil
+}
+
+// String describes the selection for logs, e.g. "v1" or "v1, 20% v2".
+func (s *PromptSelector) String() string {
+	if s.candidate == nil || s.share == 0 {
+		return s.active.Version
+	}
+	return fmt.Sprintf("%s, %g%% %s", s.active.Version, s.share*100, s.candidate.Version)
+}
+
+// Select picks the prompts for a call. Calls are bucketed by the transcript set with
+// WithTranscriptID, and at random when there is none.
+func (s *PromptSelector) Select(ctx context.Context) *PromptSet {
+	if s.candidate == nil || s.share <= 0 {
+		return s.active
+	}
+
+	bucket := rand.Float64()
+	if transcriptID := TranscriptIDFromContext(ctx); transcriptID != "" {
+		hash := fnv.New32a()
+		hash.Write([]byte(transcriptID))
+		bucket = float64(hash.Sum32()%10000) / 10000
+	}
+	if bucket < s.share {
+		return s.candidate
+	}
+	return s.active
+}
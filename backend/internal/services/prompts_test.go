@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePrompt stores a prompt file under dir/version.
+func writePrompt(t *testing.T, dir, version, name, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, version), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, version, name+".txt"), []byte(content), 0o644))
+}
+
+func TestLoadPromptRegistry_Embedded(t *testing.T) {
+	registry, err := LoadPromptRegistry("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultPromptVersion}, registry.Versions())
+
+	prompts, ok := registry.Get(DefaultPromptVersion)
+	require.True(t, ok)
+	summary, ok := prompts.summary("chapters")
+	require.True(t, ok)
+	assert.Contains(t, summary, "You are an expert summarizer")
+	assert.Contains(t, summary, "Divide the video into 3-12 chapters")
+	assert.NotContains(t, summary, promptInstructionsPlaceholder)
+
+	for _, extractionType := range extractionTypes {
+		_, ok := prompts.extraction(extractionType)
+		assert.True(t, ok, extractionType)
+	}
+	_, ok = prompts.summary("haiku")
+	assert.False(t, ok)
+	assert.Contains(t, prompts.answer(), "Q&A specialist")
+	assert.Contains(t, prompts.punctuation(), "restore punctuation")
+	assert.Contains(t, prompts.speakers(), "speaker turns")
+}
+
+func TestLoadPromptRegistry_DirectoryOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "v1", "answer", "Answer tersely.")
+	writePrompt(t, dir, "v2", "summary.brief", "One sentence only.\n")
+
+	registry, err := LoadPromptRegistry(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1", "v2"}, registry.Versions())
+
+	v1, _ := registry.Get("v1")
+	assert.Equal(t, "Answer tersely.", v1.answer())
+
+	v2, ok := registry.Get("v2")
+	require.True(t, ok)
+	brief, _ := v2.summary("brief")
+	assert.Contains(t, brief, "One sentence only.")
+	assert.Contains(t, brief, "You are an expert summarizer", "the shared summary prompt is inherited")
+	assert.Equal(t, v1.answer(), v2.answer(), "v2 inherits the overridden v1 prompts")
+}
+
+func TestLoadPromptRegistry_RejectsInvalidPrompts(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "v2", "sumary.brief", "typo")
+	_, err := LoadPromptRegistry(dir)
+	assert.ErrorContains(t, err, "unknown prompt v2/sumary.brief.txt")
+
+	dir = t.TempDir()
+	writePrompt(t, dir, "v2", "summary", "Summarize as JSON.")
+	_, err = LoadPromptRegistry(dir)
+	assert.ErrorContains(t, err, "has no {{instructions}} placeholder")
+}
+
+func TestPromptSelector(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "v2", "answer", "Answer tersely.")
+	registry, err := LoadPromptRegistry(dir)
+	require.NoError(t, err)
+
+	_, err = NewPromptSelector(registry, "v1", "v3", 0.5)
+	assert.ErrorIs(t, err, ErrUnknownPromptVersion)
+	_, err = NewPromptSelector(registry, "v1", "v2", 1.5)
+	assert.Error(t, err)
+
+	selector, err := NewPromptSelector(registry, "v1", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", selector.Select(context.Background()).Version)
+	assert.Equal(t, "v1", selector.String())
+
+	selector, err = NewPromptSelector(registry, "v1", "v2", 0.2)
+	require.NoError(t, err)
+	assert.Equal(t, "v1, 20% v2", selector.String())
+
+	candidates := 0
+	for i := 0; i < 1000; i++ {
+		ctx := WithTranscriptID(context.Background(), fmt.Sprintf("transcript-%d", i))
+		version := selector.Select(ctx).Version
+		assert.Equal(t, version, selector.Select(ctx).Version, "a transcript keeps its version")
+		if version == "v2" {
+			candidates++
+		}
+	}
+	assert.InDelta(t, 200, candidates, 50)
+}
+
+func TestAIService_RecordsPromptVersion(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "v2", "answer", "Answer tersely.")
+	registry, err := LoadPromptRegistry(dir)
+	require.NoError(t, err)
+	selector, err := NewPromptSelector(registry, "v1", "v2", 1)
+	require.NoError(t, err)
+
+	provider, err := NewMockProvider(MockOptions{})
+	require.NoError(t, err)
+	service := NewAIService(provider, DefaultMockModel, WithPromptSelector(selector))
+
+	summary, err := service.Summarize(context.Background(), mockTranscript, "brief")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", summary.PromptVersion)
+
+	v1, _ := registry.Get("v1")
+	answer, err := service.Answer(WithPromptSet(context.Background(), v1), mockTranscript, "What is built?")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", answer.PromptVersion, "prompts pinned by the caller win")
+}
+
+func TestOpenAIProvider_SendsSelectedPrompt(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "v2", "answer", "Answer tersely.")
+	registry, err := LoadPromptRegistry(dir)
+	require.NoError(t, err)
+	v2, _ := registry.Get("v2")
+
+	server, received, _ := localChatServer(t, `{"answer": "Go", "confidence": "high", "sources": [], "not_found": false}`)
+	provider, err := NewLocalProvider(server.URL+"/v1", "", "llama3.1", 1000, 0.2)
+	require.NoError(t, err)
+
+	answer, err := provider.Answer(WithPromptSet(context.Background(), v2), "we use Go", "Which language?")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", answer.PromptVersion)
+	assert.True(t, strings.HasPrefix(received.Messages[0].Content, "Answer tersely."), received.Messages[0].Content)
+}
+
+func TestProviders_GradeWithTheDefaultPrompt(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "v2", "grade", "Always give 5.")
+	registry, err := LoadPromptRegistry(dir)
+	require.NoError(t, err)
+	v2, _ := registry.Get("v2")
+
+	client := &scriptedChatClient{replies: []string{`{"score": 9, "reasoning": "Great."}`, `{"score": 4, "reasoning": "Misses the deploy step."}`}}
+	provider := &OpenAIProvider{client: client, model: "gpt-4o", maxTokens: 1000, format: openAIFormatJSONSchema}
+
+	grade, err := provider.Grade(WithPromptSet(context.Background(), v2), "We deploy on Fridays.", "brief summary", `{"text": "They deploy."}`)
+	require.NoError(t, err)
+	assert.Equal(t, 4, grade.Score, "a score out of range is sent back for repair")
+	assert.Equal(t, "Misses the deploy step.", grade.Reasoning)
+	assert.Equal(t, DefaultPromptVersion, grade.PromptVersion)
+
+	require.Len(t, client.requests, 2)
+	assert.Equal(t, defaultPrompts.grade(), client.requests[0].Messages[0].Content)
+	assert.Equal(t, "grade", client.requests[0].ResponseFormat.JSONSchema.Name)
+	assert.Contains(t, client.requests[0].Messages[1].Content, "Task: brief summary")
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrAIRequestLimitExceeded is returned when a call could not get through the local rate limiter
+// within its maximum wait. It wraps ErrAIRateLimited so the call fails over like a provider 429.
+var ErrAIRequestLimitExceeded = fmt.Errorf("%w: local request limit exceeded", ErrAIRateLimited)
+
+// RateLimit bounds the calls made to one provider/model. Zero values mean unlimited.
+type RateLimit struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+	MaxConcurrency    int
+}
+
+// RateLimitError reports how long the caller should wait before trying again.
+type RateLimitError struct {
+	Provider   string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: %v (retry after %s)", e.Provider, ErrAIRequestLimitExceeded, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrAIRequestLimitExceeded
+}
+
+// RateLimiter applies token-bucket limits on requests and tokens per minute and caps concurrent
+// calls for every provider/model. Callers queue for up to maxWait before being rejected.
+type RateLimiter struct {
+	defaults  RateLimit
+	overrides map[string]RateLimit
+	maxWait   time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	limiters map[string]*providerLimiter
+}
+
+// NewRateLimiter creates a limiter. Overrides are keyed by lowercase "provider:model" or
+// "provider"; the most specific match wins, otherwise defaults apply.
+func NewRateLimiter(defaults RateLimit, overrides map[string]RateLimit, maxWait time.Duration) *RateLimiter {
+	return &RateLimiter{
+		defaults:  defaults,
+		overrides: overrides,
+		maxWait:   maxWait,
+		now:       time.Now,
+		limiters:  make(map[string]*providerLimiter),
+	}
+}
+
+// Limit wraps a provider so every call goes through the limiter.
+func (l *RateLimiter) Limit(provider NamedProvider) NamedProvider {
+	if l == nil || provider.Provider == nil {
+		return provider
+	}
+	provider.Provider = &limitedProvider{next: provider.Provider, limiter: l.limiterFor(provider), key: provider.String()}
+	return provider
+}
+
+func (l *RateLimiter) limiterFor(provider NamedProvider) *providerLimiter {
+	key := strings.ToLower(provider.String())
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limiter, ok := l.limiters[key]; ok {
+		return limiter
+	}
+
+	limit, ok := l.overrides[key]
+	if !ok {
+		limit, ok = l.overrides[strings.ToLower(provider.Name)]
+	}
+	if !ok {
+		limit = l.defaults
+	}
+
+	now := l.now()
+	limiter := &providerLimiter{now: l.now, maxWait: l.maxWait}
+	if limit.RequestsPerMinute > 0 {
+		limiter.requests = newTokenBucket(limit.RequestsPerMinute, now)
+	}
+	if limit.TokensPerMinute > 0 {
+		limiter.tokens = newTokenBucket(limit.TokensPerMinute, now)
+	}
+	if limit.MaxConcurrency > 0 {
+		limiter.slots = make(chan struct{}, limit.MaxConcurrency)
+	}
+	l.limiters[key] = limiter
+	return limiter
+}
+
+// tokenBucket refills continuously up to a one-minute burst. Reservations may drive the level
+// negative; later callers then wait for the deficit to refill.
+type tokenBucket struct {
+	capacity float64
+	level    float64
+	perSec   float64
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		level:    float64(perMinute),
+		perSec:   float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.level = math.Min(b.capacity, b.level+elapsed*b.perSec)
+		b.last = now
+	}
+}
+
+// waitFor returns how long until n units are available.
+func (b *tokenBucket) waitFor(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	if deficit := math.Min(n, b.capacity) - b.level; deficit > 0 {
+		return time.Duration(deficit / b.perSec * float64(time.Second))
+	}
+	return 0
+}
+
+type providerLimiter struct {
+	now     func() time.Time
+	maxWait time.Duration
+
+	mu       sync.Mutex
+	requests *tokenBucket
+	tokens   *tokenBucket
+	slots    chan struct{}
+}
+
+// acquire waits for a concurrency slot and for both buckets to cover the call, reserving one
+// request and estimatedTokens tokens. The returned release settles the token estimate against
+// the actual usage and frees the slot.
+func (p *providerLimiter) acquire(ctx context.Context, key string, estimatedTokens int) (func(actualTokens int), error) {
+	deadline := p.now().Add(p.maxWait)
+
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			timer := time.NewTimer(max(deadline.Sub(p.now()), 0))
+			defer timer.Stop()
+			select {
+			case p.slots <- struct{}{}:
+			case <-timer.C:
+				return nil, &RateLimitError{Provider: key, RetryAfter: time.Second}
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+	releaseSlot := func() {
+		if p.slots != nil {
+			<-p.slots
+		}
+	}
+
+	for {
+		p.mu.Lock()
+		now := p.now()
+		wait := time.Duration(0)
+		if p.requests != nil {
+			wait = max(wait, p.requests.waitFor(1, now))
+		}
+		if p.tokens != nil {
+			wait = max(wait, p.tokens.waitFor(float64(estimatedTokens), now))
+		}
+		if wait == 0 {
+			if p.requests != nil {
+				p.requests.level--
+			}
+			if p.tokens != nil {
+				p.tokens.level -= float64(estimatedTokens)
+			}
+			p.mu.Unlock()
+			break
+		}
+		p.mu.Unlock()
+
+		if now.Add(wait).After(deadline) {
+			releaseSlot()
+			return nil, &RateLimitError{Provider: key, RetryAfter: wait}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			releaseSlot()
+			return nil, ctx.Err()
+		}
+	}
+
+	return func(actualTokens int) {
+		if p.tokens != nil && actualTokens > 0 {
+			p.mu.Lock()
+			p.tokens.refill(p.now())
+			p.tokens.level = math.Min(p.tokens.capacity, p.tokens.level-float64(actualTokens-estimatedTokens))
+			p.mu.Unlock()
+		}
+		releaseSlot()
+	}, nil
+}
+
+// estimateTokens approximates prompt tokens from text length (about four characters per token).
+func estimateTokens(texts ...string) int {
+	chars := 0
+	for _, text := range texts {
+		chars += len(text)
+	}
+	return chars/4 + 1
+}
+
+// limitedProvider gates an AIProvider behind a providerLimiter.
+type limitedProvider struct {
+	next    AIProvider
+	limiter *providerLimiter
+	key     string
+}
+
+// Unwrap returns the rate limited provider.
+func (p *limitedProvider) Unwrap() AIProvider {
+	return p.next
+}
+
+func limitCall[T aiResult](ctx context.Context, p *limitedProvider, estimate int, call func() (T, error)) (T, error) {
+	var zero T
+	release, err := p.limiter.acquire(ctx, p.key, estimate)
+	if err != nil {
+		return zero, err
+	}
+
+	result, err := call()
+	actual := 0
+	if err == nil {
+		actual = result.usage().Total()
+	}
+	release(actual)
+	return result, err
+}
+
+func (p *limitedProvider) Summarize(ctx context.Context, text string, summaryType string) (*AISummary, error) {
+	return limitCall(ctx, p, estimateTokens(text), func() (*AISummary, error) {
+		return p.next.Summarize(ctx, text, summaryType)
+	})
+}
+
+func (p *limitedProvider) Extract(ctx context.Context, text string, extractionType string) (*AIExtraction, error) {
+	return limitCall(ctx, p, estimateTokens(text), func() (*AIExtraction, error) {
+		return p.next.Extract(ctx, text, extractionType)
+	})
+}
+
+func (p *limitedProvider) Translate(ctx context.Context, text string, targetLang string) (*AITranslation, error) {
+	return limitCall(ctx, p, estimateTokens(text), func() (*AITranslation, error) {
+		return p.next.Translate(ctx, text, targetLang)
+	})
+}
+
+func (p *limitedProvider) Answer(ctx context.Context, text string, question string) (*AIAnswer, error) {
+	return limitCall(ctx, p, estimateTokens(text, question), func() (*AIAnswer, error) {
+		return p.next.Answer(ctx, text, question)
+	})
+}
+
+func (p *limitedProvider) Punctuate(ctx context.Context, lines []string) (*AIPunctuation, error) {
+	return limitCall(ctx, p, estimateTokens(lines...), func() (*AIPunctuation, error) {
+		return p.next.Punctuate(ctx, lines)
+	})
+}
+
+func (p *limitedProvider) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error) {
+	return limitCall(ctx, p, estimateTokens(lines...), func() (*AISpeakerTurns, error) {
+		return p.next.LabelSpeakers(ctx, lines, knownSpeakers)
+	})
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingProvider holds Summarize calls until release is closed.
+type blockingProvider struct {
+	fakeProvider
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProvider) Summarize(ctx context.Context, text, summaryType string) (*AISummary, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return &AISummary{Model: "blocking"}, nil
+}
+
+func TestRateLimiter_CapsConcurrency(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{MaxConcurrency: 1}, nil, 20*time.Millisecond)
+	blocking := &blockingProvider{started: make(chan struct{}, 1), release: make(chan struct{})}
+	provider := limiter.Limit(NamedProvider{Name: "openai", Model: "gpt-4", Provider: blocking})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := provider.Provider.Summarize(context.Background(), "text", "brief")
+		done <- err
+	}()
+	<-blocking.started
+
+	_, err := provider.Provider.Summarize(context.Background(), "text", "brief")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrAIRequestLimitExceeded)
+	assert.ErrorIs(t, err, ErrAIRateLimited, "local limits fail over like provider rate limits")
+
+	close(blocking.release)
+	require.NoError(t, <-done)
+
+	// The slot is free again once the first call finished.
+	blocking.release = make(chan struct{})
+	close(blocking.release)
+	_, err = provider.Provider.Summarize(context.Background(), "text", "brief")
+	require.NoError(t, err)
+}
+
+func TestRateLimiter_RequestsPerMinute(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(RateLimit{RequestsPerMinute: 2}, nil, time.Second)
+	limiter.now = func() time.Time { return now }
+	provider := limiter.Limit(NamedProvider{Name: "anthropic", Provider: &fakeProvider{model: "claude"}})
+
+	for i := 0; i < 2; i++ {
+		_, err := provider.Provider.Extract(context.Background(), "text", "code")
+		require.NoError(t, err)
+	}
+
+	_, err := provider.Provider.Extract(context.Background(), "text", "code")
+	var limitErr *RateLimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "anthropic", limitErr.Provider)
+	assert.Equal(t, 30*time.Second, limitErr.RetryAfter, "one request refills every 30s at 2 rpm")
+
+	now = now.Add(30 * time.Second)
+	_, err = provider.Provider.Extract(context.Background(), "text", "code")
+	require.NoError(t, err)
+}
+
+func TestRateLimiter_TokensPerMinuteSettlesActualUsage(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(RateLimit{TokensPerMinute: 600}, nil, time.Second)
+	limiter.now = func() time.Time { return now }
+
+	heavy := &usageProvider{tokens: 900}
+	provider := limiter.Limit(NamedProvider{Name: "google", Model: "gemini", Provider: heavy})
+
+	_, err := provider.Provider.Answer(context.Background(), "short", "question")
+	require.NoError(t, err)
+
+	// The call used 900 tokens against a 600/min budget, leaving a 300 token deficit.
+	_, err = provider.Provider.Answer(context.Background(), "short", "question")
+	var limitErr *RateLimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Greater(t, limitErr.RetryAfter, 30*time.Second)
+}
+
+func TestRateLimiter_OverridesAndSharedBuckets(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{RequestsPerMinute: 100}, map[string]RateLimit{
+		"openai":        {RequestsPerMinute: 1},
+		"openai:gpt-4o": {RequestsPerMinute: 100},
+	}, 0)
+
+	gpt4 := NamedProvider{Name: "openai", Model: "gpt-4", Provider: &fakeProvider{}}
+	first, second := limiter.Limit(gpt4), limiter.Limit(gpt4)
+	_, err := first.Provider.Summarize(context.Background(), "text", "brief")
+	require.NoError(t, err)
+	_, err = second.Provider.Summarize(context.Background(), "text", "brief")
+	assert.ErrorIs(t, err, ErrAIRequestLimitExceeded, "wrappers of the same provider/model share a bucket")
+
+	gpt4o := limiter.Limit(NamedProvider{Name: "openai", Model: "GPT-4o", Provider: &fakeProvider{}})
+	for i := 0; i < 5; i++ {
+		_, err = gpt4o.Provider.Summarize(context.Background(), "text", "brief")
+		require.NoError(t, err)
+	}
+}
+
+func TestRateLimiter_FailsOverToNextProvider(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{}, map[string]RateLimit{"openai": {RequestsPerMinute: 1}}, 0)
+	svc := NewAIServiceWithChain([]NamedProvider{
+		limiter.Limit(NamedProvider{Name: "openai", Model: "gpt-4", Provider: &fakeProvider{model: "gpt-4"}}),
+		limiter.Limit(NamedProvider{Name: "anthropic", Model: "claude", Provider: &fakeProvider{model: "claude"}}),
+	})
+
+	first, err := svc.Summarize(context.Background(), "text", "brief")
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4", first.Model)
+
+	second, err := svc.Summarize(context.Background(), "text", "brief")
+	require.NoError(t, err)
+	assert.Equal(t, "claude", second.Model)
+}
+
+// usageProvider reports a fixed token usage for every answer.
+type usageProvider struct {
+	fakeProvider
+	tokens int
+}
+
+func (p *usageProvider) Answer(context.Context, string, string) (*AIAnswer, error) {
+	return &AIAnswer{TokensUsed: p.tokens}, nil
+}
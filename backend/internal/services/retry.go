@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRetryPeekBytes bounds how much of a 429 body is buffered to tell rate limits from billing quota errors.
+const maxRetryPeekBytes = 64 << 10
+
+// RetryPolicy controls how provider HTTP calls are retried on rate limits and transient failures.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the backoff before the second attempt; it doubles with every further attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A server hint longer than this is not waited for, so the
+	// caller can fail over to another provider instead.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used by every built-in provider.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    20 * time.Second,
+}
+
+// RetryStats counts provider HTTP calls and the attempts they took.
+type RetryStats struct {
+	Requests  int64 `json:"requests"`
+	Attempts  int64 `json:"attempts"`
+	Retries   int64 `json:"retries"`
+	Exhausted int64 `json:"exhausted"`
+}
+
+var (
+	retryStatsMu sync.Mutex
+	retryStats   = make(map[string]*RetryStats)
+)
+
+// AIRetryStats returns a snapshot of the retry counters keyed by provider name.
+func AIRetryStats() map[string]RetryStats {
+	retryStatsMu.Lock()
+	defer retryStatsMu.Unlock()
+
+	snapshot := make(map[string]RetryStats, len(retryStats))
+	for provider, stats := range retryStats {
+		snapshot[provider] = *stats
+	}
+	return snapshot
+}
+
+func recordRetryAttempts(provider string, attempts int, exhausted bool) {
+	retryStatsMu.Lock()
+	defer retryStatsMu.Unlock()
+
+	stats, ok := retryStats[provider]
+	if !ok {
+		stats = &RetryStats{}
+		retryStats[provider] = stats
+	}
+	stats.Requests++
+	stats.Attempts += int64(attempts)
+	stats.Retries += int64(attempts - 1)
+	if exhausted {
+		stats.Exhausted++
+	}
+}
+
+// retryTransport retries provider requests that failed with a rate limit, an overloaded or
+// unavailable upstream, or a transport error. It honors Retry-After and the providers'
+// rate-limit reset headers, otherwise backs off exponentially with jitter, and never sleeps
+// past the request context's deadline.
+type retryTransport struct {
+	provider string
+	policy   RetryPolicy
+	base     http.RoundTripper
+	now      func() time.Time
+}
+
+// newRetryingHTTPClient returns an HTTP client whose requests are retried according to policy.
+func newRetryingHTTPClient(provider string, policy RetryPolicy) *http.Client {
+	return &http.Client{Transport: &retryTransport{provider: provider, policy: policy}}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	now := t.now
+	if now == nil {
+		now = time.Now
+	}
+	maxAttempts := max(t.policy.MaxAttempts, 1)
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			if req.GetBody == nil && req.Body != nil && req.Body != http.NoBody {
+				recordRetryAttempts(t.provider, attempt-1, true)
+				return nil, errors.New("retry: request body cannot be replayed")
+			}
+			attemptReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					recordRetryAttempts(t.provider, attempt-1, true)
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := base.RoundTrip(attemptReq)
+		if !t.shouldRetry(ctx, resp, err) {
+			recordRetryAttempts(t.provider, attempt, false)
+			return resp, err
+		}
+
+		delay, ok := t.nextDelay(attempt, resp, now())
+		if ok && attempt < maxAttempts {
+			if deadline, hasDeadline := ctx.Deadline(); hasDeadline && now().Add(delay).After(deadline) {
+				ok = false
+			}
+		}
+		if !ok || attempt >= maxAttempts {
+			recordRetryAttempts(t.provider, attempt, true)
+			return resp, err
+		}
+
+		slog.WarnContext(req.Context(), "AI request failed, retrying", "provider", t.provider, "attempt", attempt,
+			"max_attempts", maxAttempts, "cause", describeRetryCause(resp, err), "retry_in", delay.Round(time.Millisecond).String())
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxRetryPeekBytes))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			recordRetryAttempts(t.provider, attempt, true)
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry reports whether the attempt failed in a way another attempt can fix. A 429 caused
+// by an exhausted billing quota is final, so its body is buffered and inspected.
+func (t *retryTransport) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		body, readErr := io.ReadAll(io.LimitReader(resp.Body, maxRetryPeekBytes))
+		rest := resp.Body
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), rest), rest}
+		if readErr != nil {
+			return false
+		}
+		return !strings.Contains(string(body), "insufficient_quota")
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529:
+		return true
+	default:
+		return false
+	}
+}
+
+// nextDelay returns how long to wait before the next attempt. Server hints win over the
+// computed backoff; ok is false when the hint exceeds the policy's MaxDelay.
+func (t *retryTransport) nextDelay(attempt int, resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp != nil {
+		if hint, found := retryAfterHint(resp.Header, now, resp.StatusCode == http.StatusTooManyRequests); found {
+			if t.policy.MaxDelay > 0 && hint > t.policy.MaxDelay {
+				return 0, false
+			}
+			return hint, true
+		}
+	}
+
+	backoff := t.policy.BaseDelay << (attempt - 1)
+	if backoff <= 0 || (t.policy.MaxDelay > 0 && backoff > t.policy.MaxDelay) {
+		backoff = t.policy.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0, true
+	}
+	// Equal jitter: wait at least half the backoff so concurrent callers still spread out.
+	half := backoff / 2
+	return half + rand.N(backoff-half+1), true
+}
+
+// retryAfterHint reads the wait time a provider asked for. Retry-After-Ms and Retry-After are
+// checked first; for rate-limited responses the OpenAI and Anthropic reset headers follow, taking
+// the longest reset.
+func retryAfterHint(header http.Header, now time.Time, rateLimited bool) (time.Duration, bool) {
+	if raw := strings.TrimSpace(header.Get("Retry-After-Ms")); raw != "" {
+		if ms, err := strconv.ParseFloat(raw, 64); err == nil && ms >= 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+	}
+	if raw := strings.TrimSpace(header.Get("Retry-After")); raw != "" {
+		if seconds, err := strconv.ParseFloat(raw, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+		if at, err := http.ParseTime(raw); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+
+	if !rateLimited {
+		return 0, false
+	}
+
+	var longest time.Duration
+	found := false
+	for _, key := range []string{"X-Ratelimit-Reset-Requests", "X-Ratelimit-Reset-Tokens"} {
+		if d, err := time.ParseDuration(strings.TrimSpace(header.Get(key))); err == nil && d >= 0 {
+			longest, found = max(longest, d), true
+		}
+	}
+	for _, key := range []string{"Anthropic-Ratelimit-Requests-Reset", "Anthropic-Ratelimit-Tokens-Reset"} {
+		if at, err := time.Parse(time.RFC3339, strings.TrimSpace(header.Get(key))); err == nil {
+			longest, found = max(longest, at.Sub(now)), true
+		}
+	}
+	return longest, found
+}
+
+func describeRetryCause(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
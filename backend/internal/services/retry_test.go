@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+// scriptedServer replies with the given statuses in order and 200 afterwards, recording each request body.
+func scriptedServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *atomic.Int32, *[]string) {
+	t.Helper()
+	var calls atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statuses[n-1])
+			_, _ = w.Write([]byte(`{"error":{"message":"slow down"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls, &bodies
+}
+
+func postJSON(t *testing.T, ctx context.Context, client *http.Client, url string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(`{"prompt":"hi"}`))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestRetryTransport_RetriesTransientFailures(t *testing.T) {
+	server, calls, bodies := scriptedServer(t, nil, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+	before := AIRetryStats()["retry-test-transient"]
+
+	resp := postJSON(t, context.Background(), newRetryingHTTPClient("retry-test-transient", fastRetryPolicy), server.URL)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, []string{`{"prompt":"hi"}`, `{"prompt":"hi"}`, `{"prompt":"hi"}`}, *bodies, "body is replayed on every attempt")
+
+	after := AIRetryStats()["retry-test-transient"]
+	assert.Equal(t, before.Requests+1, after.Requests)
+	assert.Equal(t, before.Attempts+3, after.Attempts)
+	assert.Equal(t, before.Retries+2, after.Retries)
+	assert.Equal(t, before.Exhausted, after.Exhausted)
+}
+
+func TestRetryTransport_GivesUpAfterMaxAttempts(t *testing.T) {
+	server, calls, _ := scriptedServer(t, nil, 503, 503, 503, 503)
+
+	resp := postJSON(t, context.Background(), newRetryingHTTPClient("retry-test-exhausted", fastRetryPolicy), server.URL)
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "slow down", "final error body reaches the provider")
+	assert.Equal(t, int64(1), AIRetryStats()["retry-test-exhausted"].Exhausted)
+}
+
+func TestRetryTransport_DoesNotRetryClientErrorsOrQuota(t *testing.T) {
+	server, calls, _ := scriptedServer(t, nil, http.StatusBadRequest)
+	resp := postJSON(t, context.Background(), newRetryingHTTPClient("retry-test", fastRetryPolicy), server.URL)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	quota := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"code":"insufficient_quota"}}`))
+	}))
+	defer quota.Close()
+
+	resp = postJSON(t, context.Background(), newRetryingHTTPClient("retry-test", fastRetryPolicy), quota.URL)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "insufficient_quota", "peeked body is still readable")
+}
+
+func TestRetryTransport_HonorsRetryAfter(t *testing.T) {
+	server, calls, _ := scriptedServer(t, http.Header{"Retry-After-Ms": {"30"}}, http.StatusTooManyRequests)
+
+	start := time.Now()
+	resp := postJSON(t, context.Background(), newRetryingHTTPClient("retry-test", fastRetryPolicy), server.URL)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestRetryTransport_StopsWhenHintExceedsDeadlineOrMaxDelay(t *testing.T) {
+	server, calls, _ := scriptedServer(t, http.Header{"Retry-After": {"1"}}, http.StatusTooManyRequests)
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	resp := postJSON(t, ctx, newRetryingHTTPClient("retry-test", policy), server.URL)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "waiting would outlive the deadline")
+	assert.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	resp = postJSON(t, context.Background(), newRetryingHTTPClient("retry-test", fastRetryPolicy), server.URL)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "hint above MaxDelay is left to failover")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRetryAfterHint(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		header      http.Header
+		rateLimited bool
+		want        time.Duration
+		found       bool
+	}{
+		{"seconds", http.Header{"Retry-After": {"2"}}, false, 2 * time.Second, true},
+		{"milliseconds", http.Header{"Retry-After-Ms": {"250"}}, false, 250 * time.Millisecond, true},
+		{"http date", http.Header{"Retry-After": {now.Add(3 * time.Second).Format(http.TimeFormat)}}, false, 3 * time.Second, true},
+		{"openai reset", http.Header{"X-Ratelimit-Reset-Requests": {"1s"}, "X-Ratelimit-Reset-Tokens": {"6m0s"}}, true, 6 * time.Minute, true},
+		{"anthropic reset", http.Header{"Anthropic-Ratelimit-Requests-Reset": {now.Add(4 * time.Second).Format(time.RFC3339)}}, true, 4 * time.Second, true},
+		{"reset ignored unless rate limited", http.Header{"X-Ratelimit-Reset-Requests": {"1s"}}, false, 0, false},
+		{"none", http.Header{}, true, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := retryAfterHint(tt.header, now, tt.rateLimited)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRetryTransport_BackoffIsJitteredAndCapped(t *testing.T) {
+	transport := &retryTransport{policy: RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}}
+
+	for attempt := 1; attempt <= 8; attempt++ {
+		delay, ok := transport.nextDelay(attempt, nil, time.Now())
+		require.True(t, ok)
+		backoff := min(100*time.Millisecond<<(attempt-1), time.Second)
+		assert.GreaterOrEqual(t, delay, backoff/2)
+		assert.LessOrEqual(t, delay, backoff)
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+)
+
+// speakerBatchSize bounds how many transcript lines are labeled per provider call. Labels found in
+// earlier batches are passed along so the same person keeps the same label across batches.
+const speakerBatchSize = 200
+
+// SpeakerLabeler detects speaker turns in a batch of transcript lines.
+type SpeakerLabeler interface {
+	LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error)
+}
+
+// AssignSpeakers labels every line with a speaker. The returned labels correspond one-to-one with
+// lines, and the returned AISpeakerTurns holds the turns with line numbers relative to the whole
+// transcript together with the aggregated model and token usage.
+func AssignSpeakers(ctx context.Context, labeler SpeakerLabeler, lines []string) ([]string, *AISpeakerTurns, error) {
+	if labeler == nil {
+		return nil, nil, ErrAIProviderNotConfigured
+	}
+
+	labels := make([]string, 0, len(lines))
+	usage := &AISpeakerTurns{}
+	var known []string
+	seen := make(map[string]bool)
+
+	for start := 0; start < len(lines); start += speakerBatchSize {
+		end := min(start+speakerBatchSize, len(lines))
+
+		result, err := labeler.LabelSpeakers(ctx, lines[start:end], known)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		batchLabels, err := expandSpeakerTurns(result.Turns, end-start, lastLabel(labels))
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse speaker response: %w", err)
+		}
+		labels = append(labels, batchLabels...)
+
+		for _, turn := range result.Turns {
+			usage.Turns = append(usage.Turns, SpeakerTurn{Line: start + turn.Line, Speaker: turn.Speaker})
+			if !seen[turn.Speaker] {
+				seen[turn.Speaker] = true
+				known = append(known, turn.Speaker)
+			}
+		}
+		usage.Model = result.Model
+		usage.PromptVersion = result.PromptVersion
+		usage.TokensUsed += result.TokensUsed
+	}
+
+	return labels, usage, nil
+}
+
+// expandSpeakerTurns converts turns into one label per line. Lines before the first turn keep the
+// speaker carried over from the previous batch, or the first turn's speaker when there is none.
+func expandSpeakerTurns(turns []SpeakerTurn, lineCount int, carried string) ([]string, error) {
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("no speaker turns returned")
+	}
+
+	current := carried
+	if current == "" {
+		current = turns[0].Speaker
+	}
+
+	labels := make([]string, lineCount)
+	next := 0
+	for i := range labels {
+		for next < len(turns) && turns[next].Line == i+1 {
+			current = turns[next].Speaker
+			next++
+		}
+		labels[i] = current
+	}
+
+	if next != len(turns) {
+		return nil, fmt.Errorf("turn %d has invalid line %d", next+1, turns[next].Line)
+	}
+	return labels, nil
+}
+
+func lastLabel(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return labels[len(labels)-1]
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scriptedSpeakerLabeler struct {
+	responses []*AISpeakerTurns
+	known     [][]string
+	batches   []int
+}
+
+func (l *scriptedSpeakerLabeler) LabelSpeakers(_ context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error) {
+	l.known = append(l.known, append([]string(nil), knownSpeakers...))
+	l.batches = append(l.batches, len(lines))
+	response := l.responses[0]
+	l.responses = l.responses[1:]
+	return response, nil
+}
+
+func TestAssignSpeakers_ExpandsTurnsToLines(t *testing.T) {
+	labeler := &scriptedSpeakerLabeler{responses: []*AISpeakerTurns{{
+		Turns:      []SpeakerTurn{{Line: 1, Speaker: "Host"}, {Line: 3, Speaker: "Guest"}, {Line: 4, Speaker: "Host"}},
+		Model:      "stub",
+		TokensUsed: 12,
+	}}}
+
+	labels, usage, err := AssignSpeakers(context.Background(), labeler, []string{"a", "b", "c", "d"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Host", "Host", "Guest", "Host"}, labels)
+	assert.Equal(t, "stub", usage.Model)
+	assert.Equal(t, 12, usage.TokensUsed)
+}
+
+func TestAssignSpeakers_CarriesSpeakersAcrossBatches(t *testing.T) {
+	lines := make([]string, speakerBatchSize+2)
+	for i := range lines {
+		lines[i] = "line"
+	}
+
+	labeler := &scriptedSpeakerLabeler{responses: []*AISpeakerTurns{
+		{Turns: []SpeakerTurn{{Line: 1, Speaker: "Host"}, {Line: speakerBatchSize, Speaker: "Guest"}}, TokensUsed: 5},
+		{Turns: []SpeakerTurn{{Line: 2, Speaker: "Host"}}, TokensUsed: 3},
+	}}
+
+	labels, usage, err := AssignSpeakers(context.Background(), labeler, lines)
+	require.NoError(t, err)
+
+	require.Len(t, labels, len(lines))
+	assert.Equal(t, "Guest", labels[speakerBatchSize-1])
+	assert.Equal(t, "Guest", labels[speakerBatchSize], "first line of the second batch keeps the previous speaker")
+	assert.Equal(t, "Host", labels[speakerBatchSize+1])
+
+	assert.Equal(t, []int{speakerBatchSize, 2}, labeler.batches)
+	assert.Equal(t, []string{"Host", "Guest"}, labeler.known[1])
+	assert.Equal(t, 8, usage.TokensUsed)
+	assert.Equal(t, speakerBatchSize+2, usage.Turns[2].Line)
+}
+
+func TestAssignSpeakers_RejectsUnorderedTurns(t *testing.T) {
+	labeler := &scriptedSpeakerLabeler{responses: []*AISpeakerTurns{{
+		Turns: []SpeakerTurn{{Line: 2, Speaker: "Host"}, {Line: 1, Speaker: "Guest"}},
+	}}}
+
+	_, _, err := AssignSpeakers(context.Background(), labeler, []string{"a", "b"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parse speaker response")
+}
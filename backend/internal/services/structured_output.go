@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+// outputSchema is the JSON schema a structured call's reply must follow. It is generated from
+// the Go payload type the reply is decoded into, so prompts, schemas and decoders cannot drift.
+type outputSchema struct {
+	name       string
+	definition jsonschema.Definition
+}
+
+// newOutputSchema generates the schema for payload. Fields are described by their
+// `description` tags and restricted to a set of values by comma-separated `enum` tags.
+func newOutputSchema(name string, payload any) *outputSchema {
+	definition, err := jsonschema.GenerateSchemaForType(payload)
+	if err != nil {
+		panic(fmt.Sprintf("generate %s schema: %v", name, err))
+	}
+	return &outputSchema{name: name, definition: *definition}
+}
+
+// schemaDialect selects how a schema is rendered for a provider.
+type schemaDialect int
+
+const (
+	// schemaDialectJSON is JSON Schema with closed objects, as OpenAI's strict mode requires.
+	schemaDialectJSON schemaDialect = iota
+	// schemaDialectGemini is Gemini's OpenAPI subset: upper-case types, no additionalProperties.
+	schemaDialectGemini
+)
+
+// render returns the schema as a JSON object in the given dialect, with $refs inlined.
+func (s *outputSchema) render(dialect schemaDialect) schemaObject {
+	return renderSchema(s.definition, s.definition.Defs, dialect)
+}
+
+func renderSchema(definition jsonschema.Definition, defs map[string]jsonschema.Definition, dialect schemaDialect) schemaObject {
+	if definition.Ref != "" {
+		return renderSchema(defs[strings.TrimPrefix(definition.Ref, "#/$defs/")], defs, dialect)
+	}
+
+	schemaType := string(definition.Type)
+	if dialect == schemaDialectGemini {
+		schemaType = strings.ToUpper(schemaType)
+	}
+	rendered := schemaObject{"type": schemaType}
+	if definition.Description != "" {
+		rendered["description"] = definition.Description
+	}
+	if len(definition.Enum) > 0 {
+		rendered["enum"] = definition.Enum
+	}
+
+	switch definition.Type {
+	case jsonschema.Object:
+		properties := make(schemaObject, len(definition.Properties))
+		for name, property := range definition.Properties {
+			properties[name] = renderSchema(property, defs, dialect)
+		}
+		rendered["properties"] = properties
+		rendered["required"] = append([]string{}, definition.Required...)
+		if dialect == schemaDialectJSON {
+			rendered["additionalProperties"] = false
+		}
+	case jsonschema.Array:
+		if definition.Items != nil {
+			rendered["items"] = renderSchema(*definition.Items, defs, dialect)
+		}
+	}
+	return rendered
+}
+
+// withSchemaInstructions appends schema to systemPrompt for providers that cannot enforce it.
+func withSchemaInstructions(systemPrompt string, schema *outputSchema) string {
+	rendered, err := json.Marshal(schema.render(schemaDialectJSON))
+	if err != nil {
+		return systemPrompt
+	}
+	return systemPrompt + "\n\nReply with only a JSON object that follows this JSON schema:\n" + string(rendered)
+}
+
+// schemaObject is a rendered schema. It implements json.Marshaler for go-openai's request types.
+type schemaObject map[string]any
+
+func (s schemaObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any(s))
+}
+
+// Payload types only used to generate extraction schemas; replies are decoded into
+// ExtractionItem, which shares their JSON field names.
+type codeExtractionPayload struct {
+	Items []struct {
+		Language      string `json:"language" description:"Programming language, e.g. python, javascript, bash or sql"`
+		Code          string `json:"code"`
+		Context       string `json:"context" description:"What the code does"`
+		TimestampHint string `json:"timestamp_hint" description:"When the speaker mentions it, or an empty string"`
+	} `json:"items"`
+}
+
+type quoteExtractionPayload struct {
+	Items []struct {
+		Quote      string `json:"quote"`
+		Speaker    string `json:"speaker" description:"Speaker label or name, or an empty string when unknown"`
+		Context    string `json:"context" description:"Why the quote is significant"`
+		Importance string `json:"importance" enum:"high,medium,low"`
+	} `json:"items"`
+}
+
+type actionItemExtractionPayload struct {
+	Items []struct {
+		Action   string `json:"action"`
+		Category string `json:"category" enum:"task,recommendation,step"`
+		Priority string `json:"priority" enum:"high,medium,low"`
+		Context  string `json:"context" description:"Why the action matters"`
+	} `json:"items"`
+}
+
+var (
+	summarySchema     = newOutputSchema("summary", summaryPayload{})
+	answerSchema      = newOutputSchema("answer", answerPayload{})
+	punctuationSchema = newOutputSchema("punctuation", punctuationPayload{})
+	speakerSchema     = newOutputSchema("speaker", speakerPayload{})
+	translationSchema = newOutputSchema("translation", translationPayload{})
+	gradeSchema       = newOutputSchema("grade", gradePayload{})
+
+	extractionSchemas = map[string]*outputSchema{
+		"code":         newOutputSchema("extraction", codeExtractionPayload{}),
+		"quotes":       newOutputSchema("extraction", quoteExtractionPayload{}),
+		"action_items": newOutputSchema("extraction", actionItemExtractionPayload{}),
+	}
+)
+
+// structuredOutput pairs a schema with the decoder that validates replies against it.
+type structuredOutput[T any] struct {
+	schema *outputSchema
+	decode func(raw string) (T, error)
+}
+
+var (
+	summaryOutput = func(ctx context.Context) structuredOutput[*summaryPayload] {
+		return structuredOutput[*summaryPayload]{schema: summarySchema, decode: func(raw string) (*summaryPayload, error) {
+			return decodeSummaryPayload(ctx, raw)
+		}}
+	}
+	answerOutput     = structuredOutput[*answerPayload]{schema: answerSchema, decode: decodeAnswerPayload}
+	extractionOutput = func(extractionType string) structuredOutput[[]ExtractionItem] {
+		return structuredOutput[[]ExtractionItem]{schema: extractionSchemas[extractionType], decode: decodeExtractionPayload}
+	}
+	punctuationOutput = func(lineCount int) structuredOutput[[]string] {
+		return structuredOutput[[]string]{schema: punctuationSchema, decode: func(raw string) ([]string, error) {
+			return decodePunctuationPayload(raw, lineCount)
+		}}
+	}
+	translationOutput = structuredOutput[string]{schema: translationSchema, decode: decodeTranslationPayload}
+	gradeOutput       = structuredOutput[*gradePayload]{schema: gradeSchema, decode: decodeGradePayload}
+	speakerOutput     = func(lineCount int) structuredOutput[[]SpeakerTurn] {
+		return structuredOutput[[]SpeakerTurn]{schema: speakerSchema, decode: func(raw string) ([]SpeakerTurn, error) {
+			return decodeSpeakerPayload(raw, lineCount)
+		}}
+	}
+)
+
+// completeFunc makes one completion call. Providers pass schema to their native structured
+// output mechanism.
+type completeFunc func(ctx context.Context, systemPrompt, userPrompt string, schema *outputSchema) (string, TokenUsage, error)
+
+// maxRepairEcho bounds how much of a rejected reply is sent back in a repair request.
+const maxRepairEcho = 8000
+
+// completeStructured requests output following output's schema and decodes the reply. Native
+// structured outputs make invalid replies rare, but a reply can still be truncated or fail the
+// decoder's checks (such as the number of lines), so it is sent back once with the error for the
+// model to repair. translate maps provider errors to the ErrAI* errors and may be nil when
+// complete already returns them. The usage of both calls is returned.
+func completeStructured[T any](ctx context.Context, complete completeFunc, translate func(error) error, systemPrompt, userPrompt string, output structuredOutput[T]) (T, TokenUsage, error) {
+	var zero T
+	if translate == nil {
+		translate = func(err error) error { return err }
+	}
+
+	raw, usage, err := complete(ctx, systemPrompt, userPrompt, output.schema)
+	if err != nil {
+		return zero, TokenUsage{}, translate(err)
+	}
+	decoded, decodeErr := output.decode(raw)
+	if decodeErr == nil {
+		return decoded, usage, nil
+	}
+
+	slog.WarnContext(ctx, "AI response rejected, asking for a repair", "schema", output.schema.name, "error", decodeErr)
+	repairPrompt := fmt.Sprintf("%s\n\nYour previous reply could not be used (%v):\n%s\n\nReply again with only a corrected JSON object that follows the required schema exactly.",
+		userPrompt, decodeErr, truncateString(raw, maxRepairEcho))
+	repaired, repairUsage, err := complete(ctx, systemPrompt, repairPrompt, output.schema)
+	usage = usage.add(repairUsage)
+	if err != nil {
+		return zero, usage, translate(err)
+	}
+	decoded, decodeErr = output.decode(repaired)
+	if decodeErr != nil {
+		return zero, usage, fmt.Errorf("parse %s response: %w", output.schema.name, decodeErr)
+	}
+	return decoded, usage, nil
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputSchema_Render(t *testing.T) {
+	rendered, err := json.Marshal(summarySchema.render(schemaDialectJSON))
+	require.NoError(t, err)
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(rendered, &schema))
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, false, schema["additionalProperties"])
+	assert.ElementsMatch(t, []any{"text", "key_points", "sections", "chapters"}, schema["required"])
+	assert.NotContains(t, string(rendered), "$ref", "named payload types are inlined")
+
+	chapter := schema["properties"].(map[string]any)["chapters"].(map[string]any)["items"].(map[string]any)
+	assert.Equal(t, false, chapter["additionalProperties"])
+	assert.Equal(t, "integer", chapter["properties"].(map[string]any)["start_ms"].(map[string]any)["type"])
+
+	gemini := answerSchema.render(schemaDialectGemini)
+	assert.Equal(t, "OBJECT", gemini["type"])
+	assert.NotContains(t, gemini, "additionalProperties")
+	confidence := gemini["properties"].(schemaObject)["confidence"].(schemaObject)
+	assert.Equal(t, "STRING", confidence["type"])
+	assert.Equal(t, []string{"high", "medium", "low"}, confidence["enum"])
+
+	for _, extractionType := range extractionTypes {
+		assert.Contains(t, extractionSchemas, extractionType)
+	}
+}
+
+func TestCompleteStructured_RepairsInvalidReply(t *testing.T) {
+	var prompts []string
+	replies := []string{`{"answer": "Go", "confidence": `, `{"answer":"Go","confidence":"high","sources":[],"not_found":false}`}
+	complete := func(ctx context.Context, systemPrompt, userPrompt string, schema *outputSchema) (string, TokenUsage, error) {
+		assert.Same(t, answerSchema, schema)
+		prompts = append(prompts, userPrompt)
+		reply := replies[len(prompts)-1]
+		return reply, TokenUsage{PromptTokens: 100, CompletionTokens: 10}, nil
+	}
+
+	answer, usage, err := completeStructured(context.Background(), complete, nil, "system", "Question: which language?", answerOutput)
+	require.NoError(t, err)
+	assert.Equal(t, "Go", answer.Answer)
+	assert.Equal(t, TokenUsage{PromptTokens: 200, CompletionTokens: 20}, usage)
+
+	require.Len(t, prompts, 2)
+	assert.True(t, strings.HasPrefix(prompts[1], "Question: which language?"), "the repair request repeats the original prompt")
+	assert.Contains(t, prompts[1], "unexpected end of JSON input")
+	assert.Contains(t, prompts[1], `{"answer": "Go", "confidence": `)
+}
+
+func TestCompleteStructured_GivesUpAfterOneRepair(t *testing.T) {
+	calls := 0
+	complete := func(ctx context.Context, systemPrompt, userPrompt string, schema *outputSchema) (string, TokenUsage, error) {
+		calls++
+		return `{"lines": ["Only one."]}`, TokenUsage{}, nil
+	}
+
+	_, _, err := completeStructured(context.Background(), complete, nil, "system", "user", punctuationOutput(2))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parse punctuation response: expected 2 lines, got 1")
+	assert.Equal(t, 2, calls)
+}
+
+func TestCompleteStructured_TranslatesProviderErrors(t *testing.T) {
+	complete := func(ctx context.Context, systemPrompt, userPrompt string, schema *outputSchema) (string, TokenUsage, error) {
+		return "", TokenUsage{}, errors.New("anthropic API error (status 529): overloaded")
+	}
+
+	_, _, err := completeStructured(context.Background(), complete, translateAnthropicError, "system", "user", summaryOutput(context.Background()))
+	assert.ErrorIs(t, err, ErrAIServiceUnavailable)
+}
+
+// roundTripFunc stubs a provider's HTTP API.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// capturingClient answers every request with body and stores the decoded request in received.
+func capturingClient(t *testing.T, body string, received *map[string]any) *http.Client {
+	t.Helper()
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		require.NoError(t, json.NewDecoder(req.Body).Decode(received))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	})}
+}
+
+func TestAnthropicProvider_UsesToolSchema(t *testing.T) {
+	var received map[string]any
+	provider := &AnthropicProvider{
+		apiKey:    "key",
+		model:     "claude-3-5-sonnet-20241022",
+		maxTokens: 1000,
+		httpClient: capturingClient(t, `{
+			"content": [{"type": "tool_use", "name": "answer", "input": {"answer": "Go", "confidence": "high", "sources": ["we use Go"], "not_found": false}}],
+			"usage": {"input_tokens": 50, "output_tokens": 20}
+		}`, &received),
+	}
+
+	answer, err := provider.Answer(context.Background(), "we use Go", "Which language?")
+	require.NoError(t, err)
+	assert.Equal(t, "Go", answer.Answer)
+	assert.Equal(t, []string{"we use Go"}, answer.Sources)
+	assert.Equal(t, 70, answer.TokensUsed)
+
+	assert.Equal(t, map[string]any{"type": "tool", "name": "answer"}, received["tool_choice"])
+	tools := received["tools"].([]any)
+	require.Len(t, tools, 1)
+	inputSchema := tools[0].(map[string]any)["input_schema"].(map[string]any)
+	assert.Equal(t, "object", inputSchema["type"])
+	assert.Contains(t, inputSchema["properties"], "not_found")
+}
+
+func TestGeminiProvider_UsesResponseSchema(t *testing.T) {
+	var received map[string]any
+	provider := &GeminiProvider{
+		apiKey:    "key",
+		model:     "gemini-1.5-flash",
+		maxTokens: 1000,
+		httpClient: capturingClient(t, `{
+			"candidates": [{"content": {"parts": [{"text": "{\"turns\": [{\"line\": 1, \"speaker\": \"Host\"}]}"}]}}],
+			"usageMetadata": {"promptTokenCount": 40, "candidatesTokenCount": 10, "totalTokenCount": 50}
+		}`, &received),
+	}
+
+	turns, err := provider.LabelSpeakers(context.Background(), []string{"hello", "welcome"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []SpeakerTurn{{Line: 1, Speaker: "Host"}}, turns.Turns)
+
+	config := received["generationConfig"].(map[string]any)
+	assert.Equal(t, "application/json", config["responseMimeType"])
+	schema := config["responseSchema"].(map[string]any)
+	assert.Equal(t, "OBJECT", schema["type"])
+	assert.NotContains(t, schema, "additionalProperties")
+}
+
+func TestGeminiProvider_SendsKeyInHeader(t *testing.T) {
+	var sent *http.Request
+	provider := &GeminiProvider{
+		apiKey:    "AIzaSecret",
+		model:     "gemini-1.5-flash",
+		maxTokens: 1000,
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body: io.NopCloser(strings.NewReader(`{
+					"candidates": [{"content": {"parts": [{"text": "{\"turns\": [{\"line\": 1, \"speaker\": \"Host\"}]}"}]}}]
+				}`)),
+			}, nil
+		})},
+	}
+
+	_, err := provider.LabelSpeakers(context.Background(), []string{"hello"}, nil)
+	require.NoError(t, err)
+	require.NotNil(t, sent)
+	assert.Equal(t, "AIzaSecret", sent.Header.Get("x-goog-api-key"))
+	assert.Empty(t, sent.URL.RawQuery, "the key must not appear in the URL")
+}
+
+func TestLocalProvider_DescribesSchemaInPrompt(t *testing.T) {
+	server, received, _ := localChatServer(t, `{"items": []}`)
+	provider, err := NewLocalProvider(server.URL+"/v1", "", "llama3.1", 1000, 0.2)
+	require.NoError(t, err)
+
+	_, err = provider.Extract(context.Background(), "no code here", "code")
+	require.NoError(t, err)
+
+	assert.Nil(t, received.ResponseFormat, "OpenAI-compatible servers differ in the response formats they accept")
+	require.NotEmpty(t, received.Messages)
+	assert.Contains(t, received.Messages[0].Content, "follows this JSON schema")
+	assert.Contains(t, received.Messages[0].Content, `"timestamp_hint"`)
+}
+
+func TestOpenAIResponseFormatFor(t *testing.T) {
+	for model, want := range map[string]openAIResponseFormat{
+		"gpt-4":                          openAIFormatPrompt,
+		"gpt-4-0613":                     openAIFormatPrompt,
+		"gpt-3.5-turbo-16k":              openAIFormatPrompt,
+		"o1-mini":                        openAIFormatPrompt,
+		"gpt-3.5-turbo":                  openAIFormatJSONObject,
+		"gpt-4-turbo":                    openAIFormatJSONObject,
+		"gpt-4o-2024-05-13":              openAIFormatJSONObject,
+		"gpt-4o":                         openAIFormatJSONSchema,
+		"gpt-4o-mini":                    openAIFormatJSONSchema,
+		"GPT-4.1":                        openAIFormatJSONSchema,
+		"o3-mini":                        openAIFormatJSONSchema,
+		"ft:gpt-4o-mini-2024-07-18:acme": openAIFormatJSONSchema,
+	} {
+		assert.Equal(t, want, openAIResponseFormatFor(model), model)
+	}
+}
+
+// scriptedChatClient answers with replies in order and keeps the requests.
+type scriptedChatClient struct {
+	replies  []string
+	requests []openai.ChatCompletionRequest
+}
+
+func (c *scriptedChatClient) CreateChatCompletion(_ context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	c.requests = append(c.requests, request)
+	reply := c.replies[min(len(c.requests), len(c.replies))-1]
+	return openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: reply}}},
+	}, nil
+}
+
+func (c *scriptedChatClient) ListModels(context.Context) (openai.ModelsList, error) {
+	return openai.ModelsList{}, nil
+}
+
+func TestOpenAIProvider_FallsBackForModelsWithoutStructuredOutputs(t *testing.T) {
+	valid := `{"answer":"Go","confidence":"high","sources":[],"not_found":false}`
+
+	t.Run("gpt-4 relies on the prompt and the repair retry", func(t *testing.T) {
+		client := &scriptedChatClient{replies: []string{"Sure! The answer is Go.", valid}}
+		provider := &OpenAIProvider{client: client, model: "gpt-4", maxTokens: 1000, format: openAIResponseFormatFor("gpt-4")}
+
+		answer, err := provider.Answer(context.Background(), "We write it in Go.", "Which language?")
+		require.NoError(t, err)
+		assert.Equal(t, "Go", answer.Answer)
+
+		require.Len(t, client.requests, 2)
+		for _, request := range client.requests {
+			assert.Nil(t, request.ResponseFormat)
+			assert.Contains(t, request.Messages[0].Content, "follows this JSON schema")
+		}
+		assert.Contains(t, client.requests[1].Messages[1].Content, "could not be used")
+	})
+
+	t.Run("gpt-3.5-turbo uses JSON mode", func(t *testing.T) {
+		client := &scriptedChatClient{replies: []string{valid}}
+		provider := &OpenAIProvider{client: client, model: "gpt-3.5-turbo", maxTokens: 1000, format: openAIResponseFormatFor("gpt-3.5-turbo")}
+
+		_, err := provider.Answer(context.Background(), "We write it in Go.", "Which language?")
+		require.NoError(t, err)
+		require.Len(t, client.requests, 1)
+		require.NotNil(t, client.requests[0].ResponseFormat)
+		assert.Equal(t, openai.ChatCompletionResponseFormatTypeJSONObject, client.requests[0].ResponseFormat.Type)
+		assert.Nil(t, client.requests[0].ResponseFormat.JSONSchema)
+	})
+
+	t.Run("gpt-4o gets a strict schema", func(t *testing.T) {
+		client := &scriptedChatClient{replies: []string{valid}}
+		provider := &OpenAIProvider{client: client, model: "gpt-4o", maxTokens: 1000, format: openAIResponseFormatFor("gpt-4o")}
+
+		_, err := provider.Answer(context.Background(), "We write it in Go.", "Which language?")
+		require.NoError(t, err)
+		require.NotNil(t, client.requests[0].ResponseFormat)
+		require.NotNil(t, client.requests[0].ResponseFormat.JSONSchema)
+		assert.Equal(t, "answer", client.requests[0].ResponseFormat.JSONSchema.Name)
+		assert.True(t, client.requests[0].ResponseFormat.JSONSchema.Strict)
+		assert.NotContains(t, client.requests[0].Messages[0].Content, "follows this JSON schema")
+	})
+}
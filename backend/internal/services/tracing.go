@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/tracing"
+)
+
+var tracer = otel.Tracer("github.com/yourusername/yt-transcript-downloader/internal/services")
+
+// startCompletionSpan starts the span around one provider completion request. Retries of the
+// request happen inside it.
+func startCompletionSpan(ctx context.Context, provider, model string, schema *outputSchema) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{semconv.GenAIProviderNameKey.String(provider), semconv.GenAIRequestModel(model)}
+	if schema != nil {
+		attrs = append(attrs, attribute.String("gen_ai.output.schema", schema.name))
+	}
+	return tracer.Start(ctx, "chat "+model, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endCompletionSpan records the token usage of a completion and ends its span.
+func endCompletionSpan(span trace.Span, usage TokenUsage, err error) {
+	if err == nil {
+		span.SetAttributes(semconv.GenAIUsageInputTokens(usage.PromptTokens), semconv.GenAIUsageOutputTokens(usage.CompletionTokens))
+	}
+	tracing.EndSpan(span, err)
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/tracing/tracingtest"
+)
+
+func TestCompletionSpan_RecordsModelAndUsage(t *testing.T) {
+	tracingtest.Install()
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+
+	provider := &OpenAIProvider{client: &mockChatCompletionClient{
+		response: openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: `{"text":"Summary.","key_points":[],"sections":[]}`}}},
+			Usage:   openai.Usage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120},
+		},
+	}, model: "gpt-4"}
+	_, err := provider.Summarize(ctx, "sample transcript", "brief")
+	require.NoError(t, err)
+	parent.End()
+
+	spans := tracingtest.Spans(parent.SpanContext().TraceID())
+	require.Len(t, spans, 2)
+	completion := spans[0]
+	assert.Equal(t, "chat gpt-4", completion.Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), completion.Parent.SpanID())
+	assert.Contains(t, completion.Attributes, semconv.GenAIRequestModel("gpt-4"))
+	assert.Contains(t, completion.Attributes, semconv.GenAIUsageInputTokens(100))
+	assert.Contains(t, completion.Attributes, semconv.GenAIUsageOutputTokens(20))
+}
+
+func TestYouTubeSpan_RecordsErrors(t *testing.T) {
+	tracingtest.Install()
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+
+	service := NewYouTubeService()
+	service.minInterval = 0
+	_, err := service.GetTranscript(ctx, "!invalid!", "en")
+	require.Error(t, err)
+	parent.End()
+
+	spans := tracingtest.Spans(parent.SpanContext().TraceID())
+	require.Len(t, spans, 2)
+	assert.Equal(t, "youtube.GetTranscript", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// sentencePauseThreshold ends a sentence when the speaker pauses for longer than this,
+	// which is the only boundary signal available in unpunctuated auto captions.
+	sentencePauseThreshold = 1200 * time.Millisecond
+	maxSentenceWords       = 40
+
+	paragraphPauseThreshold = 2500 * time.Millisecond
+	maxParagraphSentences   = 6
+	maxParagraphLength      = 90 * time.Second
+
+	punctuationBatchSize = 80
+)
+
+// nonSpeechTagPattern matches caption annotations such as "[Music]" or "(applause)".
+var nonSpeechTagPattern = regexp.MustCompile(`[\[(][^\])]{1,30}[\])]`)
+
+// CleanSentence is a reconstructed sentence with the time range it was spoken in.
+type CleanSentence struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// CleanParagraph groups consecutive sentences into a readable block.
+type CleanParagraph struct {
+	Start     time.Duration
+	End       time.Duration
+	Sentences []CleanSentence
+}
+
+// Punctuator restores punctuation and capitalization for a batch of caption lines.
+type Punctuator interface {
+	Punctuate(ctx context.Context, lines []string) (*AIPunctuation, error)
+}
+
+type timedWord struct {
+	start time.Duration
+	end   time.Duration
+	text  string
+}
+
+// ReconstructSentences merges caption fragments into sentences. Fragments are split into words
+// whose timing is interpolated across the fragment, so sentences that start or end mid-fragment
+// keep accurate start and end times. Sentences end on terminal punctuation, on long pauses, or
+// when they grow beyond maxSentenceWords.
+func ReconstructSentences(lines []TranscriptLine) []CleanSentence {
+	var sentences []CleanSentence
+	var current []timedWord
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		texts := make([]string, 0, len(current))
+		for _, word := range current {
+			texts = append(texts, word.text)
+		}
+		sentences = append(sentences, CleanSentence{
+			Start: current[0].start,
+			End:   current[len(current)-1].end,
+			Text:  strings.Join(texts, " "),
+		})
+		current = nil
+	}
+
+	for _, line := range lines {
+		words := strings.Fields(nonSpeechTagPattern.ReplaceAllString(line.Text, " "))
+		if len(words) == 0 {
+			continue
+		}
+
+		if len(current) > 0 && line.Start-current[len(current)-1].end > sentencePauseThreshold {
+			flush()
+		}
+
+		count := time.Duration(len(words))
+		for i, text := range words {
+			current = append(current, timedWord{
+				start: line.Start + line.Duration*time.Duration(i)/count,
+				end:   line.Start + line.Duration*time.Duration(i+1)/count,
+				text:  text,
+			})
+			if endsSentence(text) || len(current) >= maxSentenceWords {
+				flush()
+			}
+		}
+	}
+	flush()
+
+	return sentences
+}
+
+// GroupParagraphs splits sentences into paragraphs on long pauses or once a paragraph grows
+// past maxParagraphSentences or maxParagraphLength.
+func GroupParagraphs(sentences []CleanSentence) []CleanParagraph {
+	var paragraphs []CleanParagraph
+	var current *CleanParagraph
+
+	for _, sentence := range sentences {
+		if current != nil {
+			pause := sentence.Start - current.End
+			if pause > paragraphPauseThreshold ||
+				len(current.Sentences) >= maxParagraphSentences ||
+				sentence.End-current.Start > maxParagraphLength {
+				paragraphs = append(paragraphs, *current)
+				current = nil
+			}
+		}
+
+		if current == nil {
+			current = &CleanParagraph{Start: sentence.Start}
+		}
+		current.Sentences = append(current.Sentences, sentence)
+		current.End = sentence.End
+	}
+
+	if current != nil {
+		paragraphs = append(paragraphs, *current)
+	}
+	return paragraphs
+}
+
+// NeedsPunctuation reports whether the sentences look like unpunctuated auto captions,
+// i.e. most of them were split on pauses or length rather than on punctuation.
+func NeedsPunctuation(sentences []CleanSentence) bool {
+	if len(sentences) == 0 {
+		return false
+	}
+
+	punctuated := 0
+	for _, sentence := range sentences {
+		if endsSentence(sentence.Text) {
+			punctuated++
+		}
+	}
+	return float64(punctuated)/float64(len(sentences)) < 0.5
+}
+
+// RestorePunctuation sends sentence texts to the punctuator in batches and re-segments the
+// result, so sentence boundaries follow the restored punctuation while timings are preserved.
+// The returned AIPunctuation aggregates model and token usage across batches.
+func RestorePunctuation(ctx context.Context, p Punctuator, sentences []CleanSentence) ([]CleanSentence, *AIPunctuation, error) {
+	if p == nil {
+		return nil, nil, ErrAIProviderNotConfigured
+	}
+
+	usage := &AIPunctuation{Lines: make([]string, 0, len(sentences))}
+	for start := 0; start < len(sentences); start += punctuationBatchSize {
+		end := min(start+punctuationBatchSize, len(sentences))
+
+		batch := make([]string, 0, end-start)
+		for _, sentence := range sentences[start:end] {
+			batch = append(batch, sentence.Text)
+		}
+
+		result, err := p.Punctuate(ctx, batch)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(result.Lines) != len(batch) {
+			return nil, nil, fmt.Errorf("parse punctuation response: expected %d lines, got %d", len(batch), len(result.Lines))
+		}
+
+		usage.Lines = append(usage.Lines, result.Lines...)
+		usage.Model = result.Model
+		usage.PromptVersion = result.PromptVersion
+		usage.TokensUsed += result.TokensUsed
+	}
+
+	lines := make([]TranscriptLine, 0, len(sentences))
+	for i, sentence := range sentences {
+		lines = append(lines, TranscriptLine{
+			Start:    sentence.Start,
+			Duration: sentence.End - sentence.Start,
+			Text:     usage.Lines[i],
+		})
+	}
+
+	return ReconstructSentences(lines), usage, nil
+}
+
+func endsSentence(word string) bool {
+	trimmed := strings.TrimRight(word, `"')]”’`)
+	if trimmed == "" {
+		return false
+	}
+	switch trimmed[len(trimmed)-1] {
+	case '.', '!', '?':
+		return true
+	}
+	return strings.HasSuffix(trimmed, "…")
+}
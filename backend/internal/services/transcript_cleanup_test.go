@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubPunctuator struct {
+	batches [][]string
+}
+
+func (p *stubPunctuator) Punctuate(_ context.Context, lines []string) (*AIPunctuation, error) {
+	p.batches = append(p.batches, lines)
+	restored := make([]string, 0, len(lines))
+	for _, line := range lines {
+		restored = append(restored, strings.ToUpper(line[:1])+line[1:]+".")
+	}
+	return &AIPunctuation{Lines: restored, Model: "stub", TokensUsed: 10}, nil
+}
+
+func TestReconstructSentences_SplitsMidFragmentWithInterpolatedTiming(t *testing.T) {
+	lines := []TranscriptLine{
+		{Start: 0, Duration: 2 * time.Second, Text: "Hello there. How"},
+		{Start: 2 * time.Second, Duration: 2 * time.Second, Text: "are you today?"},
+	}
+
+	sentences := ReconstructSentences(lines)
+	require.Len(t, sentences, 2)
+
+	assert.Equal(t, "Hello there.", sentences[0].Text)
+	assert.Equal(t, time.Duration(0), sentences[0].Start)
+	assert.InDelta(t, float64(1333*time.Millisecond), float64(sentences[0].End), float64(time.Millisecond))
+
+	assert.Equal(t, "How are you today?", sentences[1].Text)
+	assert.InDelta(t, float64(1333*time.Millisecond), float64(sentences[1].Start), float64(time.Millisecond))
+	assert.Equal(t, 4*time.Second, sentences[1].End)
+}
+
+func TestReconstructSentences_SplitsOnPausesAndDropsNonSpeechTags(t *testing.T) {
+	lines := []TranscriptLine{
+		{Start: 0, Duration: time.Second, Text: "[Music] so we start"},
+		{Start: time.Second, Duration: time.Second, Text: "with the basics"},
+		{Start: 5 * time.Second, Duration: time.Second, Text: "then move on"},
+	}
+
+	sentences := ReconstructSentences(lines)
+	require.Len(t, sentences, 2)
+	assert.Equal(t, "so we start with the basics", sentences[0].Text)
+	assert.Equal(t, "then move on", sentences[1].Text)
+	assert.Equal(t, 5*time.Second, sentences[1].Start)
+}
+
+func TestGroupParagraphs_SplitsOnLongPause(t *testing.T) {
+	sentences := []CleanSentence{
+		{Start: 0, End: time.Second, Text: "One."},
+		{Start: time.Second, End: 2 * time.Second, Text: "Two."},
+		{Start: 10 * time.Second, End: 11 * time.Second, Text: "Three."},
+	}
+
+	paragraphs := GroupParagraphs(sentences)
+	require.Len(t, paragraphs, 2)
+	assert.Len(t, paragraphs[0].Sentences, 2)
+	assert.Equal(t, 2*time.Second, paragraphs[0].End)
+	assert.Equal(t, "Three.", paragraphs[1].Sentences[0].Text)
+}
+
+func TestNeedsPunctuation(t *testing.T) {
+	assert.True(t, NeedsPunctuation([]CleanSentence{{Text: "so we start"}, {Text: "and then"}}))
+	assert.False(t, NeedsPunctuation([]CleanSentence{{Text: "We start."}, {Text: "and then"}}))
+	assert.False(t, NeedsPunctuation(nil))
+}
+
+func TestRestorePunctuation_BatchesAndPreservesTiming(t *testing.T) {
+	sentences := make([]CleanSentence, 0, punctuationBatchSize+5)
+	for i := range punctuationBatchSize + 5 {
+		start := time.Duration(i) * time.Second
+		sentences = append(sentences, CleanSentence{Start: start, End: start + time.Second, Text: "word"})
+	}
+
+	punctuator := &stubPunctuator{}
+	restored, usage, err := RestorePunctuation(context.Background(), punctuator, sentences)
+	require.NoError(t, err)
+
+	require.Len(t, punctuator.batches, 2)
+	assert.Len(t, punctuator.batches[0], punctuationBatchSize)
+	assert.Len(t, punctuator.batches[1], 5)
+	assert.Equal(t, 20, usage.TokensUsed)
+	assert.Equal(t, "stub", usage.Model)
+
+	require.Len(t, restored, len(sentences))
+	assert.Equal(t, "Word.", restored[3].Text)
+	assert.Equal(t, 3*time.Second, restored[3].Start)
+	assert.Equal(t, 4*time.Second, restored[3].End)
+}
+
+func TestRestorePunctuation_RejectsMismatchedLineCount(t *testing.T) {
+	_, _, err := RestorePunctuation(context.Background(), mismatchedPunctuator{}, []CleanSentence{{Text: "a"}, {Text: "b"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected 2 lines")
+}
+
+type mismatchedPunctuator struct{}
+
+func (mismatchedPunctuator) Punctuate(context.Context, []string) (*AIPunctuation, error) {
+	return &AIPunctuation{Lines: []string{"only one."}}, nil
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/metrics"
+)
+
+// usageRecordTimeout bounds how long a ledger write may delay the AI response.
+const usageRecordTimeout = 5 * time.Second
+
+// maxUsageErrorLength truncates provider error messages stored in the ledger.
+const maxUsageErrorLength = 500
+
+// UsageRecord is a single provider call in the usage ledger.
+type UsageRecord struct {
+	Provider         string
+	Model            string
+	Operation        string
+	TranscriptID     string
+	ClientKey        string
+	PromptVersion    string
+	PromptTokens     int
+	CompletionTokens int
+	Latency          time.Duration
+	CostUSD          float64
+	Success          bool
+	Error            string
+}
+
+// UsageRecorder persists usage records.
+type UsageRecorder interface {
+	RecordUsage(ctx context.Context, record UsageRecord) error
+}
+
+// UsageRecorderFunc adapts a function to UsageRecorder.
+type UsageRecorderFunc func(ctx context.Context, record UsageRecord) error
+
+// RecordUsage calls f.
+func (f UsageRecorderFunc) RecordUsage(ctx context.Context, record UsageRecord) error {
+	return f(ctx, record)
+}
+
+// ModelPrice is the USD price per million prompt and completion tokens.
+type ModelPrice struct {
+	PromptPerMillion     float64
+	CompletionPerMillion float64
+}
+
+// PriceTable maps model names, or model name prefixes, to prices.
+type PriceTable map[string]ModelPrice
+
+// DefaultPriceTable holds list prices for the models the built-in providers default to.
+var DefaultPriceTable = PriceTable{
+	"gpt-4":             {PromptPerMillion: 30, CompletionPerMillion: 60},
+	"gpt-4-turbo":       {PromptPerMillion: 10, CompletionPerMillion: 30},
+	"gpt-4o":            {PromptPerMillion: 2.5, CompletionPerMillion: 10},
+	"gpt-4o-mini":       {PromptPerMillion: 0.15, CompletionPerMillion: 0.6},
+	"gpt-3.5-turbo":     {PromptPerMillion: 0.5, CompletionPerMillion: 1.5},
+	"claude-3-5-sonnet": {PromptPerMillion: 3, CompletionPerMillion: 15},
+	"claude-3-5-haiku":  {PromptPerMillion: 0.8, CompletionPerMillion: 4},
+	"claude-3-opus":     {PromptPerMillion: 15, CompletionPerMillion: 75},
+	"claude-3-sonnet":   {PromptPerMillion: 3, CompletionPerMillion: 15},
+	"claude-3-haiku":    {PromptPerMillion: 0.25, CompletionPerMillion: 1.25},
+	"gemini-1.5-flash":  {PromptPerMillion: 0.075, CompletionPerMillion: 0.3},
+	"gemini-1.5-pro":    {PromptPerMillion: 1.25, CompletionPerMillion: 5},
+}
+
+// Merge returns a copy of t with overrides applied.
+func (t PriceTable) Merge(overrides PriceTable) PriceTable {
+	merged := make(PriceTable, len(t)+len(overrides))
+	for model, price := range t {
+		merged[model] = price
+	}
+	for model, price := range overrides {
+		merged[strings.ToLower(model)] = price
+	}
+	return merged
+}
+
+// Cost prices usage for a model. Dated or suffixed model names such as
+// "claude-3-5-sonnet-20241022" match the longest listed prefix; unknown models cost 0.
+func (t PriceTable) Cost(model string, usage TokenUsage) float64 {
+	model = strings.ToLower(model)
+	price, ok := t[model]
+	if !ok {
+		longest := -1
+		for name, candidate := range t {
+			if strings.HasPrefix(model, name) && len(name) > longest {
+				price, longest = candidate, len(name)
+			}
+		}
+		if longest < 0 {
+			return 0
+		}
+	}
+	return (float64(usage.PromptTokens)*price.PromptPerMillion +
+		float64(usage.CompletionTokens)*price.CompletionPerMillion) / 1e6
+}
+
+type transcriptIDContextKey struct{}
+
+// WithTranscriptID attributes AI calls made with ctx to a transcript in the usage ledger.
+func WithTranscriptID(ctx context.Context, transcriptID string) context.Context {
+	return context.WithValue(ctx, transcriptIDContextKey{}, transcriptID)
+}
+
+// TranscriptIDFromContext returns the transcript set by WithTranscriptID, if any.
+func TranscriptIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(transcriptIDContextKey{}).(string)
+	return id
+}
+
+type clientKeyContextKey struct{}
+
+// WithClientKey attributes AI calls made with ctx to an API client for the usage ledger and
+// per-key budgets.
+func WithClientKey(ctx context.Context, clientKey string) context.Context {
+	return context.WithValue(ctx, clientKeyContextKey{}, clientKey)
+}
+
+// ClientKeyFromContext returns the client set by WithClientKey, if any.
+func ClientKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(clientKeyContextKey{}).(string)
+	return key
+}
+
+// UsageMeter records every provider call, successful or not, with its token usage, latency
+// and cost.
+type UsageMeter struct {
+	recorder UsageRecorder
+	prices   PriceTable
+	now      func() time.Time
+}
+
+// NewUsageMeter creates a meter that prices calls with prices and stores them with recorder.
+func NewUsageMeter(recorder UsageRecorder, prices PriceTable) *UsageMeter {
+	return &UsageMeter{recorder: recorder, prices: prices, now: time.Now}
+}
+
+// Meter wraps a provider so its calls are recorded. Wrap it inside the rate limiter so calls
+// rejected locally, which never reach the provider, are not recorded.
+func (m *UsageMeter) Meter(provider NamedProvider) NamedProvider {
+	if m == nil || m.recorder == nil || provider.Provider == nil {
+		return provider
+	}
+	provider.Provider = &meteredProvider{next: provider.Provider, meter: m, name: provider.Name, model: provider.Model}
+	return provider
+}
+
+// aiResult is implemented by every AI result type.
+type aiResult interface {
+	usage() TokenUsage
+	model() string
+}
+
+// resultUsage falls back to TokensUsed for providers that do not split their usage.
+func resultUsage(usage TokenUsage, tokensUsed int) TokenUsage {
+	if usage.Total() == 0 && tokensUsed > 0 {
+		return TokenUsage{PromptTokens: tokensUsed}
+	}
+	return usage
+}
+
+func (r *AISummary) usage() TokenUsage {
+	if r == nil {
+		return TokenUsage{}
+	}
+	return resultUsage(r.Usage, r.TokensUsed)
+}
+
+func (r *AISummary) model() string {
+	if r == nil {
+		return ""
+	}
+	return r.Model
+}
+
+func (r *AIExtraction) usage() TokenUsage {
+	if r == nil {
+		return TokenUsage{}
+	}
+	return resultUsage(r.Usage, r.TokensUsed)
+}
+
+func (r *AIExtraction) model() string {
+	if r == nil {
+		return ""
+	}
+	return r.Model
+}
+
+func (r *AITranslation) usage() TokenUsage {
+	if r == nil {
+		return TokenUsage{}
+	}
+	return resultUsage(r.Usage, r.TokensUsed)
+}
+
+func (r *AITranslation) model() string {
+	if r == nil {
+		return ""
+	}
+	return r.Model
+}
+
+func (r *AIAnswer) usage() TokenUsage {
+	if r == nil {
+		return TokenUsage{}
+	}
+	return resultUsage(r.Usage, r.TokensUsed)
+}
+
+func (r *AIAnswer) model() string {
+	if r == nil {
+		return ""
+	}
+	return r.Model
+}
+
+func (r *AIPunctuation) usage() TokenUsage {
+	if r == nil {
+		return TokenUsage{}
+	}
+	return resultUsage(r.Usage, r.TokensUsed)
+}
+
+func (r *AIPunctuation) model() string {
+	if r == nil {
+		return ""
+	}
+	return r.Model
+}
+
+func (r *AISpeakerTurns) usage() TokenUsage {
+	if r == nil {
+		return TokenUsage{}
+	}
+	return resultUsage(r.Usage, r.TokensUsed)
+}
+
+func (r *AISpeakerTurns) model() string {
+	if r == nil {
+		return ""
+	}
+	return r.Model
+}
+
+// meteredProvider records the calls of an AIProvider.
+type meteredProvider struct {
+	next  AIProvider
+	meter *UsageMeter
+	name  string
+	model string
+}
+
+// Unwrap returns the metered provider.
+func (p *meteredProvider) Unwrap() AIProvider {
+	return p.next
+}
+
+func meterCall[T aiResult](ctx context.Context, p *meteredProvider, operation string, call func() (T, error)) (T, error) {
+	start := p.meter.now()
+	result, err := call()
+
+	record := UsageRecord{
+		Provider:     p.name,
+		Model:        p.model,
+		Operation:    operation,
+		TranscriptID: TranscriptIDFromContext(ctx),
+		ClientKey:    ClientKeyFromContext(ctx),
+		Latency:      p.meter.now().Sub(start),
+		Success:      err == nil,
+	}
+	if operation != OperationTranslate {
+		record.PromptVersion = promptsFromContext(ctx).Version
+	}
+	if err != nil {
+		record.Error = truncateUTF8(err.Error(), maxUsageErrorLength)
+	} else {
+		usage := result.usage()
+		if model := result.model(); model != "" {
+			record.Model = model
+		}
+		record.PromptTokens = usage.PromptTokens
+		record.CompletionTokens = usage.CompletionTokens
+		record.CostUSD = p.meter.prices.Cost(record.Model, usage)
+	}
+
+	observeAICall(record)
+
+	// The ledger must not fail or outlive the call it describes by much.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), usageRecordTimeout)
+	defer cancel()
+	if recordErr := p.meter.recorder.RecordUsage(recordCtx, record); recordErr != nil {
+		slog.WarnContext(ctx, "failed to record AI usage", "provider", p.name, "operation", operation, "error", recordErr)
+	}
+
+	return result, err
+}
+
+func (p *meteredProvider) Summarize(ctx context.Context, text string, summaryType string) (*AISummary, error) {
+	return meterCall(ctx, p, OperationSummarize, func() (*AISummary, error) {
+		return p.next.Summarize(ctx, text, summaryType)
+	})
+}
+
+func (p *meteredProvider) Extract(ctx context.Context, text string, extractionType string) (*AIExtraction, error) {
+	return meterCall(ctx, p, OperationExtract, func() (*AIExtraction, error) {
+		return p.next.Extract(ctx, text, extractionType)
+	})
+}
+
+func (p *meteredProvider) Translate(ctx context.Context, text string, targetLang string) (*AITranslation, error) {
+	return meterCall(ctx, p, OperationTranslate, func() (*AITranslation, error) {
+		return p.next.Translate(ctx, text, targetLang)
+	})
+}
+
+func (p *meteredProvider) Answer(ctx context.Context, text string, question string) (*AIAnswer, error) {
+	return meterCall(ctx, p, OperationAnswer, func() (*AIAnswer, error) {
+		return p.next.Answer(ctx, text, question)
+	})
+}
+
+func (p *meteredProvider) Punctuate(ctx context.Context, lines []string) (*AIPunctuation, error) {
+	return meterCall(ctx, p, OperationPunctuate, func() (*AIPunctuation, error) {
+		return p.next.Punctuate(ctx, lines)
+	})
+}
+
+func (p *meteredProvider) LabelSpeakers(ctx context.Context, lines []string, knownSpeakers []string) (*AISpeakerTurns, error) {
+	return meterCall(ctx, p, OperationLabelSpeakers, func() (*AISpeakerTurns, error) {
+		return p.next.LabelSpeakers(ctx, lines, knownSpeakers)
+	})
+}
+
+// observeAICall exports a call to the Prometheus metrics.
+func observeAICall(record UsageRecord) {
+	outcome := "ok"
+	if !record.Success {
+		outcome = "error"
+	}
+	metrics.AICalls.WithLabelValues(record.Provider, record.Model, record.Operation, outcome).Inc()
+	metrics.AICallDuration.WithLabelValues(record.Provider, record.Model, record.Operation).Observe(record.Latency.Seconds())
+	metrics.AITokens.WithLabelValues(record.Provider, record.Model, record.Operation, "prompt").Add(float64(record.PromptTokens))
+	metrics.AITokens.WithLabelValues(record.Provider, record.Model, record.Operation, "completion").Add(float64(record.CompletionTokens))
+}
+
+// truncateUTF8 cuts s to at most maxBytes without splitting a multi-byte character.
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
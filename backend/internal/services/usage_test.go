@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/metrics"
+)
+
+type recordedUsage struct {
+	records []UsageRecord
+	err     error
+}
+
+func (r *recordedUsage) RecordUsage(_ context.Context, record UsageRecord) error {
+	r.records = append(r.records, record)
+	return r.err
+}
+
+// summaryUsageProvider returns a summary with a fixed token split from a dated model.
+type summaryUsageProvider struct {
+	fakeProvider
+}
+
+func (p *summaryUsageProvider) Summarize(context.Context, string, string) (*AISummary, error) {
+	usage := TokenUsage{PromptTokens: 1000, CompletionTokens: 200}
+	return &AISummary{Model: "claude-3-5-sonnet-20241022", TokensUsed: usage.Total(), Usage: usage}, nil
+}
+
+func TestUsageMeter_RecordsSuccessfulCall(t *testing.T) {
+	recorder := &recordedUsage{}
+	meter := NewUsageMeter(recorder, DefaultPriceTable)
+	provider := meter.Meter(NamedProvider{Name: "anthropic", Model: "claude-3-5-sonnet", Provider: &summaryUsageProvider{}})
+
+	calls := metrics.AICalls.WithLabelValues("anthropic", "claude-3-5-sonnet-20241022", OperationSummarize, "ok")
+	completionTokens := metrics.AITokens.WithLabelValues("anthropic", "claude-3-5-sonnet-20241022", OperationSummarize, "completion")
+	callsBefore, tokensBefore := testutil.ToFloat64(calls), testutil.ToFloat64(completionTokens)
+
+	ctx := WithTranscriptID(context.Background(), "transcript-1")
+	summary, err := provider.Provider.Summarize(ctx, "text", "brief")
+	require.NoError(t, err)
+	assert.Equal(t, 1200, summary.TokensUsed)
+
+	require.Len(t, recorder.records, 1)
+	record := recorder.records[0]
+	assert.Equal(t, "anthropic", record.Provider)
+	assert.Equal(t, "claude-3-5-sonnet-20241022", record.Model)
+	assert.Equal(t, OperationSummarize, record.Operation)
+	assert.Equal(t, "transcript-1", record.TranscriptID)
+	assert.Equal(t, DefaultPromptVersion, record.PromptVersion)
+	assert.Equal(t, 1000, record.PromptTokens)
+	assert.Equal(t, 200, record.CompletionTokens)
+	assert.True(t, record.Success)
+	assert.Empty(t, record.Error)
+	assert.InDelta(t, 0.006, record.CostUSD, 1e-9)
+	assert.Equal(t, callsBefore+1, testutil.ToFloat64(calls))
+	assert.Equal(t, tokensBefore+200, testutil.ToFloat64(completionTokens))
+}
+
+func TestUsageMeter_RecordsFailedCall(t *testing.T) {
+	recorder := &recordedUsage{}
+	meter := NewUsageMeter(recorder, DefaultPriceTable)
+	provider := meter.Meter(NamedProvider{Name: "openai", Model: "gpt-4", Provider: &fakeProvider{err: ErrAIRateLimited}})
+
+	_, err := provider.Provider.Extract(context.Background(), "text", "code")
+	require.ErrorIs(t, err, ErrAIRateLimited)
+
+	require.Len(t, recorder.records, 1)
+	record := recorder.records[0]
+	assert.Equal(t, "gpt-4", record.Model)
+	assert.Equal(t, OperationExtract, record.Operation)
+	assert.False(t, record.Success)
+	assert.Contains(t, record.Error, "rate limit")
+	assert.Zero(t, record.CostUSD)
+	assert.Empty(t, record.TranscriptID)
+}
+
+func TestUsageMeter_TruncatesErrorOnRuneBoundary(t *testing.T) {
+	recorder := &recordedUsage{}
+	meter := NewUsageMeter(recorder, DefaultPriceTable)
+	message := "x" + strings.Repeat("é", maxUsageErrorLength)
+	provider := meter.Meter(NamedProvider{Name: "openai", Model: "gpt-4", Provider: &fakeProvider{err: errors.New(message)}})
+
+	_, err := provider.Provider.Extract(context.Background(), "text", "code")
+	require.Error(t, err)
+
+	require.Len(t, recorder.records, 1)
+	stored := recorder.records[0].Error
+	assert.True(t, utf8.ValidString(stored))
+	assert.LessOrEqual(t, len(stored), maxUsageErrorLength)
+	assert.True(t, strings.HasPrefix(message, stored))
+	assert.Len(t, stored, maxUsageErrorLength-1, "the split character is dropped whole")
+}
+
+func TestUsageMeter_LedgerFailureDoesNotFailCall(t *testing.T) {
+	recorder := &recordedUsage{err: errors.New("database down")}
+	meter := NewUsageMeter(recorder, DefaultPriceTable)
+	provider := meter.Meter(NamedProvider{Name: "openai", Model: "gpt-4", Provider: &fakeProvider{model: "gpt-4"}})
+
+	_, err := provider.Provider.Answer(context.Background(), "text", "question?")
+	require.NoError(t, err)
+	assert.Len(t, recorder.records, 1)
+}
+
+func TestUsageMeter_MeasuresLatency(t *testing.T) {
+	recorder := &recordedUsage{}
+	meter := NewUsageMeter(recorder, DefaultPriceTable)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	meter.now = func() time.Time {
+		now = now.Add(1500 * time.Millisecond)
+		return now
+	}
+	provider := meter.Meter(NamedProvider{Name: "openai", Model: "gpt-4", Provider: &fakeProvider{model: "gpt-4"}})
+
+	_, err := provider.Provider.Punctuate(context.Background(), []string{"hello"})
+	require.NoError(t, err)
+
+	require.Len(t, recorder.records, 1)
+	assert.Equal(t, 1500*time.Millisecond, recorder.records[0].Latency)
+	assert.Equal(t, OperationPunctuate, recorder.records[0].Operation)
+}
+
+func TestPriceTable_Cost(t *testing.T) {
+	prices := DefaultPriceTable.Merge(PriceTable{"GPT-4o": {PromptPerMillion: 5, CompletionPerMillion: 20}})
+	usage := TokenUsage{PromptTokens: 1_000_000, CompletionTokens: 100_000}
+
+	assert.InDelta(t, 7.0, prices.Cost("gpt-4o", usage), 1e-9)
+	// The longest matching prefix wins: gpt-4o-mini, not gpt-4o or gpt-4.
+	assert.InDelta(t, 0.21, prices.Cost("gpt-4o-mini-2024-07-18", usage), 1e-9)
+	assert.InDelta(t, 36.0, prices.Cost("gpt-4-0613", usage), 1e-9)
+	assert.Zero(t, prices.Cost("unknown-model", usage))
+	// Merge does not modify the receiver.
+	assert.Equal(t, 2.5, DefaultPriceTable["gpt-4o"].PromptPerMillion)
+}
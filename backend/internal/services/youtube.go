@@ -1,15 +1,22 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	youtube "github.com/kkdai/youtube/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/metrics"
+	"github.com/yourusername/yt-transcript-downloader/internal/tracing"
 )
 
 var videoIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{6,}$`)
@@ -45,6 +52,7 @@ type YouTubeService struct {
 	mu          sync.Mutex
 	lastRequest time.Time
 	minInterval time.Duration
+	lastSuccess atomic.Int64 // unix nanoseconds
 }
 
 // NewYouTubeService constructs a YouTubeService using the default youtube client.
@@ -56,7 +64,13 @@ func NewYouTubeService() *YouTubeService {
 }
 
 // GetVideoMetadata retrieves video metadata for the provided identifier or URL.
-func (s *YouTubeService) GetVideoMetadata(videoID string) (*VideoMetadata, error) {
+func (s *YouTubeService) GetVideoMetadata(ctx context.Context, videoID string) (metadata *VideoMetadata, err error) {
+	ctx, span := tracer.Start(ctx, "youtube.GetVideoMetadata", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("youtube.video_id", videoID)))
+	defer func() {
+		s.recordFetch("metadata", err)
+		tracing.EndSpan(span, err)
+	}()
 	if s == nil {
 		return nil, errors.New("youtube service is nil")
 	}
@@ -68,7 +82,7 @@ func (s *YouTubeService) GetVideoMetadata(videoID string) (*VideoMetadata, error
 
 	s.waitForRateLimit()
 
-	video, err := s.client.GetVideo(id)
+	video, err := s.client.GetVideoContext(ctx, id)
 	if err != nil {
 		return nil, classifyVideoError(err)
 	}
@@ -85,7 +99,13 @@ func (s *YouTubeService) GetVideoMetadata(videoID string) (*VideoMetadata, error
 // GetTranscript retrieves timed transcript lines for a video in the requested language.
 // If the requested language is unavailable it falls back to English, favouring manual tracks
 // before auto-generated ones.
-func (s *YouTubeService) GetTranscript(videoID, language string) ([]TranscriptLine, error) {
+func (s *YouTubeService) GetTranscript(ctx context.Context, videoID, language string) (lines []TranscriptLine, err error) {
+	ctx, span := tracer.Start(ctx, "youtube.GetTranscript", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("youtube.video_id", videoID), attribute.String("youtube.language", language)))
+	defer func() {
+		s.recordFetch("transcript", err)
+		tracing.EndSpan(span, err)
+	}()
 	if s == nil {
 		return nil, errors.New("youtube service is nil")
 	}
@@ -97,7 +117,7 @@ func (s *YouTubeService) GetTranscript(videoID, language string) ([]TranscriptLi
 
 	s.waitForRateLimit()
 
-	video, err := s.client.GetVideo(id)
+	video, err := s.client.GetVideoContext(ctx, id)
 	if err != nil {
 		return nil, classifyVideoError(err)
 	}
@@ -109,7 +129,7 @@ func (s *YouTubeService) GetTranscript(videoID, language string) ([]TranscriptLi
 
 	var lastErr error
 	for _, track := range tracks {
-		transcript, err := s.client.GetTranscript(video, track.LanguageCode)
+		transcript, err := s.client.GetTranscriptCtx(ctx, video, track.LanguageCode)
 		if err != nil {
 			if errors.Is(err, youtube.ErrTranscriptDisabled) {
 				lastErr = ErrTranscriptDisabled
@@ -185,6 +205,39 @@ func validateVideoID(id string) (string, error) {
 	return id, nil
 }
 
+// recordFetch counts a call in the metrics and notes when YouTube last answered.
+func (s *YouTubeService) recordFetch(operation string, err error) {
+	outcome := observeYouTubeFetch(operation, err)
+	if s != nil && outcome != "error" && outcome != "rate_limited" {
+		s.lastSuccess.Store(time.Now().UnixNano())
+	}
+}
+
+// observeYouTubeFetch counts a YouTube call by the error class it ended with and returns that
+// outcome label.
+func observeYouTubeFetch(operation string, err error) string {
+	outcome := "ok"
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrVideoNotFound):
+		outcome = "video_not_found"
+	case errors.Is(err, ErrVideoPrivate):
+		outcome = "video_private"
+	case errors.Is(err, ErrVideoAgeRestricted):
+		outcome = "video_age_restricted"
+	case errors.Is(err, ErrTranscriptDisabled):
+		outcome = "transcript_disabled"
+	case errors.Is(err, ErrTranscriptUnavailable):
+		outcome = "transcript_unavailable"
+	case errors.Is(err, ErrRateLimited):
+		outcome = "rate_limited"
+	default:
+		outcome = "error"
+	}
+	metrics.YouTubeFetches.WithLabelValues(operation, outcome).Inc()
+	return outcome
+}
+
 func classifyVideoError(err error) error {
 	if err == nil {
 		return nil
@@ -1,12 +1,16 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"testing"
 	"time"
 
 	youtube "github.com/kkdai/youtube/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/yourusername/yt-transcript-downloader/internal/metrics"
 )
 
 const testVideoID = "dQw4w9WgXcQ" // Public video: Rick Astley - Never Gonna Give You Up
@@ -21,7 +25,7 @@ func TestYouTubeService_GetVideoMetadata_Success(t *testing.T) {
 	service := NewYouTubeService()
 	service.minInterval = 0
 
-	meta, err := service.GetVideoMetadata(testVideoID)
+	meta, err := service.GetVideoMetadata(context.Background(), testVideoID)
 	if err != nil {
 		t.Fatalf("GetVideoMetadata returned error: %v", err)
 	}
@@ -50,11 +54,16 @@ func TestYouTubeService_GetVideoMetadata_Success(t *testing.T) {
 func TestYouTubeService_GetVideoMetadata_InvalidID(t *testing.T) {
 	service := NewYouTubeService()
 	service.minInterval = 0
+	failures := metrics.YouTubeFetches.WithLabelValues("metadata", "error")
+	before := testutil.ToFloat64(failures)
 
-	_, err := service.GetVideoMetadata("invalid!!!")
+	_, err := service.GetVideoMetadata(context.Background(), "invalid!!!")
 	if err == nil {
 		t.Fatal("expected error for invalid video id")
 	}
+	if got := testutil.ToFloat64(failures); got != before+1 {
+		t.Fatalf("expected the failed fetch to be counted, got %v after %v", got, before)
+	}
 }
 
 func TestYouTubeService_GetVideoMetadata_ClientError(t *testing.T) {
@@ -64,7 +73,7 @@ func TestYouTubeService_GetVideoMetadata_ClientError(t *testing.T) {
 		HTTPClient: &http.Client{Transport: errorTransport{}},
 	}
 
-	_, err := service.GetVideoMetadata(testVideoID)
+	_, err := service.GetVideoMetadata(context.Background(), testVideoID)
 	if err == nil {
 		t.Fatal("expected error when client fails to fetch metadata")
 	}
@@ -129,7 +138,7 @@ func TestYouTubeService_GetTranscript_DefaultLanguage(t *testing.T) {
 	service := NewYouTubeService()
 	service.minInterval = 0
 
-	lines, err := service.GetTranscript(testVideoID, "")
+	lines, err := service.GetTranscript(context.Background(), testVideoID, "")
 	if err != nil {
 		t.Fatalf("GetTranscript returned error: %v", err)
 	}
@@ -163,7 +172,7 @@ func TestYouTubeService_GetTranscript_LanguageSelection(t *testing.T) {
 		t.Fatalf("expected first candidate to be id auto transcript, got %s %s", candidates[0].LanguageCode, candidates[0].Kind)
 	}
 
-	lines, err := service.GetTranscript(videoWithIndonesianTranscript, "id")
+	lines, err := service.GetTranscript(context.Background(), videoWithIndonesianTranscript, "id")
 	if err != nil {
 		t.Fatalf("GetTranscript returned error: %v", err)
 	}
@@ -181,7 +190,7 @@ func TestYouTubeService_GetTranscript_Missing(t *testing.T) {
 	service := NewYouTubeService()
 	service.minInterval = 0
 
-	_, err := service.GetTranscript(videoWithoutTranscript, "en")
+	_, err := service.GetTranscript(context.Background(), videoWithoutTranscript, "en")
 	if err == nil {
 		t.Fatal("expected error when transcript is missing")
 	}
@@ -194,7 +203,7 @@ func TestYouTubeService_GetTranscript_InvalidID(t *testing.T) {
 	service := NewYouTubeService()
 	service.minInterval = 0
 
-	if _, err := service.GetTranscript("!invalid!", "en"); err == nil {
+	if _, err := service.GetTranscript(context.Background(), "!invalid!", "en"); err == nil {
 		t.Fatal("expected error for invalid video id")
 	}
 }
@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup_RejectsInvalidEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"", "localhost:4318", "grpc://collector:4317"} {
+		_, err := Setup(context.Background(), Config{Endpoint: endpoint, SampleRatio: 1, ServiceName: "test"})
+		assert.ErrorContains(t, err, "invalid tracing endpoint", endpoint)
+	}
+}
+
+func TestSetup_InstallsProvider(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{Endpoint: "http://127.0.0.1:1", SampleRatio: 0, ServiceName: "test"})
+	require.NoError(t, err)
+	assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+
+	_, span := otel.Tracer("test").Start(context.Background(), "unsampled")
+	assert.False(t, span.SpanContext().IsSampled(), "a ratio of 0 drops new traces")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestEndSpan_RecordsError(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "failing")
+	EndSpan(span, errors.New("boom"))
+	_, span = provider.Tracer("test").Start(context.Background(), "ok")
+	EndSpan(span, nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "boom", spans[0].Status.Description)
+	assert.Len(t, spans[0].Events, 1)
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+}
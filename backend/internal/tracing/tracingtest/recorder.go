@@ -0,0 +1,43 @@
+// Package tracingtest records spans in memory so tests can assert on them.
+package tracingtest
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	installOnce sync.Once
+	exporter    *tracetest.InMemoryExporter
+)
+
+// Install makes the global tracer provider record every span in memory and returns the
+// recorder. Tracers created before the first call are delegated to it too, so it is installed
+// once per test binary and shared; tell tests apart by trace ID.
+func Install() *tracetest.InMemoryExporter {
+	installOnce.Do(func() {
+		exporter = tracetest.NewInMemoryExporter()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(
+			sdktrace.WithSyncer(exporter),
+			sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	return exporter
+}
+
+// Spans returns the ended spans of one trace, in the order they ended.
+func Spans(traceID trace.TraceID) tracetest.SpanStubs {
+	var spans tracetest.SpanStubs
+	for _, span := range Install().GetSpans() {
+		if span.SpanContext.TraceID() == traceID {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
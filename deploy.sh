@@ -75,7 +75,7 @@ echo "     - DB_HOST, DB_PORT, DB_NAME, DB_USER, DB_PASSWORD"
 echo "     - GOOGLE_API_KEY, AI_PROVIDER, AI_MODEL, AI_MAX_TOKENS, AI_TEMPERATURE"
 echo "     - NEXT_PUBLIC_BACKEND_URL (use http://localhost:8080 for internal)"
 echo "  2. Enable HTTPS in CapRover"
-echo "  3. Run database migrations: go run cmd/migrate/main.go up"
+echo "  3. Run database migrations: ./server migrate up (or set DB_AUTO_MIGRATE=true)"
 echo "  4. Configure custom domain (optional)"
 echo "  5. Test endpoints:"
 echo "     - Frontend: https://your-app.yourserver.com"
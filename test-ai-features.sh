@@ -2,6 +2,7 @@
 
 # AI Features Testing Script
 # This script tests all three AI features locally
+# Start the backend with AI_PROVIDER=mock to run it without API keys
 
 set -e
 